@@ -0,0 +1,187 @@
+/* add the rewrite rules of destination repository for replication policy */
+ALTER TABLE replication_policy ADD COLUMN dest_repo_rules text;
+
+/* add the platforms of manifest lists to be replicated for replication policy */
+ALTER TABLE replication_policy ADD COLUMN platforms text;
+
+/* add the states of chunked blob uploads for replication task, used to resume the uploads when the job is retried */
+ALTER TABLE replication_task ADD COLUMN upload_state text;
+
+/* add the concurrency and bandwidth limits for replication policy and registry */
+ALTER TABLE replication_policy ADD COLUMN max_concurrent_tasks int NOT NULL DEFAULT 0;
+ALTER TABLE replication_policy ADD COLUMN max_bytes_per_second bigint NOT NULL DEFAULT 0;
+ALTER TABLE registry ADD COLUMN max_concurrent_tasks int NOT NULL DEFAULT 0;
+ALTER TABLE registry ADD COLUMN max_bytes_per_second bigint NOT NULL DEFAULT 0;
+
+/* add the source and destination resources of replication task, used to reschedule the task when retrying the execution */
+ALTER TABLE replication_task ADD COLUMN resources text;
+
+/* add the statistics of the data moved by replication tasks and executions */
+ALTER TABLE replication_task ADD COLUMN bytes_transferred bigint NOT NULL DEFAULT 0;
+ALTER TABLE replication_task ADD COLUMN blobs_copied int NOT NULL DEFAULT 0;
+ALTER TABLE replication_task ADD COLUMN blobs_skipped int NOT NULL DEFAULT 0;
+ALTER TABLE replication_task ADD COLUMN manifest_digests text;
+ALTER TABLE replication_task ADD COLUMN throughput bigint NOT NULL DEFAULT 0;
+ALTER TABLE replication_execution ADD COLUMN bytes_transferred bigint NOT NULL DEFAULT 0;
+ALTER TABLE replication_execution ADD COLUMN blobs_copied int NOT NULL DEFAULT 0;
+ALTER TABLE replication_execution ADD COLUMN blobs_skipped int NOT NULL DEFAULT 0;
+ALTER TABLE replication_execution ADD COLUMN manifests int NOT NULL DEFAULT 0;
+ALTER TABLE replication_execution ADD COLUMN throughput bigint NOT NULL DEFAULT 0;
+
+/* add the setting to verify the digests of the replicated resources on the destination registry */
+ALTER TABLE replication_policy ADD COLUMN verify_digest boolean NOT NULL DEFAULT FALSE;
+
+/* add the settings of the metadata replicated along with the images, e.g. labels, descriptions and signatures */
+ALTER TABLE replication_policy ADD COLUMN metadata_sync text;
+
+/* add the health check history of registries */
+CREATE TABLE registry_health (
+    id SERIAL PRIMARY KEY NOT NULL,
+    registry_id int NOT NULL,
+    status varchar(16),
+    previous_status varchar(16),
+    latency bigint NOT NULL DEFAULT 0,
+    error text,
+    check_time timestamp default CURRENT_TIMESTAMP
+);
+CREATE INDEX registry_health_registry_time ON registry_health (registry_id, check_time);
+
+/* add the warning of replication policy set when its source or destination registry is unhealthy */
+ALTER TABLE replication_policy ADD COLUMN health_warning text;
+
+/* add the quotas of the storage and tag count overridden by projects */
+CREATE TABLE quota (
+    id SERIAL PRIMARY KEY NOT NULL,
+    project_id int NOT NULL,
+    storage_hard bigint NOT NULL DEFAULT -1,
+    storage_soft bigint NOT NULL DEFAULT -1,
+    count_hard bigint NOT NULL DEFAULT -1,
+    count_soft bigint NOT NULL DEFAULT -1,
+    creation_time timestamp default CURRENT_TIMESTAMP,
+    update_time timestamp default CURRENT_TIMESTAMP,
+    CONSTRAINT unique_quota_project UNIQUE (project_id)
+);
+
+/* add the artifacts, the blobs they reference and the sizes of blobs to calculate the quota usage of projects */
+CREATE TABLE artifact (
+    id SERIAL PRIMARY KEY NOT NULL,
+    project_id int NOT NULL,
+    repo varchar(255) NOT NULL,
+    tag varchar(255) NOT NULL DEFAULT '',
+    digest varchar(255) NOT NULL,
+    creation_time timestamp default CURRENT_TIMESTAMP,
+    CONSTRAINT unique_artifact UNIQUE (repo, tag, digest)
+);
+CREATE INDEX artifact_project_id ON artifact (project_id);
+
+CREATE TABLE artifact_blob (
+    id SERIAL PRIMARY KEY NOT NULL,
+    digest_af varchar(255) NOT NULL,
+    digest_blob varchar(255) NOT NULL,
+    creation_time timestamp default CURRENT_TIMESTAMP,
+    CONSTRAINT unique_artifact_blob UNIQUE (digest_af, digest_blob)
+);
+
+CREATE TABLE blob (
+    id SERIAL PRIMARY KEY NOT NULL,
+    digest varchar(255) NOT NULL,
+    content_type varchar(255),
+    size bigint NOT NULL DEFAULT 0,
+    creation_time timestamp default CURRENT_TIMESTAMP,
+    CONSTRAINT unique_blob_digest UNIQUE (digest)
+);
+
+/* add the storage and tag count reserved by the pushes in progress, so the concurrent pushes can't exceed the quota together */
+CREATE TABLE quota_reservation (
+    id SERIAL PRIMARY KEY NOT NULL,
+    project_id int NOT NULL,
+    storage bigint NOT NULL DEFAULT 0,
+    count bigint NOT NULL DEFAULT 0,
+    creation_time timestamp default CURRENT_TIMESTAMP
+);
+CREATE INDEX quota_reservation_project_id ON quota_reservation (project_id);
+
+/* add the state of the backfill of the artifacts pushed before the quotas existed, the backfill runs only once */
+CREATE TABLE artifact_backfill (
+    id SERIAL PRIMARY KEY NOT NULL,
+    status varchar(16) NOT NULL,
+    update_time timestamp default CURRENT_TIMESTAMP
+);
+INSERT INTO artifact_backfill (status) VALUES ('pending');
+
+/* add the tag retention policies of projects and the histories of their executions */
+CREATE TABLE retention_policy (
+    id SERIAL PRIMARY KEY NOT NULL,
+    project_id int NOT NULL,
+    rules text,
+    cron varchar(64),
+    schedule_job_id varchar(64),
+    creation_time timestamp default CURRENT_TIMESTAMP,
+    update_time timestamp default CURRENT_TIMESTAMP,
+    CONSTRAINT unique_retention_policy_project UNIQUE (project_id)
+);
+
+CREATE TABLE retention_execution (
+    id SERIAL PRIMARY KEY NOT NULL,
+    project_id int NOT NULL,
+    trigger varchar(64),
+    dry_run boolean DEFAULT false,
+    start_time timestamp default CURRENT_TIMESTAMP
+);
+CREATE INDEX retention_execution_project_id ON retention_execution (project_id);
+
+CREATE TABLE retention_task (
+    id SERIAL PRIMARY KEY NOT NULL,
+    execution_id int NOT NULL,
+    repository varchar(255) NOT NULL,
+    job_id varchar(64),
+    status varchar(32),
+    total int NOT NULL DEFAULT 0,
+    retained int NOT NULL DEFAULT 0,
+    deleted text,
+    start_time timestamp,
+    end_time timestamp
+);
+CREATE INDEX retention_task_execution_id ON retention_task (execution_id);
+
+/* add the immutable tag rules of projects */
+CREATE TABLE immutable_tag_rule (
+    id SERIAL PRIMARY KEY NOT NULL,
+    project_id int NOT NULL,
+    repositories varchar(255),
+    tags varchar(255),
+    creation_time timestamp default CURRENT_TIMESTAMP,
+    update_time timestamp default CURRENT_TIMESTAMP
+);
+CREATE INDEX immutable_tag_rule_project_id ON immutable_tag_rule (project_id);
+
+/* add the webhook endpoints of projects and the deliveries of events to them */
+CREATE TABLE webhook_endpoint (
+    id SERIAL PRIMARY KEY NOT NULL,
+    project_id int NOT NULL,
+    name varchar(255) NOT NULL,
+    address varchar(512) NOT NULL,
+    secret varchar(512),
+    event_types text,
+    skip_cert_verify boolean DEFAULT false,
+    enabled boolean DEFAULT true,
+    creator varchar(255),
+    creation_time timestamp default CURRENT_TIMESTAMP,
+    update_time timestamp default CURRENT_TIMESTAMP,
+    CONSTRAINT unique_webhook_endpoint_name UNIQUE (project_id, name)
+);
+
+CREATE TABLE webhook_delivery (
+    id SERIAL PRIMARY KEY NOT NULL,
+    endpoint_id int NOT NULL,
+    event_type varchar(64) NOT NULL,
+    payload text,
+    job_id varchar(64),
+    status varchar(32),
+    attempts int NOT NULL DEFAULT 0,
+    status_code int NOT NULL DEFAULT 0,
+    error text,
+    creation_time timestamp default CURRENT_TIMESTAMP,
+    update_time timestamp default CURRENT_TIMESTAMP
+);
+CREATE INDEX webhook_delivery_endpoint_id ON webhook_delivery (endpoint_id);
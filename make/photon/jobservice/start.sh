@@ -2,5 +2,8 @@
 if [ -d /var/log/jobs ]; then
     chown -R 10000:10000 /var/log/jobs/
 fi
+if [ -d /var/lib/replication/trust ]; then
+    chown -R 10000:10000 /var/lib/replication/trust/
+fi
 sudo -E -u \#10000 "/harbor/harbor_jobservice" "-c" "/etc/jobservice/config.yml"
 
@@ -185,6 +185,7 @@ def parse_yaml_config(config_file_path):
     # jobservice config
     js_config = configs.get('jobservice') or {}
     config_dict['max_job_workers'] = js_config["max_job_workers"]
+    config_dict['replication_trust_passphrase'] = js_config.get("replication_trust_passphrase") or ''
     config_dict['jobservice_secret'] = generate_random_string(16)
 
 
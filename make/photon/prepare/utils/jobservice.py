@@ -19,6 +19,8 @@ def prepare_job_service(config_dict):
     # Job log is stored in data dir
     job_log_dir = os.path.join('/data', "job_logs")
     prepare_config_dir(job_log_dir)
+    # The signing keys used to replicate the content trust signatures are stored in data dir
+    prepare_config_dir(os.path.join('/data', "replication_trust"))
     # Render Jobservice env
     render_jinja(
         job_service_env_template_path,
@@ -14,7 +14,10 @@ import (
 	"strings"
 
 	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
 	hlog "github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/pkg/webhook"
 	"github.com/goharbor/harbor/src/replication"
 	rep_event "github.com/goharbor/harbor/src/replication/event"
 )
@@ -83,6 +86,21 @@ func director(target *url.URL, cred *Credential, req *http.Request) {
 	}
 }
 
+// publish the webhook event of the chart upload built from the replication event
+func publishChartUploadEvent(e *rep_event.Event) {
+	if e.Resource == nil || e.Resource.Metadata == nil || e.Resource.Metadata.Repository == nil ||
+		len(e.Resource.Metadata.Vtags) == 0 {
+		return
+	}
+	namespace, chart := utils.ParseRepository(e.Resource.Metadata.Repository.Name)
+	webhook.Publish(&webhook.Event{
+		Type:    models.WebhookEventUploadChart,
+		Project: namespace,
+		Chart:   chart,
+		Version: e.Resource.Metadata.Vtags[0],
+	})
+}
+
 // Modify the http response
 func modifyResponse(res *http.Response) error {
 	// Upload chart success, then to the notification to replication handler
@@ -92,7 +110,10 @@ func modifyResponse(res *http.Response) error {
 		chartUploadEvent := res.Request.Context().Value(common.ChartUploadCtxKey)
 		e, ok := chartUploadEvent.(*rep_event.Event)
 		if !ok {
-			hlog.Error("failed to convert chart upload context into replication event.")
+			// the provenance file uploading has no event context
+			if chartUploadEvent != nil {
+				hlog.Error("failed to convert chart upload context into replication event.")
+			}
 		} else {
 			// Todo: it used as the replacement of webhook, will be removed when webhook to be introduced.
 			go func() {
@@ -100,6 +121,7 @@ func modifyResponse(res *http.Response) error {
 					hlog.Errorf("failed to handle event: %v", err)
 				}
 			}()
+			publishChartUploadEvent(e)
 		}
 
 	}
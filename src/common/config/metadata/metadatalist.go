@@ -147,5 +147,10 @@ var (
 		{Name: common.WithNotary, Scope: SystemScope, Group: BasicGroup, EnvKey: "WITH_NOTARY", DefaultValue: "false", ItemType: &BoolType{}, Editable: true},
 		// the unit of expiration is minute, 43200 minutes = 30 days
 		{Name: common.RobotTokenDuration, Scope: UserScope, Group: BasicGroup, EnvKey: "ROBOT_TOKEN_DURATION", DefaultValue: "43200", ItemType: &IntType{}, Editable: true},
+		// the default quotas of projects, the unit of storage is byte and -1 means unlimited
+		{Name: common.QuotaStorageHard, Scope: UserScope, Group: BasicGroup, EnvKey: "QUOTA_STORAGE_HARD", DefaultValue: "-1", ItemType: &Int64Type{}, Editable: true},
+		{Name: common.QuotaStorageSoft, Scope: UserScope, Group: BasicGroup, EnvKey: "QUOTA_STORAGE_SOFT", DefaultValue: "-1", ItemType: &Int64Type{}, Editable: true},
+		{Name: common.QuotaCountHard, Scope: UserScope, Group: BasicGroup, EnvKey: "QUOTA_COUNT_HARD", DefaultValue: "-1", ItemType: &Int64Type{}, Editable: true},
+		{Name: common.QuotaCountSoft, Scope: UserScope, Group: BasicGroup, EnvKey: "QUOTA_COUNT_SOFT", DefaultValue: "-1", ItemType: &Int64Type{}, Editable: true},
 	}
 )
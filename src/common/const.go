@@ -137,6 +137,11 @@ const (
 	AuthProxyUserNamePrefix = "tokenreview$"
 	CoreConfigPath          = "/api/internal/configurations"
 	RobotTokenDuration      = "robot_token_duration"
+	// the default quotas of projects, the negative value means unlimited
+	QuotaStorageHard = "quota_storage_hard"
+	QuotaStorageSoft = "quota_storage_soft"
+	QuotaCountHard   = "quota_count_hard"
+	QuotaCountSoft   = "quota_count_soft"
 
 	OIDCCallbackPath = "/c/oidc/callback"
 	OIDCLoginPath    = "/c/oidc/login"
@@ -15,6 +15,8 @@
 package dao
 
 import (
+	"time"
+
 	"github.com/astaxie/beego/orm"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils/log"
@@ -107,3 +109,44 @@ func CountPull(repoName string) (int64, error) {
 	}
 	return num, nil
 }
+
+// GetLastPushTimes returns the time when each tag of the repository was pushed last time,
+// keyed by the tag names, the tags which have no push log are absent from the map
+func GetLastPushTimes(repoName string) (map[string]time.Time, error) {
+	logs := []*models.AccessLog{}
+	sql := `select repo_tag, max(op_time) as op_time from access_log
+		where repo_name = ? and operation = 'push'
+		group by repo_tag`
+	if _, err := GetOrmer().Raw(sql, repoName).QueryRows(&logs); err != nil {
+		return nil, err
+	}
+	times := map[string]time.Time{}
+	for _, l := range logs {
+		times[l.RepoTag] = l.OpTime
+	}
+	return times, nil
+}
+
+// GetLastPullTime returns the time when the tag of the repository was pulled last time,
+// the zero value of time is returned if no pull log found
+func GetLastPullTime(repoName, tag string) (time.Time, error) {
+	return getLastOperationTime(repoName, tag, "pull")
+}
+
+func getLastOperationTime(repoName, tag, operation string) (time.Time, error) {
+	logs := []models.AccessLog{}
+	_, err := GetOrmer().QueryTable(&models.AccessLog{}).
+		Filter("repo_name", repoName).
+		Filter("repo_tag", tag).
+		Filter("operation", operation).
+		OrderBy("-op_time").
+		Limit(1).
+		All(&logs)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(logs) == 0 {
+		return time.Time{}, nil
+	}
+	return logs[0].OpTime, nil
+}
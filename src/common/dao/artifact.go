@@ -0,0 +1,180 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+const (
+	artifactBackfillPending = "pending"
+	artifactBackfillRunning = "running"
+	artifactBackfillDone    = "done"
+	// the backfill claimed by a core which stops refreshing it, e.g. the core is killed,
+	// can be claimed by others after the timeout
+	artifactBackfillTimeout = time.Hour
+)
+
+// AddArtifact records the artifact, the tag is moved to the new digest if it points to another one
+func AddArtifact(artifact *models.Artifact) error {
+	o := GetOrmer()
+	if len(artifact.Tag) > 0 {
+		if _, err := o.QueryTable(&models.Artifact{}).
+			Filter("Repo", artifact.Repo).
+			Filter("Tag", artifact.Tag).
+			Exclude("Digest", artifact.Digest).Delete(); err != nil {
+			return err
+		}
+	}
+	_, _, err := o.ReadOrCreate(artifact, "Repo", "Tag", "Digest")
+	return err
+}
+
+// GetArtifact returns the artifact that the tag of repository points to, nil is returned if it doesn't exist
+func GetArtifact(repo, tag string) (*models.Artifact, error) {
+	artifacts := []*models.Artifact{}
+	_, err := GetOrmer().QueryTable(&models.Artifact{}).
+		Filter("Repo", repo).
+		Filter("Tag", tag).All(&artifacts)
+	if err != nil {
+		return nil, err
+	}
+	if len(artifacts) == 0 {
+		return nil, nil
+	}
+	return artifacts[0], nil
+}
+
+// DeleteArtifactByTag deletes the artifact of the tag
+func DeleteArtifactByTag(repo, tag string) error {
+	_, err := GetOrmer().QueryTable(&models.Artifact{}).
+		Filter("Repo", repo).
+		Filter("Tag", tag).Delete()
+	return err
+}
+
+// DeleteArtifactsByRepo deletes all the artifacts of the repository
+func DeleteArtifactsByRepo(repo string) error {
+	_, err := GetOrmer().QueryTable(&models.Artifact{}).
+		Filter("Repo", repo).Delete()
+	return err
+}
+
+// GetRepositoriesWithoutArtifacts returns the repositories which have no artifact recorded
+func GetRepositoriesWithoutArtifacts() ([]*models.RepoRecord, error) {
+	repositories := []*models.RepoRecord{}
+	sql := `select * from repository as r
+		where not exists (select 1 from artifact as a where a.repo = r.name)`
+	if _, err := GetOrmer().Raw(sql).QueryRows(&repositories); err != nil {
+		return nil, err
+	}
+	return repositories, nil
+}
+
+// AddArtifactBlobs records the blobs referenced by the manifest, the existing ones are skipped
+func AddArtifactBlobs(digestAF string, digestBlobs ...string) error {
+	o := GetOrmer()
+	for _, digest := range digestBlobs {
+		if _, _, err := o.ReadOrCreate(&models.ArtifactBlob{
+			DigestAF:   digestAF,
+			DigestBlob: digest,
+		}, "DigestAF", "DigestBlob"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddBlob records the size of the blob, the size is updated if the blob exists
+func AddBlob(blob *models.Blob) error {
+	_, err := GetOrmer().InsertOrUpdate(blob, "digest")
+	return err
+}
+
+// GetBlob returns the blob with the digest, nil is returned if it doesn't exist
+func GetBlob(digest string) (*models.Blob, error) {
+	blobs := []*models.Blob{}
+	_, err := GetOrmer().QueryTable(&models.Blob{}).
+		Filter("Digest", digest).All(&blobs)
+	if err != nil {
+		return nil, err
+	}
+	if len(blobs) == 0 {
+		return nil, nil
+	}
+	return blobs[0], nil
+}
+
+// GetBlobsOfProject returns the digests in the list which are referenced by the artifacts of the project
+func GetBlobsOfProject(projectID int64, digests ...string) ([]string, error) {
+	result := []string{}
+	if len(digests) == 0 {
+		return result, nil
+	}
+	sql := fmt.Sprintf(`select distinct ab.digest_blob from artifact as a
+		join artifact_blob as ab on a.digest = ab.digest_af
+		where a.project_id = ? and ab.digest_blob in ( %s )`, paramPlaceholder(len(digests)))
+	params := []interface{}{projectID}
+	for _, digest := range digests {
+		params = append(params, digest)
+	}
+	if _, err := GetOrmer().Raw(sql, params...).QueryRows(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteArtifactsByDigest deletes the artifacts of the repository which point to the digest
+func DeleteArtifactsByDigest(repo, digest string) error {
+	_, err := GetOrmer().QueryTable(&models.Artifact{}).
+		Filter("Repo", repo).
+		Filter("Digest", digest).Delete()
+	return err
+}
+
+// ClaimArtifactBackfill claims the backfill of the artifacts pushed before the quotas existed, false is
+// returned if the backfill is done or it's being run by another core
+func ClaimArtifactBackfill() (bool, error) {
+	now := time.Now()
+	sql := `update artifact_backfill set status = ?, update_time = ?
+		where status = ? or (status = ? and update_time < ?)`
+	result, err := GetOrmer().Raw(sql, artifactBackfillRunning, now, artifactBackfillPending,
+		artifactBackfillRunning, now.Add(-artifactBackfillTimeout)).Exec()
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RefreshArtifactBackfill keeps the backfill claimed by the core running it
+func RefreshArtifactBackfill() error {
+	sql := `update artifact_backfill set update_time = ? where status = ?`
+	_, err := GetOrmer().Raw(sql, time.Now(), artifactBackfillRunning).Exec()
+	return err
+}
+
+// FinishArtifactBackfill marks the backfill done, so it won't run again
+func FinishArtifactBackfill() error {
+	sql := `update artifact_backfill set status = ?, update_time = ?`
+	_, err := GetOrmer().Raw(sql, artifactBackfillDone, time.Now()).Exec()
+	return err
+}
@@ -528,6 +528,52 @@ func TestCountPull(t *testing.T) {
 	}
 }
 
+func TestGetLastPushTimes(t *testing.T) {
+	repoName := currentProject.Name + "/push-time"
+	pushTimes, err := GetLastPushTimes(repoName)
+	if err != nil {
+		t.Errorf("Error occurred in GetLastPushTimes: %v", err)
+	}
+	if len(pushTimes) != 0 {
+		t.Errorf("The push times should be empty, actual: %v", pushTimes)
+	}
+
+	now := time.Now()
+	for _, l := range []struct {
+		tag    string
+		opTime time.Time
+	}{
+		{"latest", now.Add(-time.Hour)},
+		{"latest", now},
+		{"v1", now.Add(-time.Minute)},
+	} {
+		if err = AddAccessLog(models.AccessLog{
+			Username:  currentUser.Username,
+			ProjectID: currentProject.ProjectID,
+			RepoName:  repoName,
+			RepoTag:   l.tag,
+			Operation: "push",
+			OpTime:    l.opTime,
+		}); err != nil {
+			t.Errorf("Error occurred in AccessLog: %v", err)
+		}
+	}
+
+	pushTimes, err = GetLastPushTimes(repoName)
+	if err != nil {
+		t.Errorf("Error occurred in GetLastPushTimes: %v", err)
+	}
+	if len(pushTimes) != 2 {
+		t.Errorf("The count of push times does not match, expected: 2, actual: %d", len(pushTimes))
+	}
+	if pushTimes["latest"].Unix() != now.Unix() {
+		t.Errorf("The push time of latest does not match, expected: %v, actual: %v", now, pushTimes["latest"])
+	}
+	if pushTimes["v1"].Unix() != now.Add(-time.Minute).Unix() {
+		t.Errorf("The push time of v1 does not match, expected: %v, actual: %v", now.Add(-time.Minute), pushTimes["v1"])
+	}
+}
+
 /*
 func TestProjectExists(t *testing.T) {
 	var exists bool
@@ -0,0 +1,68 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// AddImmutableTagRule ...
+func AddImmutableTagRule(rule *models.ImmutableTagRule) (int64, error) {
+	return GetOrmer().Insert(rule)
+}
+
+// GetImmutableTagRule returns the rule specified by ID, nil is returned if it doesn't exist
+func GetImmutableTagRule(id int64) (*models.ImmutableTagRule, error) {
+	rule := &models.ImmutableTagRule{
+		ID: id,
+	}
+	if err := GetOrmer().Read(rule); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ListImmutableTagRules returns the immutable tag rules of the project
+func ListImmutableTagRules(projectID int64) ([]*models.ImmutableTagRule, error) {
+	rules := []*models.ImmutableTagRule{}
+	_, err := GetOrmer().QueryTable(&models.ImmutableTagRule{}).
+		Filter("ProjectID", projectID).OrderBy("ID").All(&rules)
+	return rules, err
+}
+
+// UpdateImmutableTagRule updates the patterns of the rule
+func UpdateImmutableTagRule(rule *models.ImmutableTagRule) error {
+	_, err := GetOrmer().Update(rule, "Repositories", "Tags", "UpdateTime")
+	return err
+}
+
+// DeleteImmutableTagRule ...
+func DeleteImmutableTagRule(id int64) error {
+	_, err := GetOrmer().Delete(&models.ImmutableTagRule{
+		ID: id,
+	})
+	return err
+}
+
+// DeleteImmutableTagRulesOfProject deletes all the immutable tag rules of the project
+func DeleteImmutableTagRulesOfProject(projectID int64) error {
+	_, err := GetOrmer().QueryTable(&models.ImmutableTagRule{}).
+		Filter("ProjectID", projectID).Delete()
+	return err
+}
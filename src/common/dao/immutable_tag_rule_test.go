@@ -0,0 +1,67 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmutableTagRule(t *testing.T) {
+	require.Nil(t, ClearTable("immutable_tag_rule"))
+
+	id, err := AddImmutableTagRule(&models.ImmutableTagRule{
+		ProjectID:    1,
+		Repositories: "library/**",
+		Tags:         "release-*",
+	})
+	require.Nil(t, err)
+	_, err = AddImmutableTagRule(&models.ImmutableTagRule{
+		ProjectID: 1,
+		Tags:      "latest",
+	})
+	require.Nil(t, err)
+
+	rule, err := GetImmutableTagRule(id)
+	require.Nil(t, err)
+	require.NotNil(t, rule)
+	assert.Equal(t, "library/**", rule.Repositories)
+
+	rules, err := ListImmutableTagRules(1)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(rules))
+	assert.Equal(t, id, rules[0].ID)
+
+	// update
+	rule.Tags = "v*"
+	require.Nil(t, UpdateImmutableTagRule(rule))
+	rule, err = GetImmutableTagRule(id)
+	require.Nil(t, err)
+	require.NotNil(t, rule)
+	assert.Equal(t, "v*", rule.Tags)
+
+	require.Nil(t, DeleteImmutableTagRule(id))
+	rule, err = GetImmutableTagRule(id)
+	require.Nil(t, err)
+	assert.Nil(t, rule)
+
+	require.Nil(t, DeleteImmutableTagRulesOfProject(1))
+	rules, err = ListImmutableTagRules(1)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(rules))
+}
@@ -0,0 +1,137 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+// GetQuota returns the quota overridden by the project, nil is returned if the project doesn't have one
+func GetQuota(projectID int64) (*models.Quota, error) {
+	quotas := []*models.Quota{}
+	_, err := GetOrmer().QueryTable(&models.Quota{}).
+		Filter("ProjectID", projectID).All(&quotas)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotas) == 0 {
+		return nil, nil
+	}
+	return quotas[0], nil
+}
+
+// SetQuota creates or updates the quota of the project
+func SetQuota(quota *models.Quota) error {
+	_, err := GetOrmer().InsertOrUpdate(quota, "project_id")
+	return err
+}
+
+// DeleteQuota deletes the quota of the project
+func DeleteQuota(projectID int64) error {
+	_, err := GetOrmer().QueryTable(&models.Quota{}).
+		Filter("ProjectID", projectID).Delete()
+	return err
+}
+
+// the reservations older than it are left by the interrupted pushes, e.g. core is restarted
+// during the push, and aren't counted in the usages any more
+const quotaReservationTTL = time.Hour
+
+// GetQuotaUsage returns the storage and tag count used by the project. The storage is the total size of
+// the distinct blobs and manifests referenced by the artifacts of the project, so the blobs shared by
+// multiple artifacts are counted once
+func GetQuotaUsage(projectID int64) (*models.QuotaUsage, error) {
+	return getQuotaUsage(GetOrmer(), projectID)
+}
+
+func getQuotaUsage(o orm.Ormer, projectID int64) (*models.QuotaUsage, error) {
+	usage := &models.QuotaUsage{}
+	sql := `select coalesce(sum(b.size), 0) from blob as b
+		where b.digest in (
+			select ab.digest_blob from artifact as a
+			join artifact_blob as ab on a.digest = ab.digest_af
+			where a.project_id = ?)`
+	if err := o.Raw(sql, projectID).QueryRow(&usage.Storage); err != nil {
+		return nil, err
+	}
+	count, err := o.QueryTable(&models.Artifact{}).
+		Filter("ProjectID", projectID).Exclude("Tag", "").Count()
+	if err != nil {
+		return nil, err
+	}
+	usage.Count = count
+	return usage, nil
+}
+
+// ReserveQuota reserves the storage and tag count for a push to the project. The usage of the project
+// plus the reservations of the other pushes in progress is passed to "check", and the reservation is
+// only added if it returns nil, otherwise the error of "check" is returned. The check and the reservation
+// are serialized per project with a transaction level advisory lock, so the concurrent pushes can't pass
+// the check together. Returns the ID of the reservation
+func ReserveQuota(projectID, storage, count int64, check func(usage *models.QuotaUsage) error) (int64, error) {
+	o := orm.NewOrm()
+	if err := o.Begin(); err != nil {
+		return 0, err
+	}
+	id, err := reserveQuota(o, projectID, storage, count, check)
+	if err != nil {
+		if e := o.Rollback(); e != nil {
+			log.Errorf("failed to rollback the quota reservation of project %d: %v", projectID, e)
+		}
+		return 0, err
+	}
+	if err = o.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func reserveQuota(o orm.Ormer, projectID, storage, count int64, check func(usage *models.QuotaUsage) error) (int64, error) {
+	// released when the transaction ends
+	if _, err := o.Raw(`select pg_advisory_xact_lock(?)`, projectID).Exec(); err != nil {
+		return 0, err
+	}
+	usage, err := getQuotaUsage(o, projectID)
+	if err != nil {
+		return 0, err
+	}
+	reserved := &models.QuotaUsage{}
+	sql := `select coalesce(sum(storage), 0) as storage, coalesce(sum(count), 0) as count
+		from quota_reservation where project_id = ? and creation_time > ?`
+	if err = o.Raw(sql, projectID, time.Now().Add(-quotaReservationTTL)).QueryRow(reserved); err != nil {
+		return 0, err
+	}
+	usage.Storage += reserved.Storage
+	usage.Count += reserved.Count
+	if err = check(usage); err != nil {
+		return 0, err
+	}
+	return o.Insert(&models.QuotaReservation{
+		ProjectID: projectID,
+		Storage:   storage,
+		Count:     count,
+	})
+}
+
+// DeleteQuotaReservation deletes the reservation, the expired reservations are cleaned up along with it
+func DeleteQuotaReservation(id int64) error {
+	sql := `delete from quota_reservation where id = ? or creation_time < ?`
+	_, err := GetOrmer().Raw(sql, id, time.Now().Add(-quotaReservationTTL)).Exec()
+	return err
+}
@@ -0,0 +1,175 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuota(t *testing.T) {
+	require.Nil(t, ClearTable("quota"))
+
+	q, err := GetQuota(1)
+	require.Nil(t, err)
+	assert.Nil(t, q)
+
+	err = SetQuota(&models.Quota{
+		ProjectID:   1,
+		StorageHard: 1024,
+		StorageSoft: 512,
+		CountHard:   -1,
+		CountSoft:   -1,
+	})
+	require.Nil(t, err)
+	q, err = GetQuota(1)
+	require.Nil(t, err)
+	require.NotNil(t, q)
+	assert.Equal(t, int64(1024), q.StorageHard)
+
+	// update
+	err = SetQuota(&models.Quota{
+		ProjectID:   1,
+		StorageHard: 2048,
+		StorageSoft: 512,
+		CountHard:   10,
+		CountSoft:   -1,
+	})
+	require.Nil(t, err)
+	q, err = GetQuota(1)
+	require.Nil(t, err)
+	require.NotNil(t, q)
+	assert.Equal(t, int64(2048), q.StorageHard)
+	assert.Equal(t, int64(10), q.CountHard)
+
+	require.Nil(t, DeleteQuota(1))
+	q, err = GetQuota(1)
+	require.Nil(t, err)
+	assert.Nil(t, q)
+}
+
+func TestGetQuotaUsage(t *testing.T) {
+	require.Nil(t, ClearTable("artifact"))
+	require.Nil(t, ClearTable("artifact_blob"))
+	require.Nil(t, ClearTable("blob"))
+
+	blobs := []*models.Blob{
+		{Digest: "sha256:manifest1", Size: 10},
+		{Digest: "sha256:manifest2", Size: 20},
+		{Digest: "sha256:layer", Size: 100},
+	}
+	for _, blob := range blobs {
+		require.Nil(t, AddBlob(blob))
+	}
+	require.Nil(t, AddArtifactBlobs("sha256:manifest1", "sha256:manifest1", "sha256:layer"))
+	require.Nil(t, AddArtifactBlobs("sha256:manifest2", "sha256:manifest2", "sha256:layer"))
+	require.Nil(t, AddArtifact(&models.Artifact{
+		ProjectID: 1,
+		Repo:      "library/quota",
+		Tag:       "1.0",
+		Digest:    "sha256:manifest1",
+	}))
+	require.Nil(t, AddArtifact(&models.Artifact{
+		ProjectID: 1,
+		Repo:      "library/quota",
+		Tag:       "2.0",
+		Digest:    "sha256:manifest2",
+	}))
+
+	// the shared layer is counted only once
+	usage, err := GetQuotaUsage(1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(130), usage.Storage)
+	assert.Equal(t, int64(2), usage.Count)
+
+	digests, err := GetBlobsOfProject(1, "sha256:layer", "sha256:unknown")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"sha256:layer"}, digests)
+
+	// the tag is moved to another manifest
+	require.Nil(t, AddArtifact(&models.Artifact{
+		ProjectID: 1,
+		Repo:      "library/quota",
+		Tag:       "2.0",
+		Digest:    "sha256:manifest1",
+	}))
+	usage, err = GetQuotaUsage(1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(110), usage.Storage)
+	assert.Equal(t, int64(2), usage.Count)
+
+	require.Nil(t, DeleteArtifactsByDigest("library/quota", "sha256:manifest1"))
+	usage, err = GetQuotaUsage(1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), usage.Storage)
+	assert.Equal(t, int64(0), usage.Count)
+}
+
+func TestReserveQuota(t *testing.T) {
+	require.Nil(t, ClearTable("artifact"))
+	require.Nil(t, ClearTable("quota_reservation"))
+
+	var seen *models.QuotaUsage
+	check := func(usage *models.QuotaUsage) error {
+		seen = usage
+		if usage.Count >= 1 {
+			return errors.New("exceeded")
+		}
+		return nil
+	}
+	id, err := ReserveQuota(1, 100, 1, check)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), seen.Storage)
+
+	// the reservation of the push in progress is counted
+	_, err = ReserveQuota(1, 100, 1, check)
+	require.NotNil(t, err)
+	assert.Equal(t, int64(100), seen.Storage)
+	assert.Equal(t, int64(1), seen.Count)
+
+	// the reservations of other projects aren't counted
+	_, err = ReserveQuota(2, 100, 1, check)
+	require.Nil(t, err)
+
+	require.Nil(t, DeleteQuotaReservation(id))
+	_, err = ReserveQuota(1, 100, 1, check)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), seen.Storage)
+}
+
+func TestArtifactBackfill(t *testing.T) {
+	_, err := GetOrmer().Raw(`update artifact_backfill set status = ?`, artifactBackfillPending).Exec()
+	require.Nil(t, err)
+
+	claimed, err := ClaimArtifactBackfill()
+	require.Nil(t, err)
+	assert.True(t, claimed)
+
+	// the backfill is being run by another core
+	require.Nil(t, RefreshArtifactBackfill())
+	claimed, err = ClaimArtifactBackfill()
+	require.Nil(t, err)
+	assert.False(t, claimed)
+
+	// the backfill runs only once
+	require.Nil(t, FinishArtifactBackfill())
+	claimed, err = ClaimArtifactBackfill()
+	require.Nil(t, err)
+	assert.False(t, claimed)
+}
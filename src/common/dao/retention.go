@@ -0,0 +1,244 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// GetRetentionPolicy returns the retention policy of the project, nil is returned if the project has no policy
+func GetRetentionPolicy(projectID int64) (*models.RetentionPolicy, error) {
+	policies := []*models.RetentionPolicy{}
+	_, err := GetOrmer().QueryTable(&models.RetentionPolicy{}).
+		Filter("ProjectID", projectID).All(&policies)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	policy := policies[0]
+	policy.Rules = []*models.RetentionRule{}
+	if len(policy.RulesText) > 0 {
+		if err = json.Unmarshal([]byte(policy.RulesText), &policy.Rules); err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+// SetRetentionPolicy creates the retention policy of the project or updates it if it exists
+func SetRetentionPolicy(policy *models.RetentionPolicy) error {
+	data, err := json.Marshal(policy.Rules)
+	if err != nil {
+		return err
+	}
+	policy.RulesText = string(data)
+	_, err = GetOrmer().InsertOrUpdate(policy, "project_id")
+	return err
+}
+
+// DeleteRetentionPolicy deletes the retention policy of the project
+func DeleteRetentionPolicy(projectID int64) error {
+	_, err := GetOrmer().QueryTable(&models.RetentionPolicy{}).
+		Filter("ProjectID", projectID).Delete()
+	return err
+}
+
+// AddRetentionExecution ...
+func AddRetentionExecution(execution *models.RetentionExecution) (int64, error) {
+	if execution.StartTime.IsZero() {
+		execution.StartTime = time.Now()
+	}
+	return GetOrmer().Insert(execution)
+}
+
+// GetRetentionExecution returns the execution specified by ID, nil is returned if it doesn't exist
+func GetRetentionExecution(id int64) (*models.RetentionExecution, error) {
+	execution := &models.RetentionExecution{
+		ID: id,
+	}
+	if err := GetOrmer().Read(execution); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := fillRetentionExecution(execution); err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+// GetTotalOfRetentionExecutions returns the total count of executions of the project
+func GetTotalOfRetentionExecutions(projectID int64) (int64, error) {
+	return GetOrmer().QueryTable(&models.RetentionExecution{}).
+		Filter("ProjectID", projectID).Count()
+}
+
+// ListRetentionExecutions returns the executions of the project, the latest one first
+func ListRetentionExecutions(projectID int64, pagination *models.Pagination) ([]*models.RetentionExecution, error) {
+	qs := GetOrmer().QueryTable(&models.RetentionExecution{}).
+		Filter("ProjectID", projectID).OrderBy("-StartTime")
+	if pagination != nil && pagination.Size > 0 {
+		qs = qs.Limit(pagination.Size, (pagination.Page-1)*pagination.Size)
+	}
+	executions := []*models.RetentionExecution{}
+	if _, err := qs.All(&executions); err != nil {
+		return nil, err
+	}
+	for _, execution := range executions {
+		if err := fillRetentionExecution(execution); err != nil {
+			return nil, err
+		}
+	}
+	return executions, nil
+}
+
+// roll up the status and statistics of the execution from its tasks
+func fillRetentionExecution(execution *models.RetentionExecution) error {
+	stats := []struct {
+		Status string `orm:"column(status)"`
+		C      int    `orm:"column(c)"`
+	}{}
+	if _, err := GetOrmer().Raw(`select status, count(*) as c from retention_task
+		where execution_id = ? group by status`, execution.ID).QueryRows(&stats); err != nil {
+		return err
+	}
+	for _, stat := range stats {
+		execution.Total += stat.C
+		switch stat.Status {
+		case models.JobFinished:
+			execution.Succeed += stat.C
+		case models.JobError:
+			execution.Failed += stat.C
+		case models.JobStopped, models.JobCanceled:
+			execution.Stopped += stat.C
+		default:
+			execution.InProgress += stat.C
+		}
+	}
+	switch {
+	case execution.InProgress > 0:
+		execution.Status = models.RetentionStatusInProgress
+	case execution.Failed > 0:
+		execution.Status = models.RetentionStatusFailed
+	case execution.Stopped > 0:
+		execution.Status = models.RetentionStatusStopped
+	default:
+		execution.Status = models.RetentionStatusSucceed
+	}
+	return nil
+}
+
+// AddRetentionTask ...
+func AddRetentionTask(task *models.RetentionTask) (int64, error) {
+	if err := marshalRetentionTask(task); err != nil {
+		return 0, err
+	}
+	return GetOrmer().Insert(task)
+}
+
+// GetRetentionTask returns the task specified by ID, nil is returned if it doesn't exist
+func GetRetentionTask(id int64) (*models.RetentionTask, error) {
+	task := &models.RetentionTask{
+		ID: id,
+	}
+	if err := GetOrmer().Read(task); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := unmarshalRetentionTask(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// ListRetentionTasks returns the tasks of the execution
+func ListRetentionTasks(executionID int64) ([]*models.RetentionTask, error) {
+	tasks := []*models.RetentionTask{}
+	if _, err := GetOrmer().QueryTable(&models.RetentionTask{}).
+		Filter("ExecutionID", executionID).OrderBy("Repository").All(&tasks); err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if err := unmarshalRetentionTask(task); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// UpdateRetentionTask updates the properties of the task, all the properties
+// except the ID are updated if no property specified
+func UpdateRetentionTask(task *models.RetentionTask, props ...string) error {
+	if err := marshalRetentionTask(task); err != nil {
+		return err
+	}
+	for i, prop := range props {
+		if prop == "Deleted" {
+			props[i] = "DeletedText"
+		}
+	}
+	_, err := GetOrmer().Update(task, props...)
+	return err
+}
+
+// UpdateRetentionTaskStatus updates the status of the task, the start time is set when the task
+// starts running and the end time is set when it is done. The status of the task that is already
+// done isn't updated as the status changes from jobservice may be out of order
+func UpdateRetentionTaskStatus(id int64, status string) error {
+	params := orm.Params{
+		"status": status,
+	}
+	now := time.Now()
+	switch status {
+	case models.JobRunning:
+		params["start_time"] = now
+	case models.JobFinished, models.JobError, models.JobStopped, models.JobCanceled:
+		params["end_time"] = now
+	}
+	_, err := GetOrmer().QueryTable(&models.RetentionTask{}).
+		Filter("id", id).
+		Exclude("status__in", models.JobFinished, models.JobError, models.JobStopped, models.JobCanceled).
+		Update(params)
+	return err
+}
+
+func marshalRetentionTask(task *models.RetentionTask) error {
+	if task.Deleted == nil {
+		task.Deleted = []string{}
+	}
+	data, err := json.Marshal(task.Deleted)
+	if err != nil {
+		return err
+	}
+	task.DeletedText = string(data)
+	return nil
+}
+
+func unmarshalRetentionTask(task *models.RetentionTask) error {
+	task.Deleted = []string{}
+	if len(task.DeletedText) == 0 {
+		return nil
+	}
+	return json.Unmarshal([]byte(task.DeletedText), &task.Deleted)
+}
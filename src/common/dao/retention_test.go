@@ -0,0 +1,129 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	require.Nil(t, ClearTable("retention_policy"))
+
+	policy, err := GetRetentionPolicy(1)
+	require.Nil(t, err)
+	assert.Nil(t, policy)
+
+	err = SetRetentionPolicy(&models.RetentionPolicy{
+		ProjectID: 1,
+		Rules: []*models.RetentionRule{
+			{
+				Template: models.RetentionTemplateLatestPushed,
+				Count:    10,
+			},
+		},
+	})
+	require.Nil(t, err)
+	policy, err = GetRetentionPolicy(1)
+	require.Nil(t, err)
+	require.NotNil(t, policy)
+	require.Equal(t, 1, len(policy.Rules))
+	assert.Equal(t, 10, policy.Rules[0].Count)
+
+	// update
+	policy.Cron = "0 0 0 * * *"
+	policy.Rules = append(policy.Rules, &models.RetentionRule{
+		Template: models.RetentionTemplateAlways,
+		Tags:     "release-*",
+	})
+	require.Nil(t, SetRetentionPolicy(policy))
+	policy, err = GetRetentionPolicy(1)
+	require.Nil(t, err)
+	require.NotNil(t, policy)
+	assert.Equal(t, 2, len(policy.Rules))
+	assert.Equal(t, "0 0 0 * * *", policy.Cron)
+
+	require.Nil(t, DeleteRetentionPolicy(1))
+	policy, err = GetRetentionPolicy(1)
+	require.Nil(t, err)
+	assert.Nil(t, policy)
+}
+
+func TestRetentionExecution(t *testing.T) {
+	require.Nil(t, ClearTable("retention_execution"))
+	require.Nil(t, ClearTable("retention_task"))
+
+	id, err := AddRetentionExecution(&models.RetentionExecution{
+		ProjectID: 1,
+		Trigger:   models.RetentionTriggerManual,
+		DryRun:    true,
+	})
+	require.Nil(t, err)
+
+	var taskIDs []int64
+	for _, repository := range []string{"library/hello-world", "library/ubuntu"} {
+		taskID, err := AddRetentionTask(&models.RetentionTask{
+			ExecutionID: id,
+			Repository:  repository,
+			Status:      models.JobPending,
+		})
+		require.Nil(t, err)
+		taskIDs = append(taskIDs, taskID)
+	}
+
+	execution, err := GetRetentionExecution(id)
+	require.Nil(t, err)
+	require.NotNil(t, execution)
+	assert.True(t, execution.DryRun)
+	assert.Equal(t, models.RetentionStatusInProgress, execution.Status)
+	assert.Equal(t, 2, execution.InProgress)
+
+	require.Nil(t, UpdateRetentionTaskStatus(taskIDs[0], models.JobFinished))
+	require.Nil(t, UpdateRetentionTaskStatus(taskIDs[1], models.JobError))
+	// the status of the finished task isn't changed
+	require.Nil(t, UpdateRetentionTaskStatus(taskIDs[1], models.JobRunning))
+	require.Nil(t, UpdateRetentionTask(&models.RetentionTask{
+		ID:       taskIDs[0],
+		Total:    3,
+		Retained: 1,
+		Deleted:  []string{"ci-1", "ci-2"},
+	}, "Total", "Retained", "Deleted"))
+
+	total, err := GetTotalOfRetentionExecutions(1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	executions, err := ListRetentionExecutions(1, nil)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(executions))
+	assert.Equal(t, models.RetentionStatusFailed, executions[0].Status)
+	assert.Equal(t, 1, executions[0].Succeed)
+	assert.Equal(t, 1, executions[0].Failed)
+
+	tasks, err := ListRetentionTasks(id)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(tasks))
+	assert.Equal(t, "library/hello-world", tasks[0].Repository)
+	assert.Equal(t, []string{"ci-1", "ci-2"}, tasks[0].Deleted)
+	assert.Equal(t, models.JobError, tasks[1].Status)
+
+	task, err := GetRetentionTask(taskIDs[0])
+	require.Nil(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, 3, task.Total)
+	assert.NotNil(t, task.EndTime)
+}
@@ -0,0 +1,188 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// AddWebhookEndpoint ...
+func AddWebhookEndpoint(endpoint *models.WebhookEndpoint) (int64, error) {
+	if err := marshalWebhookEndpoint(endpoint); err != nil {
+		return 0, err
+	}
+	return GetOrmer().Insert(endpoint)
+}
+
+// GetWebhookEndpoint returns the endpoint specified by ID, nil is returned if it doesn't exist
+func GetWebhookEndpoint(id int64) (*models.WebhookEndpoint, error) {
+	endpoint := &models.WebhookEndpoint{
+		ID: id,
+	}
+	if err := GetOrmer().Read(endpoint); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := unmarshalWebhookEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// ListWebhookEndpoints returns the webhook endpoints of the project
+func ListWebhookEndpoints(projectID int64) ([]*models.WebhookEndpoint, error) {
+	endpoints := []*models.WebhookEndpoint{}
+	if _, err := GetOrmer().QueryTable(&models.WebhookEndpoint{}).
+		Filter("ProjectID", projectID).OrderBy("ID").All(&endpoints); err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		if err := unmarshalWebhookEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+	}
+	return endpoints, nil
+}
+
+// UpdateWebhookEndpoint updates the endpoint, all the properties except the ID
+// and creation time are updated
+func UpdateWebhookEndpoint(endpoint *models.WebhookEndpoint) error {
+	if err := marshalWebhookEndpoint(endpoint); err != nil {
+		return err
+	}
+	_, err := GetOrmer().Update(endpoint, "Name", "Address", "Secret", "EventTypesText",
+		"SkipCertVerify", "Enabled", "UpdateTime")
+	return err
+}
+
+// DeleteWebhookEndpoint deletes the endpoint and its deliveries
+func DeleteWebhookEndpoint(id int64) error {
+	if _, err := GetOrmer().QueryTable(&models.WebhookDelivery{}).
+		Filter("EndpointID", id).Delete(); err != nil {
+		return err
+	}
+	_, err := GetOrmer().Delete(&models.WebhookEndpoint{
+		ID: id,
+	})
+	return err
+}
+
+// AddWebhookDelivery ...
+func AddWebhookDelivery(delivery *models.WebhookDelivery) (int64, error) {
+	return GetOrmer().Insert(delivery)
+}
+
+// GetWebhookDelivery returns the delivery specified by ID, nil is returned if it doesn't exist
+func GetWebhookDelivery(id int64) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{
+		ID: id,
+	}
+	if err := GetOrmer().Read(delivery); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// GetTotalOfWebhookDeliveries returns the total count of deliveries to the endpoint
+func GetTotalOfWebhookDeliveries(endpointID int64) (int64, error) {
+	return GetOrmer().QueryTable(&models.WebhookDelivery{}).
+		Filter("EndpointID", endpointID).Count()
+}
+
+// ListWebhookDeliveries returns the deliveries to the endpoint, the latest one first
+func ListWebhookDeliveries(endpointID int64, pagination *models.Pagination) ([]*models.WebhookDelivery, error) {
+	qs := GetOrmer().QueryTable(&models.WebhookDelivery{}).
+		Filter("EndpointID", endpointID).OrderBy("-ID")
+	if pagination != nil && pagination.Size > 0 {
+		qs = qs.Limit(pagination.Size, (pagination.Page-1)*pagination.Size)
+	}
+	deliveries := []*models.WebhookDelivery{}
+	_, err := qs.All(&deliveries)
+	return deliveries, err
+}
+
+// UpdateWebhookDelivery updates the properties of the delivery, all the properties
+// except the ID are updated if no property specified
+func UpdateWebhookDelivery(delivery *models.WebhookDelivery, props ...string) error {
+	_, err := GetOrmer().Update(delivery, props...)
+	return err
+}
+
+// RecordWebhookDeliveryAttempt increases the attempts of the delivery by one and records the
+// status code and error of the attempt
+func RecordWebhookDeliveryAttempt(id int64, statusCode int, errMsg string) error {
+	_, err := GetOrmer().QueryTable(&models.WebhookDelivery{}).
+		Filter("id", id).
+		Update(orm.Params{
+			"attempts":    orm.ColValue(orm.ColAdd, 1),
+			"status_code": statusCode,
+			"error":       errMsg,
+			"update_time": time.Now(),
+		})
+	return err
+}
+
+// PruneWebhookDeliveries keeps the latest deliveries to the endpoint up to the count and deletes the others
+func PruneWebhookDeliveries(endpointID int64, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	sql := `delete from webhook_delivery where endpoint_id = ? and id < (
+		select id from webhook_delivery where endpoint_id = ? order by id desc offset ? limit 1)`
+	_, err := GetOrmer().Raw(sql, endpointID, endpointID, keep-1).Exec()
+	return err
+}
+
+// UpdateWebhookDeliveryStatus updates the status of the delivery. The status of the delivery
+// that is already done isn't updated as the status changes from jobservice may be out of order
+func UpdateWebhookDeliveryStatus(id int64, status string) error {
+	_, err := GetOrmer().QueryTable(&models.WebhookDelivery{}).
+		Filter("id", id).
+		Exclude("status__in", models.JobFinished, models.JobError, models.JobStopped, models.JobCanceled).
+		Update(orm.Params{
+			"status":      status,
+			"update_time": time.Now(),
+		})
+	return err
+}
+
+func marshalWebhookEndpoint(endpoint *models.WebhookEndpoint) error {
+	if endpoint.EventTypes == nil {
+		endpoint.EventTypes = []string{}
+	}
+	data, err := json.Marshal(endpoint.EventTypes)
+	if err != nil {
+		return err
+	}
+	endpoint.EventTypesText = string(data)
+	return nil
+}
+
+func unmarshalWebhookEndpoint(endpoint *models.WebhookEndpoint) error {
+	endpoint.EventTypes = []string{}
+	if len(endpoint.EventTypesText) == 0 {
+		return nil
+	}
+	return json.Unmarshal([]byte(endpoint.EventTypesText), &endpoint.EventTypes)
+}
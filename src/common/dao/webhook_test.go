@@ -0,0 +1,104 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookEndpoint(t *testing.T) {
+	require.Nil(t, ClearTable("webhook_endpoint"))
+	require.Nil(t, ClearTable("webhook_delivery"))
+
+	id, err := AddWebhookEndpoint(&models.WebhookEndpoint{
+		ProjectID:  1,
+		Name:       "ci",
+		Address:    "https://example.com/hook",
+		EventTypes: []string{models.WebhookEventPushImage},
+		Enabled:    true,
+	})
+	require.Nil(t, err)
+
+	endpoint, err := GetWebhookEndpoint(id)
+	require.Nil(t, err)
+	require.NotNil(t, endpoint)
+	assert.Equal(t, "ci", endpoint.Name)
+	assert.Equal(t, []string{models.WebhookEventPushImage}, endpoint.EventTypes)
+
+	// update
+	endpoint.EventTypes = append(endpoint.EventTypes, models.WebhookEventDeleteImage)
+	endpoint.Enabled = false
+	require.Nil(t, UpdateWebhookEndpoint(endpoint))
+	endpoints, err := ListWebhookEndpoints(1)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(endpoints))
+	assert.Equal(t, 2, len(endpoints[0].EventTypes))
+	assert.False(t, endpoints[0].Enabled)
+
+	// deliveries
+	deliveryID, err := AddWebhookDelivery(&models.WebhookDelivery{
+		EndpointID: id,
+		EventType:  models.WebhookEventPushImage,
+		Payload:    "{}",
+		Status:     models.JobPending,
+	})
+	require.Nil(t, err)
+	require.Nil(t, RecordWebhookDeliveryAttempt(deliveryID, 500, "internal error"))
+	require.Nil(t, UpdateWebhookDeliveryStatus(deliveryID, models.JobError))
+	// the status of the delivery that is done isn't updated
+	require.Nil(t, UpdateWebhookDeliveryStatus(deliveryID, models.JobRunning))
+	delivery, err := GetWebhookDelivery(deliveryID)
+	require.Nil(t, err)
+	require.NotNil(t, delivery)
+	assert.Equal(t, models.JobError, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, 500, delivery.StatusCode)
+
+	total, err := GetTotalOfWebhookDeliveries(id)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	deliveries, err := ListWebhookDeliveries(id, &models.Pagination{Page: 1, Size: 10})
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(deliveries))
+
+	// prune
+	var latest int64
+	for i := 0; i < 3; i++ {
+		latest, err = AddWebhookDelivery(&models.WebhookDelivery{
+			EndpointID: id,
+			EventType:  models.WebhookEventPushImage,
+			Status:     models.JobPending,
+		})
+		require.Nil(t, err)
+	}
+	require.Nil(t, PruneWebhookDeliveries(id, 2))
+	deliveries, err = ListWebhookDeliveries(id, nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(deliveries))
+	assert.Equal(t, latest, deliveries[0].ID)
+
+	// delete
+	require.Nil(t, DeleteWebhookEndpoint(id))
+	endpoint, err = GetWebhookEndpoint(id)
+	require.Nil(t, err)
+	assert.Nil(t, endpoint)
+	delivery, err = GetWebhookDelivery(deliveryID)
+	require.Nil(t, err)
+	assert.Nil(t, delivery)
+}
@@ -0,0 +1,104 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/astaxie/beego/orm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	dbMaxOpenConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "max_open_connections"),
+		"The maximum number of the open connections to the database.",
+		nil, nil)
+	dbConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "connections"),
+		"The number of the connections to the database by state.",
+		[]string{"state"}, nil)
+	dbWaitCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "wait_count_total"),
+		"The total number of the connections waited for.",
+		nil, nil)
+	dbWaitDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "db", "wait_duration_seconds_total"),
+		"The total time blocked waiting for a new connection.",
+		nil, nil)
+
+	redisConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "redis", "pool_connections"),
+		"The number of the connections in the redis pool by state.",
+		[]string{"pool", "state"}, nil)
+)
+
+// NewDBCollector returns a collector exposing the statistics of the connection
+// pool of the default database registered in the ORM. Nothing is collected
+// before the database is registered.
+func NewDBCollector() prometheus.Collector {
+	return &dbCollector{}
+}
+
+type dbCollector struct{}
+
+func (d *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbMaxOpenConnectionsDesc
+	ch <- dbConnectionsDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitDurationDesc
+}
+
+func (d *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	db, err := orm.GetDB()
+	if err != nil {
+		return
+	}
+	stats := db.Stats()
+	ch <- prometheus.MustNewConstMetric(dbMaxOpenConnectionsDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbConnectionsDesc, prometheus.GaugeValue, float64(stats.InUse), "in_use")
+	ch <- prometheus.MustNewConstMetric(dbConnectionsDesc, prometheus.GaugeValue, float64(stats.Idle), "idle")
+	ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(dbWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds())
+}
+
+// RedisPool is the part of the redigo connection pool exposing the statistics
+type RedisPool interface {
+	ActiveCount() int
+	IdleCount() int
+}
+
+// NewRedisPoolCollector returns a collector exposing the statistics of the redis connection pool
+func NewRedisPoolCollector(name string, pool RedisPool) prometheus.Collector {
+	return &redisPoolCollector{
+		name: name,
+		pool: pool,
+	}
+}
+
+type redisPoolCollector struct {
+	name string
+	pool RedisPool
+}
+
+func (r *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisConnectionsDesc
+}
+
+func (r *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	active := r.pool.ActiveCount()
+	idle := r.pool.IdleCount()
+	// the active connections include the idle ones
+	ch <- prometheus.MustNewConstMetric(redisConnectionsDesc, prometheus.GaugeValue, float64(active-idle), r.name, "in_use")
+	ch <- prometheus.MustNewConstMetric(redisConnectionsDesc, prometheus.GaugeValue, float64(idle), r.name, "idle")
+}
@@ -0,0 +1,53 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRedisPool struct {
+	active int
+	idle   int
+}
+
+func (f *fakeRedisPool) ActiveCount() int {
+	return f.active
+}
+
+func (f *fakeRedisPool) IdleCount() int {
+	return f.idle
+}
+
+func TestRedisPoolCollector(t *testing.T) {
+	collector := NewRedisPoolCollector("test", &fakeRedisPool{active: 5, idle: 2})
+	expected := `
+# HELP harbor_redis_pool_connections The number of the connections in the redis pool by state.
+# TYPE harbor_redis_pool_connections gauge
+harbor_redis_pool_connections{pool="test",state="idle"} 2
+harbor_redis_pool_connections{pool="test",state="in_use"} 3
+`
+	assert.Nil(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
+
+func TestRegister(t *testing.T) {
+	// registering the collectors with the same descriptors more than once doesn't panic
+	Register(NewDBCollector())
+	Register(NewDBCollector())
+}
@@ -0,0 +1,112 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// UnmatchedRoute is the route label of the requests matching none of the routes,
+// the raw paths aren't used to keep the cardinality of the metrics bounded
+const UnmatchedRoute = "unmatched"
+
+// RouteFunc returns the route pattern of the request used as the "route" label
+type RouteFunc func(req *http.Request) string
+
+// MuxRoute returns the RouteFunc which resolves the path template of the request against the gorilla router
+func MuxRoute(router *mux.Router) RouteFunc {
+	return func(req *http.Request) string {
+		match := &mux.RouteMatch{}
+		if !router.Match(req, match) || match.Route == nil {
+			return UnmatchedRoute
+		}
+		template, err := match.Route.GetPathTemplate()
+		if err != nil {
+			return UnmatchedRoute
+		}
+		return template
+	}
+}
+
+// ObserveHTTPRequest records the request served in the HTTP metrics
+func ObserveHTTPRequest(method, route string, code int, elapsed time.Duration) {
+	if len(route) == 0 {
+		route = UnmatchedRoute
+	}
+	if code == 0 {
+		code = http.StatusOK
+	}
+	HTTPRequestsTotal.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	HTTPRequestDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// InstrumentHandler wraps the handler to record the requests it serves in the HTTP metrics
+func InstrumentHandler(next http.Handler, route RouteFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		recorder := NewStatusRecorder(w)
+		next.ServeHTTP(recorder, req)
+		ObserveHTTPRequest(req.Method, route(req), recorder.Status(), time.Since(start))
+	})
+}
+
+// StatusRecorder is a http.ResponseWriter which records the status code written
+type StatusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+// NewStatusRecorder returns an instance of StatusRecorder wrapping the writer
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{
+		ResponseWriter: w,
+	}
+}
+
+// WriteHeader records the status code and writes it to the wrapped writer
+func (s *StatusRecorder) WriteHeader(code int) {
+	if s.code == 0 {
+		s.code = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the data to the wrapped writer, the status code is
+// set to 200 if it isn't written before as what the http package does
+func (s *StatusRecorder) Write(data []byte) (int, error) {
+	if s.code == 0 {
+		s.code = http.StatusOK
+	}
+	return s.ResponseWriter.Write(data)
+}
+
+// Flush flushes the wrapped writer if it supports
+func (s *StatusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Status returns the status code written, 200 is returned if nothing is written
+func (s *StatusRecorder) Status() int {
+	if s.code == 0 {
+		return http.StatusOK
+	}
+	return s.code
+}
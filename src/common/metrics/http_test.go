@@ -0,0 +1,72 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMuxRoute(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/jobs/{job_id}", func(w http.ResponseWriter, req *http.Request) {}).Methods(http.MethodGet)
+	route := MuxRoute(router)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/api/jobs/1", nil)
+	assert.Equal(t, "/api/jobs/{job_id}", route(req))
+
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/api/unknown", nil)
+	assert.Equal(t, UnmatchedRoute, route(req))
+}
+
+func TestInstrumentHandler(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/ping", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}).Methods(http.MethodPost)
+	router.HandleFunc("/api/health", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}).Methods(http.MethodGet)
+	handler := InstrumentHandler(router, MuxRoute(router))
+
+	accepted := HTTPRequestsTotal.WithLabelValues(http.MethodPost, "/api/ping", "202")
+	ok := HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/api/health", "200")
+	unmatched := HTTPRequestsTotal.WithLabelValues(http.MethodGet, UnmatchedRoute, "404")
+	acceptedBefore, okBefore, unmatchedBefore := testutil.ToFloat64(accepted), testutil.ToFloat64(ok), testutil.ToFloat64(unmatched)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "http://localhost/api/ping", nil))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/api/health", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/not/found", nil))
+
+	assert.Equal(t, acceptedBefore+1, testutil.ToFloat64(accepted))
+	assert.Equal(t, okBefore+1, testutil.ToFloat64(ok))
+	assert.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+}
+
+func TestStatusRecorder(t *testing.T) {
+	recorder := NewStatusRecorder(httptest.NewRecorder())
+	assert.Equal(t, http.StatusOK, recorder.Status())
+
+	recorder.WriteHeader(http.StatusNotFound)
+	_, _ = recorder.Write([]byte("not found"))
+	assert.Equal(t, http.StatusNotFound, recorder.Status())
+}
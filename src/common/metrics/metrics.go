@@ -0,0 +1,134 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "harbor"
+
+var (
+	// HTTPRequestsTotal counts the HTTP requests served by the component by method, route and status code
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "The total number of the HTTP requests served.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestDuration observes the latency of the HTTP requests served by the component
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "The latency of the HTTP requests served.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// TokenRequestsTotal counts the requests for the registry tokens by service and result
+	TokenRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "core",
+		Name:      "token_requests_total",
+		Help:      "The total number of the token requests handled by the token service.",
+	}, []string{"service", "result"})
+
+	// RegistryRequestDuration observes the latency of the requests passed through to the registry
+	RegistryRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "core",
+		Name:      "registry_request_duration_seconds",
+		Help:      "The latency of the requests proxied to the registry.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// ScanJobsTotal counts the completed scan jobs by status
+	ScanJobsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scan",
+		Name:      "jobs_total",
+		Help:      "The total number of the completed scan jobs.",
+	}, []string{"status"})
+
+	// ReplicationTasksTotal counts the completed replication tasks by status
+	ReplicationTasksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "replication",
+		Name:      "tasks_total",
+		Help:      "The total number of the completed replication tasks.",
+	}, []string{"status"})
+
+	// ReplicationTransferredBytes counts the bytes moved by the replication tasks by resource type
+	ReplicationTransferredBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "replication",
+		Name:      "transferred_bytes_total",
+		Help:      "The total bytes transferred by the replication tasks.",
+	}, []string{"resource_type"})
+
+	// JobsTotal counts the jobs run by the job service by job name and result status
+	JobsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "jobservice",
+		Name:      "jobs_total",
+		Help:      "The total number of the jobs run by the job service.",
+	}, []string{"job_name", "status"})
+
+	// JobDuration observes the running time of the jobs by job name
+	JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "jobservice",
+		Name:      "job_duration_seconds",
+		Help:      "The running time of the jobs run by the job service.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600, 7200},
+	}, []string{"job_name"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		TokenRequestsTotal,
+		RegistryRequestDuration,
+		ScanJobsTotal,
+		ReplicationTasksTotal,
+		ReplicationTransferredBytes,
+		JobsTotal,
+		JobDuration,
+	)
+}
+
+// Register registers the collectors to the default registry, the collectors
+// registered already are ignored so it's safe to be called more than once
+func Register(collectors ...prometheus.Collector) {
+	for _, collector := range collectors {
+		if err := prometheus.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+				continue
+			}
+			log.Errorf("failed to register the metrics collector: %v", err)
+		}
+	}
+}
+
+// Handler returns the handler which exposes the metrics in the default registry
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -37,5 +37,16 @@ func init() {
 		new(JobLog),
 		new(Robot),
 		new(OIDCUser),
-		new(CVEWhitelist))
+		new(CVEWhitelist),
+		new(Quota),
+		new(QuotaReservation),
+		new(Artifact),
+		new(ArtifactBlob),
+		new(Blob),
+		new(RetentionPolicy),
+		new(RetentionExecution),
+		new(RetentionTask),
+		new(ImmutableTagRule),
+		new(WebhookEndpoint),
+		new(WebhookDelivery))
 }
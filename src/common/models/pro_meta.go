@@ -26,6 +26,8 @@ const (
 	ProMetaSeverity             = "severity"
 	ProMetaAutoScan             = "auto_scan"
 	ProMetaReuseSysCVEWhitelist = "reuse_sys_cve_whitelist"
+	ProMetaProxyCacheRegistryID = "proxy_cache_registry_id" // the upstream registry endpoint of a proxy cache project
+	ProMetaProxyCacheStaleness  = "proxy_cache_staleness"   // seconds before the upstream manifest is checked again
 	SeverityNone                = "negligible"
 	SeverityLow                 = "low"
 	SeverityMedium              = "medium"
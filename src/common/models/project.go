@@ -15,10 +15,15 @@
 package models
 
 import (
+	"strconv"
 	"strings"
 	"time"
 )
 
+// DefaultProxyCacheStaleness is the staleness window used by the proxy cache projects
+// which don't specify one
+const DefaultProxyCacheStaleness = time.Hour
+
 // ProjectTable is the table name for project
 const ProjectTable = "project"
 
@@ -111,6 +116,39 @@ func (p *Project) AutoScan() bool {
 	return isTrue(auto)
 }
 
+// IsProxyCache returns whether the project is a proxy cache of an upstream registry
+func (p *Project) IsProxyCache() bool {
+	_, exist := p.ProxyCacheRegistryID()
+	return exist
+}
+
+// ProxyCacheRegistryID returns the ID of the upstream registry endpoint of the proxy cache project
+func (p *Project) ProxyCacheRegistryID() (int64, bool) {
+	value, exist := p.GetMetadata(ProMetaProxyCacheRegistryID)
+	if !exist {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// ProxyCacheStaleness returns the period during which the cached manifests are served
+// without checking the upstream registry
+func (p *Project) ProxyCacheStaleness() time.Duration {
+	value, exist := p.GetMetadata(ProMetaProxyCacheStaleness)
+	if !exist {
+		return DefaultProxyCacheStaleness
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return DefaultProxyCacheStaleness
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 func isTrue(value string) bool {
 	return strings.ToLower(value) == "true" ||
 		strings.ToLower(value) == "1"
@@ -168,6 +206,7 @@ type ProjectRequest struct {
 	Public       *int              `json:"public"` // deprecated, reserved for project creation in replication
 	Metadata     map[string]string `json:"metadata"`
 	CVEWhitelist CVEWhitelist      `json:"cve_whitelist"`
+	Quota        *Quota            `json:"quota,omitempty"` // only system admin can set the quota
 }
 
 // ProjectQueryResult ...
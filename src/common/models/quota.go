@@ -0,0 +1,60 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "time"
+
+// QuotaUnlimited is the value of the limit which means there is no limitation
+const QuotaUnlimited int64 = -1
+
+// Quota defines the hard and soft limits of the storage in bytes and the count of tags of a project.
+// The pushes exceeding the hard limits are rejected and the ones exceeding the soft limits are only
+// warned, the negative value means unlimited
+type Quota struct {
+	ID           int64     `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID    int64     `orm:"column(project_id)" json:"project_id"`
+	StorageHard  int64     `orm:"column(storage_hard)" json:"storage_hard"`
+	StorageSoft  int64     `orm:"column(storage_soft)" json:"storage_soft"`
+	CountHard    int64     `orm:"column(count_hard)" json:"count_hard"`
+	CountSoft    int64     `orm:"column(count_soft)" json:"count_soft"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (q *Quota) TableName() string {
+	return "quota"
+}
+
+// QuotaReservation is the storage and tag count reserved by a push in progress, it's counted
+// in the usage of the project until the push is done and the pushed resources are recorded
+type QuotaReservation struct {
+	ID           int64     `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID    int64     `orm:"column(project_id)" json:"project_id"`
+	Storage      int64     `orm:"column(storage)" json:"storage"`
+	Count        int64     `orm:"column(count)" json:"count"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+}
+
+// TableName ...
+func (q *QuotaReservation) TableName() string {
+	return "quota_reservation"
+}
+
+// QuotaUsage is the storage in bytes and the count of tags used by a project
+type QuotaUsage struct {
+	Storage int64 `json:"storage"`
+	Count   int64 `json:"count"`
+}
@@ -0,0 +1,120 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+// the templates of retention rules
+const (
+	// RetentionTemplateLatestPushed keeps the N most recently pushed tags
+	RetentionTemplateLatestPushed = "latest_pushed"
+	// RetentionTemplateRecentlyPulled keeps the tags pulled within the last N days
+	RetentionTemplateRecentlyPulled = "recently_pulled"
+	// RetentionTemplateLabeled keeps the tags carrying any of the labels
+	RetentionTemplateLabeled = "labeled"
+	// RetentionTemplateAlways keeps all the tags
+	RetentionTemplateAlways = "always"
+)
+
+// the triggers and statuses of retention executions
+const (
+	RetentionTriggerManual   = "Manual"
+	RetentionTriggerSchedule = "Schedule"
+
+	RetentionStatusInProgress = "InProgress"
+	RetentionStatusSucceed    = "Succeed"
+	RetentionStatusFailed     = "Failed"
+	RetentionStatusStopped    = "Stopped"
+)
+
+// RetentionPolicy holds the retention rules of a project. The tags which aren't retained
+// by any rule are deleted when the policy is executed
+type RetentionPolicy struct {
+	ID        int64            `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID int64            `orm:"column(project_id)" json:"project_id"`
+	Rules     []*RetentionRule `orm:"-" json:"rules"`
+	RulesText string           `orm:"column(rules)" json:"-"`
+	// the cron string to run the policy periodically, the policy can only be
+	// executed manually if it is empty
+	Cron string `orm:"column(cron)" json:"cron"`
+	// the ID of the periodic job in jobservice
+	ScheduleJobID string    `orm:"column(schedule_job_id)" json:"-"`
+	CreationTime  time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime    time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (r *RetentionPolicy) TableName() string {
+	return "retention_policy"
+}
+
+// RetentionRule selects the tags to retain. The rule only applies to the repositories and tags
+// matching the patterns, the patterns are matched with doublestar and an empty one matches all
+type RetentionRule struct {
+	Template     string `json:"template"`
+	Repositories string `json:"repositories"`
+	Tags         string `json:"tags"`
+	// the count of tags to keep for template "latest_pushed"
+	Count int `json:"count,omitempty"`
+	// the days for template "recently_pulled"
+	Days int `json:"days,omitempty"`
+	// the names of labels for template "labeled"
+	Labels []string `json:"labels,omitempty"`
+}
+
+// RetentionExecution is one run of the retention policy of the project. The status and
+// statistics are rolled up from the tasks when it is retrieved
+type RetentionExecution struct {
+	ID         int64     `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID  int64     `orm:"column(project_id)" json:"project_id"`
+	Trigger    string    `orm:"column(trigger)" json:"trigger"`
+	DryRun     bool      `orm:"column(dry_run)" json:"dry_run"`
+	StartTime  time.Time `orm:"column(start_time)" json:"start_time"`
+	Status     string    `orm:"-" json:"status"`
+	Total      int       `orm:"-" json:"total"`
+	Succeed    int       `orm:"-" json:"succeed"`
+	Failed     int       `orm:"-" json:"failed"`
+	InProgress int       `orm:"-" json:"in_progress"`
+	Stopped    int       `orm:"-" json:"stopped"`
+}
+
+// TableName ...
+func (r *RetentionExecution) TableName() string {
+	return "retention_execution"
+}
+
+// RetentionTask applies the rules to one repository in the execution
+type RetentionTask struct {
+	ID          int64  `orm:"pk;auto;column(id)" json:"id"`
+	ExecutionID int64  `orm:"column(execution_id)" json:"execution_id"`
+	Repository  string `orm:"column(repository)" json:"repository"`
+	JobID       string `orm:"column(job_id)" json:"job_id"`
+	Status      string `orm:"column(status)" json:"status"`
+	// the count of tags in the repository and the ones retained
+	Total    int `orm:"column(total)" json:"total"`
+	Retained int `orm:"column(retained)" json:"retained"`
+	// the tags deleted, or would be deleted if the execution is a dry run
+	Deleted     []string   `orm:"-" json:"deleted"`
+	DeletedText string     `orm:"column(deleted)" json:"-"`
+	StartTime   *time.Time `orm:"column(start_time)" json:"start_time"`
+	EndTime     *time.Time `orm:"column(end_time)" json:"end_time,omitempty"`
+}
+
+// TableName ...
+func (r *RetentionTask) TableName() string {
+	return "retention_task"
+}
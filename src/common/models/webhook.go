@@ -0,0 +1,89 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+// the types of events delivered to the webhook endpoints
+const (
+	WebhookEventPushImage             = "pushImage"
+	WebhookEventPullImage             = "pullImage"
+	WebhookEventDeleteImage           = "deleteImage"
+	WebhookEventScanningCompleted     = "scanningCompleted"
+	WebhookEventScanningFailed        = "scanningFailed"
+	WebhookEventUploadChart           = "uploadChart"
+	WebhookEventDeleteChart           = "deleteChart"
+	WebhookEventQuotaExceeded         = "quotaExceeded"
+	WebhookEventRegistryHealthChanged = "registryHealthChanged"
+)
+
+// WebhookEventTypes contains all the supported types of webhook events
+var WebhookEventTypes = []string{
+	WebhookEventPushImage,
+	WebhookEventPullImage,
+	WebhookEventDeleteImage,
+	WebhookEventScanningCompleted,
+	WebhookEventScanningFailed,
+	WebhookEventUploadChart,
+	WebhookEventDeleteChart,
+	WebhookEventQuotaExceeded,
+	WebhookEventRegistryHealthChanged,
+}
+
+// WebhookEndpoint is the HTTP endpoint registered by the project to receive the events of the
+// types it subscribes. The payloads are signed with the secret if it is set
+type WebhookEndpoint struct {
+	ID        int64  `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID int64  `orm:"column(project_id)" json:"project_id"`
+	Name      string `orm:"column(name)" json:"name"`
+	Address   string `orm:"column(address)" json:"address"`
+	// the secret is stored encrypted and never returned by the API
+	Secret         string    `orm:"column(secret)" json:"secret,omitempty"`
+	EventTypes     []string  `orm:"-" json:"event_types"`
+	EventTypesText string    `orm:"column(event_types)" json:"-"`
+	SkipCertVerify bool      `orm:"column(skip_cert_verify)" json:"skip_cert_verify"`
+	Enabled        bool      `orm:"column(enabled)" json:"enabled"`
+	Creator        string    `orm:"column(creator)" json:"creator"`
+	CreationTime   time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime     time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (w *WebhookEndpoint) TableName() string {
+	return "webhook_endpoint"
+}
+
+// WebhookDelivery records the delivery of one event to the endpoint. The attempts, status code
+// and error are updated by the job each time it tries to deliver the payload
+type WebhookDelivery struct {
+	ID           int64     `orm:"pk;auto;column(id)" json:"id"`
+	EndpointID   int64     `orm:"column(endpoint_id)" json:"endpoint_id"`
+	EventType    string    `orm:"column(event_type)" json:"event_type"`
+	Payload      string    `orm:"column(payload)" json:"payload"`
+	JobID        string    `orm:"column(job_id)" json:"job_id"`
+	Status       string    `orm:"column(status)" json:"status"`
+	Attempts     int       `orm:"column(attempts)" json:"attempts"`
+	StatusCode   int       `orm:"column(status_code)" json:"status_code"`
+	Error        string    `orm:"column(error)" json:"error"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (w *WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
@@ -144,6 +144,24 @@ var (
 		{Resource: rbac.ResourceRobot, Action: rbac.ActionUpdate},
 		{Resource: rbac.ResourceRobot, Action: rbac.ActionDelete},
 		{Resource: rbac.ResourceRobot, Action: rbac.ActionList},
+
+		{Resource: rbac.ResourceTagRetention, Action: rbac.ActionCreate},
+		{Resource: rbac.ResourceTagRetention, Action: rbac.ActionRead},
+		{Resource: rbac.ResourceTagRetention, Action: rbac.ActionUpdate},
+		{Resource: rbac.ResourceTagRetention, Action: rbac.ActionDelete},
+		{Resource: rbac.ResourceTagRetention, Action: rbac.ActionList},
+
+		{Resource: rbac.ResourceImmutableTag, Action: rbac.ActionCreate},
+		{Resource: rbac.ResourceImmutableTag, Action: rbac.ActionRead},
+		{Resource: rbac.ResourceImmutableTag, Action: rbac.ActionUpdate},
+		{Resource: rbac.ResourceImmutableTag, Action: rbac.ActionDelete},
+		{Resource: rbac.ResourceImmutableTag, Action: rbac.ActionList},
+
+		{Resource: rbac.ResourceWebhook, Action: rbac.ActionCreate},
+		{Resource: rbac.ResourceWebhook, Action: rbac.ActionRead},
+		{Resource: rbac.ResourceWebhook, Action: rbac.ActionUpdate},
+		{Resource: rbac.ResourceWebhook, Action: rbac.ActionDelete},
+		{Resource: rbac.ResourceWebhook, Action: rbac.ActionList},
 	}
 )
 
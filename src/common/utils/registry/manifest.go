@@ -16,6 +16,8 @@ package registry
 
 import (
 	"github.com/docker/distribution"
+	// register the OCI image manifest schema
+	_ "github.com/docker/distribution/manifest/ocischema"
 )
 
 // UnMarshal converts []byte to be distribution.Manifest
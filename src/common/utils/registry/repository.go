@@ -116,15 +116,20 @@ func (r *Repository) ListTag() ([]string, error) {
 
 }
 
-// ManifestExist ...
-func (r *Repository) ManifestExist(reference string) (digest string, exist bool, err error) {
+// ManifestExist checks the existence of the manifest, the schema1 and schema2 manifests
+// are accepted if no media type is specified
+func (r *Repository) ManifestExist(reference string, acceptMediaTypes ...string) (digest string, exist bool, err error) {
 	req, err := http.NewRequest("HEAD", buildManifestURL(r.Endpoint.String(), r.Name, reference), nil)
 	if err != nil {
 		return
 	}
 
-	req.Header.Add(http.CanonicalHeaderKey("Accept"), schema1.MediaTypeManifest)
-	req.Header.Add(http.CanonicalHeaderKey("Accept"), schema2.MediaTypeManifest)
+	if len(acceptMediaTypes) == 0 {
+		acceptMediaTypes = []string{schema1.MediaTypeManifest, schema2.MediaTypeManifest}
+	}
+	for _, mediaType := range acceptMediaTypes {
+		req.Header.Add(http.CanonicalHeaderKey("Accept"), mediaType)
+	}
 
 	resp, err := r.client.Do(req)
 	if err != nil {
@@ -258,26 +263,75 @@ func (r *Repository) DeleteManifest(digest string) error {
 	}
 }
 
-// MountBlob ...
-func (r *Repository) MountBlob(digest, from string) error {
+// MountBlob mounts the blob from the repository "from" into the current repository and
+// returns whether the blob is mounted. If the registry cannot mount the blob(e.g. the blob
+// doesn't exist in the repository "from" or the user has no permission to read it), it
+// starts an upload session instead, the session is cancelled and false is returned
+func (r *Repository) MountBlob(digest, from string) (bool, error) {
 	req, err := http.NewRequest("POST", buildMountBlobURL(r.Endpoint.String(), r.Name, digest, from), nil)
+	if err != nil {
+		return false, err
+	}
 	req.Header.Set(http.CanonicalHeaderKey("Content-Length"), "0")
 
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return err
+		return false, parseError(err)
 	}
 
-	if resp.StatusCode/100 != 2 {
-		defer resp.Body.Close()
-		b, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return err
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return true, nil
+	case http.StatusAccepted:
+		// the upload session will be purged by the registry finally even if it isn't cancelled
+		if location := resp.Header.Get(http.CanonicalHeaderKey("Location")); len(location) > 0 {
+			_ = r.cancelBlobUpload(location)
 		}
-		return fmt.Errorf("status %d, body: %s", resp.StatusCode, string(b))
+		return false, nil
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	return false, &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(b),
+	}
+}
+
+// cancel the upload session, the uploaded data of the session is discarded
+func (r *Repository) cancelBlobUpload(location string) error {
+	url, err := buildBlobUploadURL(r.Endpoint.String(), location)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return parseError(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(b),
+	}
 }
 
 // DeleteTag ...
@@ -329,6 +383,35 @@ func (r *Repository) BlobExist(digest string) (bool, error) {
 	}
 }
 
+// BlobSize returns the size of the blob got by a HEAD request, so the blob isn't pulled
+func (r *Repository) BlobSize(digest string) (int64, error) {
+	req, err := http.NewRequest("HEAD", buildBlobURL(r.Endpoint.String(), r.Name, digest), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, parseError(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return strconv.ParseInt(resp.Header.Get(http.CanonicalHeaderKey("Content-Length")), 10, 64)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(b),
+	}
+}
+
 // PullBlob : client must close data if it is not nil
 func (r *Repository) PullBlob(digest string) (size int64, data io.ReadCloser, err error) {
 	req, err := http.NewRequest("GET", buildBlobURL(r.Endpoint.String(), r.Name, digest), nil)
@@ -439,6 +522,161 @@ func (r *Repository) PushBlob(digest string, size int64, data io.Reader) error {
 	return r.monolithicBlobUpload(location, digest, size, data)
 }
 
+// PullBlobChunk pulls the bytes [start, end] of the blob, client must close data if it is not nil
+func (r *Repository) PullBlobChunk(digest string, blobSize, start, end int64) (size int64, data io.ReadCloser, err error) {
+	req, err := http.NewRequest("GET", buildBlobURL(r.Endpoint.String(), r.Name, digest), nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set(http.CanonicalHeaderKey("Range"), fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		err = parseError(err)
+		return
+	}
+
+	// the registry may ignore the range header and return the whole blob,
+	// it is acceptable only when the whole blob is requested
+	if resp.StatusCode == http.StatusPartialContent ||
+		(resp.StatusCode == http.StatusOK && start == 0 && end == blobSize-1) {
+		contengLength := resp.Header.Get(http.CanonicalHeaderKey("Content-Length"))
+		size, err = strconv.ParseInt(contengLength, 10, 64)
+		if err != nil {
+			resp.Body.Close()
+			return
+		}
+		data = resp.Body
+		return
+	}
+	// can not close the connect if the status code is 206
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		err = fmt.Errorf("the registry doesn't support pulling blob %s by range", digest)
+		return
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	err = &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(b),
+	}
+
+	return
+}
+
+// InitiateBlobUpload starts a blob upload session and returns the location of it
+func (r *Repository) InitiateBlobUpload() (string, error) {
+	location, _, err := r.initiateBlobUpload(r.Name)
+	return location, err
+}
+
+// GetBlobUploadStatus returns the location for the next request of the upload
+// session and the offset of the next byte that the session expects
+func (r *Repository) GetBlobUploadStatus(location string) (string, int64, error) {
+	url, err := buildBlobUploadURL(r.Endpoint.String(), location)
+	if err != nil {
+		return "", 0, err
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", 0, parseError(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return parseBlobUploadResponse(resp, location)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return "", 0, &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(b),
+	}
+}
+
+// PushBlobChunk uploads the chunk which starts at the offset to the upload session,
+// returns the location for the next request and the offset of the next byte
+func (r *Repository) PushBlobChunk(location string, offset, size int64, data io.Reader) (string, int64, error) {
+	url, err := buildBlobUploadURL(r.Endpoint.String(), location)
+	if err != nil {
+		return "", 0, err
+	}
+	req, err := http.NewRequest("PATCH", url, data)
+	if err != nil {
+		return "", 0, err
+	}
+	req.ContentLength = size
+	req.Header.Set(http.CanonicalHeaderKey("Content-Type"), "application/octet-stream")
+	req.Header.Set(http.CanonicalHeaderKey("Content-Range"), fmt.Sprintf("%d-%d", offset, offset+size-1))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", 0, parseError(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusAccepted {
+		return parseBlobUploadResponse(resp, location)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return "", 0, &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(b),
+	}
+}
+
+// CompleteBlobUpload completes the upload session after all the chunks are uploaded
+func (r *Repository) CompleteBlobUpload(location, digest string) error {
+	return r.monolithicBlobUpload(location, digest, 0, nil)
+}
+
+// parse the location and the offset of the next byte from the response of upload session,
+// the format of range header is "0-<offset of the last byte received>". Some registries
+// return "0-0" when no byte is received, so it is treated as an empty upload
+func parseBlobUploadResponse(resp *http.Response, location string) (string, int64, error) {
+	if l := resp.Header.Get(http.CanonicalHeaderKey("Location")); len(l) > 0 {
+		location = l
+	}
+	rng := resp.Header.Get(http.CanonicalHeaderKey("Range"))
+	if len(rng) == 0 {
+		return location, 0, nil
+	}
+	parts := strings.SplitN(rng, "-", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid range header: %s", rng)
+	}
+	end, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid range header: %s", rng)
+	}
+	if end <= 0 {
+		return location, 0, nil
+	}
+	return location, end + 1, nil
+}
+
 // DeleteBlob ...
 func (r *Repository) DeleteBlob(digest string) error {
 	req, err := http.NewRequest("DELETE", buildBlobURL(r.Endpoint.String(), r.Name, digest), nil)
@@ -493,15 +731,10 @@ func buildInitiateBlobUploadURL(endpoint, repoName string) string {
 }
 
 func buildMonolithicBlobUploadURL(endpoint, location, digest string) (string, error) {
-	relative, err := isRelativeURL(location)
+	location, err := buildBlobUploadURL(endpoint, location)
 	if err != nil {
 		return "", err
 	}
-	// when the registry enables "relativeurls", the location returned
-	// has no scheme and host part
-	if relative {
-		location = endpoint + location
-	}
 	query := ""
 	if strings.ContainsRune(location, '?') {
 		query = "&"
@@ -512,6 +745,19 @@ func buildMonolithicBlobUploadURL(endpoint, location, digest string) (string, er
 	return fmt.Sprintf("%s%s", location, query), nil
 }
 
+func buildBlobUploadURL(endpoint, location string) (string, error) {
+	relative, err := isRelativeURL(location)
+	if err != nil {
+		return "", err
+	}
+	// when the registry enables "relativeurls", the location returned
+	// has no scheme and host part
+	if relative {
+		location = endpoint + location
+	}
+	return location, nil
+}
+
 func isRelativeURL(endpoint string) (bool, error) {
 	u, err := url.Parse(endpoint)
 	if err != nil {
@@ -94,6 +94,38 @@ func TestBlobExist(t *testing.T) {
 	}
 }
 
+func TestBlobSize(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		dgt := path[strings.LastIndex(path, "/")+1:]
+		if dgt == digest {
+			w.Header().Add(http.CanonicalHeaderKey("Content-Length"), strconv.Itoa(len(blob)))
+			w.Header().Add(http.CanonicalHeaderKey("Docker-Content-Digest"), digest)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	server := test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  "HEAD",
+			Pattern: fmt.Sprintf("/v2/%s/blobs/", repository),
+			Handler: handler,
+		})
+	defer server.Close()
+
+	client, err := newRepository(server.URL)
+	require.Nil(t, err)
+
+	size, err := client.BlobSize(digest)
+	require.Nil(t, err)
+	assert.Equal(t, int64(len(blob)), size)
+
+	_, err = client.BlobSize("invalid_digest")
+	assert.NotNil(t, err)
+}
+
 func TestPullBlob(t *testing.T) {
 	handler := test.Handler(&test.Response{
 		Headers: map[string]string{
@@ -179,6 +211,129 @@ func TestPushBlob(t *testing.T) {
 	}
 }
 
+func TestPullBlobChunk(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(blob[start : end+1])
+	}
+
+	server := test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  "GET",
+			Pattern: fmt.Sprintf("/v2/%s/blobs/%s", repository, digest),
+			Handler: handler,
+		})
+	defer server.Close()
+
+	client, err := newRepository(server.URL)
+	require.Nil(t, err)
+
+	size, reader, err := client.PullBlobChunk(digest, int64(len(blob)), 1, 2)
+	require.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, int64(2), size)
+	b, err := ioutil.ReadAll(reader)
+	require.Nil(t, err)
+	assert.Equal(t, blob[1:3], b)
+}
+
+func TestPushBlobByChunks(t *testing.T) {
+	location := ""
+	received := []byte{}
+	initUploadHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(http.CanonicalHeaderKey("Content-Length"), "0")
+		w.Header().Add(http.CanonicalHeaderKey("Location"), location)
+		w.Header().Add(http.CanonicalHeaderKey("Range"), "0-0")
+		w.Header().Add(http.CanonicalHeaderKey("Docker-Upload-UUID"), uuid)
+		w.WriteHeader(http.StatusAccepted)
+	}
+	statusHandler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(http.CanonicalHeaderKey("Location"), location)
+		w.Header().Add(http.CanonicalHeaderKey("Range"), fmt.Sprintf("0-%d", len(received)-1))
+		w.WriteHeader(http.StatusNoContent)
+	}
+	patchHandler := func(w http.ResponseWriter, r *http.Request) {
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "%d-%d", &start, &end); err != nil ||
+			start != len(received) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		received = append(received, data...)
+		w.Header().Add(http.CanonicalHeaderKey("Location"), location)
+		w.Header().Add(http.CanonicalHeaderKey("Range"), fmt.Sprintf("0-%d", len(received)-1))
+		w.WriteHeader(http.StatusAccepted)
+	}
+	completeHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("digest") != digest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	server := test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  "POST",
+			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/", repository),
+			Handler: initUploadHandler,
+		},
+		&test.RequestHandlerMapping{
+			Method:  "GET",
+			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, uuid),
+			Handler: statusHandler,
+		},
+		&test.RequestHandlerMapping{
+			Method:  "PATCH",
+			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, uuid),
+			Handler: patchHandler,
+		},
+		&test.RequestHandlerMapping{
+			Method:  "PUT",
+			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, uuid),
+			Handler: completeHandler,
+		})
+	defer server.Close()
+	location = fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, uuid)
+
+	client, err := newRepository(server.URL)
+	require.Nil(t, err)
+
+	loc, err := client.InitiateBlobUpload()
+	require.Nil(t, err)
+	assert.Equal(t, location, loc)
+
+	loc, offset, err := client.GetBlobUploadStatus(loc)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+
+	loc, offset, err = client.PushBlobChunk(loc, offset, 2, bytes.NewReader(blob[:2]))
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), offset)
+
+	// the chunk doesn't start at the offset that the session expects
+	_, _, err = client.PushBlobChunk(loc, 0, 2, bytes.NewReader(blob[2:]))
+	require.NotNil(t, err)
+
+	loc, offset, err = client.GetBlobUploadStatus(loc)
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), offset)
+
+	loc, offset, err = client.PushBlobChunk(loc, offset, 2, bytes.NewReader(blob[2:]))
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), offset)
+
+	require.Nil(t, client.CompleteBlobUpload(loc, digest))
+	assert.Equal(t, blob, received)
+}
+
 func TestDeleteBlob(t *testing.T) {
 	handler := test.Handler(&test.Response{
 		StatusCode: http.StatusAccepted,
@@ -435,15 +590,33 @@ func TestBuildMountBlobURL(t *testing.T) {
 }
 
 func TestMountBlob(t *testing.T) {
+	mounted := true
+	cancelled := false
 	mountHandler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, digest, r.URL.Query().Get("mount"))
+		assert.Equal(t, "library/hi-world", r.URL.Query().Get("from"))
+		if mounted {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/uuid", repository))
 		w.WriteHeader(http.StatusAccepted)
 	}
+	cancelHandler := func(w http.ResponseWriter, r *http.Request) {
+		cancelled = true
+		w.WriteHeader(http.StatusNoContent)
+	}
 
 	server := test.NewServer(
 		&test.RequestHandlerMapping{
 			Method:  "POST",
 			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/", repository),
 			Handler: mountHandler,
+		},
+		&test.RequestHandlerMapping{
+			Method:  "DELETE",
+			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/uuid", repository),
+			Handler: cancelHandler,
 		})
 	defer server.Close()
 
@@ -452,7 +625,15 @@ func TestMountBlob(t *testing.T) {
 		t.Fatalf("failed to create client for repository: %v", err)
 	}
 
-	if err = client.MountBlob(digest, "library/hi-world"); err != nil {
-		t.Fatalf("failed to mount blob: %v", err)
-	}
+	// mounted
+	ok, err := client.MountBlob(digest, "library/hi-world")
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// not mounted, the upload session is cancelled
+	mounted = false
+	ok, err = client.MountBlob(digest, "library/hi-world")
+	require.Nil(t, err)
+	assert.False(t, ok)
+	assert.True(t, cancelled)
 }
@@ -7,6 +7,8 @@ import (
 	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/rbac"
+	rep_event "github.com/goharbor/harbor/src/replication/event"
+	rep_model "github.com/goharbor/harbor/src/replication/model"
 )
 
 const (
@@ -18,6 +20,8 @@ const (
 type ChartLabelAPI struct {
 	LabelResourceAPI
 	project       *models.Project
+	chartName     string
+	version       string
 	chartFullName string
 }
 
@@ -54,6 +58,8 @@ func (cla *ChartLabelAPI) Prepare() {
 		cla.SendNotFoundError(err)
 		return
 	}
+	cla.chartName = chartName
+	cla.version = version
 	cla.chartFullName = fmt.Sprintf("%s/%s:%s", project, chartName, version)
 }
 
@@ -91,7 +97,10 @@ func (cla *ChartLabelAPI) MarkLabel() {
 		ResourceName: cla.chartFullName,
 	}
 
-	cla.markLabelToResource(label2Res)
+	if cla.markLabelToResource(label2Res) {
+		handleLabelEvent(rep_event.EventTypeLabelAdd, rep_model.ResourceTypeChart,
+			fmt.Sprintf("%s/%s", cla.project.Name, cla.chartName), cla.version)
+	}
 }
 
 // RemoveLabel handles the request of removing label from chart.
@@ -111,7 +120,10 @@ func (cla *ChartLabelAPI) RemoveLabel() {
 		return
 	}
 
-	cla.removeLabelFromResource(common.ResourceTypeChart, cla.chartFullName, label.ID)
+	if cla.removeLabelFromResource(common.ResourceTypeChart, cla.chartFullName, label.ID) {
+		handleLabelEvent(rep_event.EventTypeLabelDelete, rep_model.ResourceTypeChart,
+			fmt.Sprintf("%s/%s", cla.project.Name, cla.chartName), cla.version)
+	}
 }
 
 // GetLabels gets labels for the specified chart version.
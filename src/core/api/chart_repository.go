@@ -16,9 +16,11 @@ import (
 	"github.com/goharbor/harbor/src/core/label"
 
 	"github.com/goharbor/harbor/src/chartserver"
+	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/rbac"
 	hlog "github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/pkg/webhook"
 	rep_event "github.com/goharbor/harbor/src/replication/event"
 	"github.com/goharbor/harbor/src/replication/model"
 )
@@ -272,6 +274,7 @@ func (cra *ChartRepositoryAPI) DeleteChartVersion() {
 		cra.ParseAndHandleError("fail to delete chart version", err)
 		return
 	}
+	cra.publishChartDeleteEvent(chartName, version)
 }
 
 // UploadChartVersion handles POST /api/:repo/charts
@@ -301,6 +304,10 @@ func (cra *ChartRepositoryAPI) UploadChartVersion() {
 		if err := cra.addEventContext(formFiles, cra.Ctx.Request); err != nil {
 			hlog.Errorf("Failed to add chart upload context, %v", err)
 		}
+	} else {
+		if err := cra.addEventContextFromBody(cra.Ctx.Request); err != nil {
+			hlog.Errorf("Failed to add chart upload context, %v", err)
+		}
 	}
 
 	// Directly proxy to the backend
@@ -360,6 +367,19 @@ func (cra *ChartRepositoryAPI) DeleteChart() {
 		cra.SendInternalServerError(err)
 		return
 	}
+	for _, chartVersion := range chartVersions {
+		cra.publishChartDeleteEvent(chartName, chartVersion.GetVersion())
+	}
+}
+
+func (cra *ChartRepositoryAPI) publishChartDeleteEvent(chartName, version string) {
+	webhook.Publish(&webhook.Event{
+		Type:     models.WebhookEventDeleteChart,
+		Operator: cra.SecurityCtx.GetUsername(),
+		Project:  cra.namespace,
+		Chart:    chartName,
+		Version:  version,
+	})
 }
 
 func (cra *ChartRepositoryAPI) removeLabelsFromChart(chartName, version string) error {
@@ -433,25 +453,10 @@ func (cra *ChartRepositoryAPI) addEventContext(files []formFile, request *http.R
 				hlog.Errorf("failed to copy file content for upload event, %v", err)
 				return err
 			}
-			chartOpr := chartserver.ChartOperator{}
-			chartDetails, err := chartOpr.GetChartData(Buf.Bytes())
+			e, err := newChartUploadEvent(cra.namespace, Buf.Bytes())
 			if err != nil {
-				hlog.Errorf("failed to get chart content for upload event, %v", err)
 				return err
 			}
-
-			e := &rep_event.Event{
-				Type: rep_event.EventTypeChartUpload,
-				Resource: &model.Resource{
-					Type: model.ResourceTypeChart,
-					Metadata: &model.ResourceMetadata{
-						Repository: &model.Repository{
-							Name: fmt.Sprintf("%s/%s", cra.namespace, chartDetails.Metadata.Name),
-						},
-						Vtags: []string{chartDetails.Metadata.Version},
-					},
-				},
-			}
 			*request = *(request.WithContext(context.WithValue(request.Context(), common.ChartUploadCtxKey, e)))
 			break
 		}
@@ -460,6 +465,51 @@ func (cra *ChartRepositoryAPI) addEventContext(files []formFile, request *http.R
 	return nil
 }
 
+// The func is the same as "addEventContext" but for the request whose body is the chart
+// package itself rather than a multipart form. The body is refilled after reading.
+func (cra *ChartRepositoryAPI) addEventContextFromBody(request *http.Request) error {
+	if request.Body == nil {
+		return nil
+	}
+	data, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		hlog.Errorf("failed to read request body for upload event, %v", err)
+		return err
+	}
+	request.Body.Close()
+	request.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	e, err := newChartUploadEvent(cra.namespace, data)
+	if err != nil {
+		return err
+	}
+	*request = *(request.WithContext(context.WithValue(request.Context(), common.ChartUploadCtxKey, e)))
+	return nil
+}
+
+// build the chart upload event from the content of the chart package
+func newChartUploadEvent(namespace string, content []byte) (*rep_event.Event, error) {
+	chartOpr := chartserver.ChartOperator{}
+	chartDetails, err := chartOpr.GetChartData(content)
+	if err != nil {
+		hlog.Errorf("failed to get chart content for upload event, %v", err)
+		return nil, err
+	}
+
+	return &rep_event.Event{
+		Type: rep_event.EventTypeChartUpload,
+		Resource: &model.Resource{
+			Type: model.ResourceTypeChart,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: fmt.Sprintf("%s/%s", namespace, chartDetails.Metadata.Name),
+				},
+				Vtags: []string{chartDetails.Metadata.Version},
+			},
+		},
+	}, nil
+}
+
 // If the files are uploaded with multipart/form-data mimetype, beego will extract the data
 // from the request automatically. Then the request passed to the backend server with proxying
 // way will have empty content.
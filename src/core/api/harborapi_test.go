@@ -39,6 +39,9 @@ import (
 	_ "github.com/goharbor/harbor/src/core/auth/ldap"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/filter"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/pkg/retention"
+	"github.com/goharbor/harbor/src/pkg/webhook"
 	"github.com/goharbor/harbor/src/replication/model"
 	"github.com/goharbor/harbor/src/testing/apitests/apilib"
 )
@@ -91,6 +94,8 @@ func init() {
 
 	filter.Init()
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.SecurityFilter)
+	retention.Init(coreutils.GetJobServiceClient(), config.InternalCoreURL())
+	webhook.Init(coreutils.GetJobServiceClient(), config.InternalCoreURL())
 
 	beego.Router("/api/health", &HealthAPI{}, "get:CheckHealth")
 	beego.Router("/api/search/", &SearchAPI{})
@@ -104,6 +109,16 @@ func init() {
 	beego.Router("/api/users/:id/sysadmin", &UserAPI{}, "put:ToggleUserAdminRole")
 	beego.Router("/api/projects/:id([0-9]+)/logs", &ProjectAPI{}, "get:Logs")
 	beego.Router("/api/projects/:id([0-9]+)/_deletable", &ProjectAPI{}, "get:Deletable")
+	beego.Router("/api/projects/:id([0-9]+)/quota", &ProjectAPI{}, "get:GetQuota;delete:DeleteQuota")
+	beego.Router("/api/projects/:id([0-9]+)/retention", &RetentionAPI{}, "get:GetPolicy;put:PutPolicy;delete:DeletePolicy")
+	beego.Router("/api/projects/:id([0-9]+)/retention/executions", &RetentionAPI{}, "get:ListExecutions;post:Execute")
+	beego.Router("/api/projects/:id([0-9]+)/retention/executions/:eid([0-9]+)/tasks", &RetentionAPI{}, "get:ListTasks")
+	beego.Router("/api/projects/:id([0-9]+)/retention/executions/:eid([0-9]+)/tasks/:tid([0-9]+)/log", &RetentionAPI{}, "get:GetTaskLog")
+	beego.Router("/api/projects/:id([0-9]+)/immutabletagrules", &ImmutableTagRuleAPI{}, "get:List;post:Post")
+	beego.Router("/api/projects/:id([0-9]+)/immutabletagrules/:rid([0-9]+)", &ImmutableTagRuleAPI{}, "put:Put;delete:Delete")
+	beego.Router("/api/projects/:id([0-9]+)/webhook/endpoints", &WebhookAPI{}, "get:ListEndpoints;post:PostEndpoint")
+	beego.Router("/api/projects/:id([0-9]+)/webhook/endpoints/:eid([0-9]+)", &WebhookAPI{}, "get:GetEndpoint;put:PutEndpoint;delete:DeleteEndpoint")
+	beego.Router("/api/projects/:id([0-9]+)/webhook/endpoints/:eid([0-9]+)/deliveries", &WebhookAPI{}, "get:ListDeliveries")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/?:name", &MetadataAPI{}, "get:Get")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/", &MetadataAPI{}, "post:Post")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/:name", &MetadataAPI{}, "put:Put;delete:Delete")
@@ -126,6 +141,7 @@ func init() {
 	beego.Router("/api/registries", &RegistryAPI{}, "get:List;post:Post")
 	beego.Router("/api/registries/ping", &RegistryAPI{}, "post:Ping")
 	beego.Router("/api/registries/:id([0-9]+)", &RegistryAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/registries/:id([0-9]+)/health", &RegistryAPI{}, "get:GetHealth")
 	beego.Router("/api/systeminfo", &SystemInfoAPI{}, "get:GetGeneralInfo")
 	beego.Router("/api/systeminfo/volumes", &SystemInfoAPI{}, "get:GetVolumeInfo")
 	beego.Router("/api/systeminfo/getcert", &SystemInfoAPI{}, "get:GetCert")
@@ -152,11 +168,16 @@ func init() {
 	beego.Router("/api/replication/adapters", &ReplicationAdapterAPI{}, "get:List")
 	beego.Router("/api/replication/executions", &ReplicationOperationAPI{}, "get:ListExecutions;post:CreateExecution")
 	beego.Router("/api/replication/executions/:id([0-9]+)", &ReplicationOperationAPI{}, "get:GetExecution;put:StopExecution")
+	beego.Router("/api/replication/executions/:id([0-9]+)/retry", &ReplicationOperationAPI{}, "post:RetryExecution")
 	beego.Router("/api/replication/executions/:id([0-9]+)/tasks", &ReplicationOperationAPI{}, "get:ListTasks")
 	beego.Router("/api/replication/executions/:id([0-9]+)/tasks/:tid([0-9]+)/log", &ReplicationOperationAPI{}, "get:GetTaskLog")
 
 	beego.Router("/api/replication/policies", &ReplicationPolicyAPI{}, "get:List;post:Create")
 	beego.Router("/api/replication/policies/:id([0-9]+)", &ReplicationPolicyAPI{}, "get:Get;put:Update;delete:Delete")
+	beego.Router("/api/replication/policies/:id([0-9]+)/plan", &ReplicationPolicyAPI{}, "post:Plan")
+	beego.Router("/api/replication/policies/plan", &ReplicationPolicyAPI{}, "post:PlanUnsaved")
+	beego.Router("/api/replication/policies/export", &ReplicationPolicyAPI{}, "get:Export")
+	beego.Router("/api/replication/policies/import", &ReplicationPolicyAPI{}, "post:Import")
 
 	// Charts are controlled under projects
 	chartRepositoryAPIType := &ChartRepositoryAPI{}
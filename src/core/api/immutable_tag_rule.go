@@ -0,0 +1,167 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/pkg/immutable"
+)
+
+// ImmutableTagRuleAPI handles the requests to the immutable tag rules of the project
+type ImmutableTagRuleAPI struct {
+	BaseController
+	project *models.Project
+}
+
+// Prepare ...
+func (i *ImmutableTagRuleAPI) Prepare() {
+	i.BaseController.Prepare()
+	if !i.SecurityCtx.IsAuthenticated() {
+		i.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+
+	id, err := i.GetInt64FromPath(":id")
+	if err != nil || id <= 0 {
+		i.SendBadRequestError(errors.New("invalid project ID"))
+		return
+	}
+	project, err := i.ProjectMgr.Get(id)
+	if err != nil {
+		i.ParseAndHandleError(fmt.Sprintf("failed to get project %d", id), err)
+		return
+	}
+	if project == nil {
+		i.SendNotFoundError(fmt.Errorf("project %d not found", id))
+		return
+	}
+	i.project = project
+}
+
+func (i *ImmutableTagRuleAPI) requireAccess(action rbac.Action) bool {
+	resource := rbac.NewProjectNamespace(i.project.ProjectID).Resource(rbac.ResourceImmutableTag)
+	if !i.SecurityCtx.Can(action, resource) {
+		i.SendForbiddenError(errors.New(i.SecurityCtx.GetUsername()))
+		return false
+	}
+	return true
+}
+
+// List returns the immutable tag rules of the project
+func (i *ImmutableTagRuleAPI) List() {
+	if !i.requireAccess(rbac.ActionList) {
+		return
+	}
+	rules, err := dao.ListImmutableTagRules(i.project.ProjectID)
+	if err != nil {
+		i.SendInternalServerError(fmt.Errorf("failed to list the immutable tag rules of project %d: %v",
+			i.project.ProjectID, err))
+		return
+	}
+	i.WriteJSONData(rules)
+}
+
+// Post creates an immutable tag rule for the project
+func (i *ImmutableTagRuleAPI) Post() {
+	if !i.requireAccess(rbac.ActionCreate) {
+		return
+	}
+	rule := &models.ImmutableTagRule{}
+	if err := i.DecodeJSONReq(rule); err != nil {
+		i.SendBadRequestError(err)
+		return
+	}
+	if err := immutable.Validate(rule); err != nil {
+		i.SendBadRequestError(err)
+		return
+	}
+	rule.ProjectID = i.project.ProjectID
+	id, err := dao.AddImmutableTagRule(rule)
+	if err != nil {
+		i.SendInternalServerError(fmt.Errorf("failed to add the immutable tag rule to project %d: %v",
+			i.project.ProjectID, err))
+		return
+	}
+	i.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
+}
+
+// Put updates the patterns of the immutable tag rule
+func (i *ImmutableTagRuleAPI) Put() {
+	if !i.requireAccess(rbac.ActionUpdate) {
+		return
+	}
+	rule := i.getRule()
+	if rule == nil {
+		return
+	}
+	req := &models.ImmutableTagRule{}
+	if err := i.DecodeJSONReq(req); err != nil {
+		i.SendBadRequestError(err)
+		return
+	}
+	if err := immutable.Validate(req); err != nil {
+		i.SendBadRequestError(err)
+		return
+	}
+	rule.Repositories = req.Repositories
+	rule.Tags = req.Tags
+	rule.UpdateTime = time.Now()
+	if err := dao.UpdateImmutableTagRule(rule); err != nil {
+		i.SendInternalServerError(fmt.Errorf("failed to update the immutable tag rule %d: %v", rule.ID, err))
+		return
+	}
+}
+
+// Delete deletes the immutable tag rule
+func (i *ImmutableTagRuleAPI) Delete() {
+	if !i.requireAccess(rbac.ActionDelete) {
+		return
+	}
+	rule := i.getRule()
+	if rule == nil {
+		return
+	}
+	if err := dao.DeleteImmutableTagRule(rule.ID); err != nil {
+		i.SendInternalServerError(fmt.Errorf("failed to delete the immutable tag rule %d: %v", rule.ID, err))
+		return
+	}
+}
+
+// get the rule specified in the path, nil is returned and the error is sent if it isn't found
+func (i *ImmutableTagRuleAPI) getRule() *models.ImmutableTagRule {
+	id, err := i.GetInt64FromPath(":rid")
+	if err != nil || id <= 0 {
+		i.SendBadRequestError(errors.New("invalid immutable tag rule ID"))
+		return nil
+	}
+	rule, err := dao.GetImmutableTagRule(id)
+	if err != nil {
+		i.SendInternalServerError(fmt.Errorf("failed to get the immutable tag rule %d: %v", id, err))
+		return nil
+	}
+	if rule == nil || rule.ProjectID != i.project.ProjectID {
+		i.SendNotFoundError(fmt.Errorf("immutable tag rule %d not found", id))
+		return nil
+	}
+	return rule
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmutableTagRuleAPI(t *testing.T) {
+	rule := &models.ImmutableTagRule{
+		Repositories: "library/hello-world",
+		Tags:         "latest",
+	}
+
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/projects/1/immutabletagrules",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404, project not found
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1000/immutabletagrules",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/immutabletagrules",
+				credential: projGuest,
+				bodyJSON:   rule,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, invalid pattern
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/immutabletagrules",
+				credential: projAdmin,
+				bodyJSON: &models.ImmutableTagRule{
+					Tags: "release-[",
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/immutabletagrules",
+				credential: projAdmin,
+				bodyJSON:   rule,
+			},
+			code: http.StatusCreated,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	rules := []*models.ImmutableTagRule{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/immutabletagrules",
+		credential: projAdmin,
+	}, &rules)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(rules))
+	assert.Equal(t, "library/hello-world", rules[0].Repositories)
+	assert.Equal(t, "latest", rules[0].Tags)
+	id := rules[0].ID
+	defer dao.DeleteImmutableTagRule(id)
+
+	cases = []*codeCheckingCase{
+		// 412, the tag is immutable
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/repositories/library/hello-world/tags/latest",
+				credential: projAdmin,
+			},
+			code: http.StatusPreconditionFailed,
+		},
+		// 404, the rule doesn't exist
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/immutabletagrules/10000",
+				credential: projAdmin,
+				bodyJSON:   rule,
+			},
+			code: http.StatusNotFound,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        fmt.Sprintf("/api/projects/1/immutabletagrules/%d", id),
+				credential: projAdmin,
+				bodyJSON: &models.ImmutableTagRule{
+					Repositories: "library/**",
+					Tags:         "release-*",
+				},
+			},
+			code: http.StatusOK,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        fmt.Sprintf("/api/projects/1/immutabletagrules/%d", id),
+				credential: projGuest,
+			},
+			code: http.StatusForbidden,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        fmt.Sprintf("/api/projects/1/immutabletagrules/%d", id),
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
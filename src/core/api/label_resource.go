@@ -5,7 +5,11 @@ import (
 	"strconv"
 
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/label"
+	"github.com/goharbor/harbor/src/replication"
+	rep_event "github.com/goharbor/harbor/src/replication/event"
+	rep_model "github.com/goharbor/harbor/src/replication/model"
 )
 
 // LabelResourceAPI provides the related basic functions to handle marking labels to resources
@@ -33,22 +37,46 @@ func (lra *LabelResourceAPI) getLabelsOfResource(rType string, rIDOrName interfa
 	lra.ServeJSON()
 }
 
-func (lra *LabelResourceAPI) markLabelToResource(rl *models.ResourceLabel) {
+func (lra *LabelResourceAPI) markLabelToResource(rl *models.ResourceLabel) bool {
 	labelID, err := lra.labelManager.MarkLabelToResource(rl)
 	if err != nil {
 		lra.handleErrors(err)
-		return
+		return false
 	}
 
 	// return the ID of label and return status code 200 rather than 201 as the label is not created
 	lra.Redirect(http.StatusOK, strconv.FormatInt(labelID, 10))
+	return true
 }
 
-func (lra *LabelResourceAPI) removeLabelFromResource(rType string, rIDOrName interface{}, labelID int64) {
+func (lra *LabelResourceAPI) removeLabelFromResource(rType string, rIDOrName interface{}, labelID int64) bool {
 	if err := lra.labelManager.RemoveLabelFromResource(rType, rIDOrName, labelID); err != nil {
 		lra.handleErrors(err)
-		return
+		return false
 	}
+	return true
+}
+
+// send the label change of image/chart to the replication handler, so that
+// the event based policies with label filters can take effect
+func handleLabelEvent(eventType string, resourceType rep_model.ResourceType, repository, vtag string) {
+	go func() {
+		e := &rep_event.Event{
+			Type: eventType,
+			Resource: &rep_model.Resource{
+				Type: resourceType,
+				Metadata: &rep_model.ResourceMetadata{
+					Repository: &rep_model.Repository{
+						Name: repository,
+					},
+					Vtags: []string{vtag},
+				},
+			},
+		}
+		if err := replication.EventHandler.Handle(e); err != nil {
+			log.Errorf("failed to handle event: %v", err)
+		}
+	}()
 }
 
 // eat the error of validate method of label manager
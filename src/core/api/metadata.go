@@ -149,6 +149,10 @@ func (m *MetadataAPI) Post() {
 		return
 	}
 
+	if !m.validateProxyCacheRegistry(m.project, ms) {
+		return
+	}
+
 	keys := reflect.ValueOf(ms).MapKeys()
 	mts, err := m.metaMgr.Get(m.project.ProjectID, keys[0].String())
 	if err != nil {
@@ -195,6 +199,10 @@ func (m *MetadataAPI) Put() {
 		return
 	}
 
+	if !m.validateProxyCacheRegistry(m.project, ms) {
+		return
+	}
+
 	if err := m.metaMgr.Update(m.project.ProjectID, map[string]string{
 		m.name: ms[m.name],
 	}); err != nil {
@@ -209,6 +217,11 @@ func (m *MetadataAPI) Delete() {
 		return
 	}
 
+	if m.name == models.ProMetaProxyCacheRegistryID && !m.SecurityCtx.IsSysAdmin() {
+		m.SendForbiddenError(errors.New("only system admin can unset the upstream registry of proxy cache projects"))
+		return
+	}
+
 	if err := m.metaMgr.Delete(m.project.ProjectID, m.name); err != nil {
 		m.SendInternalServerError(fmt.Errorf("failed to delete metadata %s of project %d: %v", m.name, m.project.ProjectID, err))
 		return
@@ -248,5 +261,23 @@ func validateProjectMetadata(metas map[string]string) (map[string]string, error)
 		}
 	}
 
+	value, exist = metas[models.ProMetaProxyCacheRegistryID]
+	if exist {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid proxy cache registry ID %s", value)
+		}
+		metas[models.ProMetaProxyCacheRegistryID] = strconv.FormatInt(id, 10)
+	}
+
+	value, exist = metas[models.ProMetaProxyCacheStaleness]
+	if exist {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid proxy cache staleness %s", value)
+		}
+		metas[models.ProMetaProxyCacheStaleness] = strconv.FormatInt(seconds, 10)
+	}
+
 	return metas, nil
 }
@@ -60,6 +60,30 @@ func TestValidateProjectMetadata(t *testing.T) {
 	ms, err = validateProjectMetadata(metas)
 	require.Nil(t, err)
 	assert.Equal(t, "high", ms[models.ProMetaSeverity])
+
+	// invalid proxy cache registry ID
+	metas = map[string]string{
+		models.ProMetaProxyCacheRegistryID: "0",
+	}
+	ms, err = validateProjectMetadata(metas)
+	require.NotNil(t, err)
+
+	// invalid proxy cache staleness
+	metas = map[string]string{
+		models.ProMetaProxyCacheStaleness: "-1",
+	}
+	ms, err = validateProjectMetadata(metas)
+	require.NotNil(t, err)
+
+	// valid proxy cache settings
+	metas = map[string]string{
+		models.ProMetaProxyCacheRegistryID: "1",
+		models.ProMetaProxyCacheStaleness:  "600",
+	}
+	ms, err = validateProjectMetadata(metas)
+	require.Nil(t, err)
+	assert.Equal(t, "1", ms[models.ProMetaProxyCacheRegistryID])
+	assert.Equal(t, "600", ms[models.ProMetaProxyCacheStaleness])
 }
 
 func TestMetaAPI(t *testing.T) {
@@ -110,3 +134,79 @@ func TestMetaAPI(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, http.StatusNotFound, code)
 }
+
+func TestMetaAPIProxyCacheRegistry(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// project admin cannot set the upstream registry
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/metadatas",
+				credential: projAdmin,
+				bodyJSON: map[string]string{
+					models.ProMetaProxyCacheRegistryID: "1",
+				},
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/metadatas/" + models.ProMetaProxyCacheRegistryID,
+				credential: projAdmin,
+				bodyJSON: map[string]string{
+					models.ProMetaProxyCacheRegistryID: "1",
+				},
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/projects/1/metadatas/" + models.ProMetaProxyCacheRegistryID,
+				credential: projAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1",
+				credential: projAdmin,
+				bodyJSON: map[string]interface{}{
+					"metadata": map[string]string{
+						models.ProMetaProxyCacheRegistryID: "1",
+					},
+				},
+			},
+			code: http.StatusForbidden,
+		},
+		// invalid registry ID
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1",
+				credential: sysAdmin,
+				bodyJSON: map[string]interface{}{
+					"metadata": map[string]string{
+						models.ProMetaProxyCacheRegistryID: "abc",
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// the registry must exist
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/metadatas",
+				credential: sysAdmin,
+				bodyJSON: map[string]string{
+					models.ProMetaProxyCacheRegistryID: "10000",
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
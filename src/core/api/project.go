@@ -27,6 +27,10 @@ import (
 	errutil "github.com/goharbor/harbor/src/common/utils/error"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/pkg/quota"
+	"github.com/goharbor/harbor/src/pkg/retention"
+	"github.com/goharbor/harbor/src/pkg/webhook"
+	"github.com/goharbor/harbor/src/replication"
 
 	"errors"
 	"strconv"
@@ -146,6 +150,14 @@ func (p *ProjectAPI) Post() {
 		return
 	}
 
+	if !p.validateProxyCacheRegistry(nil, pro.Metadata) {
+		return
+	}
+
+	if !p.validateQuota(pro.Quota) {
+		return
+	}
+
 	if pro.Metadata == nil {
 		pro.Metadata = map[string]string{}
 	}
@@ -189,6 +201,14 @@ func (p *ProjectAPI) Post() {
 		return
 	}
 
+	if pro.Quota != nil {
+		pro.Quota.ProjectID = projectID
+		if err = quota.NewDefaultManager(config.QuotaDefaults).Set(pro.Quota); err != nil {
+			p.SendInternalServerError(fmt.Errorf("failed to set the quota of project %d: %v", projectID, err))
+			return
+		}
+	}
+
 	go func() {
 		if err = dao.AddAccessLog(
 			models.AccessLog{
@@ -206,6 +226,56 @@ func (p *ProjectAPI) Post() {
 	p.Redirect(http.StatusCreated, strconv.FormatInt(projectID, 10))
 }
 
+// make sure the upstream registry of the proxy cache project is set by system admin and exists, it
+// must be called on every path writing the metadata as the credential of the registry is used to pull.
+// The "project" is the one being updated, nil when creating, the unchanged registry isn't restricted
+func (b *BaseController) validateProxyCacheRegistry(project *models.Project, metas map[string]string) bool {
+	value, exist := metas[models.ProMetaProxyCacheRegistryID]
+	if !exist {
+		return true
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		b.SendBadRequestError(fmt.Errorf("invalid registry id: %s", value))
+		return false
+	}
+	if project != nil {
+		if current, ok := project.ProxyCacheRegistryID(); ok && current == id {
+			return true
+		}
+	}
+	if !b.SecurityCtx.IsSysAdmin() {
+		b.SendForbiddenError(errors.New("only system admin can set the upstream registry of proxy cache projects"))
+		return false
+	}
+	registry, err := replication.RegistryMgr.Get(id)
+	if err != nil {
+		b.SendInternalServerError(fmt.Errorf("failed to get registry %d: %v", id, err))
+		return false
+	}
+	if registry == nil {
+		b.SendBadRequestError(fmt.Errorf("registry %d not found", id))
+		return false
+	}
+	return true
+}
+
+// only system admin can override the default quota of the project
+func (p *ProjectAPI) validateQuota(q *models.Quota) bool {
+	if q == nil {
+		return true
+	}
+	if !p.SecurityCtx.IsSysAdmin() {
+		p.SendForbiddenError(errors.New("only system admin can set the quota of projects"))
+		return false
+	}
+	if err := quota.Validate(q); err != nil {
+		p.SendBadRequestError(fmt.Errorf("invalid quota: %v", err))
+		return false
+	}
+	return true
+}
+
 // Head ...
 func (p *ProjectAPI) Head() {
 	name := p.GetString("project_name")
@@ -260,6 +330,19 @@ func (p *ProjectAPI) Delete() {
 		return
 	}
 
+	if err = quota.NewDefaultManager(config.QuotaDefaults).Reset(p.project.ProjectID); err != nil {
+		log.Errorf("failed to delete the quota of project %d: %v", p.project.ProjectID, err)
+	}
+	if err = retention.Ctl.DeletePolicy(p.project.ProjectID); err != nil {
+		log.Errorf("failed to delete the retention policy of project %d: %v", p.project.ProjectID, err)
+	}
+	if err = dao.DeleteImmutableTagRulesOfProject(p.project.ProjectID); err != nil {
+		log.Errorf("failed to delete the immutable tag rules of project %d: %v", p.project.ProjectID, err)
+	}
+	if err = webhook.Ctl.DeleteEndpointsOfProject(p.project.ProjectID); err != nil {
+		log.Errorf("failed to delete the webhook endpoints of project %d: %v", p.project.ProjectID, err)
+	}
+
 	go func() {
 		if err := dao.AddAccessLog(models.AccessLog{
 			Username:  p.SecurityCtx.GetUsername(),
@@ -459,6 +542,14 @@ func (p *ProjectAPI) Put() {
 		return
 	}
 
+	if !p.validateProxyCacheRegistry(p.project, req.Metadata) {
+		return
+	}
+
+	if !p.validateQuota(req.Quota) {
+		return
+	}
+
 	if err := p.ProjectMgr.Update(p.project.ProjectID,
 		&models.Project{
 			Metadata:     req.Metadata,
@@ -468,6 +559,65 @@ func (p *ProjectAPI) Put() {
 			p.project.ProjectID), err)
 		return
 	}
+
+	if req.Quota != nil {
+		req.Quota.ProjectID = p.project.ProjectID
+		if err := quota.NewDefaultManager(config.QuotaDefaults).Set(req.Quota); err != nil {
+			p.SendInternalServerError(fmt.Errorf("failed to set the quota of project %d: %v",
+				p.project.ProjectID, err))
+			return
+		}
+	}
+}
+
+type quotaResp struct {
+	Quota *models.Quota      `json:"quota"`
+	Usage *models.QuotaUsage `json:"usage"`
+}
+
+// GetQuota returns the quota of the project, the default one is returned if the project
+// has no quota set, and the current usage of the project
+func (p *ProjectAPI) GetQuota() {
+	if !p.requireAccess(rbac.ActionRead) {
+		return
+	}
+
+	mgr := quota.NewDefaultManager(config.QuotaDefaults)
+	q, err := mgr.Get(p.project.ProjectID)
+	if err != nil {
+		p.SendInternalServerError(fmt.Errorf("failed to get the quota of project %d: %v",
+			p.project.ProjectID, err))
+		return
+	}
+	usage, err := mgr.GetUsage(p.project.ProjectID)
+	if err != nil {
+		p.SendInternalServerError(fmt.Errorf("failed to get the usage of project %d: %v",
+			p.project.ProjectID, err))
+		return
+	}
+
+	p.WriteJSONData(&quotaResp{
+		Quota: q,
+		Usage: usage,
+	})
+}
+
+// DeleteQuota removes the quota set for the project, the default quota takes effect after that
+func (p *ProjectAPI) DeleteQuota() {
+	if !p.SecurityCtx.IsAuthenticated() {
+		p.SendUnAuthorizedError(errors.New("Unauthorized"))
+		return
+	}
+	if !p.SecurityCtx.IsSysAdmin() {
+		p.SendForbiddenError(errors.New(p.SecurityCtx.GetUsername()))
+		return
+	}
+
+	if err := quota.NewDefaultManager(config.QuotaDefaults).Reset(p.project.ProjectID); err != nil {
+		p.SendInternalServerError(fmt.Errorf("failed to delete the quota of project %d: %v",
+			p.project.ProjectID, err))
+		return
+	}
 }
 
 // Logs ...
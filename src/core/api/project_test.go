@@ -423,3 +423,106 @@ func TestDeletable(t *testing.T) {
 	assert.Equal(t, http.StatusOK, code)
 	assert.False(t, del)
 }
+
+func TestProjectQuota(t *testing.T) {
+	q := &models.Quota{
+		StorageHard: 1024,
+		StorageSoft: 512,
+		CountHard:   10,
+		CountSoft:   -1,
+	}
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/projects/1/quota",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, only system admin can set the quota
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1",
+				credential: projAdmin,
+				bodyJSON: &models.ProjectRequest{
+					Quota: q,
+				},
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, invalid quota
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1",
+				credential: sysAdmin,
+				bodyJSON: &models.ProjectRequest{
+					Quota: &models.Quota{
+						StorageHard: 512,
+						StorageSoft: 1024,
+						CountHard:   -1,
+						CountSoft:   -1,
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1",
+				credential: sysAdmin,
+				bodyJSON: &models.ProjectRequest{
+					Quota: q,
+				},
+			},
+			code: http.StatusOK,
+		},
+		// 403, only system admin can delete the quota
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/projects/1/quota",
+				credential: projAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	resp := &quotaResp{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/quota",
+		credential: projAdmin,
+	}, resp)
+	require.Nil(t, err)
+	require.NotNil(t, resp.Quota)
+	assert.Equal(t, int64(1), resp.Quota.ProjectID)
+	assert.Equal(t, int64(1024), resp.Quota.StorageHard)
+	assert.Equal(t, int64(10), resp.Quota.CountHard)
+	require.NotNil(t, resp.Usage)
+
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodDelete,
+			url:        "/api/projects/1/quota",
+			credential: sysAdmin,
+		},
+		code: http.StatusOK,
+	})
+
+	// the default quota takes effect after the quota is deleted
+	resp = &quotaResp{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/quota",
+		credential: sysAdmin,
+	}, resp)
+	require.Nil(t, err)
+	require.NotNil(t, resp.Quota)
+	assert.Equal(t, models.QuotaUnlimited, resp.Quota.StorageHard)
+}
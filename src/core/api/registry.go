@@ -13,6 +13,7 @@ import (
 	"github.com/goharbor/harbor/src/core/api/models"
 	"github.com/goharbor/harbor/src/replication"
 	"github.com/goharbor/harbor/src/replication/adapter"
+	rep_models "github.com/goharbor/harbor/src/replication/dao/models"
 	"github.com/goharbor/harbor/src/replication/event"
 	"github.com/goharbor/harbor/src/replication/model"
 	"github.com/goharbor/harbor/src/replication/policy"
@@ -282,8 +283,18 @@ func (t *RegistryAPI) Put() {
 	if req.Insecure != nil {
 		r.Insecure = *req.Insecure
 	}
+	if req.MaxConcurrentTasks != nil {
+		r.MaxConcurrentTasks = *req.MaxConcurrentTasks
+	}
+	if req.MaxBytesPerSecond != nil {
+		r.MaxBytesPerSecond = *req.MaxBytesPerSecond
+	}
 
-	t.Validate(r)
+	isValid, err := t.Validate(r)
+	if !isValid {
+		t.SendBadRequestError(err)
+		return
+	}
 
 	if r.Name != originalName {
 		reg, err := t.manager.GetByName(r.Name)
@@ -424,6 +435,43 @@ func (t *RegistryAPI) GetInfo() {
 	t.WriteJSONData(process(info))
 }
 
+// GetHealth returns the health check history of the registry, the latest one first
+func (t *RegistryAPI) GetHealth() {
+	id, err := t.GetIDFromURL()
+	if err != nil {
+		t.SendBadRequestError(err)
+		return
+	}
+
+	r, err := t.manager.Get(id)
+	if err != nil {
+		t.SendInternalServerError(fmt.Errorf("failed to get registry %d: %v", id, err))
+		return
+	}
+	if r == nil {
+		t.SendNotFoundError(fmt.Errorf("registry %d not found", id))
+		return
+	}
+
+	page, size, err := t.GetPaginationParams()
+	if err != nil {
+		t.SendBadRequestError(err)
+		return
+	}
+	query := &rep_models.RegistryHealthQuery{
+		RegistryID: id,
+	}
+	query.Page = page
+	query.Size = size
+	total, histories, err := t.manager.ListHealth(query)
+	if err != nil {
+		t.SendInternalServerError(fmt.Errorf("failed to list health check history of registry %d: %v", id, err))
+		return
+	}
+	t.SetPaginationHeader(total, page, size)
+	t.WriteJSONData(histories)
+}
+
 // GetNamespace get the namespace of a registry
 // TODO remove
 func (t *RegistryAPI) GetNamespace() {
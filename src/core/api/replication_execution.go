@@ -188,6 +188,68 @@ func (r *ReplicationOperationAPI) StopExecution() {
 	}
 }
 
+// RetryExecution retries the failed and stopped tasks of one execution
+func (r *ReplicationOperationAPI) RetryExecution() {
+	executionID, err := r.GetInt64FromPath(":id")
+	if err != nil || executionID <= 0 {
+		r.SendBadRequestError(errors.New("invalid execution ID"))
+		return
+	}
+	execution, err := replication.OperationCtl.GetExecution(executionID)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to get execution %d: %v", executionID, err))
+		return
+	}
+	if execution == nil {
+		r.SendNotFoundError(fmt.Errorf("execution %d not found", executionID))
+		return
+	}
+	if execution.Status == models.ExecutionStatusInProgress {
+		r.SendConflictError(fmt.Errorf("execution %d is in progress", executionID))
+		return
+	}
+
+	total, _, err := replication.OperationCtl.ListTasks(&models.TaskQuery{
+		ExecutionID: executionID,
+		Statuses:    []string{models.TaskStatusFailed, models.TaskStatusStopped},
+		Pagination: models.Pagination{
+			Page: 1,
+			Size: 1,
+		},
+	})
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to list tasks of execution %d: %v", executionID, err))
+		return
+	}
+	if total == 0 {
+		r.SendBadRequestError(fmt.Errorf("execution %d has no failed or stopped tasks", executionID))
+		return
+	}
+
+	policy, err := replication.PolicyCtl.Get(execution.PolicyID)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to get policy %d: %v", execution.PolicyID, err))
+		return
+	}
+	if policy == nil {
+		r.SendNotFoundError(fmt.Errorf("policy %d not found", execution.PolicyID))
+		return
+	}
+	if !policy.Enabled {
+		r.SendBadRequestError(fmt.Errorf("the policy %d is disabled", execution.PolicyID))
+		return
+	}
+	if err = event.PopulateRegistries(replication.RegistryMgr, policy); err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to populate registries for policy %d: %v", execution.PolicyID, err))
+		return
+	}
+
+	if err = replication.OperationCtl.RetryReplication(policy, executionID); err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to retry execution %d: %v", executionID, err))
+		return
+	}
+}
+
 // ListTasks ...
 func (r *ReplicationOperationAPI) ListTasks() {
 	executionID, err := r.GetInt64FromPath(":id")
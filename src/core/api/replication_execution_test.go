@@ -29,9 +29,18 @@ type fakedOperationController struct{}
 func (f *fakedOperationController) StartReplication(policy *model.Policy, resource *model.Resource, trigger model.TriggerType) (int64, error) {
 	return 1, nil
 }
+func (f *fakedOperationController) PlanReplication(policy *model.Policy) (*model.Plan, error) {
+	return &model.Plan{
+		PolicyID: policy.ID,
+		Items:    []*model.PlanItem{},
+	}, nil
+}
 func (f *fakedOperationController) StopReplication(int64) error {
 	return nil
 }
+func (f *fakedOperationController) RetryReplication(policy *model.Policy, executionID int64) error {
+	return nil
+}
 func (f *fakedOperationController) ListExecutions(...*models.ExecutionQuery) (int64, []*models.Execution, error) {
 	return 1, []*models.Execution{
 		{
@@ -323,6 +332,60 @@ func TestStopExecution(t *testing.T) {
 	runCodeCheckingCases(t, cases...)
 }
 
+func TestRetryExecution(t *testing.T) {
+	operationCtl := replication.OperationCtl
+	policyMgr := replication.PolicyCtl
+	registryMgr := replication.RegistryMgr
+	defer func() {
+		replication.OperationCtl = operationCtl
+		replication.PolicyCtl = policyMgr
+		replication.RegistryMgr = registryMgr
+	}()
+	replication.OperationCtl = &fakedOperationController{}
+	replication.PolicyCtl = &fakedPolicyManager{}
+	replication.RegistryMgr = &fakedRegistryManager{}
+
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/replication/executions/1/retry",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/executions/1/retry",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/executions/2/retry",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/executions/1/retry",
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	runCodeCheckingCases(t, cases...)
+}
+
 func TestListTasks(t *testing.T) {
 	operationCtl := replication.OperationCtl
 	defer func() {
@@ -25,11 +25,15 @@ import (
 	"github.com/goharbor/harbor/src/replication/dao/models"
 	"github.com/goharbor/harbor/src/replication/event"
 	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/policy/declarative"
 	"github.com/goharbor/harbor/src/replication/registry"
 )
 
 // TODO rename the file to "replication.go"
 
+// the max size of the declarative document imported
+const maxPolicyDocumentSize = 4 << 20
+
 // ReplicationPolicyAPI handles the replication policy requests
 type ReplicationPolicyAPI struct {
 	BaseController
@@ -96,6 +100,13 @@ func (r *ReplicationPolicyAPI) Create() {
 	}
 
 	policy.Creator = r.SecurityCtx.GetUsername()
+	// flag the policy at once if its registries are unhealthy rather than waiting for the next health check
+	srcRegistryID, destRegistryID := registryIDs(policy)
+	policy.HealthWarning, err = registry.PolicyHealthWarning(replication.RegistryMgr, srcRegistryID, destRegistryID)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to check the health status of the registries of the policy: %v", err))
+		return
+	}
 	id, err := replication.PolicyCtl.Create(policy)
 	if err != nil {
 		r.SendInternalServerError(fmt.Errorf("failed to create the policy: %v", err))
@@ -118,22 +129,23 @@ func (r *ReplicationPolicyAPI) validateName(policy *model.Policy) bool {
 	return true
 }
 
-// make sure the registry referred exists
+// make sure the registries referred exist, both the source registry
+// and destination registry can be remote ones
 func (r *ReplicationPolicyAPI) validateRegistry(policy *model.Policy) bool {
-	var registryID int64
-	if policy.SrcRegistry != nil && policy.SrcRegistry.ID > 0 {
-		registryID = policy.SrcRegistry.ID
-	} else {
-		registryID = policy.DestRegistry.ID
-	}
-	registry, err := replication.RegistryMgr.Get(registryID)
-	if err != nil {
-		r.SendConflictError(fmt.Errorf("failed to get registry %d: %v", registryID, err))
-		return false
-	}
-	if registry == nil {
-		r.SendBadRequestError(fmt.Errorf("registry %d not found", registryID))
-		return false
+	for _, reg := range []*model.Registry{policy.SrcRegistry, policy.DestRegistry} {
+		// the local Harbor registry
+		if reg == nil || reg.ID == 0 {
+			continue
+		}
+		registry, err := replication.RegistryMgr.Get(reg.ID)
+		if err != nil {
+			r.SendConflictError(fmt.Errorf("failed to get registry %d: %v", reg.ID, err))
+			return false
+		}
+		if registry == nil {
+			r.SendBadRequestError(fmt.Errorf("registry %d not found", reg.ID))
+			return false
+		}
 	}
 	return true
 }
@@ -198,12 +210,36 @@ func (r *ReplicationPolicyAPI) Update() {
 	}
 
 	policy.ID = id
+	// the health warning is maintained by the registry health check, it's recomputed
+	// if the source or destination registry is changed
+	policy.HealthWarning = originalPolicy.HealthWarning
+	srcRegistryID, destRegistryID := registryIDs(policy)
+	originalSrcRegistryID, originalDestRegistryID := registryIDs(originalPolicy)
+	if srcRegistryID != originalSrcRegistryID || destRegistryID != originalDestRegistryID {
+		policy.HealthWarning, err = registry.PolicyHealthWarning(replication.RegistryMgr, srcRegistryID, destRegistryID)
+		if err != nil {
+			r.SendInternalServerError(fmt.Errorf("failed to check the health status of the registries of policy %d: %v", id, err))
+			return
+		}
+	}
 	if err := replication.PolicyCtl.Update(policy); err != nil {
 		r.SendInternalServerError(fmt.Errorf("failed to update the policy %d: %v", id, err))
 		return
 	}
 }
 
+// registryIDs returns the IDs of the source and destination registries of the policy
+func registryIDs(policy *model.Policy) (int64, int64) {
+	var srcRegistryID, destRegistryID int64
+	if policy.SrcRegistry != nil {
+		srcRegistryID = policy.SrcRegistry.ID
+	}
+	if policy.DestRegistry != nil {
+		destRegistryID = policy.DestRegistry.ID
+	}
+	return srcRegistryID, destRegistryID
+}
+
 // Delete the replication policy
 func (r *ReplicationPolicyAPI) Delete() {
 	id, err := r.GetInt64FromPath(":id")
@@ -239,6 +275,192 @@ func (r *ReplicationPolicyAPI) Delete() {
 	}
 }
 
+// Plan returns what the replication of the specified policy would do without running it
+func (r *ReplicationPolicyAPI) Plan() {
+	id, err := r.GetInt64FromPath(":id")
+	if id <= 0 || err != nil {
+		r.SendBadRequestError(errors.New("invalid policy ID"))
+		return
+	}
+
+	policy, err := replication.PolicyCtl.Get(id)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to get the policy %d: %v", id, err))
+		return
+	}
+	if policy == nil {
+		r.SendNotFoundError(fmt.Errorf("policy %d not found", id))
+		return
+	}
+	r.plan(policy)
+}
+
+// PlanUnsaved returns what the replication of the policy in the request body
+// would do, the policy doesn't need to be saved before
+func (r *ReplicationPolicyAPI) PlanUnsaved() {
+	policy := &model.Policy{}
+	isValid, err := r.DecodeJSONReqAndValidate(policy)
+	if !isValid {
+		r.SendBadRequestError(err)
+		return
+	}
+	if !r.validateRegistry(policy) {
+		return
+	}
+	// convert the filters in the same way as they are loaded from database
+	if err = normalizeFilters(policy); err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+	r.plan(policy)
+}
+
+func (r *ReplicationPolicyAPI) plan(policy *model.Policy) {
+	if err := event.PopulateRegistries(replication.RegistryMgr, policy); err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to populate registries for policy %d: %v", policy.ID, err))
+		return
+	}
+	plan, err := replication.OperationCtl.PlanReplication(policy)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to build the replication plan for policy %d: %v", policy.ID, err))
+		return
+	}
+	r.WriteJSONData(plan)
+}
+
+// Export the replication policies as a declarative document in YAML or JSON format
+func (r *ReplicationPolicyAPI) Export() {
+	format := r.GetString("format", declarative.FormatYAML)
+	if format != declarative.FormatYAML && format != declarative.FormatJSON {
+		r.SendBadRequestError(fmt.Errorf("unsupported format %s", format))
+		return
+	}
+	_, policies, err := replication.PolicyCtl.List(&model.PolicyQuery{
+		Name: r.GetString("name"),
+	})
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to list policies: %v", err))
+		return
+	}
+	doc, err := declarative.Export(replication.RegistryMgr, policies...)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to export policies: %v", err))
+		return
+	}
+	data, err := declarative.Marshal(doc, format)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to marshal policies: %v", err))
+		return
+	}
+	contentType := "application/x-yaml"
+	if format == declarative.FormatJSON {
+		contentType = "application/json"
+	}
+	r.Ctx.Output.Header("Content-Type", contentType)
+	r.Ctx.Output.Header("Content-Disposition", "attachment; filename=replication-policies."+format)
+	r.Ctx.Output.Body(data)
+}
+
+// Import the replication policies from the declarative document in YAML or JSON format. The
+// existing policies are updated only when "upsert" is true and the ones not declared in the
+// document are removed when "prune" is true. Nothing is changed when "dry_run" is true
+func (r *ReplicationPolicyAPI) Import() {
+	options := &declarative.Options{
+		Creator: r.SecurityCtx.GetUsername(),
+	}
+	var dryRun bool
+	var err error
+	for _, param := range []struct {
+		name  string
+		value *bool
+	}{
+		{"dry_run", &dryRun},
+		{"upsert", &options.Upsert},
+		{"prune", &options.Prune},
+	} {
+		if *param.value, err = r.GetBool(param.name, false); err != nil {
+			r.SendBadRequestError(fmt.Errorf("invalid %s: %s", param.name, r.GetString(param.name)))
+			return
+		}
+	}
+
+	// read one more byte than the limit to tell the oversize document from the one just fits
+	data := r.Ctx.Input.CopyBody(maxPolicyDocumentSize + 1)
+	if len(data) > maxPolicyDocumentSize {
+		r.RenderFormattedError(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("the document exceeds the limit of %d bytes", maxPolicyDocumentSize))
+		return
+	}
+	doc, err := declarative.Parse(data)
+	if err != nil {
+		r.SendBadRequestError(fmt.Errorf("invalid document: %v", err))
+		return
+	}
+	plan, err := declarative.NewPlan(replication.PolicyCtl, replication.RegistryMgr, doc, options)
+	if err != nil {
+		if _, ok := err.(*declarative.InvalidError); ok {
+			r.SendBadRequestError(err)
+			return
+		}
+		r.SendInternalServerError(fmt.Errorf("failed to build the import plan: %v", err))
+		return
+	}
+	for _, policy := range plan.Deletions {
+		isRunning, err := hasRunningExecutions(policy.ID)
+		if err != nil {
+			r.SendInternalServerError(fmt.Errorf("failed to check the execution status of policy %d: %v", policy.ID, err))
+			return
+		}
+		if isRunning {
+			r.SendPreconditionFailedError(fmt.Errorf("the policy %s has running executions, can not be deleted", policy.Name))
+			return
+		}
+	}
+
+	if !dryRun {
+		if err = plan.Apply(replication.PolicyCtl); err != nil {
+			// the policies are changed by others after the plan is built
+			if _, ok := err.(*declarative.InvalidError); ok {
+				r.SendConflictError(err)
+				return
+			}
+			r.SendInternalServerError(err)
+			return
+		}
+	}
+	r.WriteJSONData(plan.Summary(dryRun))
+}
+
+// the values of the resource and label filters decoded from the request are
+// string and []interface{}, convert them to the types that replication flows use
+func normalizeFilters(policy *model.Policy) error {
+	for _, filter := range policy.Filters {
+		switch filter.Type {
+		case model.FilterTypeResource:
+			value, ok := filter.Value.(string)
+			if !ok {
+				return fmt.Errorf("invalid resource filter: %v", filter.Value)
+			}
+			filter.Value = model.ResourceType(value)
+		case model.FilterTypeLabel:
+			values, ok := filter.Value.([]interface{})
+			if !ok {
+				return fmt.Errorf("invalid label filter: %v", filter.Value)
+			}
+			labels := []string{}
+			for _, value := range values {
+				label, ok := value.(string)
+				if !ok {
+					return fmt.Errorf("invalid label filter: %v", filter.Value)
+				}
+				labels = append(labels, label)
+			}
+			filter.Value = labels
+		}
+	}
+	return nil
+}
+
 // the execution's status will not be updated if it is not queried
 // so need to check the status of tasks to determine the status of
 // the execution
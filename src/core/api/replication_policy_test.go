@@ -16,10 +16,13 @@ package api
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/goharbor/harbor/src/replication"
+	"github.com/goharbor/harbor/src/replication/dao/models"
 	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/policy/declarative"
 )
 
 // TODO rename the file to "replication.go"
@@ -52,6 +55,9 @@ func (f *fakedRegistryManager) Remove(int64) error {
 func (f *fakedRegistryManager) HealthCheck() error {
 	return nil
 }
+func (f *fakedRegistryManager) ListHealth(*models.RegistryHealthQuery) (int64, []*models.RegistryHealth, error) {
+	return 0, nil, nil
+}
 
 func TestReplicationPolicyAPIList(t *testing.T) {
 	policyMgr := replication.PolicyCtl
@@ -174,6 +180,24 @@ func TestReplicationPolicyAPICreate(t *testing.T) {
 			},
 			code: http.StatusBadRequest,
 		},
+		// 400, destination registry not found
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies",
+				credential: sysAdmin,
+				bodyJSON: &model.Policy{
+					Name: "policy01",
+					SrcRegistry: &model.Registry{
+						ID: 1,
+					},
+					DestRegistry: &model.Registry{
+						ID: 2,
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
 		// 201
 		{
 			request: &testingRequest{
@@ -391,3 +415,210 @@ func TestReplicationPolicyAPIDelete(t *testing.T) {
 
 	runCodeCheckingCases(t, cases...)
 }
+
+func TestReplicationPolicyAPIPlan(t *testing.T) {
+	policyMgr := replication.PolicyCtl
+	registryMgr := replication.RegistryMgr
+	operationCtl := replication.OperationCtl
+	defer func() {
+		replication.PolicyCtl = policyMgr
+		replication.RegistryMgr = registryMgr
+		replication.OperationCtl = operationCtl
+	}()
+	replication.PolicyCtl = &fakedPolicyManager{}
+	replication.RegistryMgr = &fakedRegistryManager{}
+	replication.OperationCtl = &fakedOperationController{}
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/replication/policies/1/plan",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/1/plan",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404, policy not found
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/3/plan",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/1/plan",
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 400, invalid unsaved policy
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/plan",
+				credential: sysAdmin,
+				bodyJSON: &model.Policy{
+					SrcRegistry: &model.Registry{
+						ID: 1,
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, registry of unsaved policy not found
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/plan",
+				credential: sysAdmin,
+				bodyJSON: &model.Policy{
+					Name: "policy01",
+					SrcRegistry: &model.Registry{
+						ID: 2,
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200, unsaved policy
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/plan",
+				credential: sysAdmin,
+				bodyJSON: &model.Policy{
+					Name: "policy01",
+					SrcRegistry: &model.Registry{
+						ID: 1,
+					},
+					Filters: []*model.Filter{
+						{
+							Type:  model.FilterTypeResource,
+							Value: "image",
+						},
+					},
+				},
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	runCodeCheckingCases(t, cases...)
+}
+
+func TestReplicationPolicyAPIExportAndImport(t *testing.T) {
+	policyMgr := replication.PolicyCtl
+	registryMgr := replication.RegistryMgr
+	defer func() {
+		replication.PolicyCtl = policyMgr
+		replication.RegistryMgr = registryMgr
+	}()
+	replication.PolicyCtl = &fakedPolicyManager{}
+	replication.RegistryMgr = &fakedRegistryManager{}
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/replication/policies/export",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/import",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, unsupported format
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/replication/policies/export?format=xml",
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/replication/policies/export?format=json",
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 400, invalid dry_run
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/import?dry_run=maybe",
+				credential: sysAdmin,
+				bodyJSON:   &declarative.Document{},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, registry not found
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/import?dry_run=true",
+				credential: sysAdmin,
+				bodyJSON: &declarative.Document{
+					Policies: []*declarative.Policy{
+						{
+							Name:         "policy01",
+							DestRegistry: "unknown",
+						},
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 413
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/import?dry_run=true",
+				credential: sysAdmin,
+				bodyJSON: &declarative.Document{
+					Policies: []*declarative.Policy{
+						{
+							Name:        "policy01",
+							Description: strings.Repeat("a", maxPolicyDocumentSize),
+						},
+					},
+				},
+			},
+			code: http.StatusRequestEntityTooLarge,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/replication/policies/import?dry_run=true&upsert=true&prune=true",
+				credential: sysAdmin,
+				bodyJSON:   &declarative.Document{},
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	runCodeCheckingCases(t, cases...)
+}
@@ -39,7 +39,11 @@ import (
 	"github.com/goharbor/harbor/src/common/utils/notary"
 	"github.com/goharbor/harbor/src/common/utils/registry"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/proxy"
 	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/pkg/immutable"
+	"github.com/goharbor/harbor/src/pkg/quota"
+	"github.com/goharbor/harbor/src/pkg/webhook"
 	"github.com/goharbor/harbor/src/replication"
 	"github.com/goharbor/harbor/src/replication/event"
 	"github.com/goharbor/harbor/src/replication/model"
@@ -101,6 +105,7 @@ type tagResp struct {
 	Signature    *notary.Target          `json:"signature"`
 	ScanOverview *models.ImgScanOverview `json:"scan_overview,omitempty"`
 	Labels       []*models.Label         `json:"labels"`
+	PushTime     *time.Time              `json:"push_time,omitempty"`
 }
 
 type manifestResp struct {
@@ -289,6 +294,15 @@ func (ra *RepositoryAPI) Delete() {
 		tags = append(tags, tag)
 	}
 
+	// the registry deletes the manifest the tag references, so all the tags sharing
+	// the digest are removed along with it and each of them must be mutable
+	if len(tag) > 0 && !ra.requireMutableDigests(project.ProjectID, rc, repoName, tags) {
+		return
+	}
+	if len(tag) == 0 && !ra.requireMutable(project.ProjectID, repoName, tags...) {
+		return
+	}
+
 	if config.WithNotary() {
 		signedTags, err := getSignatures(ra.SecurityCtx.GetUsername(), repoName)
 		if err != nil {
@@ -330,6 +344,16 @@ func (ra *RepositoryAPI) Delete() {
 		}
 		log.Infof("delete tag: %s:%s", repoName, t)
 
+		// the manifest is deleted from registry, so are all the tags referencing it
+		artifact, err := dao.GetArtifact(repoName, t)
+		if err != nil {
+			log.Errorf("failed to get the artifact %s:%s: %v", repoName, t, err)
+		} else if artifact != nil {
+			if err = dao.DeleteArtifactsByDigest(repoName, artifact.Digest); err != nil {
+				log.Errorf("failed to delete the artifacts of %s:%s: %v", repoName, t, err)
+			}
+		}
+
 		go func(tag string) {
 			e := &event.Event{
 				Type: event.EventTypeImageDelete,
@@ -361,6 +385,14 @@ func (ra *RepositoryAPI) Delete() {
 				log.Errorf("failed to add access log: %v", err)
 			}
 		}(t)
+
+		webhook.Publish(&webhook.Event{
+			Type:       models.WebhookEventDeleteImage,
+			Operator:   ra.SecurityCtx.GetUsername(),
+			Project:    project.Name,
+			Repository: repoName,
+			Tag:        t,
+		})
 	}
 
 	exist, err := repositoryExist(repoName, rc)
@@ -391,7 +423,94 @@ func (ra *RepositoryAPI) Delete() {
 			ra.SendInternalServerError(fmt.Errorf("failed to delete repository %s: %v", repoName, err))
 			return
 		}
+		if err = dao.DeleteArtifactsByRepo(repoName); err != nil {
+			log.Errorf("failed to delete the artifacts of repository %s: %v", repoName, err)
+		}
+	}
+}
+
+// requireMutable checks whether the tags are protected by the immutable tag rules of the project,
+// the error is sent if any tag is immutable. System admin isn't restricted by the rules
+func (ra *RepositoryAPI) requireMutable(projectID int64, repository string, tags ...string) bool {
+	if ra.SecurityCtx.IsSysAdmin() {
+		return true
+	}
+	if err := immutable.Check(projectID, repository, tags...); err != nil {
+		if _, ok := err.(*immutable.ImmutableError); ok {
+			ra.SendPreconditionFailedError(err)
+			return false
+		}
+		ra.SendInternalServerError(fmt.Errorf("failed to check the immutable tag rules of %s: %v", repository, err))
+		return false
+	}
+	return true
+}
+
+// requireMutableDigests checks whether the tags and all the other tags of the repository which
+// reference the same manifests are protected by the immutable tag rules of the project
+func (ra *RepositoryAPI) requireMutableDigests(projectID int64, rc *registry.Repository, repository string, tags []string) bool {
+	if ra.SecurityCtx.IsSysAdmin() {
+		return true
+	}
+	rules, err := dao.ListImmutableTagRules(projectID)
+	if err != nil {
+		ra.SendInternalServerError(fmt.Errorf("failed to list the immutable tag rules of project %d: %v", projectID, err))
+		return false
+	}
+	// resolving the digests costs a request per tag, skip it if there is nothing to protect
+	if len(rules) == 0 {
+		return true
+	}
+	sharing, err := tagsSharingDigests(rc, tags)
+	if err != nil {
+		ra.ParseAndHandleError(fmt.Sprintf("failed to resolve the digests of the tags of %s", repository), err)
+		return false
+	}
+	return ra.requireMutable(projectID, repository, sharing...)
+}
+
+// tagsSharingDigests returns the tags together with the other tags of the repository which
+// reference the same manifests
+func tagsSharingDigests(rc *registry.Repository, tags []string) ([]string, error) {
+	digests := map[string]struct{}{}
+	for _, tag := range tags {
+		digest, exist, err := rc.ManifestExist(tag)
+		if err != nil {
+			return nil, err
+		}
+		if exist {
+			digests[digest] = struct{}{}
+		}
+	}
+	if len(digests) == 0 {
+		return tags, nil
+	}
+
+	all, err := rc.ListTag()
+	if err != nil {
+		return nil, err
+	}
+	requested := map[string]struct{}{}
+	for _, tag := range tags {
+		requested[tag] = struct{}{}
+	}
+	result := append([]string{}, tags...)
+	for _, tag := range all {
+		if _, exist := requested[tag]; exist {
+			continue
+		}
+		digest, exist, err := rc.ManifestExist(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		if _, exist := digests[digest]; exist {
+			result = append(result, tag)
+		}
 	}
+	return result, nil
 }
 
 // GetTag returns the tag of a repository
@@ -522,12 +641,54 @@ func (ra *RepositoryAPI) Retag() {
 		return
 	}
 
-	// Retag the image
-	if err = coreutils.Retag(srcImage, &models.Image{
+	// The existing tag protected by the immutable tag rules cannot be overridden
+	if request.Override {
+		exist, _, err := ra.checkExistence(repoName, request.Tag)
+		if err != nil {
+			ra.SendInternalServerError(fmt.Errorf("check existence of %s:%s error: %v", repoName, request.Tag, err))
+			return
+		}
+		if exist {
+			pro, err := ra.ProjectMgr.Get(project)
+			if err != nil {
+				ra.ParseAndHandleError(fmt.Sprintf("failed to get the project %s", project), err)
+				return
+			}
+			if pro == nil {
+				ra.SendNotFoundError(fmt.Errorf("project %s not found", project))
+				return
+			}
+			if !ra.requireMutable(pro.ProjectID, repoName, request.Tag) {
+				return
+			}
+		}
+	}
+
+	pro, err := ra.ProjectMgr.Get(project)
+	if err != nil {
+		ra.ParseAndHandleError(fmt.Sprintf("failed to get the project %s", project), err)
+		return
+	}
+	if pro == nil {
+		ra.SendNotFoundError(fmt.Errorf("project %s not found", project))
+		return
+	}
+
+	// Retag the image. The replication event of the target image is emitted by the push
+	// notification of registry as the manifest is pushed to registry directly. As the push
+	// doesn't go through the proxy, the quota of the target project is enforced here
+	err = coreutils.Retag(srcImage, &models.Image{
 		Project: project,
 		Repo:    repo,
 		Tag:     request.Tag,
-	}); err != nil {
+	}, func(mediaType string, payload []byte, push func() error) error {
+		return proxy.PushManifestWithQuota(pro, repoName, request.Tag, mediaType, payload, push)
+	})
+	if err != nil {
+		if _, ok := err.(*quota.ExceededError); ok {
+			ra.SendForbiddenError(err)
+			return
+		}
 		ra.SendInternalServerError(fmt.Errorf("%v", err))
 	}
 }
@@ -619,10 +780,17 @@ func assembleTagsInParallel(client *registry.Repository, repository string,
 		}
 	}
 
+	// the push times of all tags are loaded at once rather than one query per tag
+	pushTimes, err := dao.GetLastPushTimes(repository)
+	if err != nil {
+		pushTimes = map[string]time.Time{}
+		log.Errorf("failed to get the push times of %s: %v", repository, err)
+	}
+
 	c := make(chan *tagResp)
 	for _, tag := range tags {
 		go assembleTag(c, client, repository, tag, config.WithClair(),
-			config.WithNotary(), signatures)
+			config.WithNotary(), signatures, pushTimes)
 	}
 	result := []*tagResp{}
 	var item *tagResp
@@ -638,7 +806,7 @@ func assembleTagsInParallel(client *registry.Repository, repository string,
 
 func assembleTag(c chan *tagResp, client *registry.Repository,
 	repository, tag string, clairEnabled, notaryEnabled bool,
-	signatures map[string][]notary.Target) {
+	signatures map[string][]notary.Target, pushTimes map[string]time.Time) {
 	item := &tagResp{}
 	// labels
 	image := fmt.Sprintf("%s:%s", repository, tag)
@@ -658,6 +826,11 @@ func assembleTag(c chan *tagResp, client *registry.Repository,
 		item.tagDetail = *tagDetail
 	}
 
+	// the time when the tag was pushed last time
+	if pushTime, exist := pushTimes[tag]; exist {
+		item.PushTime = &pushTime
+	}
+
 	// scan overview
 	if clairEnabled {
 		item.ScanOverview = getScanOverview(item.Digest, item.Name)
@@ -24,6 +24,8 @@ import (
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/rbac"
 	coreutils "github.com/goharbor/harbor/src/core/utils"
+	rep_event "github.com/goharbor/harbor/src/replication/event"
+	rep_model "github.com/goharbor/harbor/src/replication/model"
 )
 
 // RepositoryLabelAPI handles requests for adding/removing label to/from repositories and images
@@ -148,7 +150,9 @@ func (r *RepositoryLabelAPI) AddToImage() {
 		ResourceType: common.ResourceTypeImage,
 		ResourceName: fmt.Sprintf("%s:%s", r.repository.Name, r.tag),
 	}
-	r.markLabelToResource(rl)
+	if r.markLabelToResource(rl) {
+		handleLabelEvent(rep_event.EventTypeLabelAdd, rep_model.ResourceTypeImage, r.repository.Name, r.tag)
+	}
 }
 
 // RemoveFromImage removes the label from an image
@@ -157,8 +161,10 @@ func (r *RepositoryLabelAPI) RemoveFromImage() {
 		return
 	}
 
-	r.removeLabelFromResource(common.ResourceTypeImage,
-		fmt.Sprintf("%s:%s", r.repository.Name, r.tag), r.label.ID)
+	if r.removeLabelFromResource(common.ResourceTypeImage,
+		fmt.Sprintf("%s:%s", r.repository.Name, r.tag), r.label.ID) {
+		handleLabelEvent(rep_event.EventTypeLabelDelete, rep_model.ResourceTypeImage, r.repository.Name, r.tag)
+	}
 }
 
 // GetOfRepository returns labels of a repository
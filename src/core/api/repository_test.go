@@ -16,11 +16,14 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/dao/project"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/registry"
 	"github.com/goharbor/harbor/src/testing/apitests/apilib"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -470,3 +473,39 @@ func TestRetag(t *testing.T) {
 
 	fmt.Printf("\n")
 }
+
+func TestTagsSharingDigests(t *testing.T) {
+	digests := map[string]string{
+		"1.0":    "sha256:a",
+		"latest": "sha256:a",
+		"2.0":    "sha256:b",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/tags/list") {
+			w.Write([]byte(`{"name":"library/hello-world","tags":["1.0","2.0","latest"]}`))
+			return
+		}
+		tag := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		digest, exist := digests[tag]
+		if !exist {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+	}))
+	defer server.Close()
+	rc, err := registry.NewRepository("library/hello-world", server.URL, http.DefaultClient)
+	require.Nil(t, err)
+
+	tags, err := tagsSharingDigests(rc, []string{"1.0"})
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{"1.0", "latest"}, tags)
+
+	tags, err = tagsSharingDigests(rc, []string{"2.0"})
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{"2.0"}, tags)
+
+	tags, err = tagsSharingDigests(rc, []string{"unknown"})
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{"unknown"}, tags)
+}
@@ -0,0 +1,264 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/pkg/retention"
+	"github.com/robfig/cron"
+)
+
+// RetentionAPI handles the requests to the tag retention policy of the project and its executions
+type RetentionAPI struct {
+	BaseController
+	project *models.Project
+}
+
+// Prepare ...
+func (r *RetentionAPI) Prepare() {
+	r.BaseController.Prepare()
+	if !r.SecurityCtx.IsAuthenticated() {
+		r.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+
+	id, err := r.GetInt64FromPath(":id")
+	if err != nil || id <= 0 {
+		r.SendBadRequestError(errors.New("invalid project ID"))
+		return
+	}
+	project, err := r.ProjectMgr.Get(id)
+	if err != nil {
+		r.ParseAndHandleError(fmt.Sprintf("failed to get project %d", id), err)
+		return
+	}
+	if project == nil {
+		r.SendNotFoundError(fmt.Errorf("project %d not found", id))
+		return
+	}
+	r.project = project
+}
+
+func (r *RetentionAPI) requireAccess(action rbac.Action) bool {
+	resource := rbac.NewProjectNamespace(r.project.ProjectID).Resource(rbac.ResourceTagRetention)
+	if !r.SecurityCtx.Can(action, resource) {
+		r.SendForbiddenError(errors.New(r.SecurityCtx.GetUsername()))
+		return false
+	}
+	return true
+}
+
+// GetPolicy returns the retention policy of the project
+func (r *RetentionAPI) GetPolicy() {
+	if !r.requireAccess(rbac.ActionRead) {
+		return
+	}
+	policy, err := retention.Ctl.GetPolicy(r.project.ProjectID)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to get the retention policy of project %d: %v",
+			r.project.ProjectID, err))
+		return
+	}
+	if policy == nil {
+		r.SendNotFoundError(fmt.Errorf("no retention policy found for project %d", r.project.ProjectID))
+		return
+	}
+	r.WriteJSONData(policy)
+}
+
+// PutPolicy creates or updates the retention policy of the project
+func (r *RetentionAPI) PutPolicy() {
+	if !r.requireAccess(rbac.ActionUpdate) {
+		return
+	}
+	policy := &models.RetentionPolicy{}
+	if err := r.DecodeJSONReq(policy); err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+	if err := retention.ValidateRules(policy.Rules); err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+	if len(policy.Cron) > 0 {
+		if _, err := cron.Parse(policy.Cron); err != nil {
+			r.SendBadRequestError(fmt.Errorf("invalid cron %s: %v", policy.Cron, err))
+			return
+		}
+	}
+	policy.ProjectID = r.project.ProjectID
+	if err := retention.Ctl.SetPolicy(policy); err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to set the retention policy of project %d: %v",
+			r.project.ProjectID, err))
+		return
+	}
+}
+
+// DeletePolicy deletes the retention policy of the project
+func (r *RetentionAPI) DeletePolicy() {
+	if !r.requireAccess(rbac.ActionDelete) {
+		return
+	}
+	if err := retention.Ctl.DeletePolicy(r.project.ProjectID); err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to delete the retention policy of project %d: %v",
+			r.project.ProjectID, err))
+		return
+	}
+}
+
+// Execute runs the retention policy of the project
+func (r *RetentionAPI) Execute() {
+	if !r.requireAccess(rbac.ActionCreate) {
+		return
+	}
+	req := &struct {
+		DryRun bool `json:"dry_run"`
+	}{}
+	if err := r.DecodeJSONReq(req); err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+	policy, err := retention.Ctl.GetPolicy(r.project.ProjectID)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to get the retention policy of project %d: %v",
+			r.project.ProjectID, err))
+		return
+	}
+	if policy == nil {
+		r.SendNotFoundError(fmt.Errorf("no retention policy found for project %d", r.project.ProjectID))
+		return
+	}
+
+	// only the scheduler job in jobservice triggers the scheduled executions
+	trigger := models.RetentionTriggerManual
+	if r.SecurityCtx.IsSolutionUser() && r.GetString("trigger") == models.RetentionTriggerSchedule {
+		trigger = models.RetentionTriggerSchedule
+	}
+	id, err := retention.Ctl.Execute(r.project.ProjectID, trigger, req.DryRun)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to execute the retention policy of project %d: %v",
+			r.project.ProjectID, err))
+		return
+	}
+	r.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
+}
+
+// ListExecutions lists the executions of the retention policy of the project
+func (r *RetentionAPI) ListExecutions() {
+	if !r.requireAccess(rbac.ActionList) {
+		return
+	}
+	page, size, err := r.GetPaginationParams()
+	if err != nil {
+		r.SendBadRequestError(err)
+		return
+	}
+	total, executions, err := retention.Ctl.ListExecutions(r.project.ProjectID, &models.Pagination{
+		Page: page,
+		Size: size,
+	})
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to list the retention executions of project %d: %v",
+			r.project.ProjectID, err))
+		return
+	}
+	r.SetPaginationHeader(total, page, size)
+	r.WriteJSONData(executions)
+}
+
+// ListTasks lists the tasks of the execution, one task per repository
+func (r *RetentionAPI) ListTasks() {
+	if !r.requireAccess(rbac.ActionList) {
+		return
+	}
+	execution := r.getExecution()
+	if execution == nil {
+		return
+	}
+	tasks, err := retention.Ctl.ListTasks(execution.ID)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to list the tasks of retention execution %d: %v",
+			execution.ID, err))
+		return
+	}
+	r.WriteJSONData(tasks)
+}
+
+// GetTaskLog returns the log of the task
+func (r *RetentionAPI) GetTaskLog() {
+	if !r.requireAccess(rbac.ActionRead) {
+		return
+	}
+	execution := r.getExecution()
+	if execution == nil {
+		return
+	}
+	taskID, err := r.GetInt64FromPath(":tid")
+	if err != nil || taskID <= 0 {
+		r.SendBadRequestError(errors.New("invalid task ID"))
+		return
+	}
+	task, err := retention.Ctl.GetTask(taskID)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to get retention task %d: %v", taskID, err))
+		return
+	}
+	if task == nil || task.ExecutionID != execution.ID {
+		r.SendNotFoundError(fmt.Errorf("retention task %d not found", taskID))
+		return
+	}
+
+	logBytes, err := retention.Ctl.GetTaskLog(taskID)
+	if err != nil {
+		if httpErr, ok := err.(*common_http.Error); ok && httpErr.Code == http.StatusNotFound {
+			r.SendNotFoundError(fmt.Errorf("the log of retention task %d not found", taskID))
+			return
+		}
+		r.SendInternalServerError(fmt.Errorf("failed to get log of retention task %d: %v", taskID, err))
+		return
+	}
+	r.Ctx.ResponseWriter.Header().Set(http.CanonicalHeaderKey("Content-Length"), strconv.Itoa(len(logBytes)))
+	r.Ctx.ResponseWriter.Header().Set(http.CanonicalHeaderKey("Content-Type"), "text/plain")
+	if _, err = r.Ctx.ResponseWriter.Write(logBytes); err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to write log of retention task %d: %v", taskID, err))
+		return
+	}
+}
+
+// get the execution specified in the path, nil is returned and the error is sent if it isn't found
+func (r *RetentionAPI) getExecution() *models.RetentionExecution {
+	id, err := r.GetInt64FromPath(":eid")
+	if err != nil || id <= 0 {
+		r.SendBadRequestError(errors.New("invalid execution ID"))
+		return nil
+	}
+	execution, err := retention.Ctl.GetExecution(id)
+	if err != nil {
+		r.SendInternalServerError(fmt.Errorf("failed to get retention execution %d: %v", id, err))
+		return nil
+	}
+	if execution == nil || execution.ProjectID != r.project.ProjectID {
+		r.SendNotFoundError(fmt.Errorf("retention execution %d not found", id))
+		return nil
+	}
+	return execution
+}
@@ -0,0 +1,279 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/pkg/retention"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakedRetentionController struct {
+	policy *models.RetentionPolicy
+	dryRun bool
+}
+
+func (f *fakedRetentionController) GetPolicy(projectID int64) (*models.RetentionPolicy, error) {
+	if f.policy != nil && f.policy.ProjectID == projectID {
+		return f.policy, nil
+	}
+	return nil, nil
+}
+func (f *fakedRetentionController) SetPolicy(policy *models.RetentionPolicy) error {
+	f.policy = policy
+	return nil
+}
+func (f *fakedRetentionController) DeletePolicy(projectID int64) error {
+	f.policy = nil
+	return nil
+}
+func (f *fakedRetentionController) Execute(projectID int64, trigger string, dryRun bool) (int64, error) {
+	f.dryRun = dryRun
+	return 1, nil
+}
+func (f *fakedRetentionController) ListExecutions(projectID int64, pagination *models.Pagination) (int64, []*models.RetentionExecution, error) {
+	return 1, []*models.RetentionExecution{
+		{
+			ID:        1,
+			ProjectID: 1,
+		},
+	}, nil
+}
+func (f *fakedRetentionController) GetExecution(id int64) (*models.RetentionExecution, error) {
+	if id == 1 {
+		return &models.RetentionExecution{
+			ID:        1,
+			ProjectID: 1,
+		}, nil
+	}
+	if id == 2 {
+		return &models.RetentionExecution{
+			ID:        2,
+			ProjectID: 1000,
+		}, nil
+	}
+	return nil, nil
+}
+func (f *fakedRetentionController) ListTasks(executionID int64) ([]*models.RetentionTask, error) {
+	return []*models.RetentionTask{
+		{
+			ID:          1,
+			ExecutionID: executionID,
+			Repository:  "library/hello-world",
+			Deleted:     []string{"ci-1"},
+		},
+	}, nil
+}
+func (f *fakedRetentionController) GetTask(id int64) (*models.RetentionTask, error) {
+	if id == 1 {
+		return &models.RetentionTask{
+			ID:          1,
+			ExecutionID: 1,
+		}, nil
+	}
+	return nil, nil
+}
+func (f *fakedRetentionController) GetTaskLog(id int64) ([]byte, error) {
+	return []byte("success"), nil
+}
+func (f *fakedRetentionController) UpdateTaskStatus(id int64, status string) error {
+	return nil
+}
+
+func TestRetentionAPI(t *testing.T) {
+	ctl := retention.Ctl
+	defer func() {
+		retention.Ctl = ctl
+	}()
+	fakedCtl := &fakedRetentionController{}
+	retention.Ctl = fakedCtl
+
+	policy := &models.RetentionPolicy{
+		Rules: []*models.RetentionRule{
+			{
+				Template: models.RetentionTemplateLatestPushed,
+				Tags:     "ci-*",
+				Count:    10,
+			},
+			{
+				Template: models.RetentionTemplateAlways,
+				Tags:     "release-*",
+			},
+		},
+		Cron: "0 0 0 * * *",
+	}
+
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/projects/1/retention",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404, project not found
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1000/retention",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 404, no policy
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/retention",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/retention",
+				credential: projGuest,
+				bodyJSON:   policy,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, no rules
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/retention",
+				credential: projAdmin,
+				bodyJSON:   &models.RetentionPolicy{},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, invalid cron
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/retention",
+				credential: projAdmin,
+				bodyJSON: &models.RetentionPolicy{
+					Rules: policy.Rules,
+					Cron:  "invalid",
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 404, no policy to execute
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/retention/executions",
+				credential: projAdmin,
+				bodyJSON:   map[string]bool{"dry_run": true},
+			},
+			code: http.StatusNotFound,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/retention",
+				credential: projAdmin,
+				bodyJSON:   policy,
+			},
+			code: http.StatusOK,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/retention",
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/retention/executions",
+				credential: projAdmin,
+				bodyJSON:   map[string]bool{"dry_run": true},
+			},
+			code: http.StatusCreated,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/retention/executions",
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 404, the execution belongs to another project
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/retention/executions/2/tasks",
+				credential: projAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/retention/executions/1/tasks",
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 404, task not found
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/retention/executions/1/tasks/2/log",
+				credential: projAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/retention/executions/1/tasks/1/log",
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        "/api/projects/1/retention",
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+	assert.True(t, fakedCtl.dryRun)
+	require.Nil(t, fakedCtl.policy)
+}
@@ -0,0 +1,231 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/pkg/webhook"
+)
+
+// WebhookAPI handles the requests to the webhook endpoints of the project and their deliveries
+type WebhookAPI struct {
+	BaseController
+	project *models.Project
+}
+
+// Prepare ...
+func (w *WebhookAPI) Prepare() {
+	w.BaseController.Prepare()
+	if !w.SecurityCtx.IsAuthenticated() {
+		w.SendUnAuthorizedError(errors.New("UnAuthorized"))
+		return
+	}
+
+	id, err := w.GetInt64FromPath(":id")
+	if err != nil || id <= 0 {
+		w.SendBadRequestError(errors.New("invalid project ID"))
+		return
+	}
+	project, err := w.ProjectMgr.Get(id)
+	if err != nil {
+		w.ParseAndHandleError(fmt.Sprintf("failed to get project %d", id), err)
+		return
+	}
+	if project == nil {
+		w.SendNotFoundError(fmt.Errorf("project %d not found", id))
+		return
+	}
+	w.project = project
+}
+
+func (w *WebhookAPI) requireAccess(action rbac.Action) bool {
+	resource := rbac.NewProjectNamespace(w.project.ProjectID).Resource(rbac.ResourceWebhook)
+	if !w.SecurityCtx.Can(action, resource) {
+		w.SendForbiddenError(errors.New(w.SecurityCtx.GetUsername()))
+		return false
+	}
+	return true
+}
+
+// ListEndpoints returns the webhook endpoints of the project
+func (w *WebhookAPI) ListEndpoints() {
+	if !w.requireAccess(rbac.ActionList) {
+		return
+	}
+	endpoints, err := webhook.Ctl.ListEndpoints(w.project.ProjectID)
+	if err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to list the webhook endpoints of project %d: %v",
+			w.project.ProjectID, err))
+		return
+	}
+	w.WriteJSONData(endpoints)
+}
+
+// GetEndpoint returns the webhook endpoint specified by ID
+func (w *WebhookAPI) GetEndpoint() {
+	if !w.requireAccess(rbac.ActionRead) {
+		return
+	}
+	endpoint := w.getEndpoint()
+	if endpoint == nil {
+		return
+	}
+	w.WriteJSONData(endpoint)
+}
+
+// PostEndpoint registers a webhook endpoint for the project
+func (w *WebhookAPI) PostEndpoint() {
+	if !w.requireAccess(rbac.ActionCreate) {
+		return
+	}
+	endpoint := &models.WebhookEndpoint{}
+	if err := w.DecodeJSONReq(endpoint); err != nil {
+		w.SendBadRequestError(err)
+		return
+	}
+	if !w.validate(endpoint, 0) {
+		return
+	}
+	endpoint.ID = 0
+	endpoint.ProjectID = w.project.ProjectID
+	endpoint.Creator = w.SecurityCtx.GetUsername()
+	id, err := webhook.Ctl.AddEndpoint(endpoint)
+	if err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to add the webhook endpoint to project %d: %v",
+			w.project.ProjectID, err))
+		return
+	}
+	w.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
+}
+
+// PutEndpoint updates the webhook endpoint, the secret is kept unchanged if it isn't set in the request
+func (w *WebhookAPI) PutEndpoint() {
+	if !w.requireAccess(rbac.ActionUpdate) {
+		return
+	}
+	endpoint := w.getEndpoint()
+	if endpoint == nil {
+		return
+	}
+	req := &models.WebhookEndpoint{}
+	if err := w.DecodeJSONReq(req); err != nil {
+		w.SendBadRequestError(err)
+		return
+	}
+	if !w.validate(req, endpoint.ID) {
+		return
+	}
+	endpoint.Name = req.Name
+	endpoint.Address = req.Address
+	endpoint.Secret = req.Secret
+	endpoint.EventTypes = req.EventTypes
+	endpoint.SkipCertVerify = req.SkipCertVerify
+	endpoint.Enabled = req.Enabled
+	endpoint.UpdateTime = time.Now()
+	if err := webhook.Ctl.UpdateEndpoint(endpoint); err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to update the webhook endpoint %d: %v", endpoint.ID, err))
+		return
+	}
+}
+
+// DeleteEndpoint deletes the webhook endpoint and its deliveries
+func (w *WebhookAPI) DeleteEndpoint() {
+	if !w.requireAccess(rbac.ActionDelete) {
+		return
+	}
+	endpoint := w.getEndpoint()
+	if endpoint == nil {
+		return
+	}
+	if err := webhook.Ctl.DeleteEndpoint(endpoint.ID); err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to delete the webhook endpoint %d: %v", endpoint.ID, err))
+		return
+	}
+}
+
+// ListDeliveries lists the deliveries of events to the webhook endpoint, the latest one first
+func (w *WebhookAPI) ListDeliveries() {
+	if !w.requireAccess(rbac.ActionList) {
+		return
+	}
+	endpoint := w.getEndpoint()
+	if endpoint == nil {
+		return
+	}
+	page, size, err := w.GetPaginationParams()
+	if err != nil {
+		w.SendBadRequestError(err)
+		return
+	}
+	total, deliveries, err := webhook.Ctl.ListDeliveries(endpoint.ID, &models.Pagination{
+		Page: page,
+		Size: size,
+	})
+	if err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to list the deliveries of webhook endpoint %d: %v",
+			endpoint.ID, err))
+		return
+	}
+	w.SetPaginationHeader(total, page, size)
+	w.WriteJSONData(deliveries)
+}
+
+// validate the endpoint and check whether its name is used by another endpoint of the project,
+// false is returned and the error is sent if it is invalid
+func (w *WebhookAPI) validate(endpoint *models.WebhookEndpoint, id int64) bool {
+	if err := webhook.ValidateEndpoint(endpoint); err != nil {
+		w.SendBadRequestError(err)
+		return false
+	}
+	endpoints, err := webhook.Ctl.ListEndpoints(w.project.ProjectID)
+	if err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to list the webhook endpoints of project %d: %v",
+			w.project.ProjectID, err))
+		return false
+	}
+	for _, e := range endpoints {
+		if e.Name == endpoint.Name && e.ID != id {
+			w.SendConflictError(fmt.Errorf("webhook endpoint %s already exists", endpoint.Name))
+			return false
+		}
+	}
+	return true
+}
+
+// get the endpoint specified in the path, nil is returned and the error is sent if it isn't found
+func (w *WebhookAPI) getEndpoint() *models.WebhookEndpoint {
+	id, err := w.GetInt64FromPath(":eid")
+	if err != nil || id <= 0 {
+		w.SendBadRequestError(errors.New("invalid webhook endpoint ID"))
+		return nil
+	}
+	endpoint, err := webhook.Ctl.GetEndpoint(id)
+	if err != nil {
+		w.SendInternalServerError(fmt.Errorf("failed to get the webhook endpoint %d: %v", id, err))
+		return nil
+	}
+	if endpoint == nil || endpoint.ProjectID != w.project.ProjectID {
+		w.SendNotFoundError(fmt.Errorf("webhook endpoint %d not found", id))
+		return nil
+	}
+	return endpoint
+}
@@ -0,0 +1,218 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakedWebhookController struct {
+	endpoints map[int64]*models.WebhookEndpoint
+}
+
+func (f *fakedWebhookController) ListEndpoints(projectID int64) ([]*models.WebhookEndpoint, error) {
+	endpoints := []*models.WebhookEndpoint{}
+	for _, endpoint := range f.endpoints {
+		if endpoint.ProjectID == projectID {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+func (f *fakedWebhookController) GetEndpoint(id int64) (*models.WebhookEndpoint, error) {
+	return f.endpoints[id], nil
+}
+func (f *fakedWebhookController) AddEndpoint(endpoint *models.WebhookEndpoint) (int64, error) {
+	endpoint.ID = int64(len(f.endpoints) + 1)
+	f.endpoints[endpoint.ID] = endpoint
+	return endpoint.ID, nil
+}
+func (f *fakedWebhookController) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	f.endpoints[endpoint.ID] = endpoint
+	return nil
+}
+func (f *fakedWebhookController) DeleteEndpoint(id int64) error {
+	delete(f.endpoints, id)
+	return nil
+}
+func (f *fakedWebhookController) DeleteEndpointsOfProject(projectID int64) error {
+	return nil
+}
+func (f *fakedWebhookController) ListDeliveries(endpointID int64, pagination *models.Pagination) (int64, []*models.WebhookDelivery, error) {
+	return 1, []*models.WebhookDelivery{
+		{
+			ID:         1,
+			EndpointID: endpointID,
+			EventType:  models.WebhookEventPushImage,
+			Status:     models.JobFinished,
+		},
+	}, nil
+}
+func (f *fakedWebhookController) Deliver(event *webhook.Event) error {
+	return nil
+}
+func (f *fakedWebhookController) UpdateDeliveryStatus(id int64, status string) error {
+	return nil
+}
+
+func TestWebhookAPI(t *testing.T) {
+	ctl := webhook.Ctl
+	defer func() {
+		webhook.Ctl = ctl
+	}()
+	webhook.Ctl = &fakedWebhookController{
+		endpoints: map[int64]*models.WebhookEndpoint{
+			// the endpoint of another project
+			1: {
+				ID:        1,
+				ProjectID: 1000,
+				Name:      "other",
+			},
+		},
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		Name:       "ci",
+		Address:    "https://example.com/hook",
+		Secret:     "secret",
+		EventTypes: []string{models.WebhookEventPushImage, models.WebhookEventScanningCompleted},
+		Enabled:    true,
+	}
+
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/projects/1/webhook/endpoints",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404, project not found
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1000/webhook/endpoints",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/webhook/endpoints",
+				credential: projGuest,
+				bodyJSON:   endpoint,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, unsupported event type
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/webhook/endpoints",
+				credential: projAdmin,
+				bodyJSON: &models.WebhookEndpoint{
+					Name:       "ci",
+					Address:    "https://example.com/hook",
+					EventTypes: []string{"unknown"},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/webhook/endpoints",
+				credential: projAdmin,
+				bodyJSON:   endpoint,
+			},
+			code: http.StatusCreated,
+		},
+		// 409, the name is used
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/projects/1/webhook/endpoints",
+				credential: projAdmin,
+				bodyJSON:   endpoint,
+			},
+			code: http.StatusConflict,
+		},
+		// 404, the endpoint belongs to another project
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/webhook/endpoints/1",
+				credential: projAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/webhook/endpoints/2",
+				credential: projAdmin,
+				bodyJSON: &models.WebhookEndpoint{
+					Name:       "ci",
+					Address:    "https://example.com/hook",
+					EventTypes: []string{models.WebhookEventPushImage},
+				},
+			},
+			code: http.StatusOK,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/webhook/endpoints/2/deliveries",
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	endpoints := []*models.WebhookEndpoint{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/webhook/endpoints",
+		credential: projAdmin,
+	}, &endpoints)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(endpoints))
+	assert.Equal(t, "ci", endpoints[0].Name)
+	assert.Equal(t, projAdmin.Name, endpoints[0].Creator)
+	assert.Equal(t, []string{models.WebhookEventPushImage}, endpoints[0].EventTypes)
+	assert.False(t, endpoints[0].Enabled)
+
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodDelete,
+			url:        "/api/projects/1/webhook/endpoints/2",
+			credential: projAdmin,
+		},
+		code: http.StatusOK,
+	})
+}
@@ -239,6 +239,16 @@ func RobotTokenDuration() int {
 	return cfgMgr.Get(common.RobotTokenDuration).GetInt()
 }
 
+// QuotaDefaults returns the default quota of the projects which don't override it
+func QuotaDefaults() *models.Quota {
+	return &models.Quota{
+		StorageHard: cfgMgr.Get(common.QuotaStorageHard).GetInt64(),
+		StorageSoft: cfgMgr.Get(common.QuotaStorageSoft).GetInt64(),
+		CountHard:   cfgMgr.Get(common.QuotaCountHard).GetInt64(),
+		CountSoft:   cfgMgr.Get(common.QuotaCountSoft).GetInt64(),
+	}
+}
+
 // ExtEndpoint returns the external URL of Harbor: protocol://host:port
 func ExtEndpoint() (string, error) {
 	return cfgMgr.Get(common.ExtEndpoint).GetString(), nil
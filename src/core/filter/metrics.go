@@ -0,0 +1,63 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common/metrics"
+)
+
+const (
+	metricsStartTimeKey = "metrics_start_time"
+	// the key of the matched route pattern set by beego
+	routerPatternKey = "RouterPattern"
+)
+
+// MetricsStartFilter records the time when the request arrives, it should be inserted before the other filters
+func MetricsStartFilter(ctx *context.Context) {
+	ctx.Input.SetData(metricsStartTimeKey, time.Now())
+}
+
+// MetricsRecoverFunc wraps the recover function of beego to record the request in the HTTP metrics
+// with the route pattern matched. Beego calls the recover function at the end of every request, so
+// the requests responded by the filters or aborted by the controllers, which the filters after the
+// router never see, are recorded too. The panics other than the aborts are passed to the wrapped function.
+func MetricsRecoverFunc(recoverFunc func(*context.Context)) func(*context.Context) {
+	return func(ctx *context.Context) {
+		err := recover()
+		defer observeRequest(ctx)
+		if err == nil || err == beego.ErrAbort {
+			return
+		}
+		func() {
+			if recoverFunc != nil {
+				defer recoverFunc(ctx)
+			}
+			panic(err)
+		}()
+	}
+}
+
+func observeRequest(ctx *context.Context) {
+	start, ok := ctx.Input.GetData(metricsStartTimeKey).(time.Time)
+	if !ok {
+		return
+	}
+	route, _ := ctx.Input.GetData(routerPatternKey).(string)
+	metrics.ObserveHTTPRequest(ctx.Request.Method, route, ctx.ResponseWriter.Status, time.Since(start))
+}
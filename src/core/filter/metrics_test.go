@@ -0,0 +1,89 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsFilter(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/api/projects/1/members", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.NewContext()
+	ctx.Reset(httptest.NewRecorder(), req)
+
+	counter := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/api/projects/:id([0-9]+)/members", "404")
+	before := testutil.ToFloat64(counter)
+	recoverFunc := MetricsRecoverFunc(nil)
+
+	// not recorded without the start time
+	recoverFunc(ctx)
+	assert.Equal(t, before, testutil.ToFloat64(counter))
+
+	MetricsStartFilter(ctx)
+	ctx.Input.SetData(routerPatternKey, "/api/projects/:id([0-9]+)/members")
+	ctx.ResponseWriter.WriteHeader(http.StatusNotFound)
+	recoverFunc(ctx)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestMetricsRecoverFunc(t *testing.T) {
+	serve := func(recoverFunc func(*context.Context), handle func(*context.Context)) {
+		req, err := http.NewRequest(http.MethodPost, "http://127.0.0.1/api/users", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.NewContext()
+		ctx.Reset(httptest.NewRecorder(), req)
+		defer recoverFunc(ctx)
+		MetricsStartFilter(ctx)
+		ctx.Input.SetData(routerPatternKey, "/api/users")
+		handle(ctx)
+	}
+	counter := func(code string) float64 {
+		return testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues(http.MethodPost, "/api/users", code))
+	}
+
+	// aborted by the controller
+	before := counter("409")
+	serve(MetricsRecoverFunc(nil), func(ctx *context.Context) {
+		ctx.ResponseWriter.WriteHeader(http.StatusConflict)
+		panic(beego.ErrAbort)
+	})
+	assert.Equal(t, before+1, counter("409"))
+
+	// the panic is passed to the wrapped function
+	before = counter("500")
+	var recovered interface{}
+	serve(MetricsRecoverFunc(func(ctx *context.Context) {
+		if recovered = recover(); recovered != nil {
+			ctx.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		}
+	}), func(ctx *context.Context) {
+		panic("crashed")
+	})
+	assert.Equal(t, "crashed", recovered)
+	assert.Equal(t, before+1, counter("500"))
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/astaxie/beego"
 	_ "github.com/astaxie/beego/session/redis"
 	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/common/utils/log"
@@ -35,8 +36,12 @@ import (
 	_ "github.com/goharbor/harbor/src/core/auth/uaa"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/filter"
+	"github.com/goharbor/harbor/src/core/notifier"
 	"github.com/goharbor/harbor/src/core/proxy"
 	"github.com/goharbor/harbor/src/core/service/token"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/pkg/retention"
+	"github.com/goharbor/harbor/src/pkg/webhook"
 	"github.com/goharbor/harbor/src/replication"
 )
 
@@ -134,11 +139,20 @@ func main() {
 	if err := replication.Init(closing); err != nil {
 		log.Fatalf("failed to init for replication: %v", err)
 	}
+	retention.Init(coreutils.GetJobServiceClient(), config.InternalCoreURL())
+	webhook.Init(coreutils.GetJobServiceClient(), config.InternalCoreURL())
+	if err := notifier.Subscribe(notifier.WebhookTopic, &webhook.Handler{}); err != nil {
+		log.Fatalf("failed to subscribe the webhook topic: %v", err)
+	}
+
+	metrics.Register(metrics.NewDBCollector())
 
 	filter.Init()
+	beego.InsertFilter("/*", beego.BeforeRouter, filter.MetricsStartFilter)
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.SecurityFilter)
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.ReadonlyFilter)
 	beego.InsertFilter("/api/*", beego.BeforeRouter, filter.MediaTypeFilter("application/json", "multipart/form-data", "application/octet-stream"))
+	beego.BConfig.RecoverFunc = filter.MetricsRecoverFunc(beego.BConfig.RecoverFunc)
 
 	initRouters()
 
@@ -159,6 +173,12 @@ func main() {
 
 	log.Info("Init proxy")
 	proxy.Init()
+	// the sync goes on in background as it may take a long time for the large registries
+	go func() {
+		if err := proxy.SyncArtifacts(); err != nil {
+			log.Errorf("failed to sync the artifacts: %v", err)
+		}
+	}()
 	// go proxy.StartProxy()
 	beego.Run()
 }
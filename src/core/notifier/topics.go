@@ -8,4 +8,8 @@ import (
 const (
 	// ScanAllPolicyTopic is for notifying the change of scanning all policy.
 	ScanAllPolicyTopic = common.ScanAllPolicy
+	// RegistryHealthTopic is for notifying the change of the health status of replication registries.
+	RegistryHealthTopic = "registry_health_status"
+	// WebhookTopic is for notifying the events delivered to the webhook endpoints of projects.
+	WebhookTopic = "webhook"
 )
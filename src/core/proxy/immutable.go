@@ -0,0 +1,111 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/pkg/immutable"
+)
+
+type immutableTagHandler struct {
+	next http.Handler
+}
+
+// The handler rejects the manifests pushed to the existing tags protected by the immutable tag rules of
+// the project, the tags that don't exist yet can still be pushed. System admin isn't restricted by the rules.
+// The handler acts before the registry authorizes the request, so the identity is taken from the registry
+// token and the requests not granted to push are left to the registry to deny.
+func (ih immutableTagHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	match, repository, reference := MatchPushManifest(req)
+	if !match || isDigest(reference) {
+		ih.next.ServeHTTP(rw, req)
+		return
+	}
+	claims := registryClaims(req)
+	if !granted(claims, repository, "push") {
+		ih.next.ServeHTTP(rw, req)
+		return
+	}
+	admin, err := isSysAdmin(claims.Subject)
+	if err != nil {
+		log.Errorf("failed to get the user %s: %v", claims.Subject, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	if admin {
+		ih.next.ServeHTTP(rw, req)
+		return
+	}
+
+	project, err := getProjectOfRepository(repository)
+	if err != nil {
+		log.Errorf("failed to get the project of repository %s: %v", repository, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		ih.next.ServeHTTP(rw, req)
+		return
+	}
+	err = immutable.Check(project.ProjectID, repository, reference)
+	if err == nil {
+		ih.next.ServeHTTP(rw, req)
+		return
+	}
+	immutableErr, ok := err.(*immutable.ImmutableError)
+	if !ok {
+		log.Errorf("failed to check the immutable tag rules for %s:%s: %v", repository, reference, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+
+	client, e := coreutils.NewRepositoryClientForUI(tokenUsername, repository)
+	if e != nil {
+		log.Errorf("failed to create the repository client for %s: %v", repository, e)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", e)), http.StatusInternalServerError)
+		return
+	}
+	_, exist, e := client.ManifestExist(reference)
+	if e != nil {
+		log.Errorf("failed to check the existence of %s:%s: %v", repository, reference, e)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", e)), http.StatusInternalServerError)
+		return
+	}
+	if !exist {
+		ih.next.ServeHTTP(rw, req)
+		return
+	}
+	log.Warningf("the push of %s:%s is denied: %v", repository, reference, err)
+	http.Error(rw, marshalError("DENIED", immutableErr.Error()), http.StatusForbidden)
+}
+
+// isSysAdmin checks whether the user of the registry token is system admin, the robot
+// accounts and the users which don't exist aren't
+func isSysAdmin(username string) (bool, error) {
+	if len(username) == 0 {
+		return false, nil
+	}
+	user, err := dao.GetUser(models.User{Username: username})
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.HasAdminRole, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/secret"
 	"github.com/goharbor/harbor/src/common/utils/clair"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/common/utils/notary"
@@ -166,6 +167,11 @@ func (uh urlHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			http.Error(rw, marshalError("PROJECT_POLICY_VIOLATION", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
 			return
 		}
+		// the manifest pulled from the upstream registry of the proxy cache project is checked
+		// rather than the local one which is missing or stale
+		if manifest, ok := req.Context().Value(upstreamManifestCtxKey).(*upstreamManifest); ok {
+			digest = manifest.digest
+		}
 
 		img := imageInfo{
 			repository:  repository,
@@ -201,12 +207,13 @@ type multipleManifestHandler struct {
 }
 
 // The handler is responsible for blocking request to upload manifest list by docker client, which is not supported so far by Harbor.
+// The manifest lists replicated by the replication service are allowed to keep all the platforms of the images.
 func (mh multipleManifestHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	match, _, _ := MatchPushManifest(req)
+	match, repository, _ := MatchPushManifest(req)
 	if match {
 		contentType := req.Header.Get("Content-type")
 		// application/vnd.docker.distribution.manifest.list.v2+json
-		if strings.Contains(contentType, "manifest.list.v2") {
+		if strings.Contains(contentType, "manifest.list.v2") && !pushedByReplication(req, repository) {
 			log.Debugf("Content-type: %s is not supported, failing the response.", contentType)
 			http.Error(rw, marshalError("UNSUPPORTED_MEDIA_TYPE", "Manifest.list is not supported."), http.StatusUnsupportedMediaType)
 			return
@@ -215,6 +222,22 @@ func (mh multipleManifestHandler) ServeHTTP(rw http.ResponseWriter, req *http.Re
 	mh.next.ServeHTTP(rw, req)
 }
 
+// pushedByReplication checks whether the manifest is pushed by the replication service. The replication
+// service authenticates with the secret of the job service, so the registry token is issued to the solution
+// user, which must not be shadowed by a user of the database with the same name
+func pushedByReplication(req *http.Request, repository string) bool {
+	claims := registryClaims(req)
+	if !granted(claims, repository, "push") || claims.Subject != secret.JobserviceUser {
+		return false
+	}
+	user, err := dao.GetUser(models.User{Username: claims.Subject})
+	if err != nil {
+		log.Errorf("failed to get the user %s: %v", claims.Subject, err)
+		return false
+	}
+	return user == nil
+}
+
 type listReposHandler struct {
 	next http.Handler
 }
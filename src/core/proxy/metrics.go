@@ -0,0 +1,36 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/metrics"
+)
+
+type registryMetricsHandler struct {
+	next http.Handler
+}
+
+// The handler observes the latency of the requests passed through to the registry, it's the last one
+// of the chain so the time spent on the interceptors isn't included.
+func (rh registryMetricsHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	recorder := metrics.NewStatusRecorder(rw)
+	rh.next.ServeHTTP(recorder, req)
+	metrics.RegistryRequestDuration.WithLabelValues(req.Method, strconv.Itoa(recorder.Status())).Observe(time.Since(start).Seconds())
+}
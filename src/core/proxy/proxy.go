@@ -40,13 +40,18 @@ func Init(urls ...string) error {
 	Proxy = httputil.NewSingleHostReverseProxy(targetURL)
 	handlers = handlerChain{
 		head: readonlyHandler{
-			next: urlHandler{
-				next: multipleManifestHandler{
-					next: listReposHandler{
-						next: contentTrustHandler{
-							next: vulnerableHandler{
-								next: Proxy,
-							}}}}}}}
+			next: proxyCacheHandler{
+				next: urlHandler{
+					next: multipleManifestHandler{
+						next: immutableTagHandler{
+							next: quotaHandler{
+								next: listReposHandler{
+									next: contentTrustHandler{
+										next: vulnerableHandler{
+											next: upstreamManifestHandler{
+												next: registryMetricsHandler{
+													next: Proxy,
+												}}}}}}}}}}}}
 	return nil
 }
 
@@ -0,0 +1,555 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/replication"
+	"github.com/goharbor/harbor/src/replication/adapter"
+)
+
+const (
+	blobURLPattern       = `^/v2/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)blobs/([a-zA-Z0-9-_+.]+:[a-fA-F0-9]+)$`
+	repositoryURLPattern = `^/v2/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)(?:manifests|blobs|tags)/`
+)
+
+// the manifest media types that can be cached, manifest list isn't supported by Harbor so far
+var cacheableManifestTypes = []string{
+	schema2.MediaTypeManifest,
+	schema1.MediaTypeSignedManifest,
+	schema1.MediaTypeManifest,
+}
+
+// MatchPullBlob checks if the request looks like a request to pull blob.  If it is returns the image and digest as 2nd and 3rd return values
+func MatchPullBlob(req *http.Request) (bool, string, string) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false, "", ""
+	}
+	re := regexp.MustCompile(blobURLPattern)
+	s := re.FindStringSubmatch(req.URL.Path)
+	if len(s) == 3 {
+		s[1] = strings.TrimSuffix(s[1], "/")
+		return true, s[1], s[2]
+	}
+	return false, "", ""
+}
+
+// matchRepository returns the repository name the registry request is sent to
+func matchRepository(req *http.Request) (bool, string) {
+	re := regexp.MustCompile(repositoryURLPattern)
+	s := re.FindStringSubmatch(req.URL.Path)
+	if len(s) == 2 {
+		return true, strings.TrimSuffix(s[1], "/")
+	}
+	return false, ""
+}
+
+const (
+	// the max number of the entries kept by the caches in the handler, the oldest one is evicted when it's full
+	maxCacheEntries = 10000
+	// how long the upstream registry of a project is cached
+	upstreamCacheTTL = 30 * time.Second
+)
+
+// boundedCache keeps the values with the time they're set, the oldest entry is evicted when it's full
+type boundedCache struct {
+	sync.Mutex
+	size    int
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	value interface{}
+	time  time.Time
+}
+
+func newBoundedCache(size int) *boundedCache {
+	return &boundedCache{
+		size:    size,
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+// get returns the value of the key if it's set within the ttl
+func (b *boundedCache) get(key string, ttl time.Duration) (interface{}, bool) {
+	b.Lock()
+	defer b.Unlock()
+	entry, exist := b.entries[key]
+	if !exist || time.Since(entry.time) >= ttl {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (b *boundedCache) set(key string, value interface{}) {
+	b.Lock()
+	defer b.Unlock()
+	if _, exist := b.entries[key]; !exist && len(b.entries) >= b.size {
+		oldest := ""
+		for k, entry := range b.entries {
+			if len(oldest) == 0 || entry.time.Before(b.entries[oldest].time) {
+				oldest = k
+			}
+		}
+		delete(b.entries, oldest)
+	}
+	b.entries[key] = &cacheEntry{
+		value: value,
+		time:  time.Now(),
+	}
+}
+
+// cacheRecorder records when the cached manifests were checked against the upstream registry last time
+type cacheRecorder struct {
+	cache *boundedCache
+}
+
+func newCacheRecorder(size int) *cacheRecorder {
+	return &cacheRecorder{
+		cache: newBoundedCache(size),
+	}
+}
+
+func (c *cacheRecorder) fresh(key string, staleness time.Duration) bool {
+	_, fresh := c.cache.get(key, staleness)
+	return fresh
+}
+
+func (c *cacheRecorder) touch(key string) {
+	c.cache.set(key, nil)
+}
+
+var (
+	recorder = newCacheRecorder(maxCacheEntries)
+	// caches the upstream registries of the projects, nil for the projects which aren't proxy caches
+	upstreams = newBoundedCache(maxCacheEntries)
+)
+
+// the upstream registry of the proxy cache project
+type upstream struct {
+	registry   adapter.ImageRegistry
+	repository string
+	staleness  time.Duration
+}
+
+// the upstream registry configured for the proxy cache project
+type projectUpstream struct {
+	registry  adapter.ImageRegistry
+	staleness time.Duration
+}
+
+// getUpstream returns the upstream of the repository, nil is returned if the project that the
+// repository belongs to isn't a proxy cache project
+func getUpstream(repository string) (*upstream, error) {
+	components := strings.SplitN(repository, "/", 2)
+	if len(components) < 2 {
+		return nil, nil
+	}
+	var pu *projectUpstream
+	if value, exist := upstreams.get(components[0], upstreamCacheTTL); exist {
+		pu, _ = value.(*projectUpstream)
+	} else {
+		var err error
+		if pu, err = getProjectUpstream(components[0]); err != nil {
+			return nil, err
+		}
+		upstreams.set(components[0], pu)
+	}
+	if pu == nil {
+		return nil, nil
+	}
+	return &upstream{
+		registry:   pu.registry,
+		repository: components[1],
+		staleness:  pu.staleness,
+	}, nil
+}
+
+func getProjectUpstream(projectName string) (*projectUpstream, error) {
+	project, err := config.GlobalProjectMgr.Get(projectName)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, nil
+	}
+	registryID, ok := project.ProxyCacheRegistryID()
+	if !ok {
+		return nil, nil
+	}
+	reg, err := replication.RegistryMgr.Get(registryID)
+	if err != nil {
+		return nil, err
+	}
+	if reg == nil {
+		return nil, fmt.Errorf("the upstream registry %d of project %s not found", registryID, project.Name)
+	}
+	factory, err := adapter.GetFactory(reg.Type)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := factory(reg)
+	if err != nil {
+		return nil, err
+	}
+	imageRegistry, ok := ad.(adapter.ImageRegistry)
+	if !ok {
+		return nil, errors.New("the adapter doesn't implement the \"ImageRegistry\" interface")
+	}
+	return &projectUpstream{
+		registry:  imageRegistry,
+		staleness: project.ProxyCacheStaleness(),
+	}, nil
+}
+
+// localRepository is the client of the local registry used to store the content pulled from the upstream registry
+type localRepository interface {
+	ManifestExist(reference string, acceptMediaTypes ...string) (digest string, exist bool, err error)
+	PushManifest(reference, mediaType string, payload []byte) (digest string, err error)
+	BlobExist(digest string) (bool, error)
+	PushBlob(digest string, size int64, data io.Reader) error
+}
+
+var newLocalRepository = func(repository string) (localRepository, error) {
+	client, err := coreutils.NewRepositoryClientForUI(tokenUsername, repository)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// copies tracks the manifests and blobs being copied from the upstream registries,
+// so that the same content isn't copied by several requests at the same time
+var copies = newInflight()
+
+type inflight struct {
+	sync.Mutex
+	running map[string]chan struct{}
+}
+
+func newInflight() *inflight {
+	return &inflight{
+		running: map[string]chan struct{}{},
+	}
+}
+
+// start marks the key as running, if it's running already false is returned along
+// with the channel which is closed when the running one is done
+func (i *inflight) start(key string) (bool, <-chan struct{}) {
+	i.Lock()
+	defer i.Unlock()
+	if ch, exist := i.running[key]; exist {
+		return false, ch
+	}
+	i.running[key] = make(chan struct{})
+	return true, nil
+}
+
+func (i *inflight) done(key string) {
+	i.Lock()
+	defer i.Unlock()
+	if ch, exist := i.running[key]; exist {
+		close(ch)
+		delete(i.running, key)
+	}
+}
+
+// upstreamManifest is the manifest pulled from the upstream registry when the local one is missing or
+// stale, it's carried in the request context and served by the "upstreamManifestHandler"
+type upstreamManifest struct {
+	mediaType string
+	digest    string
+	payload   []byte
+}
+
+const upstreamManifestCtxKey = contextKey("UpstreamManifest")
+
+type proxyCacheHandler struct {
+	next http.Handler
+}
+
+// The handler populates the repositories of proxy cache projects on demand without making the clients wait
+// for the whole image to be copied: the manifest missing locally is pulled from the upstream registry and
+// answered to the client at the end of the chain, while the blobs and then the manifest are stored in the
+// background. The blob missing locally is streamed from the upstream registry to the client and stored at
+// the same time. Only the requests carrying a registry token granting the pull access are populated, the
+// others are passed to the registry directly to be challenged.
+func (pch proxyCacheHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	match, repository := matchRepository(req)
+	if !match {
+		pch.next.ServeHTTP(rw, req)
+		return
+	}
+	up, err := getUpstream(repository)
+	if err != nil {
+		log.Errorf("failed to get the upstream registry of repository %s: %v", repository, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	if up == nil {
+		pch.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		log.Warningf("The request is prohibited for proxy cache project, url is: %s", req.URL.Path)
+		http.Error(rw, marshalError("DENIED", "The project is a proxy cache, any modification is prohibited."), http.StatusForbidden)
+		return
+	}
+
+	if !granted(registryClaims(req), repository, "pull") {
+		pch.next.ServeHTTP(rw, req)
+		return
+	}
+
+	local, err := newLocalRepository(repository)
+	if err != nil {
+		log.Errorf("failed to create the repository client for %s: %v", repository, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	// the cached content is served when the population fails, e.g. the upstream registry is unreachable
+	if m, _, reference := matchManifestURL(req); m {
+		manifest, dgt, err := fetchManifest(up, local, repository, reference)
+		if err != nil {
+			log.Errorf("failed to fetch manifest %s:%s from the upstream registry: %v", repository, reference, err)
+		} else if manifest != nil {
+			go cacheManifest(up, local, repository, reference, manifest)
+			mediaType, payload, err := manifest.Payload()
+			if err != nil {
+				log.Errorf("failed to get the payload of manifest %s:%s: %v", repository, reference, err)
+			} else {
+				ctx := context.WithValue(req.Context(), upstreamManifestCtxKey, &upstreamManifest{
+					mediaType: mediaType,
+					digest:    dgt,
+					payload:   payload,
+				})
+				req = req.WithContext(ctx)
+			}
+		}
+	} else if m, _, dgt := MatchPullBlob(req); m {
+		served, err := serveBlob(rw, req, up, local, repository, dgt)
+		if err != nil {
+			log.Errorf("failed to populate blob %s of %s from the upstream registry: %v", dgt, repository, err)
+		}
+		if served {
+			return
+		}
+	}
+	pch.next.ServeHTTP(rw, req)
+}
+
+// fetchManifest returns the manifest pulled from the upstream registry and its digest if the local one
+// doesn't exist or differs from the upstream one, nil is returned if the local one can be served
+func fetchManifest(up *upstream, local localRepository, repository, reference string) (distribution.Manifest, string, error) {
+	localDigest, exist, err := local.ManifestExist(reference)
+	if err != nil {
+		return nil, "", err
+	}
+	key := repository + ":" + reference
+	// the content referenced by digest never changes
+	if exist && (isDigest(reference) || recorder.fresh(key, up.staleness)) {
+		return nil, "", nil
+	}
+
+	upstreamExist, upstreamDigest, err := up.registry.ManifestExist(up.repository, reference)
+	if err != nil {
+		return nil, "", err
+	}
+	if !upstreamExist || (exist && upstreamDigest == localDigest) {
+		recorder.touch(key)
+		return nil, "", nil
+	}
+
+	manifest, dgt, err := up.registry.PullManifest(up.repository, reference, cacheableManifestTypes)
+	if err != nil {
+		return nil, "", err
+	}
+	// the digest returned by checking the existence is the one of manifest list if the upstream
+	// image is a multi-platform one, compare the pulled manifest with the local one again
+	if exist && dgt == localDigest {
+		recorder.touch(key)
+		return nil, "", nil
+	}
+	return manifest, dgt, nil
+}
+
+// cacheManifest stores the blobs referenced by the manifest and then the manifest, as the registry
+// rejects the manifests referencing the blobs which don't exist
+func cacheManifest(up *upstream, local localRepository, repository, reference string, manifest distribution.Manifest) {
+	key := repository + ":" + reference
+	if started, _ := copies.start(key); !started {
+		return
+	}
+	defer copies.done(key)
+	for _, descriptor := range manifest.References() {
+		if descriptor.MediaType == schema2.MediaTypeForeignLayer {
+			continue
+		}
+		if err := storeBlob(up, local, repository, descriptor.Digest.String()); err != nil {
+			log.Errorf("failed to cache blob %s of %s: %v", descriptor.Digest.String(), repository, err)
+			return
+		}
+	}
+	mediaType, payload, err := manifest.Payload()
+	if err == nil {
+		_, err = local.PushManifest(reference, mediaType, payload)
+	}
+	if err != nil {
+		log.Errorf("failed to cache manifest %s:%s: %v", repository, reference, err)
+		return
+	}
+	recorder.touch(key)
+	log.Debugf("manifest %s:%s cached", repository, reference)
+}
+
+// storeBlob copies the blob from the upstream registry if it doesn't exist locally, it waits
+// for the one being copied by others and checks the existence again
+func storeBlob(up *upstream, local localRepository, repository, dgt string) error {
+	key := repository + "@" + dgt
+	for {
+		exist, err := local.BlobExist(dgt)
+		if err != nil {
+			return err
+		}
+		if exist {
+			return nil
+		}
+		started, running := copies.start(key)
+		if !started {
+			<-running
+			continue
+		}
+		err = func() error {
+			defer copies.done(key)
+			size, data, err := up.registry.PullBlob(up.repository, dgt)
+			if err != nil {
+				return err
+			}
+			defer data.Close()
+			return local.PushBlob(dgt, size, data)
+		}()
+		return err
+	}
+}
+
+// serveBlob streams the blob missing locally from the upstream registry to the client and stores it
+// at the same time, false is returned if the request isn't served and should be passed to the registry
+func serveBlob(rw http.ResponseWriter, req *http.Request, up *upstream, local localRepository, repository, dgt string) (bool, error) {
+	exist, err := local.BlobExist(dgt)
+	if err != nil || exist {
+		return false, err
+	}
+	// only the size is needed when checking the existence
+	if req.Method == http.MethodHead {
+		return false, storeBlob(up, local, repository, dgt)
+	}
+	size, data, err := up.registry.PullBlob(up.repository, dgt)
+	if err != nil {
+		return false, err
+	}
+	defer data.Close()
+
+	rw.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	rw.Header().Set("Content-Type", "application/octet-stream")
+	rw.Header().Set("Docker-Content-Digest", dgt)
+	rw.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	rw.WriteHeader(http.StatusOK)
+
+	key := repository + "@" + dgt
+	// the blob is only served to the client when it's being stored by others
+	if started, _ := copies.start(key); !started {
+		_, err = io.Copy(rw, data)
+		return true, err
+	}
+	defer copies.done(key)
+
+	pr, pw := io.Pipe()
+	pushed := make(chan error, 1)
+	go func() {
+		err := local.PushBlob(dgt, size, pr)
+		// unblock the writes if the push stops before reading all the data
+		pr.Close()
+		pushed <- err
+	}()
+	// keep storing the blob when the client goes away and keep serving the client when the storing fails
+	client := &detachedWriter{w: rw}
+	store := &detachedWriter{w: pw}
+	_, err = io.Copy(io.MultiWriter(client, store), data)
+	pw.CloseWithError(err)
+	if e := <-pushed; e != nil && err == nil {
+		err = e
+	}
+	if err == nil {
+		log.Debugf("blob %s of %s cached", dgt, repository)
+	}
+	return true, err
+}
+
+// detachedWriter stops writing to the underlying writer after the first error without failing the
+// writes, so that one reader failing doesn't stop the data from being copied to the others
+type detachedWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (d *detachedWriter) Write(p []byte) (int, error) {
+	if d.err == nil {
+		_, d.err = d.w.Write(p)
+	}
+	return len(p), nil
+}
+
+type upstreamManifestHandler struct {
+	next http.Handler
+}
+
+// The handler answers the manifest pulled from the upstream registry by the "proxyCacheHandler", it acts
+// after the policy checks of the chain so that the manifest not stored yet is checked as the local ones
+func (uh upstreamManifestHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	manifest, ok := req.Context().Value(upstreamManifestCtxKey).(*upstreamManifest)
+	if !ok {
+		uh.next.ServeHTTP(rw, req)
+		return
+	}
+	rw.Header().Set("Content-Length", strconv.Itoa(len(manifest.payload)))
+	rw.Header().Set("Content-Type", manifest.mediaType)
+	rw.Header().Set("Docker-Content-Digest", manifest.digest)
+	rw.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	rw.Header().Set("Etag", fmt.Sprintf(`"%s"`, manifest.digest))
+	rw.WriteHeader(http.StatusOK)
+	if req.Method == http.MethodHead {
+		return
+	}
+	if _, err := rw.Write(manifest.payload); err != nil {
+		log.Errorf("failed to write the manifest %s: %v", manifest.digest, err)
+	}
+}
@@ -0,0 +1,313 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	dtoken "github.com/docker/distribution/registry/auth/token"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPullBlob(t *testing.T) {
+	assert := assert.New(t)
+	req1, _ := http.NewRequest("PUT", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", nil)
+	res1, _, _ := MatchPullBlob(req1)
+	assert.False(res1, "%s %v is not a request to pull blob", req1.Method, req1.URL)
+
+	req2, _ := http.NewRequest("GET", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", nil)
+	res2, repo2, digest2 := MatchPullBlob(req2)
+	assert.True(res2, "%s %v is a request to pull blob", req2.Method, req2.URL)
+	assert.Equal("library/ubuntu", repo2)
+	assert.Equal("sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", digest2)
+
+	req3, _ := http.NewRequest("HEAD", "http://127.0.0.1:5000/v2/path1/path2/golang/blobs/sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", nil)
+	res3, repo3, _ := MatchPullBlob(req3)
+	assert.True(res3, "%s %v is a request to pull blob", req3.Method, req3.URL)
+	assert.Equal("path1/path2/golang", repo3)
+
+	req4, _ := http.NewRequest("GET", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/uploads/", nil)
+	res4, _, _ := MatchPullBlob(req4)
+	assert.False(res4, "%s %v is not a request to pull blob", req4.Method, req4.URL)
+}
+
+func TestMatchRepository(t *testing.T) {
+	req1, _ := http.NewRequest("GET", "http://127.0.0.1:5000/v2/_catalog", nil)
+	match, _ := matchRepository(req1)
+	assert.False(t, match)
+
+	req2, _ := http.NewRequest("POST", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/uploads/", nil)
+	match, repository := matchRepository(req2)
+	assert.True(t, match)
+	assert.Equal(t, "library/ubuntu", repository)
+
+	req3, _ := http.NewRequest("GET", "http://127.0.0.1:5000/v2/library/ubuntu/tags/list", nil)
+	match, repository = matchRepository(req3)
+	assert.True(t, match)
+	assert.Equal(t, "library/ubuntu", repository)
+}
+
+func TestCacheRecorder(t *testing.T) {
+	r := newCacheRecorder(10)
+	assert.False(t, r.fresh("library/ubuntu:14.04", time.Hour))
+	r.touch("library/ubuntu:14.04")
+	assert.True(t, r.fresh("library/ubuntu:14.04", time.Hour))
+	assert.False(t, r.fresh("library/ubuntu:14.04", 0))
+}
+
+func TestBoundedCache(t *testing.T) {
+	c := newBoundedCache(2)
+	c.set("a", 1)
+	c.set("b", 2)
+	// updating an existing key doesn't evict anything
+	c.set("a", 3)
+	value, exist := c.get("a", time.Hour)
+	assert.True(t, exist)
+	assert.Equal(t, 3, value)
+	_, exist = c.get("b", time.Hour)
+	assert.True(t, exist)
+
+	// the oldest one is evicted when it's full
+	c.set("c", 4)
+	assert.Equal(t, 2, len(c.entries))
+	_, exist = c.get("b", time.Hour)
+	assert.False(t, exist)
+	_, exist = c.get("c", time.Hour)
+	assert.True(t, exist)
+	_, exist = c.get("c", 0)
+	assert.False(t, exist)
+}
+
+func TestGranted(t *testing.T) {
+	claims := &dtoken.ClaimSet{
+		Access: []*dtoken.ResourceActions{
+			{Type: "repository", Name: "library/hello-world", Actions: []string{"pull"}},
+			{Type: "repository", Name: "library/busybox", Actions: []string{"*"}},
+		},
+	}
+	assert.False(t, granted(nil, "library/hello-world", "pull"))
+	assert.True(t, granted(claims, "library/hello-world", "pull"))
+	assert.False(t, granted(claims, "library/hello-world", "push"))
+	assert.True(t, granted(claims, "library/busybox", "pull"))
+	assert.False(t, granted(claims, "library/photon", "pull"))
+}
+
+type fakedUpstreamRegistry struct {
+	blobs map[string][]byte
+}
+
+func (f *fakedUpstreamRegistry) FetchImages(filters []*model.Filter) ([]*model.Resource, error) {
+	return nil, nil
+}
+func (f *fakedUpstreamRegistry) ManifestExist(repository, reference string) (bool, string, error) {
+	return false, "", nil
+}
+func (f *fakedUpstreamRegistry) PullManifest(repository, reference string, accepttedMediaTypes []string) (distribution.Manifest, string, error) {
+	return nil, "", nil
+}
+func (f *fakedUpstreamRegistry) PushManifest(repository, reference, mediaType string, payload []byte) error {
+	return nil
+}
+func (f *fakedUpstreamRegistry) DeleteManifest(repository, reference string) error {
+	return nil
+}
+func (f *fakedUpstreamRegistry) BlobExist(repository, digest string) (bool, error) {
+	_, exist := f.blobs[digest]
+	return exist, nil
+}
+func (f *fakedUpstreamRegistry) PullBlob(repository, digest string) (int64, io.ReadCloser, error) {
+	data, exist := f.blobs[digest]
+	if !exist {
+		return 0, nil, errors.New("not found")
+	}
+	return int64(len(data)), ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+func (f *fakedUpstreamRegistry) PushBlob(repository, digest string, size int64, blob io.Reader) error {
+	return nil
+}
+
+type fakedLocalRepository struct {
+	sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	pushErr   error
+}
+
+func (f *fakedLocalRepository) ManifestExist(reference string, acceptMediaTypes ...string) (string, bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	_, exist := f.manifests[reference]
+	return "", exist, nil
+}
+func (f *fakedLocalRepository) PushManifest(reference, mediaType string, payload []byte) (string, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.manifests[reference] = payload
+	return "", nil
+}
+func (f *fakedLocalRepository) BlobExist(digest string) (bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	_, exist := f.blobs[digest]
+	return exist, nil
+}
+func (f *fakedLocalRepository) PushBlob(digest string, size int64, data io.Reader) error {
+	if f.pushErr != nil {
+		return f.pushErr
+	}
+	b, err := ioutil.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.blobs[digest] = b
+	return nil
+}
+
+func newFakedLocalRepository() *fakedLocalRepository {
+	return &fakedLocalRepository{
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+	}
+}
+
+func TestInflight(t *testing.T) {
+	i := newInflight()
+	started, _ := i.start("a")
+	assert.True(t, started)
+	started, running := i.start("a")
+	assert.False(t, started)
+	i.done("a")
+	<-running
+	started, _ = i.start("a")
+	assert.True(t, started)
+}
+
+func TestServeBlob(t *testing.T) {
+	blob := []byte("the content of the blob")
+	dgt := digest.FromBytes(blob).String()
+	up := &upstream{
+		registry:   &fakedUpstreamRegistry{blobs: map[string][]byte{dgt: blob}},
+		repository: "library/hello-world",
+	}
+	url := "http://127.0.0.1/v2/proxy/library/hello-world/blobs/" + dgt
+
+	// the blob is served to the client and stored at the same time
+	local := newFakedLocalRepository()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	rw := httptest.NewRecorder()
+	served, err := serveBlob(rw, req, up, local, "proxy/library/hello-world", dgt)
+	require.Nil(t, err)
+	assert.True(t, served)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, dgt, rw.Header().Get("Docker-Content-Digest"))
+	assert.Equal(t, blob, rw.Body.Bytes())
+	assert.Equal(t, blob, local.blobs[dgt])
+
+	// the local one is served by the registry
+	rw = httptest.NewRecorder()
+	served, err = serveBlob(rw, req, up, local, "proxy/library/hello-world", dgt)
+	require.Nil(t, err)
+	assert.False(t, served)
+
+	// the client is still served when the blob cannot be stored
+	local = newFakedLocalRepository()
+	local.pushErr = errors.New("failed to push")
+	rw = httptest.NewRecorder()
+	served, err = serveBlob(rw, req, up, local, "proxy/library/hello-world", dgt)
+	assert.NotNil(t, err)
+	assert.True(t, served)
+	assert.Equal(t, blob, rw.Body.Bytes())
+}
+
+type failedWriter struct{}
+
+func (f *failedWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("failed to write")
+}
+
+func TestDetachedWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	failed := &detachedWriter{w: &failedWriter{}}
+	n, err := io.Copy(io.MultiWriter(failed, buf), strings.NewReader("data"))
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.Equal(t, "data", buf.String())
+	assert.NotNil(t, failed.err)
+}
+
+func TestCacheManifest(t *testing.T) {
+	config := []byte("config")
+	layer := []byte("layer")
+	manifest, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeImageConfig,
+			Digest:    digest.FromBytes(config),
+			Size:      int64(len(config)),
+		},
+		Layers: []distribution.Descriptor{
+			{
+				MediaType: schema2.MediaTypeLayer,
+				Digest:    digest.FromBytes(layer),
+				Size:      int64(len(layer)),
+			},
+		},
+	})
+	require.Nil(t, err)
+	up := &upstream{
+		registry: &fakedUpstreamRegistry{blobs: map[string][]byte{
+			digest.FromBytes(config).String(): config,
+			digest.FromBytes(layer).String():  layer,
+		}},
+		repository: "library/hello-world",
+	}
+	local := newFakedLocalRepository()
+	cacheManifest(up, local, "proxy/library/hello-world", "latest", manifest)
+	assert.Equal(t, config, local.blobs[digest.FromBytes(config).String()])
+	assert.Equal(t, layer, local.blobs[digest.FromBytes(layer).String()])
+	_, payload, err := manifest.Payload()
+	require.Nil(t, err)
+	assert.Equal(t, payload, local.manifests["latest"])
+}
+
+func TestUpstreamManifestHandler(t *testing.T) {
+	next := false
+	handler := upstreamManifestHandler{
+		next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			next = true
+		}),
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/v2/proxy/library/hello-world/manifests/latest", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, next)
+
+	next = false
+	manifest := &upstreamManifest{
+		mediaType: schema2.MediaTypeManifest,
+		digest:    "sha256:digest",
+		payload:   []byte("payload"),
+	}
+	req = req.WithContext(context.WithValue(req.Context(), upstreamManifestCtxKey, manifest))
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	assert.False(t, next)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, schema2.MediaTypeManifest, rw.Header().Get("Content-Type"))
+	assert.Equal(t, "sha256:digest", rw.Header().Get("Docker-Content-Digest"))
+	assert.Equal(t, "payload", rw.Body.String())
+}
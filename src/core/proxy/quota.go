@@ -0,0 +1,512 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	// register the OCI schemas to parse the manifests pushed
+	_ "github.com/docker/distribution/manifest/ocischema"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/pkg/quota"
+	"github.com/goharbor/harbor/src/pkg/webhook"
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	blobUploadURLPattern = `^/v2/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)blobs/uploads/([a-zA-Z0-9-_.=]+)/?$`
+)
+
+// matchCompleteBlobUpload checks if the request completes a blob upload. If it is returns the repository and digest as 2nd and 3rd return values
+func matchCompleteBlobUpload(req *http.Request) (bool, string, string) {
+	if req.Method != http.MethodPut {
+		return false, "", ""
+	}
+	re := regexp.MustCompile(blobUploadURLPattern)
+	s := re.FindStringSubmatch(req.URL.Path)
+	if len(s) != 3 {
+		return false, "", ""
+	}
+	dgt := req.URL.Query().Get("digest")
+	if len(dgt) == 0 {
+		return false, "", ""
+	}
+	return true, strings.TrimSuffix(s[1], "/"), dgt
+}
+
+// matchDeleteManifest checks if the request looks like a request to delete manifest. If it is returns the image and tag/sha256 digest as 2nd and 3rd return values
+func matchDeleteManifest(req *http.Request) (bool, string, string) {
+	if req.Method != http.MethodDelete {
+		return false, "", ""
+	}
+	return matchManifestURL(req)
+}
+
+// statusRecorder records the status code of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(p)
+}
+
+type quotaHandler struct {
+	next http.Handler
+}
+
+// The handler enforces the storage and tag count quotas of projects when the blob uploads are completed and the
+// manifests are pushed, and records the artifacts and blobs pushed successfully to calculate the usages of projects.
+func (qh quotaHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if match, repository, dgt := matchCompleteBlobUpload(req); match {
+		qh.serveBlobUpload(rw, req, repository, dgt)
+		return
+	}
+	if match, repository, reference := MatchPushManifest(req); match {
+		qh.servePushManifest(rw, req, repository, reference)
+		return
+	}
+	if match, repository, reference := matchDeleteManifest(req); match {
+		rec := &statusRecorder{ResponseWriter: rw}
+		qh.next.ServeHTTP(rec, req)
+		if rec.status != http.StatusAccepted {
+			return
+		}
+		var err error
+		if isDigest(reference) {
+			err = dao.DeleteArtifactsByDigest(repository, reference)
+		} else {
+			err = dao.DeleteArtifactByTag(repository, reference)
+		}
+		if err != nil {
+			log.Errorf("failed to delete the artifacts of %s:%s: %v", repository, reference, err)
+		}
+		return
+	}
+	qh.next.ServeHTTP(rw, req)
+}
+
+func (qh quotaHandler) serveBlobUpload(rw http.ResponseWriter, req *http.Request, repository, dgt string) {
+	project, err := getProjectOfRepository(repository)
+	if err != nil {
+		log.Errorf("failed to get the project of repository %s: %v", repository, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		qh.next.ServeHTTP(rw, req)
+		return
+	}
+
+	size, err := uploadedSize(req, repository)
+	if err != nil {
+		// let the registry handle the upload, the storage is checked again when the manifest is pushed
+		log.Warningf("failed to get the size of blob %s uploaded to %s: %v", dgt, repository, err)
+		qh.next.ServeHTTP(rw, req)
+		return
+	}
+	existing, err := dao.GetBlobsOfProject(project.ProjectID, dgt)
+	if err != nil {
+		log.Errorf("failed to check the blob %s of project %s: %v", dgt, project.Name, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	var storage int64
+	if len(existing) == 0 {
+		storage = size
+	}
+	release, ok := reserveQuota(rw, project, repository, storage, 0)
+	if !ok {
+		return
+	}
+	// the blob is counted in the usage once the manifest referencing it is pushed
+	defer release()
+
+	rec := &statusRecorder{ResponseWriter: rw}
+	qh.next.ServeHTTP(rec, req)
+	if rec.status != http.StatusCreated {
+		return
+	}
+	if err = dao.AddBlob(&models.Blob{
+		Digest: dgt,
+		Size:   size,
+	}); err != nil {
+		log.Errorf("failed to record the blob %s: %v", dgt, err)
+	}
+}
+
+// uploadedSize returns the size of the blob uploaded, including the bytes received by the upload
+// session before and the ones in the body of the request completing the upload
+func uploadedSize(req *http.Request, repository string) (int64, error) {
+	client, err := coreutils.NewRepositoryClientForUI(tokenUsername, repository)
+	if err != nil {
+		return 0, err
+	}
+	query := req.URL.Query()
+	query.Del("digest")
+	location := req.URL.Path
+	if len(query) > 0 {
+		location += "?" + query.Encode()
+	}
+	_, size, err := client.GetBlobUploadStatus(location)
+	if err != nil {
+		return 0, err
+	}
+	if req.ContentLength > 0 {
+		size += req.ContentLength
+	}
+	return size, nil
+}
+
+func (qh quotaHandler) servePushManifest(rw http.ResponseWriter, req *http.Request, repository, reference string) {
+	project, err := getProjectOfRepository(repository)
+	if err != nil {
+		log.Errorf("failed to get the project of repository %s: %v", repository, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		qh.next.ServeHTTP(rw, req)
+		return
+	}
+
+	payload, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		log.Errorf("failed to read the manifest %s:%s: %v", repository, reference, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(payload))
+
+	mediaType := req.Header.Get("Content-Type")
+	manifest, _, err := distribution.UnmarshalManifest(mediaType, payload)
+	if err != nil {
+		// let the registry return the error
+		log.Debugf("failed to parse the manifest %s:%s: %v", repository, reference, err)
+		qh.next.ServeHTTP(rw, req)
+		return
+	}
+	blobs, err := referencedBlobs(manifest, mediaType, payload)
+	if err != nil {
+		log.Errorf("failed to get the blobs of manifest %s:%s: %v", repository, reference, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+
+	storage, count, err := requestedResources(project.ProjectID, repository, reference, blobs)
+	if err != nil {
+		log.Errorf("failed to calculate the resources requested by manifest %s:%s: %v", repository, reference, err)
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	release, ok := reserveQuota(rw, project, repository, storage, count)
+	if !ok {
+		return
+	}
+	// released after the artifact is recorded
+	defer release()
+
+	rec := &statusRecorder{ResponseWriter: rw}
+	qh.next.ServeHTTP(rec, req)
+	if rec.status != http.StatusCreated {
+		return
+	}
+	tag := reference
+	if isDigest(reference) {
+		tag = ""
+	}
+	if err = recordArtifact(project.ProjectID, repository, tag, blobs); err != nil {
+		log.Errorf("failed to record the artifact %s:%s: %v", repository, reference, err)
+	}
+}
+
+// referencedBlobs returns the blobs referenced by the manifest, the first one is the manifest itself.
+// The sizes of the blobs missing in the manifest, e.g. the ones of schema1 manifest, are populated
+// with the recorded ones
+func referencedBlobs(manifest distribution.Manifest, mediaType string, payload []byte) ([]*models.Blob, error) {
+	blobs := []*models.Blob{
+		{
+			Digest:      digest.FromBytes(payload).String(),
+			ContentType: mediaType,
+			Size:        int64(len(payload)),
+		},
+	}
+	for _, descriptor := range manifest.References() {
+		// the foreign layers aren't stored in the registry
+		if descriptor.MediaType == schema2.MediaTypeForeignLayer {
+			continue
+		}
+		blob := &models.Blob{
+			Digest:      descriptor.Digest.String(),
+			ContentType: descriptor.MediaType,
+			Size:        descriptor.Size,
+		}
+		if blob.Size <= 0 {
+			b, err := dao.GetBlob(blob.Digest)
+			if err != nil {
+				return nil, err
+			}
+			if b != nil {
+				blob.Size = b.Size
+			}
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs, nil
+}
+
+// requestedResources returns the storage of the blobs which aren't referenced by the project yet,
+// and the count of tags that pushing the manifest adds to the project
+func requestedResources(projectID int64, repository, reference string, blobs []*models.Blob) (int64, int64, error) {
+	digests := []string{}
+	for _, blob := range blobs {
+		digests = append(digests, blob.Digest)
+	}
+	existing, err := dao.GetBlobsOfProject(projectID, digests...)
+	if err != nil {
+		return 0, 0, err
+	}
+	existingSet := map[string]bool{}
+	for _, d := range existing {
+		existingSet[d] = true
+	}
+	var storage int64
+	for _, blob := range blobs {
+		if existingSet[blob.Digest] {
+			continue
+		}
+		// count the blobs referenced by the manifest more than once only once
+		existingSet[blob.Digest] = true
+		storage += blob.Size
+	}
+
+	var count int64
+	if !isDigest(reference) {
+		artifact, err := dao.GetArtifact(repository, reference)
+		if err != nil {
+			return 0, 0, err
+		}
+		if artifact == nil {
+			count = 1
+		}
+	}
+	return storage, count, nil
+}
+
+// recordArtifact records the artifact and the blobs it references, the first blob is the manifest itself
+func recordArtifact(projectID int64, repository, tag string, blobs []*models.Blob) error {
+	digests := []string{}
+	for _, blob := range blobs {
+		digests = append(digests, blob.Digest)
+		// keep the recorded size if the manifest doesn't contain it
+		if blob.Size <= 0 {
+			continue
+		}
+		if err := dao.AddBlob(blob); err != nil {
+			return err
+		}
+	}
+	if err := dao.AddArtifactBlobs(blobs[0].Digest, digests...); err != nil {
+		return err
+	}
+	return dao.AddArtifact(&models.Artifact{
+		ProjectID: projectID,
+		Repo:      repository,
+		Tag:       tag,
+		Digest:    blobs[0].Digest,
+	})
+}
+
+// SyncArtifacts records the artifacts and blobs of the repositories pushed before the quotas were
+// enforced, so they are counted in the usages of the projects. The sync is a one-shot backfill: it's
+// run by only one core and skipped once it's done. If the core running it is interrupted, the sync is
+// claimed by another core, or the same one after restarting, and resumes from the repositories
+// without any artifact recorded
+func SyncArtifacts() error {
+	claimed, err := dao.ClaimArtifactBackfill()
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		log.Debug("the artifacts are synced or being synced by another core, skip")
+		return nil
+	}
+	repositories, err := dao.GetRepositoriesWithoutArtifacts()
+	if err != nil {
+		return err
+	}
+	log.Infof("syncing the artifacts of %d repositories to calculate the quota usages...", len(repositories))
+	for _, repository := range repositories {
+		if err = syncArtifactsOfRepository(repository); err != nil {
+			log.Errorf("failed to sync the artifacts of repository %s: %v", repository.Name, err)
+		}
+		if err = dao.RefreshArtifactBackfill(); err != nil {
+			log.Errorf("failed to refresh the sync of artifacts: %v", err)
+		}
+	}
+	if err = dao.FinishArtifactBackfill(); err != nil {
+		return err
+	}
+	log.Info("the artifacts are synced")
+	return nil
+}
+
+func syncArtifactsOfRepository(repository *models.RepoRecord) error {
+	client, err := coreutils.NewRepositoryClientForUI(tokenUsername, repository.Name)
+	if err != nil {
+		return err
+	}
+	tags, err := client.ListTag()
+	if err != nil {
+		return err
+	}
+	mediaTypes := []string{manifestlist.MediaTypeManifestList, schema2.MediaTypeManifest, schema1.MediaTypeSignedManifest}
+	for _, tag := range tags {
+		_, mediaType, payload, err := client.PullManifest(tag, mediaTypes)
+		if err != nil {
+			return err
+		}
+		manifest, _, err := distribution.UnmarshalManifest(mediaType, payload)
+		if err != nil {
+			return err
+		}
+		blobs, err := referencedBlobs(manifest, mediaType, payload)
+		if err != nil {
+			return err
+		}
+		// the schema1 manifest doesn't contain the sizes of layers, get them from the registry
+		for _, blob := range blobs[1:] {
+			if blob.Size > 0 {
+				continue
+			}
+			if blob.Size, err = client.BlobSize(blob.Digest); err != nil {
+				return err
+			}
+		}
+		if err = recordArtifact(repository.ProjectID, repository.Name, tag, blobs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reserveQuota checks whether the resources requested exceed the quota of the project and reserves them
+// until the returned function is called. The error is written into the response and false is returned if
+// the hard limits are exceeded
+func reserveQuota(rw http.ResponseWriter, project *models.Project, repository string, storage, count int64) (func(), bool) {
+	release, err := reserve(project, repository, storage, count)
+	if err != nil {
+		if _, ok := err.(*quota.ExceededError); ok {
+			http.Error(rw, marshalError("DENIED", err.Error()), http.StatusForbidden)
+			return nil, false
+		}
+		http.Error(rw, marshalError("UNKNOWN", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return nil, false
+	}
+	return release, true
+}
+
+// reserve reserves the resources requested in the quota of the project until the returned function is called.
+// The quota.ExceededError is returned if the hard limits are exceeded
+func reserve(project *models.Project, repository string, storage, count int64) (func(), error) {
+	if storage <= 0 && count <= 0 {
+		return func() {}, nil
+	}
+	mgr := quota.NewDefaultManager(config.QuotaDefaults)
+	release, warnings, err := mgr.Reserve(project.ProjectID, storage, count)
+	if err != nil {
+		if _, ok := err.(*quota.ExceededError); ok {
+			log.Warningf("The request is rejected for project %s: %v", project.Name, err)
+			webhook.Publish(&webhook.Event{
+				Type:       models.WebhookEventQuotaExceeded,
+				Project:    project.Name,
+				Repository: repository,
+				Message:    err.Error(),
+			})
+			return nil, err
+		}
+		log.Errorf("failed to check the quota of project %s: %v", project.Name, err)
+		return nil, err
+	}
+	for _, warning := range warnings {
+		log.Warningf("Project %s: %s", project.Name, warning)
+	}
+	return release, nil
+}
+
+// PushManifestWithQuota reserves the resources requested by the manifest in the quota of the project,
+// calls push to push it and records the artifact and the blobs it references once it's pushed. It's used
+// by the manifests pushed to the registry directly rather than through the proxy, e.g. the retagged ones.
+// The quota.ExceededError is returned if the hard limits are exceeded
+func PushManifestWithQuota(project *models.Project, repository, tag, mediaType string, payload []byte, push func() error) error {
+	manifest, _, err := distribution.UnmarshalManifest(mediaType, payload)
+	if err != nil {
+		return err
+	}
+	blobs, err := referencedBlobs(manifest, mediaType, payload)
+	if err != nil {
+		return err
+	}
+	storage, count, err := requestedResources(project.ProjectID, repository, tag, blobs)
+	if err != nil {
+		return err
+	}
+	release, err := reserve(project, repository, storage, count)
+	if err != nil {
+		return err
+	}
+	// released after the artifact is recorded
+	defer release()
+
+	if err = push(); err != nil {
+		return err
+	}
+	if err = recordArtifact(project.ProjectID, repository, tag, blobs); err != nil {
+		log.Errorf("failed to record the artifact %s:%s: %v", repository, tag, err)
+	}
+	return nil
+}
+
+// getProjectOfRepository returns the project that the repository belongs to, nil is returned if the project doesn't exist
+func getProjectOfRepository(repository string) (*models.Project, error) {
+	components := strings.SplitN(repository, "/", 2)
+	if len(components) < 2 {
+		return nil, nil
+	}
+	return config.GlobalProjectMgr.Get(components[0])
+}
@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchCompleteBlobUpload(t *testing.T) {
+	req1, _ := http.NewRequest("PATCH", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/uploads/b7ac9c8e-1b0a-4f36-a4f3-8a7c5d3f5a1e?_state=abc", nil)
+	match, _, _ := matchCompleteBlobUpload(req1)
+	assert.False(t, match)
+
+	// no digest
+	req2, _ := http.NewRequest("PUT", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/uploads/b7ac9c8e-1b0a-4f36-a4f3-8a7c5d3f5a1e?_state=abc", nil)
+	match, _, _ = matchCompleteBlobUpload(req2)
+	assert.False(t, match)
+
+	req3, _ := http.NewRequest("PUT", "http://127.0.0.1:5000/v2/path1/library/ubuntu/blobs/uploads/b7ac9c8e-1b0a-4f36-a4f3-8a7c5d3f5a1e?_state=abc&digest=sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", nil)
+	match, repository, digest := matchCompleteBlobUpload(req3)
+	assert.True(t, match)
+	assert.Equal(t, "path1/library/ubuntu", repository)
+	assert.Equal(t, "sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", digest)
+}
+
+func TestMatchDeleteManifest(t *testing.T) {
+	req1, _ := http.NewRequest("GET", "http://127.0.0.1:5000/v2/library/ubuntu/manifests/14.04", nil)
+	match, _, _ := matchDeleteManifest(req1)
+	assert.False(t, match)
+
+	req2, _ := http.NewRequest("DELETE", "http://127.0.0.1:5000/v2/library/ubuntu/manifests/sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", nil)
+	match, repository, reference := matchDeleteManifest(req2)
+	assert.True(t, match)
+	assert.Equal(t, "library/ubuntu", repository)
+	assert.Equal(t, "sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", reference)
+}
+
+func TestStatusRecorder(t *testing.T) {
+	rec := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+	rec.Write([]byte("data"))
+	assert.Equal(t, http.StatusOK, rec.status)
+
+	rec = &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+	rec.WriteHeader(http.StatusCreated)
+	rec.Write([]byte("data"))
+	assert.Equal(t, http.StatusCreated, rec.status)
+}
+
+func TestReferencedBlobs(t *testing.T) {
+	payload := []byte(`{
+   "schemaVersion": 2,
+   "mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+   "config": {
+      "mediaType": "application/vnd.docker.container.image.v1+json",
+      "size": 1510,
+      "digest": "sha256:fce289e99eb9bca977dae136fbe2a82b6b7d4c372474c9235adc1741675f587e"
+   },
+   "layers": [
+      {
+         "mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip",
+         "size": 977,
+         "digest": "sha256:1b930d010525941c1d56ec53b97bd057a67ae1865eebf042686d2a2d18271ced"
+      },
+      {
+         "mediaType": "application/vnd.docker.image.rootfs.foreign.diff.tar.gzip",
+         "size": 1000,
+         "digest": "sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a"
+      }
+   ]
+}`)
+	manifest, _, err := distribution.UnmarshalManifest(schema2.MediaTypeManifest, payload)
+	require.Nil(t, err)
+	blobs, err := referencedBlobs(manifest, schema2.MediaTypeManifest, payload)
+	require.Nil(t, err)
+	// the foreign layer is skipped
+	require.Equal(t, 3, len(blobs))
+	assert.Equal(t, int64(len(payload)), blobs[0].Size)
+	assert.Equal(t, schema2.MediaTypeManifest, blobs[0].ContentType)
+	assert.Equal(t, "sha256:fce289e99eb9bca977dae136fbe2a82b6b7d4c372474c9235adc1741675f587e", blobs[1].Digest)
+	assert.Equal(t, int64(1510), blobs[1].Size)
+	assert.Equal(t, "sha256:1b930d010525941c1d56ec53b97bd057a67ae1865eebf042686d2a2d18271ced", blobs[2].Digest)
+	assert.Equal(t, int64(977), blobs[2].Size)
+}
+
+func TestPushManifestWithQuota(t *testing.T) {
+	pushed := false
+	push := func() error {
+		pushed = true
+		return nil
+	}
+	project := &models.Project{ProjectID: 1, Name: "library"}
+	// the invalid manifest isn't pushed
+	err := PushManifestWithQuota(project, "library/hello-world", "latest", schema2.MediaTypeManifest, []byte("invalid"), push)
+	assert.NotNil(t, err)
+	assert.False(t, pushed)
+}
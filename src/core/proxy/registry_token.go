@@ -0,0 +1,59 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net/http"
+	"strings"
+
+	dtoken "github.com/docker/distribution/registry/auth/token"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	coretoken "github.com/goharbor/harbor/src/core/service/token"
+)
+
+// registryClaims returns the claims of the registry token carried by the request. The registry
+// requests aren't authenticated by the security filter, so the handlers which act before the
+// registry authorizes the request get the identity and the access granted from the token.
+// Nil is returned if the request doesn't carry a valid registry token.
+func registryClaims(req *http.Request) *dtoken.ClaimSet {
+	auth := req.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		return nil
+	}
+	claims, err := coretoken.VerifyRegistryToken(strings.TrimSpace(auth[7:]))
+	if err != nil {
+		log.Debugf("invalid registry token: %v", err)
+		return nil
+	}
+	return claims
+}
+
+// granted checks whether the action on the repository is granted by the claims
+func granted(claims *dtoken.ClaimSet, repository, action string) bool {
+	if claims == nil {
+		return false
+	}
+	for _, access := range claims.Access {
+		if access.Type != "repository" || access.Name != repository {
+			continue
+		}
+		for _, a := range access.Actions {
+			if a == action || a == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
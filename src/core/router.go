@@ -16,6 +16,7 @@ package main
 
 import (
 	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/core/api"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/controllers"
@@ -69,6 +70,16 @@ func initRouters() {
 	beego.Router("/api/projects/", &api.ProjectAPI{}, "get:List;post:Post")
 	beego.Router("/api/projects/:id([0-9]+)/logs", &api.ProjectAPI{}, "get:Logs")
 	beego.Router("/api/projects/:id([0-9]+)/_deletable", &api.ProjectAPI{}, "get:Deletable")
+	beego.Router("/api/projects/:id([0-9]+)/quota", &api.ProjectAPI{}, "get:GetQuota;delete:DeleteQuota")
+	beego.Router("/api/projects/:id([0-9]+)/retention", &api.RetentionAPI{}, "get:GetPolicy;put:PutPolicy;delete:DeletePolicy")
+	beego.Router("/api/projects/:id([0-9]+)/retention/executions", &api.RetentionAPI{}, "get:ListExecutions;post:Execute")
+	beego.Router("/api/projects/:id([0-9]+)/retention/executions/:eid([0-9]+)/tasks", &api.RetentionAPI{}, "get:ListTasks")
+	beego.Router("/api/projects/:id([0-9]+)/retention/executions/:eid([0-9]+)/tasks/:tid([0-9]+)/log", &api.RetentionAPI{}, "get:GetTaskLog")
+	beego.Router("/api/projects/:id([0-9]+)/immutabletagrules", &api.ImmutableTagRuleAPI{}, "get:List;post:Post")
+	beego.Router("/api/projects/:id([0-9]+)/immutabletagrules/:rid([0-9]+)", &api.ImmutableTagRuleAPI{}, "put:Put;delete:Delete")
+	beego.Router("/api/projects/:id([0-9]+)/webhook/endpoints", &api.WebhookAPI{}, "get:ListEndpoints;post:PostEndpoint")
+	beego.Router("/api/projects/:id([0-9]+)/webhook/endpoints/:eid([0-9]+)", &api.WebhookAPI{}, "get:GetEndpoint;put:PutEndpoint;delete:DeleteEndpoint")
+	beego.Router("/api/projects/:id([0-9]+)/webhook/endpoints/:eid([0-9]+)/deliveries", &api.WebhookAPI{}, "get:ListDeliveries")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/?:name", &api.MetadataAPI{}, "get:Get")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/", &api.MetadataAPI{}, "post:Post")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/:name", &api.MetadataAPI{}, "put:Put;delete:Delete")
@@ -103,11 +114,16 @@ func initRouters() {
 	beego.Router("/api/replication/adapters", &api.ReplicationAdapterAPI{}, "get:List")
 	beego.Router("/api/replication/executions", &api.ReplicationOperationAPI{}, "get:ListExecutions;post:CreateExecution")
 	beego.Router("/api/replication/executions/:id([0-9]+)", &api.ReplicationOperationAPI{}, "get:GetExecution;put:StopExecution")
+	beego.Router("/api/replication/executions/:id([0-9]+)/retry", &api.ReplicationOperationAPI{}, "post:RetryExecution")
 	beego.Router("/api/replication/executions/:id([0-9]+)/tasks", &api.ReplicationOperationAPI{}, "get:ListTasks")
 	beego.Router("/api/replication/executions/:id([0-9]+)/tasks/:tid([0-9]+)/log", &api.ReplicationOperationAPI{}, "get:GetTaskLog")
 
 	beego.Router("/api/replication/policies", &api.ReplicationPolicyAPI{}, "get:List;post:Create")
 	beego.Router("/api/replication/policies/:id([0-9]+)", &api.ReplicationPolicyAPI{}, "get:Get;put:Update;delete:Delete")
+	beego.Router("/api/replication/policies/:id([0-9]+)/plan", &api.ReplicationPolicyAPI{}, "post:Plan")
+	beego.Router("/api/replication/policies/plan", &api.ReplicationPolicyAPI{}, "post:PlanUnsaved")
+	beego.Router("/api/replication/policies/export", &api.ReplicationPolicyAPI{}, "get:Export")
+	beego.Router("/api/replication/policies/import", &api.ReplicationPolicyAPI{}, "post:Import")
 
 	beego.Router("/api/internal/configurations", &api.ConfigAPI{}, "get:GetInternalConfig;put:Put")
 	beego.Router("/api/configurations", &api.ConfigAPI{}, "get:Get;put:Put")
@@ -130,11 +146,15 @@ func initRouters() {
 	beego.Router("/service/notifications/jobs/adminjob/:id([0-9]+)", &admin.Handler{}, "post:HandleAdminJob")
 	beego.Router("/service/notifications/jobs/replication/:id([0-9]+)", &jobs.Handler{}, "post:HandleReplicationScheduleJob")
 	beego.Router("/service/notifications/jobs/replication/task/:id([0-9]+)", &jobs.Handler{}, "post:HandleReplicationTask")
+	beego.Router("/service/notifications/jobs/retention/task/:id([0-9]+)", &jobs.Handler{}, "post:HandleRetentionTask")
+	beego.Router("/service/notifications/jobs/webhook/delivery/:id([0-9]+)", &jobs.Handler{}, "post:HandleWebhookDelivery")
 	beego.Router("/service/token", &token.Handler{})
+	beego.Handler("/metrics", metrics.Handler())
 
 	beego.Router("/api/registries", &api.RegistryAPI{}, "get:List;post:Post")
 	beego.Router("/api/registries/:id([0-9]+)", &api.RegistryAPI{}, "get:Get;put:Put;delete:Delete")
 	beego.Router("/api/registries/ping", &api.RegistryAPI{}, "post:Ping")
+	beego.Router("/api/registries/:id([0-9]+)/health", &api.RegistryAPI{}, "get:GetHealth")
 	// we use "0" as the ID of the local Harbor registry, so don't add "([0-9]+)" in the path
 	beego.Router("/api/registries/:id/info", &api.RegistryAPI{}, "get:GetInfo")
 	beego.Router("/api/registries/:id/namespace", &api.RegistryAPI{}, "get:GetNamespace")
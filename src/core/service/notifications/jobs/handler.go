@@ -20,9 +20,13 @@ import (
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/job"
 	jobmodels "github.com/goharbor/harbor/src/common/job/models"
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/api"
+	"github.com/goharbor/harbor/src/pkg/retention"
+	"github.com/goharbor/harbor/src/pkg/webhook"
 	"github.com/goharbor/harbor/src/replication"
 	"github.com/goharbor/harbor/src/replication/operation/hook"
 	"github.com/goharbor/harbor/src/replication/policy/scheduler"
@@ -81,6 +85,34 @@ func (h *Handler) HandleScan() {
 		h.SendInternalServerError(err)
 		return
 	}
+	if h.status == models.JobFinished || h.status == models.JobError {
+		metrics.ScanJobsTotal.WithLabelValues(h.status).Inc()
+		publishScanEvent(h.id, h.status)
+	}
+}
+
+// publish the webhook event when the scan job is done
+func publishScanEvent(id int64, status string) {
+	scanJob, err := dao.GetScanJob(id)
+	if err != nil {
+		log.Errorf("Failed to get scan job %d: %v", id, err)
+		return
+	}
+	if scanJob == nil {
+		return
+	}
+	eventType := models.WebhookEventScanningCompleted
+	if status == models.JobError {
+		eventType = models.WebhookEventScanningFailed
+	}
+	project, _ := utils.ParseRepository(scanJob.Repository)
+	webhook.Publish(&webhook.Event{
+		Type:       eventType,
+		Project:    project,
+		Repository: scanJob.Repository,
+		Tag:        scanJob.Tag,
+		Digest:     scanJob.Digest,
+	})
 }
 
 // HandleReplicationScheduleJob handles the webhook of replication schedule job
@@ -101,4 +133,28 @@ func (h *Handler) HandleReplicationTask() {
 		h.SendInternalServerError(err)
 		return
 	}
+	switch h.rawStatus {
+	case job.JobServiceStatusSuccess, job.JobServiceStatusError, job.JobServiceStatusStopped:
+		metrics.ReplicationTasksTotal.WithLabelValues(h.status).Inc()
+	}
+}
+
+// HandleRetentionTask handles the webhook of retention task
+func (h *Handler) HandleRetentionTask() {
+	log.Debugf("received retention task status update event: task-%d, status-%s", h.id, h.status)
+	if err := retention.Ctl.UpdateTaskStatus(h.id, h.status); err != nil {
+		log.Errorf("Failed to update retention task status, id: %d, status: %s", h.id, h.status)
+		h.SendInternalServerError(err)
+		return
+	}
+}
+
+// HandleWebhookDelivery handles the webhook of the job delivering the webhook event
+func (h *Handler) HandleWebhookDelivery() {
+	log.Debugf("received webhook delivery status update event: delivery-%d, status-%s", h.id, h.status)
+	if err := webhook.Ctl.UpdateDeliveryStatus(h.id, h.status); err != nil {
+		log.Errorf("Failed to update webhook delivery status, id: %d, status: %s", h.id, h.status)
+		h.SendInternalServerError(err)
+		return
+	}
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/goharbor/harbor/src/core/api"
 	"github.com/goharbor/harbor/src/core/config"
 	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/pkg/webhook"
 	"github.com/goharbor/harbor/src/replication"
 	"github.com/goharbor/harbor/src/replication/adapter"
 	rep_event "github.com/goharbor/harbor/src/replication/event"
@@ -136,6 +137,15 @@ func (n *NotificationHandler) Post() {
 				}
 			}()
 
+			webhook.Publish(&webhook.Event{
+				Type:       models.WebhookEventPushImage,
+				Operator:   user,
+				Project:    project,
+				Repository: repository,
+				Tag:        tag,
+				Digest:     event.Target.Digest,
+			})
+
 			if autoScanEnabled(pro) {
 				last, err := clairdao.GetLastUpdate()
 				if err != nil {
@@ -148,6 +158,14 @@ func (n *NotificationHandler) Post() {
 			}
 		}
 		if action == "pull" {
+			webhook.Publish(&webhook.Event{
+				Type:       models.WebhookEventPullImage,
+				Operator:   user,
+				Project:    project,
+				Repository: repository,
+				Tag:        tag,
+				Digest:     event.Target.Digest,
+			})
 			go func() {
 				log.Debugf("Increase the repository %s pull count.", repository)
 				if err := dao.IncreasePullCount(repository); err != nil {
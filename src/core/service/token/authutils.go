@@ -122,6 +122,29 @@ func MakeToken(username, service string, access []*token.ResourceActions) (*mode
 	}, nil
 }
 
+// VerifyRegistryToken verifies the token issued to access the registry and returns its claims,
+// an error is returned if the token isn't signed by the token service or has expired.
+func VerifyRegistryToken(rawToken string) (*token.ClaimSet, error) {
+	pk, err := libtrust.LoadKeyFile(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	t, err := token.NewToken(rawToken)
+	if err != nil {
+		return nil, err
+	}
+	if err = t.Verify(token.VerifyOptions{
+		TrustedIssuers:    []string{issuer},
+		AcceptedAudiences: []string{Registry},
+		TrustedKeys: map[string]libtrust.PublicKey{
+			pk.KeyID(): pk.PublicKey(),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return t.Claims, nil
+}
+
 func permToActions(p string) []string {
 	res := []string{}
 	if strings.Contains(p, "W") {
@@ -19,6 +19,7 @@ import (
 	"net/http"
 
 	"github.com/astaxie/beego"
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/common/utils/log"
 )
 
@@ -38,16 +39,21 @@ func (h *Handler) Get() {
 	if !ok {
 		errMsg := fmt.Sprintf("Unable to handle service: %s", service)
 		log.Errorf(errMsg)
+		// the service comes from the client, use a fixed label to bound the cardinality
+		metrics.TokenRequestsTotal.WithLabelValues("unknown", "unsupported").Inc()
 		h.CustomAbort(http.StatusBadRequest, errMsg)
 	}
 	token, err := tokenCreator.Create(request)
 	if err != nil {
 		if _, ok := err.(*unauthorizedError); ok {
+			metrics.TokenRequestsTotal.WithLabelValues(service, "unauthorized").Inc()
 			h.CustomAbort(http.StatusUnauthorized, "")
 		}
 		log.Errorf("Unexpected error when creating the token, error: %v", err)
+		metrics.TokenRequestsTotal.WithLabelValues(service, "error").Inc()
 		h.CustomAbort(http.StatusInternalServerError, "")
 	}
+	metrics.TokenRequestsTotal.WithLabelValues(service, "issued").Inc()
 	h.Data["json"] = token
 	h.ServeJSON()
 
@@ -17,6 +17,7 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	"github.com/docker/distribution/registry/auth/token"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"crypto/rsa"
 	"crypto/x509"
@@ -158,6 +159,32 @@ func TestMakeToken(t *testing.T) {
 	assert.Equal(t, claims.Audience, svc, "Audience mismatch")
 }
 
+func TestVerifyRegistryToken(t *testing.T) {
+	pk, _ := getKeyAndCertPath()
+	privateKey = pk
+	ra := []*token.ResourceActions{{
+		Type:    "repository",
+		Name:    "library/hello-world",
+		Actions: []string{"pull"},
+	}}
+	tk, err := MakeToken("tester", Registry, ra)
+	require.Nil(t, err)
+	claims, err := VerifyRegistryToken(tk.Token)
+	require.Nil(t, err)
+	assert.Equal(t, "tester", claims.Subject)
+	assert.Equal(t, *ra[0], *claims.Access[0])
+
+	// the token issued for other services isn't accepted
+	tk, err = MakeToken("tester", Notary, ra)
+	require.Nil(t, err)
+	_, err = VerifyRegistryToken(tk.Token)
+	assert.NotNil(t, err)
+
+	// tampered token
+	_, err = VerifyRegistryToken(tk.Token + "a")
+	assert.NotNil(t, err)
+}
+
 func TestPermToActions(t *testing.T) {
 	perm1 := "RWM"
 	perm2 := "MRR"
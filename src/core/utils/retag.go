@@ -25,8 +25,9 @@ import (
 	"github.com/docker/distribution/manifest/schema2"
 )
 
-// Retag tags an image to another
-func Retag(srcImage, destImage *models.Image) error {
+// Retag tags an image to another. If wrap isn't nil, the blobs and the manifest are pushed by calling the
+// push function passed to it, so the caller can check and record the manifest around the push
+func Retag(srcImage, destImage *models.Image, wrap func(mediaType string, payload []byte, push func() error) error) error {
 	isSameRepo := getRepoName(srcImage) == getRepoName(destImage)
 	srcClient, err := NewRepositoryClientForUI("harbor-ui", getRepoName(srcImage))
 	if err != nil {
@@ -72,21 +73,62 @@ func Retag(srcImage, destImage *models.Image) error {
 		return nil
 	}
 
-	if !isSameRepo {
-		for _, descriptor := range manifest.References() {
-			err := destClient.MountBlob(descriptor.Digest.String(), srcClient.Name)
-			if err != nil {
-				log.Errorf("mount blob '%s' error: %v", descriptor.Digest.String(), err)
-				return err
+	push := func() error {
+		if !isSameRepo {
+			for _, descriptor := range manifest.References() {
+				if descriptor.MediaType == schema2.MediaTypeForeignLayer {
+					continue
+				}
+				if err := copyBlob(srcClient, destClient, descriptor.Digest.String()); err != nil {
+					return err
+				}
 			}
 		}
+
+		if _, err := destClient.PushManifest(destImage.Tag, mediaType, payload); err != nil {
+			log.Errorf("push manifest '%s:%s' error: %v", destClient.Name, destImage.Tag, err)
+			return err
+		}
+		return nil
+	}
+	if wrap != nil {
+		return wrap(mediaType, payload, push)
 	}
+	return push()
+}
 
-	if _, err = destClient.PushManifest(destImage.Tag, mediaType, payload); err != nil {
-		log.Errorf("push manifest '%s:%s' error: %v", destClient.Name, destImage.Tag, err)
+// copyBlob mounts the blob from the source repository into the destination one, the blob
+// is copied if the registry cannot mount it
+func copyBlob(srcClient, destClient *registry.Repository, digest string) error {
+	exist, err := destClient.BlobExist(digest)
+	if err != nil {
+		log.Errorf("check existence of blob '%s' in %s error: %v", digest, destClient.Name, err)
 		return err
 	}
+	if exist {
+		return nil
+	}
 
+	mounted, err := destClient.MountBlob(digest, srcClient.Name)
+	if err != nil {
+		log.Errorf("mount blob '%s' error: %v", digest, err)
+		return err
+	}
+	if mounted {
+		return nil
+	}
+
+	log.Warningf("blob '%s' cannot be mounted from %s, copy it", digest, srcClient.Name)
+	size, data, err := srcClient.PullBlob(digest)
+	if err != nil {
+		log.Errorf("pull blob '%s' error: %v", digest, err)
+		return err
+	}
+	defer data.Close()
+	if err = destClient.PushBlob(digest, size, data); err != nil {
+		log.Errorf("push blob '%s' error: %v", digest, err)
+		return err
+	}
 	return nil
 }
 
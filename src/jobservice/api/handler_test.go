@@ -95,6 +95,21 @@ func (suite *APIHandlerTestSuite) TestUnAuthorizedAccess() {
 	assert.Equal(suite.T(), 401, code, "expect '401' but got none 401 error")
 }
 
+// TestMetrics ...
+func (suite *APIHandlerTestSuite) TestMetrics() {
+	_ = os.Unsetenv(secretKey)
+	defer func() {
+		_ = os.Setenv(secretKey, fakeSecret)
+	}()
+
+	_, code := suite.getReq(fmt.Sprintf("%s/%s", suite.APIAddr, "jobs/fake_job"))
+	assert.Equal(suite.T(), 401, code, "expect '401' but got none 401 error")
+
+	res, code := suite.getReq(strings.TrimSuffix(suite.APIAddr, "/api/v1") + "/metrics")
+	assert.Equal(suite.T(), 200, code, "expected 200 ok but got %d", code)
+	assert.Contains(suite.T(), string(res), `harbor_http_requests_total{code="401",method="GET",route="/api/v1/jobs/{job_id}"}`)
+}
+
 // TestLaunchJobFailed ...
 func (suite *APIHandlerTestSuite) TestLaunchJobFailed() {
 	req := createJobReq()
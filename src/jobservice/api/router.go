@@ -19,6 +19,7 @@ import (
 	"github.com/pkg/errors"
 	"net/http"
 
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/logger"
 	"github.com/gorilla/mux"
@@ -27,6 +28,8 @@ import (
 const (
 	baseRoute  = "/api"
 	apiVersion = "v1"
+
+	metricsRoute = "/metrics"
 )
 
 // Router defines the related routes for the job service and directs the request
@@ -46,6 +49,9 @@ type BaseRouter struct {
 
 	// Do auth
 	authenticator Authenticator
+
+	// Serve the requests and record them in the metrics
+	instrumented http.Handler
 }
 
 // NewBaseRouter is the constructor of BaseRouter.
@@ -58,15 +64,21 @@ func NewBaseRouter(handler Handler, authenticator Authenticator) Router {
 
 	// Register routes here
 	br.registerRoutes()
+	br.instrumented = metrics.InstrumentHandler(http.HandlerFunc(br.serve), metrics.MuxRoute(br.router))
 
 	return br
 }
 
 // ServeHTTP is the implementation of Router interface.
 func (br *BaseRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	br.instrumented.ServeHTTP(w, req)
+}
+
+func (br *BaseRouter) serve(w http.ResponseWriter, req *http.Request) {
 	// No auth required for /stats as it is a health check endpoint
+	// and /metrics which is scraped by the monitoring system
 	// Do auth for other services
-	if req.URL.String() != fmt.Sprintf("%s/%s/stats", baseRoute, apiVersion) {
+	if req.URL.String() != fmt.Sprintf("%s/%s/stats", baseRoute, apiVersion) && req.URL.Path != metricsRoute {
 		if err := br.authenticator.DoAuth(req); err != nil {
 			authErr := errs.UnauthorizedError(err)
 			if authErr == nil {
@@ -94,4 +106,6 @@ func (br *BaseRouter) registerRoutes() {
 	subRouter.HandleFunc("/jobs/{job_id}/log", br.handler.HandleJobLogReq).Methods(http.MethodGet)
 	subRouter.HandleFunc("/stats", br.handler.HandleCheckStatusReq).Methods(http.MethodGet)
 	subRouter.HandleFunc("/jobs/{job_id}/executions", br.handler.HandlePeriodicExecutions).Methods(http.MethodGet)
+
+	br.router.Handle(metricsRoute, metrics.Handler()).Methods(http.MethodGet)
 }
@@ -56,6 +56,8 @@ const (
 	GetPeriodicExecutionErrorCode
 	// StatusMismatchErrorCode is code for the error of mismatching status
 	StatusMismatchErrorCode
+	// RequeueErrorCode is code for the error of requeuing the job
+	RequeueErrorCode
 )
 
 // baseError ...
@@ -216,6 +218,23 @@ func StatusMismatchError(current, target string) error {
 	}
 }
 
+// requeueError is returned by the job which can't go on for now, e.g. the resources it
+// needs are taken by others. The job is put back to the queue without consuming a fail
+type requeueError struct {
+	baseError
+}
+
+// RequeueError returns the error of requeuing the job with the reason
+func RequeueError(reason string) error {
+	return requeueError{
+		baseError{
+			Code:        RequeueErrorCode,
+			Err:         "requeue job",
+			Description: reason,
+		},
+	}
+}
+
 // IsObjectNotFoundError return true if the error is objectNotFoundError
 func IsObjectNotFoundError(err error) bool {
 	if err == nil {
@@ -251,3 +270,12 @@ func IsStatusMismatchError(err error) bool {
 	_, ok := err.(statusMismatchError)
 	return ok
 }
+
+// IsRequeueError returns true if the error is requeueError
+func IsRequeueError(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := err.(requeueError)
+	return ok
+}
@@ -0,0 +1,180 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/common/utils"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/replication/transfer"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// the lease of the concurrency slot, it's renewed periodically by the holder
+	// and the slot is released automatically if the holder crashes
+	slotLease = 60 * time.Second
+	// the TTL of the idle token buckets
+	bucketTTL = 60 * time.Second
+)
+
+var (
+	// take one slot if the count of the unexpired slots is less than the max
+	acquireScript = redis.NewScript(1, `
+local now = tonumber(ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+  return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[3]), ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 1
+`)
+	// renew the lease of the slot if it still exists
+	renewScript = redis.NewScript(1, `
+if redis.call('ZSCORE', KEYS[1], ARGV[3]) then
+  redis.call('ZADD', KEYS[1], tonumber(ARGV[1]) + tonumber(ARGV[2]), ARGV[3])
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+	// take the tokens from the bucket which is refilled by the rate per second and whose
+	// capacity is the rate. The tokens can be overdrawn, in this case the milliseconds
+	// that the caller should wait for the bucket being refilled is returned
+	takeScript = redis.NewScript(1, `
+local rate = tonumber(ARGV[1])
+local n = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens'))
+local ts = tonumber(redis.call('HGET', KEYS[1], 'ts'))
+if tokens == nil or ts == nil then
+  tokens = rate
+  ts = now
+end
+tokens = math.min(rate, tokens + math.max(0, now - ts) * rate / 1000) - n
+redis.call('HMSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+if tokens >= 0 then
+  return 0
+end
+return math.ceil(-tokens * 1000 / rate)
+`)
+)
+
+var (
+	limiterOnce    sync.Once
+	defaultLimiter transfer.Limiter
+)
+
+// getLimiter returns the limiter shared by the replication jobs, nil is
+// returned if the Redis isn't configured
+func getLimiter() transfer.Limiter {
+	limiterOnce.Do(func() {
+		pool, namespace := getRedisPool()
+		if pool == nil {
+			logger.Warning("the redis isn't configured, the limits of replication are ignored")
+			return
+		}
+		defaultLimiter = newRedisLimiter(pool, namespace)
+	})
+	return defaultLimiter
+}
+
+// redisLimiter implements the limiter based on Redis to share the limits across the job workers
+type redisLimiter struct {
+	pool      *redis.Pool
+	namespace string
+}
+
+func newRedisLimiter(pool *redis.Pool, namespace string) transfer.Limiter {
+	return &redisLimiter{
+		pool:      pool,
+		namespace: namespace,
+	}
+}
+
+func (r *redisLimiter) Acquire(key string, max int) (bool, func(), error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	redisKey := r.key("concurrency", key)
+	id := utils.MakeIdentifier()
+	acquired, err := redis.Int(acquireScript.Do(conn, redisKey, now(), max, slotLease.Nanoseconds()/1e6, id))
+	if err != nil {
+		return false, nil, err
+	}
+	if acquired == 0 {
+		return false, nil, nil
+	}
+
+	// renew the lease periodically until the slot is released
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(slotLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.renew(redisKey, id); err != nil {
+					logger.Errorf("failed to renew the lease of slot %s: %v", redisKey, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			close(done)
+			conn := r.pool.Get()
+			defer conn.Close()
+			if _, err := conn.Do("ZREM", redisKey, id); err != nil {
+				logger.Errorf("failed to release the slot %s: %v", redisKey, err)
+			}
+		})
+	}
+	return true, release, nil
+}
+
+func (r *redisLimiter) renew(key, id string) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+	_, err := renewScript.Do(conn, key, now(), slotLease.Nanoseconds()/1e6, id)
+	return err
+}
+
+func (r *redisLimiter) Take(key string, rate int64, n int64) (time.Duration, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	wait, err := redis.Int64(takeScript.Do(conn, r.key("bandwidth", key), rate, n, now(), bucketTTL.Nanoseconds()/1e6))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+func (r *redisLimiter) key(kind, key string) string {
+	return fmt.Sprintf("%s:replication:limit:%s:%s", r.namespace, kind, key)
+}
+
+// the current time in milliseconds
+func now() int64 {
+	return time.Now().UnixNano() / 1e6
+}
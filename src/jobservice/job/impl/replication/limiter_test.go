@@ -0,0 +1,55 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/jobservice/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisLimiter(t *testing.T) {
+	pool := tests.GiveMeRedisPool()
+	namespace := tests.GiveMeTestNamespace()
+	defer func() {
+		conn := pool.Get()
+		defer conn.Close()
+		_ = tests.ClearAll(namespace, conn)
+	}()
+	l := newRedisLimiter(pool, namespace)
+
+	// concurrency
+	acquired, release, err := l.Acquire("policy:1", 1)
+	require.Nil(t, err)
+	require.True(t, acquired)
+	acquired, _, err = l.Acquire("policy:1", 1)
+	require.Nil(t, err)
+	assert.False(t, acquired)
+	release()
+	acquired, release, err = l.Acquire("policy:1", 1)
+	require.Nil(t, err)
+	require.True(t, acquired)
+	release()
+
+	// bandwidth
+	wait, err := l.Take("policy:1", 100, 100)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), wait.Nanoseconds())
+	wait, err = l.Take("policy:1", 100, 50)
+	require.Nil(t, err)
+	assert.True(t, wait.Nanoseconds() > 0)
+}
@@ -0,0 +1,84 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/replication/transfer"
+	"github.com/gomodule/redigo/redis"
+)
+
+// the TTL of the blob location records, the records of the blobs which
+// are deleted or garbage collected expire finally
+const blobLocationTTL = 24 * time.Hour
+
+var (
+	locatorOnce    sync.Once
+	defaultLocator transfer.BlobLocator
+)
+
+// getBlobLocator returns the blob locator shared by the replication jobs,
+// nil is returned if the Redis isn't configured
+func getBlobLocator() transfer.BlobLocator {
+	locatorOnce.Do(func() {
+		pool, namespace := getRedisPool()
+		if pool == nil {
+			logger.Warning("the redis isn't configured, the blobs will not be mounted during replication")
+			return
+		}
+		defaultLocator = newRedisBlobLocator(pool, namespace)
+	})
+	return defaultLocator
+}
+
+// redisBlobLocator records the locations of blobs in Redis to share them across the job workers
+type redisBlobLocator struct {
+	pool      *redis.Pool
+	namespace string
+}
+
+func newRedisBlobLocator(pool *redis.Pool, namespace string) transfer.BlobLocator {
+	return &redisBlobLocator{
+		pool:      pool,
+		namespace: namespace,
+	}
+}
+
+func (r *redisBlobLocator) Locate(registry, digest string) (string, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	repository, err := redis.String(conn.Do("GET", r.key(registry, digest)))
+	if err == redis.ErrNil {
+		return "", nil
+	}
+	return repository, err
+}
+
+func (r *redisBlobLocator) Record(registry, digest, repository string) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("SET", r.key(registry, digest), repository, "EX", int64(blobLocationTTL/time.Second))
+	return err
+}
+
+func (r *redisBlobLocator) key(registry, digest string) string {
+	return fmt.Sprintf("%s:replication:blob:%s:%s", r.namespace, registry, digest)
+}
@@ -0,0 +1,45 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/jobservice/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisBlobLocator(t *testing.T) {
+	pool := tests.GiveMeRedisPool()
+	namespace := tests.GiveMeTestNamespace()
+	defer func() {
+		conn := pool.Get()
+		defer conn.Close()
+		_ = tests.ClearAll(namespace, conn)
+	}()
+	locator := newRedisBlobLocator(pool, namespace)
+
+	// no record
+	repository, err := locator.Locate("https://registry.com", "sha256:digest")
+	require.Nil(t, err)
+	assert.Equal(t, "", repository)
+
+	err = locator.Record("https://registry.com", "sha256:digest", "library/hello-world")
+	require.Nil(t, err)
+	repository, err = locator.Locate("https://registry.com", "sha256:digest")
+	require.Nil(t, err)
+	assert.Equal(t, "library/hello-world", repository)
+}
@@ -0,0 +1,49 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"sync"
+
+	"github.com/goharbor/harbor/src/jobservice/common/utils"
+	"github.com/goharbor/harbor/src/jobservice/config"
+	"github.com/gomodule/redigo/redis"
+)
+
+var (
+	poolOnce  sync.Once
+	redisPool *redis.Pool
+)
+
+// getRedisPool returns the Redis pool and namespace configured for the job service,
+// the pool is shared by the replication jobs. Nil is returned if the Redis isn't configured
+func getRedisPool() (*redis.Pool, string) {
+	cfg := config.DefaultConfig.PoolConfig
+	if cfg == nil || cfg.RedisPoolCfg == nil || utils.IsEmptyStr(cfg.RedisPoolCfg.RedisURL) {
+		return nil, ""
+	}
+	poolOnce.Do(func() {
+		redisURL := cfg.RedisPoolCfg.RedisURL
+		redisPool = &redis.Pool{
+			MaxActive: 6,
+			MaxIdle:   6,
+			Wait:      true,
+			Dial: func() (redis.Conn, error) {
+				return redis.DialURL(redisURL)
+			},
+		}
+	})
+	return redisPool, cfg.RedisPoolCfg.Namespace
+}
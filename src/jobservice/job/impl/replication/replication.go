@@ -40,6 +40,14 @@ import (
 	_ "github.com/goharbor/harbor/src/replication/adapter/awsecr"
 	// register the AzureAcr adapter
 	_ "github.com/goharbor/harbor/src/replication/adapter/azurecr"
+	// register the Quay adapter
+	_ "github.com/goharbor/harbor/src/replication/adapter/quay"
+	// register the GitLab adapter
+	_ "github.com/goharbor/harbor/src/replication/adapter/gitlab"
+	// register the Artifactory adapter
+	_ "github.com/goharbor/harbor/src/replication/adapter/artifactory"
+	// register the ChartMuseum and Helm chart repository adapters
+	_ "github.com/goharbor/harbor/src/replication/adapter/helm"
 )
 
 // Replication implements the job interface
@@ -90,9 +98,57 @@ func (r *Replication) Run(ctx job.Context, params job.Parameters) error {
 		return err
 	}
 
+	// persist the states of chunked uploads in the task to resume them when the job is retried
+	if resumable, ok := trans.(transfer.Resumable); ok {
+		if taskID := parseTaskID(params); taskID > 0 {
+			resumable.SetUploadStateStore(newTaskUploadStateStore(taskID))
+		}
+	}
+
+	// limit the concurrency and bandwidth across the job workers
+	if limitable, ok := trans.(transfer.Limitable); ok {
+		if limiter := getLimiter(); limiter != nil {
+			limitable.SetLimiter(limiter)
+		}
+	}
+
+	// mount the blobs existing in other repositories of the destination registry
+	if mountable, ok := trans.(transfer.Mountable); ok {
+		if locator := getBlobLocator(); locator != nil {
+			mountable.SetBlobLocator(locator)
+		}
+	}
+
+	// record the statistics of the data moved in the task
+	if measurable, ok := trans.(transfer.Measurable); ok {
+		measurable.SetStatisticsReporter(newTaskStatisticsReporter(parseTaskID(params), string(src.Type)))
+	}
+
 	return trans.Transfer(src, dst)
 }
 
+// parseTaskID returns the ID of the replication task that the job belongs to,
+// 0 is returned if the param doesn't exist or isn't a valid number
+func parseTaskID(params map[string]interface{}) int64 {
+	value, exist := params["task_id"]
+	if !exist {
+		return 0
+	}
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case json.Number:
+		id, _ := v.Int64()
+		return id
+	default:
+		return 0
+	}
+}
+
 func parseParams(params map[string]interface{}) (*model.Resource, *model.Resource, error) {
 	src := &model.Resource{}
 	if err := parseParam(params, "src_resource", src); err != nil {
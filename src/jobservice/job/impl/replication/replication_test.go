@@ -59,6 +59,16 @@ func TestParseParams(t *testing.T) {
 	assert.Equal(t, "chart", string(dst.Type))
 }
 
+func TestParseTaskID(t *testing.T) {
+	// not exist
+	assert.Equal(t, int64(0), parseTaskID(map[string]interface{}{}))
+	// invalid type
+	assert.Equal(t, int64(0), parseTaskID(map[string]interface{}{"task_id": "1"}))
+	// the number is decoded as float64 from the JSON
+	assert.Equal(t, int64(1), parseTaskID(map[string]interface{}{"task_id": float64(1)}))
+	assert.Equal(t, int64(2), parseTaskID(map[string]interface{}{"task_id": int64(2)}))
+}
+
 func TestMaxFails(t *testing.T) {
 	rep := &Replication{}
 	assert.Equal(t, uint(3), rep.MaxFails())
@@ -0,0 +1,57 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"strings"
+
+	"github.com/goharbor/harbor/src/common/metrics"
+	"github.com/goharbor/harbor/src/replication/dao"
+	"github.com/goharbor/harbor/src/replication/dao/models"
+	"github.com/goharbor/harbor/src/replication/transfer"
+)
+
+// taskStatisticsReporter records the statistics of the data moved in the replication task
+// and adds the bytes transferred to the metrics
+type taskStatisticsReporter struct {
+	taskID       int64
+	resourceType string
+}
+
+func newTaskStatisticsReporter(taskID int64, resourceType string) transfer.StatisticsReporter {
+	return &taskStatisticsReporter{
+		taskID:       taskID,
+		resourceType: resourceType,
+	}
+}
+
+func (t *taskStatisticsReporter) Report(stats *transfer.Statistics) error {
+	metrics.ReplicationTransferredBytes.WithLabelValues(t.resourceType).Add(float64(stats.BytesTransferred))
+	// the task isn't tracked when the job is submitted without the task ID
+	if t.taskID <= 0 {
+		return nil
+	}
+	task := &models.Task{
+		ID:               t.taskID,
+		BytesTransferred: stats.BytesTransferred,
+		BlobsCopied:      stats.BlobsCopied,
+		BlobsSkipped:     stats.BlobsSkipped,
+		ManifestDigests:  strings.Join(stats.ManifestDigests, ","),
+		Throughput:       stats.Throughput(),
+	}
+	_, err := dao.UpdateTask(task, models.TaskPropsName.BytesTransferred, models.TaskPropsName.BlobsCopied,
+		models.TaskPropsName.BlobsSkipped, models.TaskPropsName.ManifestDigests, models.TaskPropsName.Throughput)
+	return err
+}
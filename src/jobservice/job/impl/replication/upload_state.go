@@ -0,0 +1,107 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/goharbor/harbor/src/replication/dao"
+	"github.com/goharbor/harbor/src/replication/transfer"
+)
+
+// taskUploadStateStore persists the states of chunked blob uploads in the
+// replication task, so the uploads can be resumed when the job is retried
+type taskUploadStateStore struct {
+	sync.Mutex
+	taskID int64
+}
+
+func newTaskUploadStateStore(taskID int64) transfer.UploadStateStore {
+	return &taskUploadStateStore{
+		taskID: taskID,
+	}
+}
+
+func (t *taskUploadStateStore) Get(digest string) (*transfer.UploadState, error) {
+	t.Lock()
+	defer t.Unlock()
+	states, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	return states[digest], nil
+}
+
+func (t *taskUploadStateStore) Save(state *transfer.UploadState) error {
+	t.Lock()
+	defer t.Unlock()
+	states, err := t.load()
+	if err != nil {
+		return err
+	}
+	states[state.Digest] = state
+	return t.store(states)
+}
+
+func (t *taskUploadStateStore) Delete(digest string) error {
+	t.Lock()
+	defer t.Unlock()
+	states, err := t.load()
+	if err != nil {
+		return err
+	}
+	if _, exist := states[digest]; !exist {
+		return nil
+	}
+	delete(states, digest)
+	return t.store(states)
+}
+
+func (t *taskUploadStateStore) load() (map[string]*transfer.UploadState, error) {
+	task, err := dao.GetTask(t.taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task %d not found", t.taskID)
+	}
+	states := map[string]*transfer.UploadState{}
+	if len(task.UploadState) == 0 {
+		return states, nil
+	}
+	if err = json.Unmarshal([]byte(task.UploadState), &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (t *taskUploadStateStore) store(states map[string]*transfer.UploadState) error {
+	data, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+	task, err := dao.GetTask(t.taskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return fmt.Errorf("task %d not found", t.taskID)
+	}
+	task.UploadState = string(data)
+	_, err = dao.UpdateTask(task, "UploadState")
+	return err
+}
@@ -0,0 +1,249 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/http/modifier/auth"
+	"github.com/goharbor/harbor/src/common/models"
+	reg "github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/pkg/immutable"
+	"github.com/goharbor/harbor/src/pkg/retention"
+)
+
+// Retention applies the retention rules to one repository. The tags which aren't retained by
+// any rule are deleted via the API of core, so that the labels, signatures and other resources
+// of the tags are cleaned up too
+type Retention struct {
+	logger  logger.Interface
+	client  *common_http.Client
+	coreURL string
+}
+
+// tag is the subset of the tag returned by the API of core
+type tag struct {
+	Name     string          `json:"name"`
+	Digest   string          `json:"digest"`
+	Created  time.Time       `json:"created"`
+	PushTime *time.Time      `json:"push_time"`
+	Labels   []*models.Label `json:"labels"`
+}
+
+// ShouldRetry ...
+func (r *Retention) ShouldRetry() bool {
+	return false
+}
+
+// MaxFails ...
+func (r *Retention) MaxFails() uint {
+	return 1
+}
+
+// Validate ...
+func (r *Retention) Validate(params job.Parameters) error {
+	if _, ok := params["task_id"].(float64); !ok {
+		return errors.New("missing parameter task_id")
+	}
+	if repository, ok := params["repository"].(string); !ok || len(repository) == 0 {
+		return errors.New("missing parameter repository")
+	}
+	rules, ok := params["rules"].(string)
+	if !ok {
+		return errors.New("missing parameter rules")
+	}
+	if err := json.Unmarshal([]byte(rules), &[]*models.RetentionRule{}); err != nil {
+		return fmt.Errorf("invalid rules: %v", err)
+	}
+	return nil
+}
+
+// Run ...
+func (r *Retention) Run(ctx job.Context, params job.Parameters) error {
+	r.logger = ctx.GetLogger()
+	if err := r.init(ctx); err != nil {
+		r.logger.Errorf("failed to initialize the retention job: %v", err)
+		return err
+	}
+
+	taskID := (int64)(params["task_id"].(float64))
+	repository := params["repository"].(string)
+	dryRun, _ := params["dry_run"].(bool)
+	rules := []*models.RetentionRule{}
+	if err := json.Unmarshal([]byte(params["rules"].(string)), &rules); err != nil {
+		return err
+	}
+
+	candidates, err := r.listCandidates(repository)
+	if err != nil {
+		r.logger.Errorf("failed to list the tags of repository %s: %v", repository, err)
+		return err
+	}
+	retained, deleted, err := retention.Evaluate(repository, candidates, rules, time.Now())
+	if err != nil {
+		r.logger.Errorf("failed to evaluate the retention rules: %v", err)
+		return err
+	}
+	retained, deleted, err = r.retainImmutable(repository, retained, deleted)
+	if err != nil {
+		r.logger.Errorf("failed to apply the immutable tag rules: %v", err)
+		return err
+	}
+	r.logger.Infof("%d of %d tags of repository %s are retained", len(retained), len(candidates), repository)
+
+	task := &models.RetentionTask{
+		ID:       taskID,
+		Total:    len(candidates),
+		Retained: len(retained),
+		Deleted:  []string{},
+	}
+	var failed []string
+	for _, candidate := range deleted {
+		if dryRun {
+			r.logger.Infof("[dry run] the tag %s:%s would be deleted", repository, candidate.Tag)
+			task.Deleted = append(task.Deleted, candidate.Tag)
+			continue
+		}
+		if cmd, exist := ctx.OPCommand(); exist && cmd == job.StopCommand {
+			r.logger.Info("the retention job is stopped")
+			break
+		}
+		if err = r.client.Delete(fmt.Sprintf("%s/api/repositories/%s/tags/%s", r.coreURL, repository, candidate.Tag)); err != nil {
+			// the tag has been deleted along with another one pointing to the same manifest
+			if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+				task.Deleted = append(task.Deleted, candidate.Tag)
+				continue
+			}
+			r.logger.Errorf("failed to delete the tag %s:%s: %v", repository, candidate.Tag, err)
+			failed = append(failed, candidate.Tag)
+			continue
+		}
+		r.logger.Infof("the tag %s:%s deleted", repository, candidate.Tag)
+		task.Deleted = append(task.Deleted, candidate.Tag)
+	}
+
+	if err = dao.UpdateRetentionTask(task, "Total", "Retained", "Deleted"); err != nil {
+		r.logger.Errorf("failed to update the result of retention task %d: %v", taskID, err)
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete the tags %s of repository %s", strings.Join(failed, ","), repository)
+	}
+	return nil
+}
+
+// retainImmutable moves the tags protected by the immutable tag rules of the project from the
+// deleted ones to the retained ones. As deleting a tag removes the manifest, the tags sharing the
+// digest with an immutable tag are retained too
+func (r *Retention) retainImmutable(repository string, retained, deleted []*retention.Candidate) (
+	[]*retention.Candidate, []*retention.Candidate, error) {
+	if len(deleted) == 0 {
+		return retained, deleted, nil
+	}
+	projectName := strings.SplitN(repository, "/", 2)[0]
+	project, err := dao.GetProjectByName(projectName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if project == nil {
+		return nil, nil, fmt.Errorf("project %s not found", projectName)
+	}
+	rules, err := dao.ListImmutableTagRules(project.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rules) == 0 {
+		return retained, deleted, nil
+	}
+
+	digests := map[string]struct{}{}
+	for _, candidate := range deleted {
+		rule, err := immutable.Match(rules, repository, candidate.Tag)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rule != nil {
+			r.logger.Infof("the tag %s:%s is retained as it matches the immutable tag rule %d",
+				repository, candidate.Tag, rule.ID)
+			digests[candidate.Digest] = struct{}{}
+		}
+	}
+	var remaining []*retention.Candidate
+	for _, candidate := range deleted {
+		if _, exist := digests[candidate.Digest]; exist {
+			retained = append(retained, candidate)
+			continue
+		}
+		remaining = append(remaining, candidate)
+	}
+	return retained, remaining, nil
+}
+
+func (r *Retention) init(ctx job.Context) error {
+	v, ok := ctx.Get(common.CoreURL)
+	if !ok || len(v.(string)) == 0 {
+		return fmt.Errorf("failed to get required property: %s", common.CoreURL)
+	}
+	r.coreURL = strings.TrimSuffix(v.(string), "/")
+	secret := os.Getenv("JOBSERVICE_SECRET")
+	if len(secret) == 0 {
+		return errors.New("failed to read evnironment variable JOBSERVICE_SECRET")
+	}
+	r.client = common_http.NewClient(&http.Client{
+		Transport: reg.GetHTTPTransport(true),
+	}, auth.NewSecretAuthorizer(secret))
+	return nil
+}
+
+// list the tags of the repository with the push time, pull time and labels
+func (r *Retention) listCandidates(repository string) ([]*retention.Candidate, error) {
+	tags := []*tag{}
+	if err := r.client.Get(fmt.Sprintf("%s/api/repositories/%s/tags", r.coreURL, repository), &tags); err != nil {
+		return nil, err
+	}
+	candidates := []*retention.Candidate{}
+	for _, t := range tags {
+		candidate := &retention.Candidate{
+			Tag:      t.Name,
+			Digest:   t.Digest,
+			PushTime: t.Created,
+			Labels:   []string{},
+		}
+		if t.PushTime != nil {
+			candidate.PushTime = *t.PushTime
+		}
+		pullTime, err := dao.GetLastPullTime(repository, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		candidate.PullTime = pullTime
+		for _, label := range t.Labels {
+			candidate.Labels = append(candidate.Labels, label.Name)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
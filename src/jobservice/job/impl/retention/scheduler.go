@@ -0,0 +1,71 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/http/modifier/auth"
+	"github.com/goharbor/harbor/src/common/models"
+	reg "github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/goharbor/harbor/src/jobservice/job"
+)
+
+// Scheduler is a periodic job running in Jobservice which triggers the
+// execution of the retention policy of the project via the API of core
+type Scheduler struct{}
+
+// ShouldRetry ...
+func (s *Scheduler) ShouldRetry() bool {
+	return false
+}
+
+// MaxFails ...
+func (s *Scheduler) MaxFails() uint {
+	return 0
+}
+
+// Validate ...
+func (s *Scheduler) Validate(params job.Parameters) error {
+	return nil
+}
+
+// Run ...
+func (s *Scheduler) Run(ctx job.Context, params job.Parameters) error {
+	cmd, exist := ctx.OPCommand()
+	if exist && cmd == job.StopCommand {
+		return nil
+	}
+	logger := ctx.GetLogger()
+
+	projectID := (int64)(params["project_id"].(float64))
+	url := fmt.Sprintf("%s/api/projects/%d/retention/executions?trigger=%s",
+		params["url"].(string), projectID, models.RetentionTriggerSchedule)
+	cred := auth.NewSecretAuthorizer(os.Getenv("JOBSERVICE_SECRET"))
+	client := common_http.NewClient(&http.Client{
+		Transport: reg.GetHTTPTransport(true),
+	}, cred)
+	if err := client.Post(url, struct {
+		DryRun bool `json:"dry_run"`
+	}{}); err != nil {
+		logger.Errorf("failed to run the retention schedule job: %v", err)
+		return err
+	}
+	logger.Info("the retention schedule job finished")
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	reg "github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+)
+
+// the headers set on the requests delivering the events
+const (
+	headerEvent     = "X-Harbor-Event"
+	headerDelivery  = "X-Harbor-Delivery"
+	headerSignature = "X-Harbor-Signature"
+)
+
+const (
+	// the delivery is given up after the attempts
+	maxAttempts = 5
+	timeout     = 30 * time.Second
+)
+
+// Webhook posts the payload of the event to the webhook endpoint. The payload is signed with
+// HMAC-SHA256 if the secret is set. Each run makes one attempt and records it in the delivery,
+// the failed attempts are retried by jobservice with backoff until the endpoint responds with 2xx
+type Webhook struct {
+	logger logger.Interface
+}
+
+// ShouldRetry ...
+func (w *Webhook) ShouldRetry() bool {
+	return true
+}
+
+// MaxFails ...
+func (w *Webhook) MaxFails() uint {
+	return maxAttempts
+}
+
+// Validate ...
+func (w *Webhook) Validate(params job.Parameters) error {
+	if _, ok := params["delivery_id"].(float64); !ok {
+		return errors.New("missing parameter delivery_id")
+	}
+	if address, ok := params["address"].(string); !ok || len(address) == 0 {
+		return errors.New("missing parameter address")
+	}
+	if _, ok := params["payload"].(string); !ok {
+		return errors.New("missing parameter payload")
+	}
+	return nil
+}
+
+// Run ...
+func (w *Webhook) Run(ctx job.Context, params job.Parameters) error {
+	w.logger = ctx.GetLogger()
+
+	deliveryID := (int64)(params["delivery_id"].(float64))
+	address := params["address"].(string)
+	payload := params["payload"].(string)
+	eventType, _ := params["event_type"].(string)
+	secret, _ := params["secret"].(string)
+	skipCertVerify, _ := params["skip_cert_verify"].(bool)
+
+	client := &http.Client{
+		Transport: reg.GetHTTPTransport(skipCertVerify),
+		Timeout:   timeout,
+	}
+	code, err := post(client, address, eventType, deliveryID, secret, []byte(payload))
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	if e := dao.RecordWebhookDeliveryAttempt(deliveryID, code, errMsg); e != nil {
+		w.logger.Errorf("failed to record the attempt of webhook delivery %d: %v", deliveryID, e)
+	}
+	if err != nil {
+		w.logger.Errorf("failed to deliver the event %s to %s: %v", eventType, address, err)
+		return err
+	}
+	w.logger.Infof("the event %s delivered to %s, status code: %d", eventType, address, code)
+	return nil
+}
+
+// post the payload to the address, an error is returned if the response isn't 2xx
+func post(client *http.Client, address, eventType string, deliveryID int64, secret string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerEvent, eventType)
+	req.Header.Set(headerDelivery, strconv.FormatInt(deliveryID, 10))
+	if len(secret) > 0 {
+		req.Header.Set(headerSignature, sign(secret, payload))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		io.Copy(ioutil.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	// keep the beginning of the response body to help the troubleshooting
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return resp.StatusCode, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+}
+
+// sign returns the HMAC-SHA256 of the payload in the format "sha256=<hex digest>", so the
+// receiver can verify the payload with the shared secret
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
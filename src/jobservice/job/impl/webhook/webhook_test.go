@@ -0,0 +1,67 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=b253c513ae9d9e8cb76feea9055ec8d8ac98d3d76e6f7e263cec1ac67485bb8e", sign("secret", []byte(`{"type":"pushImage"}`)))
+}
+
+func TestPost(t *testing.T) {
+	var (
+		signature string
+		event     string
+		body      []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(headerSignature)
+		event = r.Header.Get(headerEvent)
+		body, _ = ioutil.ReadAll(r.Body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("internal error"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := []byte(`{"type":"pushImage"}`)
+	code, err := post(http.DefaultClient, server.URL+"/hook", "pushImage", 1, "secret", payload)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, sign("secret", payload), signature)
+	assert.Equal(t, "pushImage", event)
+	assert.Equal(t, payload, body)
+
+	// no signature without secret
+	_, err = post(http.DefaultClient, server.URL+"/hook", "pushImage", 1, "", payload)
+	require.Nil(t, err)
+	assert.Equal(t, "", signature)
+
+	code, err = post(http.DefaultClient, server.URL+"/fail", "pushImage", 1, "secret", payload)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Contains(t, err.Error(), "internal error")
+}
@@ -30,4 +30,10 @@ const (
 	Replication = "REPLICATION"
 	// ReplicationScheduler : the name of the replication scheduler job in job service
 	ReplicationScheduler = "IMAGE_REPLICATE"
+	// Retention : the name of the tag retention job in job service
+	Retention = "RETENTION"
+	// RetentionScheduler : the name of the tag retention scheduler job in job service
+	RetentionScheduler = "RETENTION_SCHEDULER"
+	// Webhook : the name of the job delivering the webhook events in job service
+	Webhook = "WEBHOOK"
 )
@@ -20,7 +20,9 @@ import (
 
 	"fmt"
 	"github.com/gocraft/work"
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/jobservice/lcm"
 	"github.com/goharbor/harbor/src/jobservice/logger"
@@ -28,6 +30,9 @@ import (
 	"time"
 )
 
+// maxRequeueAge is the max seconds the job can be requeued since it's enqueued
+const maxRequeueAge = 2 * 24 * 3600
+
 // RedisJob is a job wrapper to wrap the job.Interface to the style which can be recognized by the redis worker.
 type RedisJob struct {
 	job     interface{}    // the real job implementation
@@ -51,6 +56,7 @@ func (rj *RedisJob) Run(j *work.Job) (err error) {
 		execContext job.Context
 		tracker     job.Tracker
 		markStopped = bp(false)
+		retrying    = bp(false)
 	)
 
 	// Defer to log the exit result
@@ -97,6 +103,18 @@ func (rj *RedisJob) Run(j *work.Job) (err error) {
 		// Switch job status based on the returned error.
 		// The err happened here should not override the job run error, just log it.
 		if err != nil {
+			if *retrying {
+				// Error is a final status, the retried run would be taken as a stopped one.
+				// Switch back to pending until the worker picks up the job again.
+				logger.Infof("Job %s:%s will be retried", j.Name, j.ID)
+				if er := tracker.Update("status", job.PendingStatus.String()); er != nil {
+					logger.Errorf("Mark job status to pending for retry error: %s", er)
+				}
+
+				return
+			}
+
+			metrics.JobsTotal.WithLabelValues(j.Name, string(job.ErrorStatus)).Inc()
 			if er := tracker.Fail(); er != nil {
 				logger.Errorf("Mark job status to failure error: %s", err)
 			}
@@ -112,10 +130,12 @@ func (rj *RedisJob) Run(j *work.Job) (err error) {
 				logger.Infof("Job %s:%s is stopped", tracker.Job().Info.JobName, tracker.Job().Info.JobID)
 				// Stopped job, no exit message printing.
 				markStopped = bp(true)
+				metrics.JobsTotal.WithLabelValues(j.Name, string(job.StoppedStatus)).Inc()
 				return
 			}
 		}
 
+		metrics.JobsTotal.WithLabelValues(j.Name, string(job.SuccessStatus)).Inc()
 		// Mark job status to success.
 		if er := tracker.Succeed(); er != nil {
 			logger.Errorf("Mark job status to success error: %s", er)
@@ -158,9 +178,18 @@ func (rj *RedisJob) Run(j *work.Job) (err error) {
 		return
 	}
 	// Run the job
+	start := time.Now()
 	err = runningJob.Run(execContext, j.Args)
+	metrics.JobDuration.WithLabelValues(j.Name).Observe(time.Since(start).Seconds())
+	// Give up requeuing the job which has waited for too long
+	if errs.IsRequeueError(err) && time.Now().Unix()-j.EnqueuedAt >= maxRequeueAge {
+		err = errors.Errorf("job has been requeued for more than %d hours, give up: %s", maxRequeueAge/3600, err)
+		execContext.GetLogger().Error(err)
+		// Make it big enough to avoid retrying
+		j.Fails = 10000000000
+	}
 	// Handle retry
-	rj.retry(runningJob, j)
+	retrying = bp(rj.retry(runningJob, j, err))
 	// Handle periodic job execution
 	if isPeriodicJobExecution(j) {
 		if er := tracker.PeriodicExecutionDone(); er != nil {
@@ -172,13 +201,29 @@ func (rj *RedisJob) Run(j *work.Job) (err error) {
 	return
 }
 
-func (rj *RedisJob) retry(j job.Interface, wj *work.Job) {
+// retry returns true if the failed job will be retried by the worker.
+// A run that will be retried is switched back to pending rather than error, as error
+// is a final status and the next run would exit at once. Only the job kinds whose
+// ShouldRetry returns true are affected: a replication job is now rerun up to its
+// MaxFails (3) and only the last failure is reported as error, and a webhook job is
+// rerun up to its MaxFails. The job kinds that don't retry, e.g. scan, GC and
+// retention, still end as error on the first failure.
+func (rj *RedisJob) retry(j job.Interface, wj *work.Job, err error) bool {
+	// The job asks to run later, put it back to the queue without consuming a fail
+	if errs.IsRequeueError(err) {
+		wj.Fails--
+		return true
+	}
+
 	if !j.ShouldRetry() {
 		// Cancel retry immediately
 		// Make it big enough to avoid retrying
 		wj.Fails = 10000000000
-		return
+		return false
 	}
+
+	// The worker counts the failure after the job returns
+	return err != nil && wj.Fails+1 < int64(j.MaxFails())
 }
 
 func isPeriodicJobExecution(j *work.Job) bool {
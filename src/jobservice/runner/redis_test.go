@@ -15,6 +15,7 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"sync"
@@ -30,6 +31,7 @@ import (
 	"github.com/goharbor/harbor/src/jobservice/tests"
 
 	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/lcm"
 	"github.com/gomodule/redigo/redis"
 	"github.com/stretchr/testify/require"
@@ -188,6 +190,75 @@ func (suite *RedisRunnerTestSuite) TestJobWrapperStopped() {
 	require.NoError(suite.T(), err)
 }
 
+// TestJobWrapperRetry tests the failed job which will be retried
+func (suite *RedisRunnerTestSuite) TestJobWrapperRetry() {
+	j := &work.Job{
+		ID:         "FAKE-j",
+		Name:       "fakeRetryJob",
+		EnqueuedAt: time.Now().Add(5 * time.Minute).Unix(),
+	}
+
+	redisJob := NewRedisJob((*fakeRetryJob)(nil), suite.envContext, suite.lcmCtl)
+	err := redisJob.Run(j)
+	require.Error(suite.T(), err)
+
+	t, err := suite.lcmCtl.Track("FAKE-j")
+	require.NoError(suite.T(), err)
+	status, err := t.Status()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), job.PendingStatus, status)
+
+	// the last attempt
+	j.Fails = 1
+	err = redisJob.Run(j)
+	require.Error(suite.T(), err)
+	status, err = t.Status()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), job.ErrorStatus, status)
+}
+
+// TestJobWrapperRequeue tests the job which asks to be requeued
+func (suite *RedisRunnerTestSuite) TestJobWrapperRequeue() {
+	j := &work.Job{
+		ID:         "FAKE-j",
+		Name:       "fakeRequeueJob",
+		EnqueuedAt: time.Now().Add(5 * time.Minute).Unix(),
+	}
+
+	redisJob := NewRedisJob((*fakeRequeueJob)(nil), suite.envContext, suite.lcmCtl)
+	err := redisJob.Run(j)
+	require.Error(suite.T(), err)
+	// the worker increases it after the job returns
+	assert.Equal(suite.T(), int64(-1), j.Fails)
+
+	t, err := suite.lcmCtl.Track("FAKE-j")
+	require.NoError(suite.T(), err)
+	status, err := t.Status()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), job.PendingStatus, status)
+}
+
+// TestJobWrapperRequeueTimeout tests the job which has been requeued for too long
+func (suite *RedisRunnerTestSuite) TestJobWrapperRequeueTimeout() {
+	j := &work.Job{
+		ID:         "FAKE-j",
+		Name:       "fakeRequeueJob",
+		EnqueuedAt: time.Now().Add(-3 * 24 * time.Hour).Unix(),
+	}
+
+	redisJob := NewRedisJob((*fakeRequeueJob)(nil), suite.envContext, suite.lcmCtl)
+	err := redisJob.Run(j)
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "give up")
+	assert.True(suite.T(), j.Fails > 0)
+
+	t, err := suite.lcmCtl.Track("FAKE-j")
+	require.NoError(suite.T(), err)
+	status, err := t.Status()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), job.ErrorStatus, status)
+}
+
 type fakeParentJob struct {
 }
 
@@ -227,3 +298,41 @@ func (j *fakePanicJob) Validate(params job.Parameters) error {
 func (j *fakePanicJob) Run(ctx job.Context, params job.Parameters) error {
 	panic("for testing")
 }
+
+type fakeRetryJob struct {
+}
+
+func (j *fakeRetryJob) MaxFails() uint {
+	return 2
+}
+
+func (j *fakeRetryJob) ShouldRetry() bool {
+	return true
+}
+
+func (j *fakeRetryJob) Validate(params job.Parameters) error {
+	return nil
+}
+
+func (j *fakeRetryJob) Run(ctx job.Context, params job.Parameters) error {
+	return errors.New("for testing")
+}
+
+type fakeRequeueJob struct {
+}
+
+func (j *fakeRequeueJob) MaxFails() uint {
+	return 1
+}
+
+func (j *fakeRequeueJob) ShouldRetry() bool {
+	return false
+}
+
+func (j *fakeRequeueJob) Validate(params job.Parameters) error {
+	return nil
+}
+
+func (j *fakeRequeueJob) Run(ctx job.Context, params job.Parameters) error {
+	return errs.RequeueError("for testing")
+}
@@ -25,6 +25,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/jobservice/api"
 	"github.com/goharbor/harbor/src/jobservice/common/utils"
 	"github.com/goharbor/harbor/src/jobservice/config"
@@ -34,8 +35,10 @@ import (
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/jobservice/job/impl/gc"
 	"github.com/goharbor/harbor/src/jobservice/job/impl/replication"
+	"github.com/goharbor/harbor/src/jobservice/job/impl/retention"
 	"github.com/goharbor/harbor/src/jobservice/job/impl/sample"
 	"github.com/goharbor/harbor/src/jobservice/job/impl/scan"
+	"github.com/goharbor/harbor/src/jobservice/job/impl/webhook"
 	"github.com/goharbor/harbor/src/jobservice/lcm"
 	"github.com/goharbor/harbor/src/jobservice/logger"
 	"github.com/goharbor/harbor/src/jobservice/worker"
@@ -106,6 +109,13 @@ func (bs *Bootstrap) LoadAndRun(ctx context.Context, cancel context.CancelFunc)
 			logger.Error(err)
 		}
 
+		// Expose the statistics of the redis pool, job queues and database connections
+		metrics.Register(
+			metrics.NewRedisPoolCollector("jobservice", redisPool),
+			cworker.NewQueueCollector(namespace, redisPool),
+			metrics.NewDBCollector(),
+		)
+
 		// Create stats manager
 		manager = mgt.NewManager(ctx, namespace, redisPool)
 		// Create hook agent, it's a singleton object
@@ -243,6 +253,9 @@ func (bs *Bootstrap) loadAndRunRedisWorkerPool(
 			job.ImageGC:              (*gc.GarbageCollector)(nil),
 			job.Replication:          (*replication.Replication)(nil),
 			job.ReplicationScheduler: (*replication.Scheduler)(nil),
+			job.Retention:            (*retention.Retention)(nil),
+			job.RetentionScheduler:   (*retention.Scheduler)(nil),
+			job.Webhook:              (*webhook.Webhook)(nil),
 		}); err != nil {
 		// exit
 		return nil, err
@@ -0,0 +1,61 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cworker
+
+import (
+	"github.com/gocraft/work"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/gomodule/redigo/redis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queueDepthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("harbor", "jobservice", "queue_depth"),
+		"The number of the jobs waiting in the queue by job name.",
+		[]string{"job_name"}, nil)
+	queueLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName("harbor", "jobservice", "queue_latency_seconds"),
+		"The time the oldest job in the queue has been waiting by job name.",
+		[]string{"job_name"}, nil)
+)
+
+// NewQueueCollector returns a collector exposing the depth and latency of the job queues in the namespace
+func NewQueueCollector(namespace string, redisPool *redis.Pool) prometheus.Collector {
+	return &queueCollector{
+		client: work.NewClient(namespace, redisPool),
+	}
+}
+
+type queueCollector struct {
+	client *work.Client
+}
+
+func (q *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+	ch <- queueLatencyDesc
+}
+
+func (q *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	queues, err := q.client.Queues()
+	if err != nil {
+		logger.Errorf("Failed to get the job queues for metrics: %s", err)
+		return
+	}
+	for _, queue := range queues {
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(queue.Count), queue.JobName)
+		ch <- prometheus.MustNewConstMetric(queueLatencyDesc, prometheus.GaugeValue, float64(queue.Latency), queue.JobName)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package immutable
+
+import (
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/replication/util"
+)
+
+// ImmutableError is returned when a tag protected by the immutable tag rule is going to be
+// overwritten or deleted
+type ImmutableError struct {
+	Repository string
+	Tag        string
+	Rule       *models.ImmutableTagRule
+}
+
+func (e *ImmutableError) Error() string {
+	return fmt.Sprintf("the tag %s:%s is immutable as it matches the immutable tag rule %d (repositories: %q, tags: %q)",
+		e.Repository, e.Tag, e.Rule.ID, e.Rule.Repositories, e.Rule.Tags)
+}
+
+// Validate checks whether the patterns of the rule are valid
+func Validate(rule *models.ImmutableTagRule) error {
+	for _, pattern := range []string{rule.Repositories, rule.Tags} {
+		// the syntax errors are only reported when the malformed part is reached,
+		// so match the pattern against itself
+		if _, err := util.Match(pattern, pattern); err != nil {
+			return fmt.Errorf("invalid pattern %s: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule which the tag of the repository matches,
+// nil is returned if no rule matches
+func Match(rules []*models.ImmutableTagRule, repository, tag string) (*models.ImmutableTagRule, error) {
+	for _, rule := range rules {
+		match, err := util.Match(rule.Repositories, repository)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+		match, err = util.Match(rule.Tags, tag)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// Check checks the tags of the repository against the immutable tag rules of the project,
+// an ImmutableError is returned if any tag is immutable
+func Check(projectID int64, repository string, tags ...string) error {
+	rules, err := dao.ListImmutableTagRules(projectID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	for _, tag := range tags {
+		rule, err := Match(rules, repository, tag)
+		if err != nil {
+			return err
+		}
+		if rule != nil {
+			return &ImmutableError{
+				Repository: repository,
+				Tag:        tag,
+				Rule:       rule,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package immutable
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate(&models.ImmutableTagRule{}))
+	assert.Nil(t, Validate(&models.ImmutableTagRule{Repositories: "library/**", Tags: "v*"}))
+	assert.NotNil(t, Validate(&models.ImmutableTagRule{Repositories: "library/[", Tags: "v*"}))
+	assert.NotNil(t, Validate(&models.ImmutableTagRule{Tags: "v["}))
+}
+
+func TestMatch(t *testing.T) {
+	rules := []*models.ImmutableTagRule{
+		{ID: 1, Repositories: "library/ubuntu", Tags: "**"},
+		{ID: 2, Repositories: "library/**", Tags: "v[0-9]*"},
+	}
+
+	rule, err := Match(rules, "library/hello-world", "latest")
+	require.Nil(t, err)
+	assert.Nil(t, rule)
+
+	rule, err = Match(rules, "library/ubuntu", "latest")
+	require.Nil(t, err)
+	require.NotNil(t, rule)
+	assert.Equal(t, int64(1), rule.ID)
+
+	rule, err = Match(rules, "library/hello-world", "v1.2.3")
+	require.Nil(t, err)
+	require.NotNil(t, rule)
+	assert.Equal(t, int64(2), rule.ID)
+}
+
+func TestImmutableError(t *testing.T) {
+	err := &ImmutableError{
+		Repository: "library/hello-world",
+		Tag:        "v1.2.3",
+		Rule: &models.ImmutableTagRule{
+			ID:           2,
+			Repositories: "library/**",
+			Tags:         "v*",
+		},
+	}
+	assert.Equal(t, `the tag library/hello-world:v1.2.3 is immutable as it matches the immutable tag rule 2 (repositories: "library/**", tags: "v*")`,
+		err.Error())
+}
@@ -0,0 +1,187 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+// const definition
+const (
+	ResourceStorage = "storage"
+	ResourceCount   = "count"
+)
+
+// Manager defines the interface of quota manager, the projects use the default quota unless they override it
+type Manager interface {
+	// Get gets the quota of the project, the default one is returned if the project doesn't override it
+	Get(projectID int64) (*models.Quota, error)
+	// Set overrides the quota of the project
+	Set(quota *models.Quota) error
+	// Reset removes the quota overridden by the project, the default one is used after that
+	Reset(projectID int64) error
+	// GetUsage gets the storage and tag count used by the project
+	GetUsage(projectID int64) (*models.QuotaUsage, error)
+	// Reserve checks the storage and tag count requested by a push against the quota of the project and
+	// reserves them atomically, the warnings are returned if any soft limit is exceeded. The reservation
+	// is counted in the usage until the returned function is called, which should be done after the
+	// pushed resources are recorded or the push fails
+	Reserve(projectID int64, storage, count int64) (func(), []string, error)
+}
+
+type defaultManager struct {
+	defaults func() *models.Quota
+}
+
+// Get gets the quota of the project, the default one is returned if the project doesn't override it
+func (d *defaultManager) Get(projectID int64) (*models.Quota, error) {
+	quota, err := dao.GetQuota(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if quota != nil {
+		return quota, nil
+	}
+	quota = d.defaults()
+	quota.ProjectID = projectID
+	return quota, nil
+}
+
+// Set overrides the quota of the project
+func (d *defaultManager) Set(quota *models.Quota) error {
+	if err := Validate(quota); err != nil {
+		return err
+	}
+	return dao.SetQuota(quota)
+}
+
+// Reset removes the quota overridden by the project
+func (d *defaultManager) Reset(projectID int64) error {
+	return dao.DeleteQuota(projectID)
+}
+
+// GetUsage gets the storage and tag count used by the project
+func (d *defaultManager) GetUsage(projectID int64) (*models.QuotaUsage, error) {
+	return dao.GetQuotaUsage(projectID)
+}
+
+// Reserve checks the storage and tag count requested against the quota of the project and reserves them atomically
+func (d *defaultManager) Reserve(projectID int64, storage, count int64) (func(), []string, error) {
+	quota, err := d.Get(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings []string
+	id, err := dao.ReserveQuota(projectID, storage, count, func(usage *models.QuotaUsage) error {
+		var e error
+		warnings, e = Check(quota, usage, storage, count)
+		return e
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() {
+		if err := dao.DeleteQuotaReservation(id); err != nil {
+			log.Errorf("failed to delete the quota reservation %d of project %d: %v", id, projectID, err)
+		}
+	}
+	return release, warnings, nil
+}
+
+// NewDefaultManager return a new instance of defaultManager, the "defaults" returns
+// the quota used by the projects which don't override it
+func NewDefaultManager(defaults func() *models.Quota) Manager {
+	return &defaultManager{
+		defaults: defaults,
+	}
+}
+
+// Validate checks whether the limits of the quota are valid
+func Validate(quota *models.Quota) error {
+	if quota == nil {
+		return errors.New("the quota cannot be null")
+	}
+	for _, limits := range []struct {
+		resource string
+		hard     int64
+		soft     int64
+	}{
+		{ResourceStorage, quota.StorageHard, quota.StorageSoft},
+		{ResourceCount, quota.CountHard, quota.CountSoft},
+	} {
+		if limits.hard < models.QuotaUnlimited || limits.soft < models.QuotaUnlimited {
+			return fmt.Errorf("the %s limits must be -1 or non-negative", limits.resource)
+		}
+		if limits.hard >= 0 && limits.soft > limits.hard {
+			return fmt.Errorf("the soft %s limit cannot be greater than the hard one", limits.resource)
+		}
+	}
+	return nil
+}
+
+// ExceededError is returned when the hard limit of the quota is exceeded
+type ExceededError struct {
+	Resource  string
+	Limit     int64
+	Used      int64
+	Requested int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("the %s quota of the project is exceeded: limit %d, used %d, requested %d",
+		e.Resource, e.Limit, e.Used, e.Requested)
+}
+
+// Check checks whether the storage and count requested can be added to the usage of the
+// project. An ExceededError is returned if any hard limit is exceeded and the warnings
+// are returned if any soft limit is exceeded
+func Check(quota *models.Quota, usage *models.QuotaUsage, storage, count int64) ([]string, error) {
+	var warnings []string
+	for _, r := range []struct {
+		resource  string
+		hard      int64
+		soft      int64
+		used      int64
+		requested int64
+	}{
+		{ResourceStorage, quota.StorageHard, quota.StorageSoft, usage.Storage, storage},
+		{ResourceCount, quota.CountHard, quota.CountSoft, usage.Count, count},
+	} {
+		// nothing is added, the push never fails even the usage has exceeded the
+		// limits, e.g. the limits are lowered after the resources are pushed
+		if r.requested <= 0 {
+			continue
+		}
+		total := r.used + r.requested
+		if r.hard >= 0 && total > r.hard {
+			return nil, &ExceededError{
+				Resource:  r.resource,
+				Limit:     r.hard,
+				Used:      r.used,
+				Requested: r.requested,
+			}
+		}
+		if r.soft >= 0 && total > r.soft {
+			warnings = append(warnings, fmt.Sprintf("the soft %s limit %d of the project is exceeded: used %d",
+				r.resource, r.soft, total))
+		}
+	}
+	return warnings, nil
+}
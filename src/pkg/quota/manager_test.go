@@ -0,0 +1,88 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		quota *models.Quota
+		valid bool
+	}{
+		{nil, false},
+		{&models.Quota{StorageHard: -1, StorageSoft: -1, CountHard: -1, CountSoft: -1}, true},
+		{&models.Quota{StorageHard: 100, StorageSoft: 80, CountHard: 10, CountSoft: -1}, true},
+		{&models.Quota{StorageHard: -2, StorageSoft: -1, CountHard: -1, CountSoft: -1}, false},
+		{&models.Quota{StorageHard: -1, StorageSoft: -1, CountHard: -1, CountSoft: -5}, false},
+		{&models.Quota{StorageHard: 100, StorageSoft: 120, CountHard: -1, CountSoft: -1}, false},
+		{&models.Quota{StorageHard: -1, StorageSoft: 120, CountHard: 10, CountSoft: 11}, false},
+	}
+	for _, c := range cases {
+		err := Validate(c.quota)
+		assert.Equal(t, c.valid, err == nil)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	quota := &models.Quota{
+		StorageHard: 100,
+		StorageSoft: 80,
+		CountHard:   10,
+		CountSoft:   -1,
+	}
+	usage := &models.QuotaUsage{
+		Storage: 70,
+		Count:   10,
+	}
+
+	// nothing requested
+	warnings, err := Check(quota, usage, 0, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(warnings))
+
+	// the soft limit of storage is exceeded
+	warnings, err = Check(quota, usage, 20, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(warnings))
+
+	// the hard limit of storage is exceeded
+	_, err = Check(quota, usage, 40, 0)
+	require.NotNil(t, err)
+	e, ok := err.(*ExceededError)
+	require.True(t, ok)
+	assert.Equal(t, ResourceStorage, e.Resource)
+	assert.Equal(t, int64(100), e.Limit)
+	assert.Equal(t, int64(70), e.Used)
+	assert.Equal(t, int64(40), e.Requested)
+
+	// the hard limit of count is exceeded
+	_, err = Check(quota, usage, 10, 1)
+	require.NotNil(t, err)
+	e, ok = err.(*ExceededError)
+	require.True(t, ok)
+	assert.Equal(t, ResourceCount, e.Resource)
+
+	// unlimited
+	warnings, err = Check(&models.Quota{StorageHard: -1, StorageSoft: -1, CountHard: -1, CountSoft: -1},
+		usage, 1000, 1000)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(warnings))
+}
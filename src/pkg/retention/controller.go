@@ -0,0 +1,254 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	commonhttp "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/job"
+	jobmodels "github.com/goharbor/harbor/src/common/job/models"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	jsjob "github.com/goharbor/harbor/src/jobservice/job"
+)
+
+// Ctl is the global retention controller
+var Ctl Controller
+
+// Init initializes the global retention controller
+func Init(js job.Client, coreURL string) {
+	Ctl = NewController(js, coreURL)
+}
+
+// Controller manages the retention policies of projects and runs them
+type Controller interface {
+	// GetPolicy returns the retention policy of the project, nil is returned if the project has no policy
+	GetPolicy(projectID int64) (*models.RetentionPolicy, error)
+	// SetPolicy creates or updates the retention policy of the project, and schedules it if the cron is set
+	SetPolicy(policy *models.RetentionPolicy) error
+	// DeletePolicy unschedules and deletes the retention policy of the project
+	DeletePolicy(projectID int64) error
+	// Execute runs the retention policy of the project and returns the ID of the execution.
+	// The tags to delete are only reported without deleting if it is a dry run
+	Execute(projectID int64, trigger string, dryRun bool) (int64, error)
+	ListExecutions(projectID int64, pagination *models.Pagination) (int64, []*models.RetentionExecution, error)
+	GetExecution(id int64) (*models.RetentionExecution, error)
+	ListTasks(executionID int64) ([]*models.RetentionTask, error)
+	GetTask(id int64) (*models.RetentionTask, error)
+	GetTaskLog(id int64) ([]byte, error)
+	// UpdateTaskStatus is called when the status of the job of the task changes
+	UpdateTaskStatus(id int64, status string) error
+}
+
+// NewController returns an instance of the default controller
+func NewController(js job.Client, coreURL string) Controller {
+	return &controller{
+		jobservice: js,
+		coreURL:    coreURL,
+	}
+}
+
+type controller struct {
+	jobservice job.Client
+	coreURL    string
+}
+
+func (c *controller) GetPolicy(projectID int64) (*models.RetentionPolicy, error) {
+	return dao.GetRetentionPolicy(projectID)
+}
+
+func (c *controller) SetPolicy(policy *models.RetentionPolicy) error {
+	original, err := dao.GetRetentionPolicy(policy.ProjectID)
+	if err != nil {
+		return err
+	}
+	if original != nil {
+		policy.ID = original.ID
+		policy.ScheduleJobID = original.ScheduleJobID
+		if original.Cron != policy.Cron {
+			if err = c.unschedule(original); err != nil {
+				return err
+			}
+			policy.ScheduleJobID = ""
+		}
+	}
+	if len(policy.Cron) > 0 && len(policy.ScheduleJobID) == 0 {
+		if policy.ScheduleJobID, err = c.schedule(policy); err != nil {
+			return err
+		}
+	}
+	return dao.SetRetentionPolicy(policy)
+}
+
+func (c *controller) DeletePolicy(projectID int64) error {
+	policy, err := dao.GetRetentionPolicy(projectID)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return nil
+	}
+	if err = c.unschedule(policy); err != nil {
+		return err
+	}
+	return dao.DeleteRetentionPolicy(projectID)
+}
+
+// the retention scheduler job in jobservice triggers the execution of the policy periodically
+func (c *controller) schedule(policy *models.RetentionPolicy) (string, error) {
+	jobID, err := c.jobservice.SubmitJob(&jobmodels.JobData{
+		Name: jsjob.RetentionScheduler,
+		Parameters: map[string]interface{}{
+			"url":        c.coreURL,
+			"project_id": policy.ProjectID,
+		},
+		Metadata: &jobmodels.JobMetadata{
+			JobKind: job.JobKindPeriodic,
+			Cron:    policy.Cron,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	log.Debugf("the retention policy of project %d scheduled: %s", policy.ProjectID, jobID)
+	return jobID, nil
+}
+
+func (c *controller) unschedule(policy *models.RetentionPolicy) error {
+	if len(policy.ScheduleJobID) == 0 {
+		return nil
+	}
+	if err := c.jobservice.PostAction(policy.ScheduleJobID, job.JobActionStop); err != nil {
+		// the schedule job doesn't exist in jobservice, ignore it
+		if e, ok := err.(*commonhttp.Error); !ok || e.Code != http.StatusNotFound {
+			return err
+		}
+	}
+	log.Debugf("the retention policy of project %d unscheduled", policy.ProjectID)
+	return nil
+}
+
+func (c *controller) Execute(projectID int64, trigger string, dryRun bool) (int64, error) {
+	policy, err := dao.GetRetentionPolicy(projectID)
+	if err != nil {
+		return 0, err
+	}
+	if policy == nil {
+		return 0, fmt.Errorf("no retention policy found for project %d", projectID)
+	}
+	rules, err := json.Marshal(policy.Rules)
+	if err != nil {
+		return 0, err
+	}
+	repositories, err := dao.GetRepositories(&models.RepositoryQuery{
+		ProjectIDs: []int64{projectID},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := dao.AddRetentionExecution(&models.RetentionExecution{
+		ProjectID: projectID,
+		Trigger:   trigger,
+		DryRun:    dryRun,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// one task per repository, the failure of one task doesn't abort the others
+	for _, repository := range repositories {
+		task := &models.RetentionTask{
+			ExecutionID: id,
+			Repository:  repository.Name,
+			Status:      models.JobPending,
+		}
+		taskID, err := dao.AddRetentionTask(task)
+		if err != nil {
+			return 0, err
+		}
+		task.ID = taskID
+		task.JobID, err = c.jobservice.SubmitJob(&jobmodels.JobData{
+			Name: jsjob.Retention,
+			Parameters: map[string]interface{}{
+				"task_id":    taskID,
+				"repository": repository.Name,
+				"rules":      string(rules),
+				"dry_run":    dryRun,
+			},
+			Metadata: &jobmodels.JobMetadata{
+				JobKind: job.JobKindGeneric,
+			},
+			StatusHook: fmt.Sprintf("%s/service/notifications/jobs/retention/task/%d", c.coreURL, taskID),
+		})
+		if err != nil {
+			log.Errorf("failed to submit the retention job for repository %s: %v", repository.Name, err)
+			if e := dao.UpdateRetentionTaskStatus(taskID, models.JobError); e != nil {
+				log.Errorf("failed to update the status of retention task %d: %v", taskID, e)
+			}
+			continue
+		}
+		if err = dao.UpdateRetentionTask(task, "JobID"); err != nil {
+			log.Errorf("failed to update the job ID of retention task %d: %v", taskID, err)
+		}
+	}
+	return id, nil
+}
+
+func (c *controller) ListExecutions(projectID int64, pagination *models.Pagination) (int64, []*models.RetentionExecution, error) {
+	total, err := dao.GetTotalOfRetentionExecutions(projectID)
+	if err != nil {
+		return 0, nil, err
+	}
+	executions, err := dao.ListRetentionExecutions(projectID, pagination)
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, executions, nil
+}
+
+func (c *controller) GetExecution(id int64) (*models.RetentionExecution, error) {
+	return dao.GetRetentionExecution(id)
+}
+
+func (c *controller) ListTasks(executionID int64) ([]*models.RetentionTask, error) {
+	return dao.ListRetentionTasks(executionID)
+}
+
+func (c *controller) GetTask(id int64) (*models.RetentionTask, error) {
+	return dao.GetRetentionTask(id)
+}
+
+func (c *controller) GetTaskLog(id int64) ([]byte, error) {
+	task, err := dao.GetRetentionTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("retention task %d not found", id)
+	}
+	if len(task.JobID) == 0 {
+		return []byte{}, nil
+	}
+	return c.jobservice.GetJobLog(task.JobID)
+}
+
+func (c *controller) UpdateTaskStatus(id int64, status string) error {
+	return dao.UpdateRetentionTaskStatus(id, status)
+}
@@ -0,0 +1,171 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/replication/util"
+)
+
+// Candidate is a tag of the repository which the rules are evaluated against
+type Candidate struct {
+	Tag    string
+	Digest string
+	// the time when the tag was pushed last time, the creation time of the image
+	// is used if no push record found
+	PushTime time.Time
+	// the time when the tag was pulled last time, zero if never pulled
+	PullTime time.Time
+	Labels   []string
+}
+
+// ValidateRules checks whether the rules are valid
+func ValidateRules(rules []*models.RetentionRule) error {
+	if len(rules) == 0 {
+		return errors.New("at least one rule is required")
+	}
+	for i, rule := range rules {
+		if rule == nil {
+			return fmt.Errorf("rule %d: the rule cannot be null", i)
+		}
+		for _, pattern := range []string{rule.Repositories, rule.Tags} {
+			// the syntax errors are only reported when the malformed part is reached,
+			// so match the pattern against itself
+			if _, err := util.Match(pattern, pattern); err != nil {
+				return fmt.Errorf("rule %d: invalid pattern %s: %v", i, pattern, err)
+			}
+		}
+		switch rule.Template {
+		case models.RetentionTemplateLatestPushed:
+			if rule.Count <= 0 {
+				return fmt.Errorf("rule %d: the count must be greater than 0", i)
+			}
+		case models.RetentionTemplateRecentlyPulled:
+			if rule.Days <= 0 {
+				return fmt.Errorf("rule %d: the days must be greater than 0", i)
+			}
+		case models.RetentionTemplateLabeled:
+			if len(rule.Labels) == 0 {
+				return fmt.Errorf("rule %d: at least one label is required", i)
+			}
+		case models.RetentionTemplateAlways:
+		default:
+			return fmt.Errorf("rule %d: unsupported template %s", i, rule.Template)
+		}
+	}
+	return nil
+}
+
+// Evaluate applies the rules to the tags of the repository and returns the ones retained
+// and the ones to delete. A tag is retained if any rule retains it. All the tags are
+// retained if no rule applies to the repository. As deleting a tag deletes the manifest
+// it points to, the tags sharing the digest with any retained one are retained too
+func Evaluate(repository string, candidates []*Candidate, rules []*models.RetentionRule,
+	now time.Time) ([]*Candidate, []*Candidate, error) {
+	applied := false
+	retained := map[string]bool{}
+	for _, rule := range rules {
+		match, err := util.Match(rule.Repositories, repository)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !match {
+			continue
+		}
+		applied = true
+
+		matched := []*Candidate{}
+		for _, candidate := range candidates {
+			match, err := util.Match(rule.Tags, candidate.Tag)
+			if err != nil {
+				return nil, nil, err
+			}
+			if match {
+				matched = append(matched, candidate)
+			}
+		}
+		for _, candidate := range retain(rule, matched, now) {
+			retained[candidate.Tag] = true
+		}
+	}
+	if !applied {
+		return candidates, []*Candidate{}, nil
+	}
+
+	digests := map[string]bool{}
+	for _, candidate := range candidates {
+		if retained[candidate.Tag] && len(candidate.Digest) > 0 {
+			digests[candidate.Digest] = true
+		}
+	}
+	kept, deleted := []*Candidate{}, []*Candidate{}
+	for _, candidate := range candidates {
+		if retained[candidate.Tag] || digests[candidate.Digest] {
+			kept = append(kept, candidate)
+		} else {
+			deleted = append(deleted, candidate)
+		}
+	}
+	return kept, deleted, nil
+}
+
+// returns the candidates retained by the rule
+func retain(rule *models.RetentionRule, candidates []*Candidate, now time.Time) []*Candidate {
+	result := []*Candidate{}
+	switch rule.Template {
+	case models.RetentionTemplateLatestPushed:
+		sorted := make([]*Candidate, len(candidates))
+		copy(sorted, candidates)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].PushTime.After(sorted[j].PushTime)
+		})
+		if len(sorted) > rule.Count {
+			sorted = sorted[:rule.Count]
+		}
+		result = sorted
+	case models.RetentionTemplateRecentlyPulled:
+		since := now.AddDate(0, 0, -rule.Days)
+		for _, candidate := range candidates {
+			if candidate.PullTime.After(since) {
+				result = append(result, candidate)
+			}
+		}
+	case models.RetentionTemplateLabeled:
+		for _, candidate := range candidates {
+			if hasAnyLabel(candidate, rule.Labels) {
+				result = append(result, candidate)
+			}
+		}
+	case models.RetentionTemplateAlways:
+		result = candidates
+	}
+	return result
+}
+
+func hasAnyLabel(candidate *Candidate, labels []string) bool {
+	for _, label := range candidate.Labels {
+		for _, l := range labels {
+			if label == l {
+				return true
+			}
+		}
+	}
+	return false
+}
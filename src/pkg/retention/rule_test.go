@@ -0,0 +1,98 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRules(t *testing.T) {
+	cases := []struct {
+		rules []*models.RetentionRule
+		valid bool
+	}{
+		{nil, false},
+		{[]*models.RetentionRule{nil}, false},
+		{[]*models.RetentionRule{{Template: "unknown"}}, false},
+		{[]*models.RetentionRule{{Template: models.RetentionTemplateLatestPushed}}, false},
+		{[]*models.RetentionRule{{Template: models.RetentionTemplateRecentlyPulled, Days: -1}}, false},
+		{[]*models.RetentionRule{{Template: models.RetentionTemplateLabeled}}, false},
+		{[]*models.RetentionRule{{Template: models.RetentionTemplateAlways, Tags: "release-["}}, false},
+		{[]*models.RetentionRule{
+			{Template: models.RetentionTemplateLatestPushed, Count: 10},
+			{Template: models.RetentionTemplateRecentlyPulled, Days: 7},
+			{Template: models.RetentionTemplateLabeled, Labels: []string{"stable"}},
+			{Template: models.RetentionTemplateAlways, Tags: "release-*"},
+		}, true},
+	}
+	for _, c := range cases {
+		err := ValidateRules(c.rules)
+		assert.Equal(t, c.valid, err == nil)
+	}
+}
+
+func tags(candidates []*Candidate) []string {
+	result := []string{}
+	for _, candidate := range candidates {
+		result = append(result, candidate.Tag)
+	}
+	return result
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	candidates := []*Candidate{
+		{Tag: "ci-1", Digest: "sha256:1", PushTime: now.Add(-4 * time.Hour)},
+		{Tag: "ci-2", Digest: "sha256:2", PushTime: now.Add(-3 * time.Hour), PullTime: now.Add(-time.Hour)},
+		{Tag: "ci-3", Digest: "sha256:3", PushTime: now.Add(-2 * time.Hour), Labels: []string{"stable"}},
+		{Tag: "ci-4", Digest: "sha256:4", PushTime: now.Add(-1 * time.Hour)},
+		{Tag: "release-1.0", Digest: "sha256:5", PushTime: now.Add(-48 * time.Hour)},
+		// shares the manifest with the latest one
+		{Tag: "nightly", Digest: "sha256:4", PushTime: now.Add(-48 * time.Hour)},
+		{Tag: "old", Digest: "sha256:6", PushTime: now.Add(-72 * time.Hour), PullTime: now.AddDate(0, 0, -30)},
+	}
+
+	// no rule applies to the repository
+	retained, deleted, err := Evaluate("library/hello-world", candidates, []*models.RetentionRule{
+		{Template: models.RetentionTemplateLatestPushed, Repositories: "library/ubuntu", Count: 1},
+	}, now)
+	require.Nil(t, err)
+	assert.Equal(t, len(candidates), len(retained))
+	assert.Equal(t, 0, len(deleted))
+
+	// keep the latest pushed tag
+	retained, deleted, err = Evaluate("library/hello-world", candidates, []*models.RetentionRule{
+		{Template: models.RetentionTemplateLatestPushed, Tags: "ci-*", Count: 1},
+	}, now)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"ci-4", "nightly"}, tags(retained))
+	assert.Equal(t, []string{"ci-1", "ci-2", "ci-3", "release-1.0", "old"}, tags(deleted))
+
+	// combine the rules
+	retained, deleted, err = Evaluate("library/hello-world", candidates, []*models.RetentionRule{
+		{Template: models.RetentionTemplateLatestPushed, Repositories: "library/**", Tags: "ci-*", Count: 1},
+		{Template: models.RetentionTemplateRecentlyPulled, Days: 7},
+		{Template: models.RetentionTemplateLabeled, Labels: []string{"stable"}},
+		{Template: models.RetentionTemplateAlways, Tags: "release-*"},
+	}, now)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"ci-2", "ci-3", "ci-4", "release-1.0", "nightly"}, tags(retained))
+	assert.Equal(t, []string{"ci-1", "old"}, tags(deleted))
+}
@@ -0,0 +1,298 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/job"
+	jobmodels "github.com/goharbor/harbor/src/common/job/models"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+	jsjob "github.com/goharbor/harbor/src/jobservice/job"
+)
+
+// the count of the latest deliveries kept for each endpoint, the older ones are pruned
+// when the new deliveries are recorded
+const maxDeliveriesPerEndpoint = 500
+
+// Ctl is the global webhook controller
+var Ctl Controller
+
+// Init initializes the global webhook controller
+func Init(js job.Client, coreURL string) {
+	Ctl = NewController(js, coreURL)
+}
+
+// Controller manages the webhook endpoints of projects and delivers the events to them
+type Controller interface {
+	// ListEndpoints returns the webhook endpoints of the project, the secrets are removed
+	ListEndpoints(projectID int64) ([]*models.WebhookEndpoint, error)
+	// GetEndpoint returns the endpoint specified by ID with the secret removed,
+	// nil is returned if it doesn't exist
+	GetEndpoint(id int64) (*models.WebhookEndpoint, error)
+	// AddEndpoint adds the endpoint, the secret is encrypted before it is stored
+	AddEndpoint(endpoint *models.WebhookEndpoint) (int64, error)
+	// UpdateEndpoint updates the endpoint, the secret is kept unchanged if it is empty
+	UpdateEndpoint(endpoint *models.WebhookEndpoint) error
+	// DeleteEndpoint deletes the endpoint and its deliveries
+	DeleteEndpoint(id int64) error
+	// DeleteEndpointsOfProject deletes all the endpoints of the project
+	DeleteEndpointsOfProject(projectID int64) error
+	ListDeliveries(endpointID int64, pagination *models.Pagination) (int64, []*models.WebhookDelivery, error)
+	// Deliver records one delivery for each endpoint subscribing the event and submits
+	// the jobs delivering the event to jobservice
+	Deliver(event *Event) error
+	// UpdateDeliveryStatus is called when the status of the job of the delivery changes
+	UpdateDeliveryStatus(id int64, status string) error
+}
+
+// ValidateEndpoint checks whether the name, address and event types of the endpoint are valid
+func ValidateEndpoint(endpoint *models.WebhookEndpoint) error {
+	if len(strings.TrimSpace(endpoint.Name)) == 0 {
+		return errors.New("empty name")
+	}
+	u, err := url.Parse(endpoint.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %v", endpoint.Address, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("invalid address %s: only the absolute HTTP or HTTPS URL is supported", endpoint.Address)
+	}
+	if len(endpoint.EventTypes) == 0 {
+		return errors.New("no event type specified")
+	}
+	for _, eventType := range endpoint.EventTypes {
+		if !isSupported(eventType) {
+			return fmt.Errorf("unsupported event type %s", eventType)
+		}
+	}
+	return nil
+}
+
+func isSupported(eventType string) bool {
+	for _, t := range models.WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// NewController returns an instance of the default controller
+func NewController(js job.Client, coreURL string) Controller {
+	return &controller{
+		jobservice: js,
+		coreURL:    coreURL,
+	}
+}
+
+type controller struct {
+	jobservice job.Client
+	coreURL    string
+}
+
+func (c *controller) ListEndpoints(projectID int64) ([]*models.WebhookEndpoint, error) {
+	endpoints, err := dao.ListWebhookEndpoints(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return endpoints, nil
+}
+
+func (c *controller) GetEndpoint(id int64) (*models.WebhookEndpoint, error) {
+	endpoint, err := dao.GetWebhookEndpoint(id)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint != nil {
+		endpoint.Secret = ""
+	}
+	return endpoint, nil
+}
+
+func (c *controller) AddEndpoint(endpoint *models.WebhookEndpoint) (int64, error) {
+	var err error
+	if endpoint.Secret, err = encrypt(endpoint.Secret); err != nil {
+		return 0, err
+	}
+	return dao.AddWebhookEndpoint(endpoint)
+}
+
+func (c *controller) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	if len(endpoint.Secret) == 0 {
+		original, err := dao.GetWebhookEndpoint(endpoint.ID)
+		if err != nil {
+			return err
+		}
+		if original == nil {
+			return fmt.Errorf("webhook endpoint %d not found", endpoint.ID)
+		}
+		endpoint.Secret = original.Secret
+	} else {
+		var err error
+		if endpoint.Secret, err = encrypt(endpoint.Secret); err != nil {
+			return err
+		}
+	}
+	return dao.UpdateWebhookEndpoint(endpoint)
+}
+
+func (c *controller) DeleteEndpoint(id int64) error {
+	return dao.DeleteWebhookEndpoint(id)
+}
+
+func (c *controller) DeleteEndpointsOfProject(projectID int64) error {
+	endpoints, err := dao.ListWebhookEndpoints(projectID)
+	if err != nil {
+		return err
+	}
+	for _, endpoint := range endpoints {
+		if err = dao.DeleteWebhookEndpoint(endpoint.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) ListDeliveries(endpointID int64, pagination *models.Pagination) (int64, []*models.WebhookDelivery, error) {
+	total, err := dao.GetTotalOfWebhookDeliveries(endpointID)
+	if err != nil {
+		return 0, nil, err
+	}
+	deliveries, err := dao.ListWebhookDeliveries(endpointID, pagination)
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, deliveries, nil
+}
+
+func (c *controller) Deliver(event *Event) error {
+	project, err := dao.GetProjectByName(event.Project)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		log.Debugf("project %s not found, skip the webhook event %s", event.Project, event.Type)
+		return nil
+	}
+	endpoints, err := dao.ListWebhookEndpoints(project.ProjectID)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// the failure of one delivery doesn't abort the others
+	for _, endpoint := range endpoints {
+		if !endpoint.Enabled || !subscribes(endpoint, event.Type) {
+			continue
+		}
+		secret, err := decrypt(endpoint.Secret)
+		if err != nil {
+			log.Errorf("failed to decrypt the secret of webhook endpoint %d: %v", endpoint.ID, err)
+			continue
+		}
+		delivery := &models.WebhookDelivery{
+			EndpointID: endpoint.ID,
+			EventType:  event.Type,
+			Payload:    string(payload),
+			Status:     models.JobPending,
+		}
+		id, err := dao.AddWebhookDelivery(delivery)
+		if err != nil {
+			return err
+		}
+		delivery.ID = id
+		if err = dao.PruneWebhookDeliveries(endpoint.ID, maxDeliveriesPerEndpoint); err != nil {
+			log.Errorf("failed to prune the deliveries of webhook endpoint %d: %v", endpoint.ID, err)
+		}
+		delivery.JobID, err = c.jobservice.SubmitJob(&jobmodels.JobData{
+			Name: jsjob.Webhook,
+			Parameters: map[string]interface{}{
+				"delivery_id":      id,
+				"event_type":       event.Type,
+				"address":          endpoint.Address,
+				"secret":           secret,
+				"skip_cert_verify": endpoint.SkipCertVerify,
+				"payload":          string(payload),
+			},
+			Metadata: &jobmodels.JobMetadata{
+				JobKind: job.JobKindGeneric,
+			},
+			StatusHook: fmt.Sprintf("%s/service/notifications/jobs/webhook/delivery/%d", c.coreURL, id),
+		})
+		if err != nil {
+			log.Errorf("failed to submit the webhook job for endpoint %d: %v", endpoint.ID, err)
+			delivery.Status = models.JobError
+			delivery.Error = err.Error()
+			if e := dao.UpdateWebhookDelivery(delivery, "Status", "Error"); e != nil {
+				log.Errorf("failed to update the webhook delivery %d: %v", id, e)
+			}
+			continue
+		}
+		if err = dao.UpdateWebhookDelivery(delivery, "JobID"); err != nil {
+			log.Errorf("failed to update the job ID of webhook delivery %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func (c *controller) UpdateDeliveryStatus(id int64, status string) error {
+	return dao.UpdateWebhookDeliveryStatus(id, status)
+}
+
+func subscribes(endpoint *models.WebhookEndpoint, eventType string) bool {
+	for _, t := range endpoint.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func encrypt(secret string) (string, error) {
+	if len(secret) == 0 {
+		return "", nil
+	}
+	key, err := config.SecretKey()
+	if err != nil {
+		return "", err
+	}
+	return utils.ReversibleEncrypt(secret, key)
+}
+
+func decrypt(secret string) (string, error) {
+	if len(secret) == 0 {
+		return "", nil
+	}
+	key, err := config.SecretKey()
+	if err != nil {
+		return "", err
+	}
+	return utils.ReversibleDecrypt(secret, key)
+}
@@ -0,0 +1,97 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEndpoint(t *testing.T) {
+	cases := []struct {
+		endpoint *models.WebhookEndpoint
+		valid    bool
+	}{
+		// empty name
+		{
+			endpoint: &models.WebhookEndpoint{
+				Address:    "https://example.com/hook",
+				EventTypes: []string{models.WebhookEventPushImage},
+			},
+			valid: false,
+		},
+		// relative address
+		{
+			endpoint: &models.WebhookEndpoint{
+				Name:       "ci",
+				Address:    "/hook",
+				EventTypes: []string{models.WebhookEventPushImage},
+			},
+			valid: false,
+		},
+		// unsupported scheme
+		{
+			endpoint: &models.WebhookEndpoint{
+				Name:       "ci",
+				Address:    "ftp://example.com/hook",
+				EventTypes: []string{models.WebhookEventPushImage},
+			},
+			valid: false,
+		},
+		// no event type
+		{
+			endpoint: &models.WebhookEndpoint{
+				Name:    "ci",
+				Address: "https://example.com/hook",
+			},
+			valid: false,
+		},
+		// unsupported event type
+		{
+			endpoint: &models.WebhookEndpoint{
+				Name:       "ci",
+				Address:    "https://example.com/hook",
+				EventTypes: []string{models.WebhookEventPushImage, "unknown"},
+			},
+			valid: false,
+		},
+		{
+			endpoint: &models.WebhookEndpoint{
+				Name:       "ci",
+				Address:    "http://192.168.0.1:8080/hook",
+				EventTypes: []string{models.WebhookEventPushImage, models.WebhookEventQuotaExceeded},
+			},
+			valid: true,
+		},
+	}
+	for _, c := range cases {
+		err := ValidateEndpoint(c.endpoint)
+		if c.valid {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+}
+
+func TestSubscribes(t *testing.T) {
+	endpoint := &models.WebhookEndpoint{
+		EventTypes: []string{models.WebhookEventPushImage, models.WebhookEventDeleteImage},
+	}
+	assert.True(t, subscribes(endpoint, models.WebhookEventPushImage))
+	assert.False(t, subscribes(endpoint, models.WebhookEventPullImage))
+}
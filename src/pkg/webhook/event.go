@@ -0,0 +1,68 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/notifier"
+)
+
+// Event is published when something happens in the project, it is delivered to the webhook
+// endpoints of the project subscribing the type of the event as the JSON payload
+type Event struct {
+	Type string `json:"type"`
+	// the unix timestamp when the event occurs
+	OccurAt int64 `json:"occur_at"`
+	// the name of the user who triggers the event, empty if it is triggered by the system
+	Operator   string `json:"operator,omitempty"`
+	Project    string `json:"project"`
+	Repository string `json:"repository,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	Chart      string `json:"chart,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Publish publishes the event to the topic "notifier.WebhookTopic", the event is delivered
+// to the endpoints asynchronously
+func Publish(event *Event) {
+	if event.OccurAt == 0 {
+		event.OccurAt = time.Now().Unix()
+	}
+	if err := notifier.Publish(notifier.WebhookTopic, event); err != nil {
+		log.Errorf("failed to publish the webhook event %s of project %s: %v", event.Type, event.Project, err)
+	}
+}
+
+// Handler handles the events published to the topic "notifier.WebhookTopic"
+type Handler struct{}
+
+// Handle delivers the event to the webhook endpoints via the global controller
+func (h *Handler) Handle(value interface{}) error {
+	event, ok := value.(*Event)
+	if !ok {
+		return fmt.Errorf("invalid webhook event: %v", value)
+	}
+	return Ctl.Deliver(event)
+}
+
+// IsStateful ...
+func (h *Handler) IsStateful() bool {
+	return false
+}
@@ -18,22 +18,25 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/registryctl/auth"
 	gorilla_handlers "github.com/gorilla/handlers"
 )
 
-// NewHandlerChain returns a gorilla router which is wrapped by  authenticate handler
-// and logging handler
+// NewHandlerChain returns a gorilla router which is wrapped by  authenticate handler,
+// metrics handler and logging handler
 func NewHandlerChain() http.Handler {
-	h := newRouter()
+	router := newRouter()
 	secrets := map[string]string{
 		"jobSecret": os.Getenv("JOBSERVICE_SECRET"),
 	}
 	insecureAPIs := map[string]bool{
 		"/api/health": true,
+		"/metrics":    true,
 	}
-	h = newAuthHandler(auth.NewSecretHandler(secrets), h, insecureAPIs)
+	h := newAuthHandler(auth.NewSecretHandler(secrets), router, insecureAPIs)
+	h = metrics.InstrumentHandler(h, metrics.MuxRoute(router))
 	h = gorilla_handlers.LoggingHandler(os.Stdout, h)
 	return h
 }
@@ -66,4 +66,9 @@ func TestNewAuthHandler(t *testing.T) {
 	r := httptest.NewRequest("GET", "http://localhost/api/health", nil)
 	handler.ServeHTTP(w, r)
 
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "http://localhost/metrics", nil)
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `harbor_http_requests_total{code="200",method="GET",route="/api/health"}`)
 }
@@ -15,15 +15,15 @@
 package handlers
 
 import (
-	"net/http"
-
+	"github.com/goharbor/harbor/src/common/metrics"
 	"github.com/goharbor/harbor/src/registryctl/api"
 	"github.com/gorilla/mux"
 )
 
-func newRouter() http.Handler {
+func newRouter() *mux.Router {
 	r := mux.NewRouter()
 	r.HandleFunc("/api/registry/gc", api.StartGC).Methods("POST")
 	r.HandleFunc("/api/health", api.Health).Methods("GET")
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
 	return r
 }
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/docker/distribution"
 	"github.com/goharbor/harbor/src/replication/filter"
@@ -58,6 +59,47 @@ type ImageRegistry interface {
 	PushBlob(repository, digest string, size int64, blob io.Reader) error
 }
 
+// ChunkedBlobRegistry defines the capabilities that an image registry should have to
+// pull the blobs by range and push the blobs by chunks. With them the transfer of big
+// blobs can be resumed from the last committed offset
+type ChunkedBlobRegistry interface {
+	// pull the bytes [start, end] of the blob
+	PullBlobChunk(repository, digest string, blobSize, start, end int64) (size int64, chunk io.ReadCloser, err error)
+	// start an upload session and return the location of it
+	InitiateBlobUpload(repository string) (location string, err error)
+	// return the location for the next request and the offset of the next byte that the upload session expects
+	GetBlobUploadStatus(repository, location string) (nextLocation string, offset int64, err error)
+	// push the chunk starting at the offset, return the location for the next request and the offset of the next byte
+	PushBlobChunk(repository, location string, offset, size int64, chunk io.Reader) (nextLocation string, nextOffset int64, err error)
+	// complete the upload session after all the chunks are pushed
+	CompleteBlobUpload(repository, location, digest string) error
+}
+
+// BlobMounter defines the capability that an image registry should have to mount the
+// blobs across repositories. With it the blobs already existing in other repositories
+// of the registry needn't be uploaded again
+type BlobMounter interface {
+	// mount the blob from the repository "from" into the repository, return whether it is mounted
+	MountBlob(repository, digest, from string) (mounted bool, err error)
+}
+
+// VTagLister defines the capability that a registry should have to list the vTags
+// of the image/chart repository with the information used by the filters, e.g. labels,
+// push time, signature and vulnerability severity
+type VTagLister interface {
+	ListVTags(resourceType model.ResourceType, repository string) ([]*VTag, error)
+}
+
+// MetadataRegistry defines the capabilities that an image registry should have to read
+// and write the metadata of the repositories and tags, e.g. descriptions, labels and
+// content trust signatures. With them the metadata can be replicated along with the images
+type MetadataRegistry interface {
+	// GetMetadata returns the metadata specified by the sync settings of the repository and tags
+	GetMetadata(repository string, tags []string, sync *model.MetadataSync) (*model.Metadata, error)
+	// PutMetadata writes the metadata specified by the sync settings to the repository and tags
+	PutMetadata(repository string, metadata *model.Metadata, sync *model.MetadataSync) error
+}
+
 // ChartRegistry defines the capabilities that a chart registry should have
 type ChartRegistry interface {
 	FetchCharts(filters []*model.Filter) ([]*model.Resource, error)
@@ -98,6 +140,13 @@ type VTag struct {
 	ResourceType string   `json:"resource_type"`
 	Name         string   `json:"name"`
 	Labels       []string `json:"labels"`
+	// the time when the vTag was pushed, zero value if it is unknown
+	PushTime time.Time `json:"push_time"`
+	// whether the image is signed
+	Signed bool `json:"signed"`
+	// the highest severity of the vulnerabilities found in the image,
+	// empty if the image isn't scanned
+	Severity string `json:"severity"`
 }
 
 // GetFilterableType returns the filterable type
@@ -120,6 +169,21 @@ func (v *VTag) GetLabels() []string {
 	return v.Labels
 }
 
+// GetPushTime returns the push time
+func (v *VTag) GetPushTime() time.Time {
+	return v.PushTime
+}
+
+// IsSigned returns whether the vTag is signed
+func (v *VTag) IsSigned() bool {
+	return v.Signed
+}
+
+// GetSeverity returns the severity of vulnerabilities
+func (v *VTag) GetSeverity() string {
+	return v.Severity
+}
+
 // RegisterFactory registers one adapter factory to the registry
 func RegisterFactory(t model.RegistryType, factory Factory) error {
 	if len(t) == 0 {
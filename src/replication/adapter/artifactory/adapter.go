@@ -0,0 +1,200 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactory
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/http/modifier"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry/auth"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/adapter/native"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/util"
+)
+
+// the package types and classes of Artifactory repositories
+const (
+	packageTypeDocker = "docker"
+	packageTypeHelm   = "helm"
+
+	repositoryClassLocal   = "local"
+	repositoryClassVirtual = "virtual"
+)
+
+func init() {
+	if err := adp.RegisterFactory(model.RegistryTypeArtifactory, func(registry *model.Registry) (adp.Adapter, error) {
+		return newAdapter(registry)
+	}); err != nil {
+		log.Errorf("failed to register factory for %s: %v", model.RegistryTypeArtifactory, err)
+		return
+	}
+	log.Infof("the factory for adapter %s registered", model.RegistryTypeArtifactory)
+}
+
+// adapter for JFrog Artifactory. The key of the Artifactory repository is used as the namespace,
+// e.g. the image "busybox" in the Docker repository "docker-local" is named as "docker-local/busybox",
+// so the Docker API must be accessed with the "repository path" method. The same for the charts
+// in the Helm repositories
+type adapter struct {
+	*native.Adapter
+	registry *model.Registry
+	url      string
+	client   *common_http.Client
+}
+
+var _ adp.Adapter = (*adapter)(nil)
+var _ adp.ImageRegistry = (*adapter)(nil)
+var _ adp.ChartRegistry = (*adapter)(nil)
+
+func newAdapter(registry *model.Registry) (*adapter, error) {
+	modifiers := []modifier.Modifier{
+		&auth.UserAgentModifier{
+			UserAgent: adp.UserAgentReplication,
+		},
+	}
+	if registry.Credential != nil && len(registry.Credential.AccessSecret) != 0 {
+		// the access secret can be the password or the API key of the user
+		modifiers = append(modifiers, auth.NewBasicAuthCredential(
+			registry.Credential.AccessKey,
+			registry.Credential.AccessSecret))
+	}
+	dockerRegistryAdapter, err := native.NewAdapter(registry)
+	if err != nil {
+		return nil, err
+	}
+	return &adapter{
+		Adapter:  dockerRegistryAdapter,
+		registry: registry,
+		url:      strings.TrimSuffix(registry.URL, "/"),
+		client: common_http.NewClient(
+			&http.Client{
+				Transport: util.GetHTTPTransport(registry.Insecure),
+			}, modifiers...),
+	}, nil
+}
+
+// Info returns information of the registry
+func (a *adapter) Info() (*model.RegistryInfo, error) {
+	return &model.RegistryInfo{
+		Type: model.RegistryTypeArtifactory,
+		SupportedResourceTypes: []model.ResourceType{
+			model.ResourceTypeImage,
+			model.ResourceTypeChart,
+		},
+		SupportedResourceFilters: []*model.FilterStyle{
+			{
+				Type:  model.FilterTypeName,
+				Style: model.FilterStyleTypeText,
+			},
+			{
+				Type:  model.FilterTypeTag,
+				Style: model.FilterStyleTypeText,
+			},
+		},
+		SupportedTriggers: []model.TriggerType{
+			model.TriggerTypeManual,
+			model.TriggerTypeScheduled,
+		},
+	}, nil
+}
+
+// HealthCheck checks health status of Artifactory
+func (a *adapter) HealthCheck() (model.HealthStatus, error) {
+	if err := a.client.Get(a.url + "/artifactory/api/system/ping"); err != nil {
+		log.Errorf("failed to ping registry %s: %v", a.registry.URL, err)
+		return model.Unhealthy, nil
+	}
+	return model.Healthy, nil
+}
+
+// PrepareForPush makes sure the Artifactory repositories that the resources are pushed into
+// are ready, the local repositories are created if they don't exist
+func (a *adapter) PrepareForPush(resources []*model.Resource) error {
+	keys := map[string]string{}
+	for _, resource := range resources {
+		if resource == nil {
+			return errors.New("the resource cannot be null")
+		}
+		if resource.Metadata == nil {
+			return errors.New("the metadata of resource cannot be null")
+		}
+		if resource.Metadata.Repository == nil {
+			return errors.New("the repository of resource cannot be null")
+		}
+		key, _, err := parseRepository(resource.Metadata.Repository.Name)
+		if err != nil {
+			return err
+		}
+		packageType := packageTypeDocker
+		if resource.Type == model.ResourceTypeChart {
+			packageType = packageTypeHelm
+		}
+		if t, exist := keys[key]; exist && t != packageType {
+			return fmt.Errorf("the repository %s cannot hold both images and charts", key)
+		}
+		keys[key] = packageType
+	}
+
+	for key, packageType := range keys {
+		if err := a.ensureRepository(key, packageType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureRepository checks the type of the existing repository or creates a local one
+func (a *adapter) ensureRepository(key, packageType string) error {
+	repo, err := a.getRepository(key)
+	if err != nil {
+		return err
+	}
+	if repo == nil {
+		if err = a.createRepository(key, packageType); err != nil {
+			return fmt.Errorf("failed to create the %s repository %s on Artifactory: %v", packageType, key, err)
+		}
+		log.Debugf("the %s repository %s created", packageType, key)
+		return nil
+	}
+	if !strings.EqualFold(repo.PackageType, packageType) {
+		return fmt.Errorf("the package type of repository %s is %s rather than %s", key, repo.PackageType, packageType)
+	}
+	switch strings.ToLower(repo.Class) {
+	case repositoryClassLocal:
+		return nil
+	case repositoryClassVirtual:
+		if len(repo.DefaultDeploymentRepo) == 0 {
+			return fmt.Errorf("the default deployment repository of the virtual repository %s isn't set", key)
+		}
+		return nil
+	default:
+		return fmt.Errorf("cannot push into the %s repository %s", repo.Class, key)
+	}
+}
+
+// parseRepository splits the name into the Artifactory repository key and the image/chart name
+func parseRepository(name string) (string, string, error) {
+	strs := strings.SplitN(name, "/", 2)
+	if len(strs) == 2 && len(strs[0]) > 0 && len(strs[1]) > 0 {
+		return strs[0], strs[1], nil
+	}
+	return "", "", fmt.Errorf("invalid repository name %s, it should be in format <repository key>/<name>", name)
+}
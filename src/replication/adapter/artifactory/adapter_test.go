@@ -0,0 +1,261 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/utils/test"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const indexYAML = `apiVersion: v1
+entries:
+  nginx:
+  - name: nginx
+    version: 1.1.0
+    urls:
+    - nginx-1.1.0.tgz
+  - name: nginx
+    version: 1.0.0
+    urls:
+    - stable/nginx-1.0.0.tgz
+  redis:
+  - name: redis
+    version: 2.0.0
+    urls:
+    - redis-2.0.0.tgz
+`
+
+// fakeArtifactory records the repositories created and the chart packages deployed or deleted
+type fakeArtifactory struct {
+	created  []*repositoryConfig
+	deployed map[string]string
+	deleted  []string
+}
+
+func (f *fakeArtifactory) server() *httptest.Server {
+	f.deployed = map[string]string{}
+	configs := map[string]string{
+		"docker-local":     `{"key":"docker-local","rclass":"local","packageType":"docker"}`,
+		"docker-virtual":   `{"key":"docker-virtual","rclass":"virtual","packageType":"docker","defaultDeploymentRepo":"docker-local"}`,
+		"docker-readonly":  `{"key":"docker-readonly","rclass":"virtual","packageType":"docker"}`,
+		"docker-remote":    `{"key":"docker-remote","rclass":"remote","packageType":"docker"}`,
+		"helm-local":       `{"key":"helm-local","rclass":"local","packageType":"helm"}`,
+		"generic-binaries": `{"key":"generic-binaries","rclass":"local","packageType":"generic"}`,
+	}
+	return test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/artifactory/api/system/ping",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("OK"))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/artifactory/api/repositories/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				config, exist := configs[strings.TrimPrefix(r.URL.Path, "/artifactory/api/repositories/")]
+				if !exist {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.Write([]byte(config))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/artifactory/api/repositories",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Query().Get("packageType") {
+				case packageTypeDocker:
+					w.Write([]byte(`[{"key":"docker-local","type":"LOCAL","packageType":"Docker"},` +
+						`{"key":"docker-virtual","type":"VIRTUAL","packageType":"Docker"},` +
+						`{"key":"docker-remote","type":"REMOTE","packageType":"Docker"}]`))
+				case packageTypeHelm:
+					w.Write([]byte(`[{"key":"helm-local","type":"LOCAL","packageType":"Helm"}]`))
+				default:
+					w.Write([]byte(`[]`))
+				}
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/artifactory/api/docker/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/artifactory/api/docker/docker-local/v2/_catalog":
+					w.Write([]byte(`{"repositories":["busybox","team/app"]}`))
+				case "/artifactory/api/docker/docker-virtual/v2/_catalog":
+					w.Write([]byte(`{"repositories":["busybox"]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/artifactory/api/helm/helm-local/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				path := strings.TrimPrefix(r.URL.Path, "/artifactory/api/helm/helm-local/")
+				if path == "index.yaml" {
+					w.Write([]byte(indexYAML))
+					return
+				}
+				w.Write([]byte("package " + path))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPut,
+			Pattern: "/artifactory/api/repositories/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				config := &repositoryConfig{}
+				json.NewDecoder(r.Body).Decode(config)
+				f.created = append(f.created, config)
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPut,
+			Pattern: "/artifactory/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				data, _ := ioutil.ReadAll(r.Body)
+				f.deployed[r.URL.Path] = string(data)
+				w.WriteHeader(http.StatusCreated)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodDelete,
+			Pattern: "/artifactory/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				f.deleted = append(f.deleted, r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/v2/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/tags/list") {
+					w.Write([]byte(`{"tags":["1.28","1.29","latest"]}`))
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+	)
+}
+
+func newTestAdapter(t *testing.T, url string) *adapter {
+	a, err := newAdapter(&model.Registry{
+		URL: url,
+		Credential: &model.Credential{
+			AccessKey:    "admin",
+			AccessSecret: "api-key",
+		},
+	})
+	require.Nil(t, err)
+	return a
+}
+
+func TestAdapter_NewAdapter(t *testing.T) {
+	factory, err := adp.GetFactory(model.RegistryTypeArtifactory)
+	require.Nil(t, err)
+	adapter, err := factory(&model.Registry{
+		Type: model.RegistryTypeArtifactory,
+		URL:  "https://artifactory.example.com",
+	})
+	require.Nil(t, err)
+	info, err := adapter.Info()
+	require.Nil(t, err)
+	assert.Equal(t, model.RegistryTypeArtifactory, info.Type)
+	assert.Equal(t, 2, len(info.SupportedResourceTypes))
+}
+
+func TestAdapter_HealthCheck(t *testing.T) {
+	fake := &fakeArtifactory{}
+	server := fake.server()
+	defer server.Close()
+
+	status, err := newTestAdapter(t, server.URL).HealthCheck()
+	require.Nil(t, err)
+	assert.Equal(t, model.Healthy, string(status))
+}
+
+func TestAdapter_PrepareForPush(t *testing.T) {
+	fake := &fakeArtifactory{}
+	server := fake.server()
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	resource := func(resourceType model.ResourceType, repository string) *model.Resource {
+		return &model.Resource{
+			Type: resourceType,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: repository,
+				},
+			},
+		}
+	}
+
+	// the repository key is missing
+	err := a.PrepareForPush([]*model.Resource{resource(model.ResourceTypeImage, "busybox")})
+	assert.NotNil(t, err)
+
+	// the local repository and the virtual repository with default deployment repository
+	err = a.PrepareForPush([]*model.Resource{
+		resource(model.ResourceTypeImage, "docker-local/busybox"),
+		resource(model.ResourceTypeImage, "docker-virtual/team/app"),
+		resource(model.ResourceTypeChart, "helm-local/nginx"),
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(fake.created))
+
+	// the nonexistent repositories are created as local ones
+	err = a.PrepareForPush([]*model.Resource{
+		resource(model.ResourceTypeImage, "docker-new/busybox"),
+		resource(model.ResourceTypeChart, "helm-new/nginx"),
+	})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(fake.created))
+	created := map[string]*repositoryConfig{}
+	for _, config := range fake.created {
+		created[config.Key] = config
+	}
+	assert.Equal(t, repositoryClassLocal, created["docker-new"].Class)
+	assert.Equal(t, packageTypeDocker, created["docker-new"].PackageType)
+	assert.Equal(t, "V2", created["docker-new"].DockerAPIVersion)
+	assert.Equal(t, packageTypeHelm, created["helm-new"].PackageType)
+
+	// cannot push into these repositories
+	for _, r := range []*model.Resource{
+		resource(model.ResourceTypeImage, "docker-readonly/busybox"),
+		resource(model.ResourceTypeImage, "docker-remote/busybox"),
+		resource(model.ResourceTypeImage, "generic-binaries/busybox"),
+		resource(model.ResourceTypeImage, "helm-local/busybox"),
+	} {
+		assert.NotNil(t, a.PrepareForPush([]*model.Resource{r}))
+	}
+}
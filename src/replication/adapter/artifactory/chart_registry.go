@@ -0,0 +1,242 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactory
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	common_http "github.com/goharbor/harbor/src/common/http"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	helm_repo "k8s.io/helm/pkg/repo"
+)
+
+// FetchCharts lists the charts of the candidate Helm repositories from their index files
+// and returns the ones matching the filters
+func (a *adapter) FetchCharts(filters []*model.Filter) ([]*model.Resource, error) {
+	keys, err := a.listCandidateRepositories(packageTypeHelm, filters)
+	if err != nil {
+		return nil, err
+	}
+	resources := []*model.Resource{}
+	for _, key := range keys {
+		index, err := a.getIndex(key)
+		if err != nil {
+			return nil, err
+		}
+		names := []string{}
+		for name := range index.Entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		repositories := []*adp.Repository{}
+		for _, name := range names {
+			repositories = append(repositories, &adp.Repository{
+				ResourceType: string(model.ResourceTypeChart),
+				Name:         fmt.Sprintf("%s/%s", key, name),
+			})
+		}
+		for _, filter := range filters {
+			if err = filter.DoFilter(&repositories); err != nil {
+				return nil, err
+			}
+		}
+		for _, repository := range repositories {
+			_, name, _ := parseRepository(repository.Name)
+			vTags := []*adp.VTag{}
+			for _, version := range index.Entries[name] {
+				vTags = append(vTags, &adp.VTag{
+					ResourceType: string(model.ResourceTypeChart),
+					Name:         version.Version,
+					PushTime:     version.Created,
+				})
+			}
+			for _, filter := range filters {
+				if err = filter.DoFilter(&vTags); err != nil {
+					return nil, err
+				}
+			}
+			for _, vTag := range vTags {
+				resources = append(resources, &model.Resource{
+					Type:     model.ResourceTypeChart,
+					Registry: a.registry,
+					Metadata: &model.ResourceMetadata{
+						Repository: &model.Repository{
+							Name: repository.Name,
+						},
+						Vtags: []string{vTag.Name},
+					},
+				})
+			}
+		}
+	}
+	return resources, nil
+}
+
+// ChartExist checks whether the chart exists, the "name" is in format "<repository key>/<chart name>"
+func (a *adapter) ChartExist(name, version string) (bool, error) {
+	chart, err := a.getChartVersion(name, version)
+	if err != nil {
+		return false, err
+	}
+	return chart != nil, nil
+}
+
+// DownloadChart downloads the chart from the URL recorded in the index file
+func (a *adapter) DownloadChart(name, version string) (io.ReadCloser, error) {
+	key, path, err := a.getChartPath(name, version)
+	if err != nil {
+		return nil, err
+	}
+	url := path
+	if !isAbsoluteURL(path) {
+		url = fmt.Sprintf("%s/artifactory/api/helm/%s/%s", a.url, key, path)
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.send(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// UploadChart deploys the chart package into the root of the repository, Artifactory
+// recalculates the index file of the repository automatically
+func (a *adapter) UploadChart(name, version string, chart io.Reader) error {
+	key, chartName, err := parseRepository(name)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/artifactory/%s/%s-%s.tgz", a.url, key, chartName, version)
+	req, err := http.NewRequest(http.MethodPut, url, chart)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := a.send(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// DeleteChart deletes the chart package from the repository
+func (a *adapter) DeleteChart(name, version string) error {
+	key, path, err := a.getChartPath(name, version)
+	if err != nil {
+		return err
+	}
+	if isAbsoluteURL(path) {
+		prefix := fmt.Sprintf("/api/helm/%s/", key)
+		index := strings.Index(path, prefix)
+		if index < 0 {
+			return fmt.Errorf("cannot get the path of chart %s:%s from URL %s", name, version, path)
+		}
+		path = path[index+len(prefix):]
+	}
+	return a.client.Delete(fmt.Sprintf("%s/artifactory/%s/%s", a.url, key, path))
+}
+
+// getIndex returns the index file of the Helm repository
+func (a *adapter) getIndex(key string) (*helm_repo.IndexFile, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/artifactory/api/helm/%s/index.yaml", a.url, key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	index := helm_repo.NewIndexFile()
+	if err = yaml.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse the index file of repository %s: %v", key, err)
+	}
+	return index, nil
+}
+
+// getChartVersion returns the specified version of chart from the index file, nil is returned if it doesn't exist
+func (a *adapter) getChartVersion(name, version string) (*helm_repo.ChartVersion, error) {
+	key, chartName, err := parseRepository(name)
+	if err != nil {
+		return nil, err
+	}
+	index, err := a.getIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, chart := range index.Entries[chartName] {
+		if chart.Version == version {
+			return chart, nil
+		}
+	}
+	return nil, nil
+}
+
+// getChartPath returns the repository key and the URL of the chart package recorded in the index
+// file, the URL is relative to the repository in most cases
+func (a *adapter) getChartPath(name, version string) (string, string, error) {
+	chart, err := a.getChartVersion(name, version)
+	if err != nil {
+		return "", "", err
+	}
+	if chart == nil {
+		return "", "", fmt.Errorf("chart %s:%s not found", name, version)
+	}
+	if len(chart.URLs) == 0 || len(chart.URLs[0]) == 0 {
+		return "", "", fmt.Errorf("cannot get the download url for chart %s:%s", name, version)
+	}
+	key, _, _ := parseRepository(name)
+	return key, chart.URLs[0], nil
+}
+
+// send the request and return the error if the response status isn't 2xx
+func (a *adapter) send(req *http.Request) (*http.Response, error) {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &common_http.Error{
+			Code:    resp.StatusCode,
+			Message: string(data),
+		}
+	}
+	return resp, nil
+}
+
+func isAbsoluteURL(url string) bool {
+	url = strings.ToLower(url)
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
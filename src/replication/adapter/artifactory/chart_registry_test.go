@@ -0,0 +1,112 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactory
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCharts(t *testing.T) {
+	fake := &fakeArtifactory{}
+	server := fake.server()
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	resources, err := a.FetchCharts(nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(resources))
+	assert.Equal(t, model.ResourceTypeChart, resources[0].Type)
+	assert.Equal(t, "helm-local/nginx", resources[0].Metadata.Repository.Name)
+	assert.Equal(t, []string{"1.1.0"}, resources[0].Metadata.Vtags)
+	assert.Equal(t, "helm-local/redis", resources[2].Metadata.Repository.Name)
+
+	resources, err = a.FetchCharts([]*model.Filter{
+		{
+			Type:  model.FilterTypeName,
+			Value: "helm-local/nginx",
+		},
+		{
+			Type:  model.FilterTypeTag,
+			Value: "1.0.*",
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(resources))
+	assert.Equal(t, []string{"1.0.0"}, resources[0].Metadata.Vtags)
+}
+
+func TestChartExist(t *testing.T) {
+	fake := &fakeArtifactory{}
+	server := fake.server()
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	exist, err := a.ChartExist("helm-local/nginx", "1.0.0")
+	require.Nil(t, err)
+	assert.True(t, exist)
+
+	exist, err = a.ChartExist("helm-local/nginx", "2.0.0")
+	require.Nil(t, err)
+	assert.False(t, exist)
+
+	_, err = a.ChartExist("nginx", "1.0.0")
+	assert.NotNil(t, err)
+}
+
+func TestDownloadChart(t *testing.T) {
+	fake := &fakeArtifactory{}
+	server := fake.server()
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	reader, err := a.DownloadChart("helm-local/nginx", "1.0.0")
+	require.Nil(t, err)
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	require.Nil(t, err)
+	assert.Equal(t, "package stable/nginx-1.0.0.tgz", string(data))
+
+	_, err = a.DownloadChart("helm-local/nginx", "2.0.0")
+	assert.NotNil(t, err)
+}
+
+func TestUploadChart(t *testing.T) {
+	fake := &fakeArtifactory{}
+	server := fake.server()
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	err := a.UploadChart("helm-local/nginx", "1.2.0", bytes.NewReader([]byte("chart")))
+	require.Nil(t, err)
+	assert.Equal(t, "chart", fake.deployed["/artifactory/helm-local/nginx-1.2.0.tgz"])
+}
+
+func TestDeleteChart(t *testing.T) {
+	fake := &fakeArtifactory{}
+	server := fake.server()
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	err := a.DeleteChart("helm-local/nginx", "1.0.0")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(fake.deleted))
+	assert.Equal(t, "/artifactory/helm-local/stable/nginx-1.0.0.tgz", fake.deleted[0])
+}
@@ -0,0 +1,85 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactory
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+)
+
+// repository is the Artifactory repository returned by the listing API
+type repository struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	PackageType string `json:"packageType"`
+}
+
+// repositoryConfig is the configuration of Artifactory repository
+type repositoryConfig struct {
+	Key                   string `json:"key"`
+	Class                 string `json:"rclass"`
+	PackageType           string `json:"packageType"`
+	DockerAPIVersion      string `json:"dockerApiVersion,omitempty"`
+	DefaultDeploymentRepo string `json:"defaultDeploymentRepo,omitempty"`
+}
+
+// listRepositories lists the local and virtual repositories of the package type. The remote
+// repositories are skipped as they only cache the content of other registries
+func (a *adapter) listRepositories(packageType string) ([]string, error) {
+	repositories := []*repository{}
+	url := fmt.Sprintf("%s/artifactory/api/repositories?packageType=%s", a.url, packageType)
+	if err := a.client.Get(url, &repositories); err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for _, repo := range repositories {
+		class := strings.ToLower(repo.Type)
+		if class != repositoryClassLocal && class != repositoryClassVirtual {
+			continue
+		}
+		keys = append(keys, repo.Key)
+	}
+	return keys, nil
+}
+
+// getRepository returns the configuration of the repository, nil is returned if the repository doesn't exist
+func (a *adapter) getRepository(key string) (*repositoryConfig, error) {
+	repo := &repositoryConfig{}
+	if err := a.client.Get(fmt.Sprintf("%s/artifactory/api/repositories/%s", a.url, key), repo); err != nil {
+		// Artifactory returns 400 for the nonexistent repository
+		if e, ok := err.(*common_http.Error); ok &&
+			(e.Code == http.StatusNotFound || e.Code == http.StatusBadRequest) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return repo, nil
+}
+
+// createRepository creates the local repository of the package type
+func (a *adapter) createRepository(key, packageType string) error {
+	repo := &repositoryConfig{
+		Key:         key,
+		Class:       repositoryClassLocal,
+		PackageType: packageType,
+	}
+	if packageType == packageTypeDocker {
+		repo.DockerAPIVersion = "V2"
+	}
+	return a.client.Put(fmt.Sprintf("%s/artifactory/api/repositories/%s", a.url, key), repo)
+}
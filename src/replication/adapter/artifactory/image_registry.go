@@ -0,0 +1,124 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/util"
+)
+
+type catalog struct {
+	Repositories []string `json:"repositories"`
+}
+
+// FetchImages lists the images of the candidate Docker repositories through the Docker API of
+// each Artifactory repository and returns the ones matching the filters
+func (a *adapter) FetchImages(filters []*model.Filter) ([]*model.Resource, error) {
+	keys, err := a.listCandidateRepositories(packageTypeDocker, filters)
+	if err != nil {
+		return nil, err
+	}
+	repositories := []*adp.Repository{}
+	for _, key := range keys {
+		images, err := a.listImages(key)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			repositories = append(repositories, &adp.Repository{
+				ResourceType: string(model.ResourceTypeImage),
+				Name:         fmt.Sprintf("%s/%s", key, image),
+			})
+		}
+	}
+	for _, filter := range filters {
+		if err = filter.DoFilter(&repositories); err != nil {
+			return nil, err
+		}
+	}
+
+	resources := []*model.Resource{}
+	for _, repository := range repositories {
+		tags, err := a.ListTag(repository.Name)
+		if err != nil {
+			return nil, err
+		}
+		vTags := []*adp.VTag{}
+		for _, tag := range tags {
+			vTags = append(vTags, &adp.VTag{
+				ResourceType: string(model.ResourceTypeImage),
+				Name:         tag,
+			})
+		}
+		for _, filter := range filters {
+			if err = filter.DoFilter(&vTags); err != nil {
+				return nil, err
+			}
+		}
+		if len(vTags) == 0 {
+			continue
+		}
+		tags = []string{}
+		for _, vTag := range vTags {
+			tags = append(tags, vTag.Name)
+		}
+		resources = append(resources, &model.Resource{
+			Type:     model.ResourceTypeImage,
+			Registry: a.registry,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: repository.Name,
+				},
+				Vtags: tags,
+			},
+		})
+	}
+	return resources, nil
+}
+
+// listImages lists the images in the Docker repository through the catalog API of the repository
+func (a *adapter) listImages(key string) ([]string, error) {
+	c := &catalog{}
+	if err := a.client.Get(fmt.Sprintf("%s/artifactory/api/docker/%s/v2/_catalog", a.url, key), c); err != nil {
+		return nil, err
+	}
+	log.Debugf("got %d images in repository %s", len(c.Repositories), key)
+	return c.Repositories, nil
+}
+
+// listCandidateRepositories returns the repository keys parsed from the name filter if it
+// specifies the keys explicitly, otherwise returns all the local and virtual repositories
+func (a *adapter) listCandidateRepositories(packageType string, filters []*model.Filter) ([]string, error) {
+	pattern := ""
+	for _, filter := range filters {
+		if filter.Type == model.FilterTypeName && !filter.IsExclusion() {
+			pattern = filter.Value.(string)
+			break
+		}
+	}
+	if len(pattern) > 0 {
+		substrings := strings.Split(pattern, "/")
+		if keys, ok := util.IsSpecificPathComponent(substrings[0]); ok {
+			log.Debugf("parsed the repositories %v from pattern %s", keys, pattern)
+			return keys, nil
+		}
+	}
+	return a.listRepositories(packageType)
+}
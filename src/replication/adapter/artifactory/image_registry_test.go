@@ -0,0 +1,55 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package artifactory
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchImages(t *testing.T) {
+	fake := &fakeArtifactory{}
+	server := fake.server()
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	// the local and virtual repositories
+	resources, err := a.FetchImages(nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(resources))
+	assert.Equal(t, "docker-local/busybox", resources[0].Metadata.Repository.Name)
+	assert.Equal(t, "docker-local/team/app", resources[1].Metadata.Repository.Name)
+	assert.Equal(t, "docker-virtual/busybox", resources[2].Metadata.Repository.Name)
+	assert.Equal(t, []string{"1.28", "1.29", "latest"}, resources[0].Metadata.Vtags)
+
+	// the repository key specified in the name filter
+	resources, err = a.FetchImages([]*model.Filter{
+		{
+			Type:  model.FilterTypeName,
+			Value: "docker-local/**",
+		},
+		{
+			Type:  model.FilterTypeTag,
+			Value: "1.*",
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(resources))
+	assert.Equal(t, "docker-local/team/app", resources[1].Metadata.Repository.Name)
+	assert.Equal(t, []string{"1.28", "1.29"}, resources[1].Metadata.Vtags)
+}
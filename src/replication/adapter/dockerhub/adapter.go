@@ -428,7 +428,7 @@ func (a *adapter) getTags(namespace, repo string, page, pageSize int) (*TagsResp
 // getFilter gets specific type filter value from filters list.
 func (a *adapter) getStringFilterValue(filterType model.FilterType, filters []*model.Filter) (string, error) {
 	for _, f := range filters {
-		if f.Type == filterType {
+		if f.Type == filterType && !f.IsExclusion() {
 			v, ok := f.Value.(string)
 			if !ok {
 				msg := fmt.Sprintf("expect filter value to be string, but got: %v", f.Value)
@@ -0,0 +1,266 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry/auth"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/adapter/native"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/util"
+)
+
+func init() {
+	if err := adp.RegisterFactory(model.RegistryTypeGitLab, func(registry *model.Registry) (adp.Adapter, error) {
+		return newAdapter(registry)
+	}); err != nil {
+		log.Errorf("failed to register factory for %s: %v", model.RegistryTypeGitLab, err)
+		return
+	}
+	log.Infof("the factory for adapter %s registered", model.RegistryTypeGitLab)
+}
+
+type adapter struct {
+	*native.Adapter
+	registry *model.Registry
+	client   *client
+}
+
+var _ adp.Adapter = (*adapter)(nil)
+
+// newAdapter creates the adapter for the container registry of GitLab. The URL of the registry
+// is the one used by "docker login" and the access secret of the credential is a personal or
+// project access token. For the project access token, the access key can be any non-empty value
+func newAdapter(registry *model.Registry) (*adapter, error) {
+	var credential auth.Credential
+	if registry.Credential != nil && len(registry.Credential.AccessSecret) != 0 {
+		credential = auth.NewBasicAuthCredential(
+			registry.Credential.AccessKey,
+			registry.Credential.AccessSecret)
+	}
+	authorizer := auth.NewStandardTokenAuthorizer(&http.Client{
+		Transport: util.GetHTTPTransport(registry.Insecure),
+	}, credential)
+
+	dockerRegistryAdapter, err := native.NewAdapterWithCustomizedAuthorizer(registry, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &adapter{
+		Adapter:  dockerRegistryAdapter,
+		registry: registry,
+		client:   newClient(registry),
+	}, nil
+}
+
+// Info returns information of the registry
+func (a *adapter) Info() (*model.RegistryInfo, error) {
+	return &model.RegistryInfo{
+		Type: model.RegistryTypeGitLab,
+		SupportedResourceTypes: []model.ResourceType{
+			model.ResourceTypeImage,
+		},
+		SupportedResourceFilters: []*model.FilterStyle{
+			{
+				Type:  model.FilterTypeName,
+				Style: model.FilterStyleTypeText,
+			},
+			{
+				Type:  model.FilterTypeTag,
+				Style: model.FilterStyleTypeText,
+			},
+		},
+		SupportedTriggers: []model.TriggerType{
+			model.TriggerTypeManual,
+			model.TriggerTypeScheduled,
+		},
+	}, nil
+}
+
+// PrepareForPush checks the projects that the repositories belong to. The repositories
+// are created by pushing on GitLab, but the projects must exist and enable the container registry
+func (a *adapter) PrepareForPush(resources []*model.Resource) error {
+	repositories := map[string]struct{}{}
+	for _, resource := range resources {
+		if resource == nil {
+			return errors.New("the resource cannot be null")
+		}
+		if resource.Metadata == nil {
+			return errors.New("the metadata of resource cannot be null")
+		}
+		if resource.Metadata.Repository == nil {
+			return errors.New("the repository of resource cannot be null")
+		}
+		if len(resource.Metadata.Repository.Name) == 0 {
+			return errors.New("the name of the repository cannot be null")
+		}
+		repositories[resource.Metadata.Repository.Name] = struct{}{}
+	}
+
+	for repository := range repositories {
+		project, err := a.findProject(repository)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return fmt.Errorf("the project of repository %s not found on GitLab", repository)
+		}
+		if !project.ContainerRegistryEnabled {
+			return fmt.Errorf("the container registry of project %s is disabled", project.PathWithNamespace)
+		}
+	}
+	return nil
+}
+
+// FetchImages lists the registry repositories of the candidate projects through GitLab API
+// as the catalog API is disabled, and returns the images matching the filters
+func (a *adapter) FetchImages(filters []*model.Filter) ([]*model.Resource, error) {
+	projects, err := a.listCandidateProjects(filters)
+	if err != nil {
+		return nil, err
+	}
+	repositories := []*adp.Repository{}
+	for _, project := range projects {
+		repos, err := a.client.listRepositories(project.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			repositories = append(repositories, &adp.Repository{
+				ResourceType: string(model.ResourceTypeImage),
+				Name:         repo.Path,
+			})
+		}
+	}
+	for _, filter := range filters {
+		if err = filter.DoFilter(&repositories); err != nil {
+			return nil, err
+		}
+	}
+
+	resources := []*model.Resource{}
+	for _, repository := range repositories {
+		tags, err := a.ListTag(repository.Name)
+		if err != nil {
+			return nil, err
+		}
+		vTags := []*adp.VTag{}
+		for _, tag := range tags {
+			vTags = append(vTags, &adp.VTag{
+				ResourceType: string(model.ResourceTypeImage),
+				Name:         tag,
+			})
+		}
+		for _, filter := range filters {
+			if err = filter.DoFilter(&vTags); err != nil {
+				return nil, err
+			}
+		}
+		if len(vTags) == 0 {
+			continue
+		}
+		tags = []string{}
+		for _, vTag := range vTags {
+			tags = append(tags, vTag.Name)
+		}
+		resources = append(resources, &model.Resource{
+			Type:     model.ResourceTypeImage,
+			Registry: a.registry,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: repository.Name,
+				},
+				Vtags: tags,
+			},
+		})
+	}
+	return resources, nil
+}
+
+// listCandidateProjects returns the projects of the repositories if the name filter specifies
+// the repositories explicitly, otherwise returns the projects which the user is a member of
+func (a *adapter) listCandidateProjects(filters []*model.Filter) ([]*project, error) {
+	pattern := ""
+	for _, filter := range filters {
+		if filter.Type == model.FilterTypeName && !filter.IsExclusion() {
+			pattern = filter.Value.(string)
+			break
+		}
+	}
+	if paths, ok := util.IsSpecificPath(pattern); ok {
+		projects := []*project{}
+		found := map[int64]struct{}{}
+		for _, path := range paths {
+			project, err := a.findProject(path)
+			if err != nil {
+				return nil, err
+			}
+			if project == nil {
+				continue
+			}
+			if _, exist := found[project.ID]; exist {
+				continue
+			}
+			found[project.ID] = struct{}{}
+			projects = append(projects, project)
+		}
+		return projects, nil
+	}
+
+	projects, err := a.client.listProjects()
+	if err != nil {
+		return nil, err
+	}
+	if len(pattern) == 0 {
+		return projects, nil
+	}
+	// narrow down the projects by the top-level group if it's specified
+	groups, ok := util.IsSpecificPathComponent(strings.Split(pattern, "/")[0])
+	if !ok {
+		return projects, nil
+	}
+	result := []*project{}
+	for _, project := range projects {
+		for _, group := range groups {
+			if strings.HasPrefix(project.PathWithNamespace, group+"/") {
+				result = append(result, project)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// findProject returns the project that the repository belongs to. The repository of GitLab
+// is named as "<project path>[/<image name>]", so try the path components from the longest
+func (a *adapter) findProject(repository string) (*project, error) {
+	components := strings.Split(repository, "/")
+	for i := len(components); i > 1; i-- {
+		project, err := a.client.getProject(strings.Join(components[:i], "/"))
+		if err != nil {
+			return nil, err
+		}
+		if project != nil {
+			return project, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,195 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/utils/test"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeGitLab(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/jwt/auth",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"token":"registry-token"}`))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/v4/projects/{id}/registry/repositories",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "access-token", r.Header.Get("PRIVATE-TOKEN"))
+				switch {
+				case strings.HasPrefix(r.URL.Path, "/api/v4/projects/1/"):
+					w.Write([]byte(`[{"id":1,"path":"group/app","project_id":1},{"id":2,"path":"group/app/worker","project_id":1}]`))
+				case strings.HasPrefix(r.URL.Path, "/api/v4/projects/2/"):
+					w.Write([]byte(`[{"id":3,"path":"other/tool","project_id":2}]`))
+				default:
+					w.Write([]byte(`[]`))
+				}
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/v4/projects/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				switch strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4/projects/") {
+				case "group%2Fapp":
+					w.Write([]byte(`{"id":1,"path_with_namespace":"group/app","container_registry_enabled":true}`))
+				case "group%2Fdisabled":
+					w.Write([]byte(`{"id":3,"path_with_namespace":"group/disabled","container_registry_enabled":false}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/v4/projects",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "access-token", r.Header.Get("PRIVATE-TOKEN"))
+				assert.Equal(t, "true", r.URL.Query().Get("membership"))
+				if r.URL.Query().Get("page") == "1" {
+					w.Header().Set("X-Next-Page", "2")
+					w.Write([]byte(`[{"id":1,"path_with_namespace":"group/app","container_registry_enabled":true}]`))
+					return
+				}
+				w.Write([]byte(`[{"id":2,"path_with_namespace":"other/tool","container_registry_enabled":true},` +
+					`{"id":3,"path_with_namespace":"group/disabled","container_registry_enabled":false}]`))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/v2/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer registry-token" {
+					w.Header().Set("WWW-Authenticate",
+						fmt.Sprintf(`Bearer realm="%s/jwt/auth",service="container_registry"`, server.URL))
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if strings.HasSuffix(r.URL.Path, "/tags/list") {
+					w.Write([]byte(`{"tags":["1.0","latest"]}`))
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+	)
+	return server
+}
+
+func newTestAdapter(t *testing.T, url string) *adapter {
+	a, err := newAdapter(&model.Registry{
+		URL: url,
+		Credential: &model.Credential{
+			AccessKey:    "user",
+			AccessSecret: "access-token",
+		},
+	})
+	require.Nil(t, err)
+	return a
+}
+
+func TestAdapter_NewAdapter(t *testing.T) {
+	factory, err := adp.GetFactory(model.RegistryTypeGitLab)
+	require.Nil(t, err)
+	adapter, err := factory(&model.Registry{
+		Type: model.RegistryTypeGitLab,
+		URL:  "https://registry.gitlab.com",
+	})
+	require.Nil(t, err)
+	info, err := adapter.Info()
+	require.Nil(t, err)
+	assert.Equal(t, model.RegistryTypeGitLab, info.Type)
+}
+
+func TestAdapter_FetchImages(t *testing.T) {
+	server := newFakeGitLab(t)
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	// all the projects that the user is a member of
+	resources, err := a.FetchImages(nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(resources))
+	assert.Equal(t, "group/app", resources[0].Metadata.Repository.Name)
+	assert.Equal(t, "group/app/worker", resources[1].Metadata.Repository.Name)
+	assert.Equal(t, "other/tool", resources[2].Metadata.Repository.Name)
+	assert.Equal(t, []string{"1.0", "latest"}, resources[0].Metadata.Vtags)
+
+	// the projects under the group
+	resources, err = a.FetchImages([]*model.Filter{
+		{
+			Type:  model.FilterTypeName,
+			Value: "group/**",
+		},
+		{
+			Type:  model.FilterTypeTag,
+			Value: "1.*",
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(resources))
+	assert.Equal(t, []string{"1.0"}, resources[0].Metadata.Vtags)
+
+	// the specific repository
+	resources, err = a.FetchImages([]*model.Filter{
+		{
+			Type:  model.FilterTypeName,
+			Value: "group/app/worker",
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(resources))
+	assert.Equal(t, "group/app/worker", resources[0].Metadata.Repository.Name)
+}
+
+func TestAdapter_PrepareForPush(t *testing.T) {
+	server := newFakeGitLab(t)
+	defer server.Close()
+	a := newTestAdapter(t, server.URL)
+
+	resource := func(repository string) *model.Resource {
+		return &model.Resource{
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: repository,
+				},
+			},
+		}
+	}
+	// the repository under the project
+	err := a.PrepareForPush([]*model.Resource{resource("group/app"), resource("group/app/api")})
+	assert.Nil(t, err)
+	// the project doesn't exist
+	err = a.PrepareForPush([]*model.Resource{resource("group/unknown")})
+	assert.NotNil(t, err)
+	// the container registry of the project is disabled
+	err = a.PrepareForPush([]*model.Resource{resource("group/disabled/app")})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,210 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/http/modifier"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry/auth"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/util"
+)
+
+const (
+	// the path of the token service of GitLab, it's the realm of the challenge returned by the registry
+	jwtAuthPath = "/jwt/auth"
+	pageSize    = 100
+)
+
+type project struct {
+	ID                       int64  `json:"id"`
+	PathWithNamespace        string `json:"path_with_namespace"`
+	ContainerRegistryEnabled bool   `json:"container_registry_enabled"`
+}
+
+type repository struct {
+	ID        int64  `json:"id"`
+	Path      string `json:"path"`
+	ProjectID int64  `json:"project_id"`
+	Location  string `json:"location"`
+}
+
+// privateTokenModifier sets the personal or project access token into the requests sent to GitLab API
+type privateTokenModifier struct {
+	token string
+}
+
+func (p *privateTokenModifier) Modify(req *http.Request) error {
+	req.Header.Set("PRIVATE-TOKEN", p.token)
+	return nil
+}
+
+// client talks with the GitLab API. Only the URL of the container registry is configured in
+// the registry, the URL of GitLab is discovered from the token service the registry points to
+type client struct {
+	registryURL string
+	client      *common_http.Client
+	lock        sync.Mutex
+	apiURL      string
+}
+
+func newClient(registry *model.Registry) *client {
+	c := &client{
+		registryURL: strings.TrimSuffix(registry.URL, "/"),
+	}
+	var modifiers []modifier.Modifier
+	if registry.Credential != nil && len(registry.Credential.AccessSecret) != 0 {
+		modifiers = append(modifiers, &privateTokenModifier{
+			token: registry.Credential.AccessSecret,
+		})
+	}
+	c.client = common_http.NewClient(&http.Client{
+		Transport: util.GetHTTPTransport(registry.Insecure),
+	}, modifiers...)
+	return c
+}
+
+// getAPIURL returns the URL of GitLab API, the URL is discovered only once
+func (c *client) getAPIURL() (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.apiURL) > 0 {
+		return c.apiURL, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, c.registryURL+"/v2/", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	for _, challenge := range auth.ParseChallengeFromResponse(resp) {
+		realm := challenge.Parameters["realm"]
+		if !strings.EqualFold(challenge.Scheme, "bearer") || !strings.HasSuffix(realm, jwtAuthPath) {
+			continue
+		}
+		c.apiURL = strings.TrimSuffix(realm, jwtAuthPath) + "/api/v4"
+		log.Debugf("the API of GitLab for registry %s is %s", c.registryURL, c.apiURL)
+		return c.apiURL, nil
+	}
+	return "", fmt.Errorf("failed to discover the GitLab instance of registry %s, the token service isn't found", c.registryURL)
+}
+
+// getProject returns the project specified by the full path, nil is returned if the project doesn't exist
+func (c *client) getProject(path string) (*project, error) {
+	apiURL, err := c.getAPIURL()
+	if err != nil {
+		return nil, err
+	}
+	p := &project{}
+	if err = c.client.Get(fmt.Sprintf("%s/projects/%s", apiURL, url.PathEscape(path)), p); err != nil {
+		if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// listProjects lists the projects which the user is a member of and have the container registry enabled
+func (c *client) listProjects() ([]*project, error) {
+	apiURL, err := c.getAPIURL()
+	if err != nil {
+		return nil, err
+	}
+	var projects []*project
+	if err = c.getAll(fmt.Sprintf("%s/projects?membership=true", apiURL), &projects); err != nil {
+		return nil, err
+	}
+	result := []*project{}
+	for _, p := range projects {
+		if p.ContainerRegistryEnabled {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// listRepositories lists the registry repositories of the project
+func (c *client) listRepositories(projectID int64) ([]*repository, error) {
+	apiURL, err := c.getAPIURL()
+	if err != nil {
+		return nil, err
+	}
+	var repositories []*repository
+	if err = c.getAll(fmt.Sprintf("%s/projects/%d/registry/repositories", apiURL, projectID), &repositories); err != nil {
+		return nil, err
+	}
+	return repositories, nil
+}
+
+// getAll gets the resources of all pages, the next page is specified by the header "X-Next-Page".
+// The parameter "v" must be a pointer to a slice
+func (c *client) getAll(endpoint string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("v should be a pointer to a slice")
+	}
+	next := "1"
+	for len(next) > 0 {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%sper_page=%d&page=%s",
+			endpoint, querySeparator(endpoint), pageSize, next), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return &common_http.Error{
+				Code:    resp.StatusCode,
+				Message: string(data),
+			}
+		}
+		page := reflect.New(rv.Elem().Type())
+		if err = json.Unmarshal(data, page.Interface()); err != nil {
+			return err
+		}
+		rv.Elem().Set(reflect.AppendSlice(rv.Elem(), page.Elem()))
+		next = resp.Header.Get("X-Next-Page")
+	}
+	return nil
+}
+
+func querySeparator(endpoint string) string {
+	if strings.Contains(endpoint, "?") {
+		return "&"
+	}
+	return "?"
+}
@@ -98,6 +98,32 @@ func (a *adapter) Info() (*model.RegistryInfo, error) {
 				Type:  model.FilterTypeTag,
 				Style: model.FilterStyleTypeText,
 			},
+			{
+				Type:  model.FilterTypeNameRegex,
+				Style: model.FilterStyleTypeText,
+			},
+			{
+				Type:  model.FilterTypeTagRegex,
+				Style: model.FilterStyleTypeText,
+			},
+			{
+				Type:  model.FilterTypeTagSemver,
+				Style: model.FilterStyleTypeText,
+			},
+			{
+				Type:  model.FilterTypePushedWithin,
+				Style: model.FilterStyleTypeText,
+			},
+			{
+				Type:   model.FilterTypeSigned,
+				Style:  model.FilterStyleTypeRadio,
+				Values: []string{"true", "false"},
+			},
+			{
+				Type:   model.FilterTypeSeverity,
+				Style:  model.FilterStyleTypeList,
+				Values: []string{"negligible", "unknown", "low", "medium", "high"},
+			},
 		},
 		SupportedTriggers: []model.TriggerType{
 			model.TriggerTypeManual,
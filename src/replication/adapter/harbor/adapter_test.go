@@ -43,7 +43,7 @@ func TestInfo(t *testing.T) {
 	info, err := adapter.Info()
 	require.Nil(t, err)
 	assert.Equal(t, model.RegistryTypeHarbor, info.Type)
-	assert.Equal(t, 2, len(info.SupportedResourceFilters))
+	assert.Equal(t, 8, len(info.SupportedResourceFilters))
 	assert.Equal(t, 2, len(info.SupportedTriggers))
 	assert.Equal(t, 2, len(info.SupportedResourceTypes))
 	assert.Equal(t, model.ResourceTypeImage, info.SupportedResourceTypes[0])
@@ -67,7 +67,7 @@ func TestInfo(t *testing.T) {
 	info, err = adapter.Info()
 	require.Nil(t, err)
 	assert.Equal(t, model.RegistryTypeHarbor, info.Type)
-	assert.Equal(t, 2, len(info.SupportedResourceFilters))
+	assert.Equal(t, 8, len(info.SupportedResourceFilters))
 	assert.Equal(t, 2, len(info.SupportedTriggers))
 	assert.Equal(t, 1, len(info.SupportedResourceTypes))
 	assert.Equal(t, model.ResourceTypeImage, info.SupportedResourceTypes[0])
@@ -22,6 +22,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"strings"
+	"time"
 
 	common_http "github.com/goharbor/harbor/src/common/http"
 	adp "github.com/goharbor/harbor/src/replication/adapter"
@@ -29,12 +30,18 @@ import (
 )
 
 type label struct {
-	Name string `json:"name"`
+	ID          int64  `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Color       string `json:"color"`
+	Scope       string `json:"scope"`
+	ProjectID   int64  `json:"project_id"`
 }
 
 type chartVersion struct {
-	Version string   `json:"version"`
-	Labels  []*label `json:"labels"`
+	Version string    `json:"version"`
+	Labels  []*label  `json:"labels"`
+	Created time.Time `json:"created"`
 }
 
 type chartVersionDetail struct {
@@ -70,27 +77,13 @@ func (a *adapter) FetchCharts(filters []*model.Filter) ([]*model.Resource, error
 			}
 		}
 		for _, repository := range repositories {
-			name := strings.SplitN(repository.Name, "/", 2)[1]
-			url := fmt.Sprintf("%s/api/chartrepo/%s/charts/%s", a.getURL(), project.Name, name)
-			versions := []*chartVersion{}
-			if err := a.client.Get(url, &versions); err != nil {
+			vTags, err := a.getChartVersions(repository.Name)
+			if err != nil {
 				return nil, err
 			}
-			if len(versions) == 0 {
+			if len(vTags) == 0 {
 				continue
 			}
-			vTags := []*adp.VTag{}
-			for _, version := range versions {
-				var labels []string
-				for _, label := range version.Labels {
-					labels = append(labels, label.Name)
-				}
-				vTags = append(vTags, &adp.VTag{
-					Name:         version.Version,
-					Labels:       labels,
-					ResourceType: string(model.ResourceTypeChart),
-				})
-			}
 			for _, filter := range filters {
 				if err = filter.DoFilter(&vTags); err != nil {
 					return nil, err
@@ -115,6 +108,33 @@ func (a *adapter) FetchCharts(filters []*model.Filter) ([]*model.Resource, error
 	return resources, nil
 }
 
+// get the versions of the chart "name" which is in format "project/chart"
+func (a *adapter) getChartVersions(name string) ([]*adp.VTag, error) {
+	project, chart, err := parseChartName(name)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/api/chartrepo/%s/charts/%s", a.getURL(), project, chart)
+	versions := []*chartVersion{}
+	if err := a.client.Get(url, &versions); err != nil {
+		return nil, err
+	}
+	vTags := []*adp.VTag{}
+	for _, version := range versions {
+		var labels []string
+		for _, label := range version.Labels {
+			labels = append(labels, label.Name)
+		}
+		vTags = append(vTags, &adp.VTag{
+			Name:         version.Version,
+			Labels:       labels,
+			ResourceType: string(model.ResourceTypeChart),
+			PushTime:     version.Created,
+		})
+	}
+	return vTags, nil
+}
+
 func (a *adapter) ChartExist(name, version string) (bool, error) {
 	_, err := a.getChartInfo(name, version)
 	if err == nil {
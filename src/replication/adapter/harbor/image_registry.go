@@ -17,7 +17,9 @@ package harbor
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	adp "github.com/goharbor/harbor/src/replication/adapter"
 	"github.com/goharbor/harbor/src/replication/model"
@@ -83,7 +85,7 @@ func (a *adapter) FetchImages(filters []*model.Filter) ([]*model.Resource, error
 func (a *adapter) listCandidateProjects(filters []*model.Filter) ([]*project, error) {
 	pattern := ""
 	for _, filter := range filters {
-		if filter.Type == model.FilterTypeName {
+		if filter.Type == model.FilterTypeName && !filter.IsExclusion() {
 			pattern = filter.Value.(string)
 			break
 		}
@@ -131,6 +133,12 @@ func (a *adapter) getTags(repository string) ([]*adp.VTag, error) {
 		Labels []*struct {
 			Name string `json:"name"`
 		}
+		PushTime     *time.Time  `json:"push_time"`
+		Signature    interface{} `json:"signature"`
+		ScanOverview *struct {
+			Severity int    `json:"severity"`
+			Status   string `json:"scan_status"`
+		} `json:"scan_overview"`
 	}{}
 	if err := a.client.Get(url, &tags); err != nil {
 		return nil, err
@@ -141,11 +149,33 @@ func (a *adapter) getTags(repository string) ([]*adp.VTag, error) {
 		for _, label := range tag.Labels {
 			labels = append(labels, label.Name)
 		}
-		vTags = append(vTags, &adp.VTag{
+		vTag := &adp.VTag{
 			Name:         tag.Name,
 			Labels:       labels,
 			ResourceType: string(model.ResourceTypeImage),
-		})
+			Signed:       tag.Signature != nil,
+		}
+		if tag.PushTime != nil {
+			vTag.PushTime = *tag.PushTime
+		}
+		// the severity is only meaningful when the scan job is finished
+		if tag.ScanOverview != nil && tag.ScanOverview.Status == models.JobFinished {
+			vTag.Severity = models.Severity(tag.ScanOverview.Severity).String()
+		}
+		vTags = append(vTags, vTag)
 	}
 	return vTags, nil
 }
+
+// ListVTags returns the tags of image or the versions of chart with the
+// information used by filters, e.g. labels, push time, signature and severity
+func (a *adapter) ListVTags(resourceType model.ResourceType, repository string) ([]*adp.VTag, error) {
+	switch resourceType {
+	case model.ResourceTypeImage:
+		return a.getTags(repository)
+	case model.ResourceTypeChart:
+		return a.getChartVersions(repository)
+	default:
+		return nil, fmt.Errorf("unsupported resource type %s", resourceType)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harbor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+)
+
+var _ adp.MetadataRegistry = &adapter{}
+
+type repository struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Labels      []*label `json:"labels"`
+}
+
+// GetMetadata returns the descriptions, labels and signatures of the repository and tags
+func (a *adapter) GetMetadata(repository string, tags []string, sync *model.MetadataSync) (*model.Metadata, error) {
+	metadata := &model.Metadata{}
+	if sync == nil {
+		return metadata, nil
+	}
+	if sync.Description || sync.Labels {
+		repo, err := a.getRepository(repository)
+		if err != nil {
+			return nil, err
+		}
+		if repo == nil {
+			return nil, fmt.Errorf("repository %s not found", repository)
+		}
+		if sync.Description {
+			metadata.Description = repo.Description
+		}
+		if sync.Labels {
+			metadata.Labels = convertLabels(repo.Labels)
+		}
+	}
+
+	tagMetadata := map[string]*model.TagMetadata{}
+	for _, tag := range tags {
+		t := &model.TagMetadata{
+			Name: tag,
+		}
+		metadata.Tags = append(metadata.Tags, t)
+		tagMetadata[tag] = t
+	}
+	if sync.Labels {
+		ts := []*struct {
+			Name   string   `json:"name"`
+			Labels []*label `json:"labels"`
+		}{}
+		if err := a.client.Get(fmt.Sprintf("%s/api/repositories/%s/tags", a.getURL(), repository), &ts); err != nil {
+			return nil, err
+		}
+		for _, tag := range ts {
+			if t, exist := tagMetadata[tag.Name]; exist {
+				t.Labels = convertLabels(tag.Labels)
+			}
+		}
+	}
+	if sync.Signatures {
+		signatures, err := a.getSignatures(repository)
+		if err != nil {
+			return nil, err
+		}
+		for tag, digest := range signatures {
+			if t, exist := tagMetadata[tag]; exist {
+				t.SignedDigest = digest
+			}
+		}
+	}
+	return metadata, nil
+}
+
+// PutMetadata updates the description of the repository, attaches the labels to the
+// repository and tags and signs the tags whose digests match the signed ones
+func (a *adapter) PutMetadata(repository string, metadata *model.Metadata, sync *model.MetadataSync) error {
+	if sync == nil || metadata == nil {
+		return nil
+	}
+	if sync.Description {
+		desc := struct {
+			Description string `json:"description"`
+		}{
+			Description: metadata.Description,
+		}
+		if err := a.client.Put(fmt.Sprintf("%s/api/repositories/%s", a.getURL(), repository), desc); err != nil {
+			return err
+		}
+		log.Debugf("the description of repository %s updated", repository)
+	}
+	if sync.Labels {
+		if err := a.putLabels(repository, metadata); err != nil {
+			return err
+		}
+	}
+	if sync.Signatures {
+		var signed []*model.TagMetadata
+		for _, tag := range metadata.Tags {
+			if len(tag.SignedDigest) > 0 {
+				signed = append(signed, tag)
+			}
+		}
+		if len(signed) > 0 {
+			if err := a.signTags(repository, signed, sync.NotaryURL); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func convertLabels(labels []*label) []*model.Label {
+	var result []*model.Label
+	for _, l := range labels {
+		result = append(result, &model.Label{
+			Name:        l.Name,
+			Description: l.Description,
+			Color:       l.Color,
+		})
+	}
+	return result
+}
+
+func (a *adapter) getRepository(name string) (*repository, error) {
+	projectName := strings.SplitN(name, "/", 2)[0]
+	project, err := a.getProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project %s not found", projectName)
+	}
+	repositories := []*repository{}
+	endpoint := fmt.Sprintf("%s/api/repositories?project_id=%d&q=%s&page=1&page_size=500",
+		a.getURL(), project.ID, url.QueryEscape(name))
+	if err = a.client.GetAndIteratePagination(endpoint, &repositories); err != nil {
+		return nil, err
+	}
+	for _, repository := range repositories {
+		if repository.Name == name {
+			return repository, nil
+		}
+	}
+	return nil, nil
+}
+
+// getSignatures returns the digests of the signed tags of the repository
+func (a *adapter) getSignatures(repository string) (map[string]string, error) {
+	targets := []*struct {
+		Tag    string            `json:"tag"`
+		Hashes map[string][]byte `json:"hashes"`
+	}{}
+	if err := a.client.Get(fmt.Sprintf("%s/api/repositories/%s/signatures", a.getURL(), repository), &targets); err != nil {
+		return nil, err
+	}
+	signatures := map[string]string{}
+	for _, target := range targets {
+		hash, exist := target.Hashes["sha256"]
+		if !exist {
+			log.Warningf("no sha256 hash found in the signature of %s:%s, skip", repository, target.Tag)
+			continue
+		}
+		signatures[target.Tag] = "sha256:" + hex.EncodeToString(hash)
+	}
+	return signatures, nil
+}
+
+// attach the labels to the repository and tags, the labels are
+// created as project level ones if they don't exist
+func (a *adapter) putLabels(repository string, metadata *model.Metadata) error {
+	projectName := strings.SplitN(repository, "/", 2)[0]
+	project, err := a.getProject(projectName)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return fmt.Errorf("project %s not found", projectName)
+	}
+	// cache the IDs of the labels to avoid querying the same label repeatedly
+	ids := map[string]int64{}
+	getLabelID := func(l *model.Label) (int64, error) {
+		if id, exist := ids[l.Name]; exist {
+			return id, nil
+		}
+		id, err := a.ensureLabel(project.ID, l)
+		if err != nil {
+			return 0, err
+		}
+		ids[l.Name] = id
+		return id, nil
+	}
+
+	for _, l := range metadata.Labels {
+		id, err := getLabelID(l)
+		if err != nil {
+			return err
+		}
+		if err = a.attachLabel(fmt.Sprintf("%s/api/repositories/%s/labels", a.getURL(), repository), id); err != nil {
+			return err
+		}
+	}
+	for _, tag := range metadata.Tags {
+		for _, l := range tag.Labels {
+			id, err := getLabelID(l)
+			if err != nil {
+				return err
+			}
+			if err = a.attachLabel(fmt.Sprintf("%s/api/repositories/%s/tags/%s/labels", a.getURL(), repository, tag.Name), id); err != nil {
+				return err
+			}
+		}
+	}
+	log.Debugf("the labels of repository %s and its tags attached", repository)
+	return nil
+}
+
+// ensureLabel returns the ID of the global or project level label with the name,
+// a project level label is created if there is no such one
+func (a *adapter) ensureLabel(projectID int64, l *model.Label) (int64, error) {
+	lbl, err := a.getLabel(fmt.Sprintf("%s/api/labels?scope=g&name=%s", a.getURL(), url.QueryEscape(l.Name)), l.Name)
+	if err != nil {
+		return 0, err
+	}
+	if lbl != nil {
+		return lbl.ID, nil
+	}
+	projectLabelURL := fmt.Sprintf("%s/api/labels?scope=p&project_id=%d&name=%s", a.getURL(), projectID, url.QueryEscape(l.Name))
+	lbl, err = a.getLabel(projectLabelURL, l.Name)
+	if err != nil {
+		return 0, err
+	}
+	if lbl != nil {
+		return lbl.ID, nil
+	}
+
+	if err = a.client.Post(a.getURL()+"/api/labels", &label{
+		Name:        l.Name,
+		Description: l.Description,
+		Color:       l.Color,
+		Scope:       "p",
+		ProjectID:   projectID,
+	}); err != nil {
+		return 0, err
+	}
+	log.Debugf("the label %s created in project %d", l.Name, projectID)
+	lbl, err = a.getLabel(projectLabelURL, l.Name)
+	if err != nil {
+		return 0, err
+	}
+	if lbl == nil {
+		return 0, fmt.Errorf("label %s not found after creating it", l.Name)
+	}
+	return lbl.ID, nil
+}
+
+// the labels are matched by name fuzzily by the API, return the exactly matched one
+func (a *adapter) getLabel(endpoint, name string) (*label, error) {
+	labels := []*label{}
+	if err := a.client.GetAndIteratePagination(endpoint, &labels); err != nil {
+		return nil, err
+	}
+	for _, l := range labels {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+	return nil, nil
+}
+
+// attach the label to the resource, the conflict error is ignored as the label is attached already
+func (a *adapter) attachLabel(endpoint string, id int64) error {
+	err := a.client.Post(endpoint, struct {
+		ID int64 `json:"id"`
+	}{
+		ID: id,
+	})
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusConflict {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,270 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harbor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/utils/test"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theupdateframework/notary/cryptoservice"
+	"github.com/theupdateframework/notary/trustmanager"
+	"github.com/theupdateframework/notary/tuf/data"
+)
+
+func TestGetMetadata(t *testing.T) {
+	hash := sha256.Sum256([]byte("manifest"))
+	server := test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/projects",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[{"project_id":1,"name":"library"}]`))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/repositories/library/hello-world/signatures",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				data, _ := json.Marshal([]interface{}{
+					map[string]interface{}{
+						"tag": "1.0",
+						"hashes": map[string][]byte{
+							"sha256": hash[:],
+						},
+					},
+				})
+				w.Write(data)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/repositories/library/hello-world/tags",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[{"name":"1.0","labels":[{"name":"stable","color":"#FFFFFF"}]},{"name":"2.0"}]`))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/repositories",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[{"name":"library/hello-world","description":"the hello world image","labels":[{"name":"official"}]}]`))
+			},
+		},
+	)
+	defer server.Close()
+	adapter, err := newAdapter(&model.Registry{
+		URL: server.URL,
+	})
+	require.Nil(t, err)
+
+	// only description
+	metadata, err := adapter.GetMetadata("library/hello-world", []string{"1.0"}, &model.MetadataSync{
+		Description: true,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "the hello world image", metadata.Description)
+	assert.Equal(t, 0, len(metadata.Labels))
+	require.Equal(t, 1, len(metadata.Tags))
+	assert.Equal(t, 0, len(metadata.Tags[0].Labels))
+	assert.Equal(t, "", metadata.Tags[0].SignedDigest)
+
+	// all metadata
+	metadata, err = adapter.GetMetadata("library/hello-world", []string{"1.0", "2.0"}, &model.MetadataSync{
+		Labels:      true,
+		Description: true,
+		Signatures:  true,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "the hello world image", metadata.Description)
+	require.Equal(t, 1, len(metadata.Labels))
+	assert.Equal(t, "official", metadata.Labels[0].Name)
+	require.Equal(t, 2, len(metadata.Tags))
+	assert.Equal(t, "1.0", metadata.Tags[0].Name)
+	require.Equal(t, 1, len(metadata.Tags[0].Labels))
+	assert.Equal(t, "stable", metadata.Tags[0].Labels[0].Name)
+	assert.Equal(t, "#FFFFFF", metadata.Tags[0].Labels[0].Color)
+	assert.Equal(t, "sha256:"+hex.EncodeToString(hash[:]), metadata.Tags[0].SignedDigest)
+	assert.Equal(t, "2.0", metadata.Tags[1].Name)
+	assert.Equal(t, 0, len(metadata.Tags[1].Labels))
+	assert.Equal(t, "", metadata.Tags[1].SignedDigest)
+}
+
+func TestPutMetadata(t *testing.T) {
+	var description string
+	var created []*label
+	attached := map[string][]int64{}
+	attach := func(w http.ResponseWriter, r *http.Request) {
+		l := &label{}
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, l)
+		for _, id := range attached[r.URL.Path] {
+			if id == l.ID {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		attached[r.URL.Path] = append(attached[r.URL.Path], l.ID)
+		w.WriteHeader(http.StatusOK)
+	}
+	server := test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/projects",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[{"project_id":1,"name":"library"}]`))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/labels",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				labels := []*label{}
+				if r.URL.Query().Get("scope") == "g" {
+					// fuzzy matched
+					labels = append(labels, &label{ID: 1, Name: "official"}, &label{ID: 2, Name: "official-stable"})
+				} else {
+					labels = created
+				}
+				data, _ := json.Marshal(labels)
+				w.Write(data)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPost,
+			Pattern: "/api/labels",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				l := &label{}
+				data, _ := ioutil.ReadAll(r.Body)
+				json.Unmarshal(data, l)
+				l.ID = int64(100 + len(created))
+				created = append(created, l)
+				w.WriteHeader(http.StatusCreated)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPost,
+			Pattern: "/api/repositories/library/hello-world/tags/1.0/labels",
+			Handler: attach,
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPost,
+			Pattern: "/api/repositories/library/hello-world/labels",
+			Handler: attach,
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPut,
+			Pattern: "/api/repositories/library/hello-world",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				desc := &struct {
+					Description string `json:"description"`
+				}{}
+				data, _ := ioutil.ReadAll(r.Body)
+				json.Unmarshal(data, desc)
+				description = desc.Description
+			},
+		},
+	)
+	defer server.Close()
+	adapter, err := newAdapter(&model.Registry{
+		URL: server.URL,
+	})
+	require.Nil(t, err)
+
+	metadata := &model.Metadata{
+		Description: "the hello world image",
+		Labels: []*model.Label{
+			{Name: "official"},
+		},
+		Tags: []*model.TagMetadata{
+			{
+				Name: "1.0",
+				Labels: []*model.Label{
+					{Name: "official"},
+					{Name: "stable", Color: "#FFFFFF"},
+				},
+			},
+		},
+	}
+	sync := &model.MetadataSync{
+		Labels:      true,
+		Description: true,
+	}
+	err = adapter.PutMetadata("library/hello-world", metadata, sync)
+	require.Nil(t, err)
+	assert.Equal(t, "the hello world image", description)
+	// the missing label is created as a project level one
+	require.Equal(t, 1, len(created))
+	assert.Equal(t, "stable", created[0].Name)
+	assert.Equal(t, "#FFFFFF", created[0].Color)
+	assert.Equal(t, "p", created[0].Scope)
+	assert.Equal(t, int64(1), created[0].ProjectID)
+	assert.Equal(t, []int64{1}, attached["/api/repositories/library/hello-world/labels"])
+	assert.Equal(t, []int64{1, 100}, attached["/api/repositories/library/hello-world/tags/1.0/labels"])
+
+	// put again, the labels attached already are ignored
+	err = adapter.PutMetadata("library/hello-world", metadata, sync)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(created))
+	assert.Equal(t, []int64{1, 100}, attached["/api/repositories/library/hello-world/tags/1.0/labels"])
+}
+
+func TestSignTags(t *testing.T) {
+	server := test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/systeminfo",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"external_url":"https://harbor.example.com"}`))
+			},
+		},
+	)
+	defer server.Close()
+	adapter, err := newAdapter(&model.Registry{
+		URL: server.URL,
+	})
+	require.Nil(t, err)
+
+	// the GUN is prefixed with the external endpoint rather than the registry URL
+	gun, err := adapter.getGUN("library/hello-world")
+	require.Nil(t, err)
+	assert.Equal(t, "harbor.example.com/library/hello-world", gun.String())
+
+	tags := []*model.TagMetadata{{Name: "1.0", SignedDigest: "sha256:digest"}}
+	// the Notary server isn't specified
+	assert.NotNil(t, adapter.signTags("library/hello-world", tags, ""))
+	// the signing keys aren't configured
+	os.Unsetenv(trustDirEnv)
+	os.Unsetenv(trustPassphraseEnv)
+	assert.NotNil(t, adapter.signTags("library/hello-world", tags, "https://harbor.example.com:4443"))
+
+	// the root key isn't generated when it is missing
+	cs := cryptoservice.NewCryptoService(trustmanager.NewKeyMemoryStore(passRetriever("passphrase")))
+	_, err = getRootKeyID(cs)
+	assert.NotNil(t, err)
+	key, err := cs.Create(data.CanonicalRootRole, "", data.ECDSAKey)
+	require.Nil(t, err)
+	id, err := getRootKeyID(cs)
+	require.Nil(t, err)
+	assert.Equal(t, key.ID(), id)
+}
@@ -0,0 +1,209 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harbor
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/util"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/theupdateframework/notary"
+	"github.com/theupdateframework/notary/client"
+	"github.com/theupdateframework/notary/trustpinning"
+	"github.com/theupdateframework/notary/tuf/data"
+	"github.com/theupdateframework/notary/tuf/signed"
+)
+
+// the environment variables configuring the signing keys, which are generated and managed by the
+// operators rather than Harbor. The root key and the keys of the targets role of the existing
+// repositories are stored under the "private" sub-directory of the trust directory
+const (
+	trustDirEnv        = "REPLICATION_TRUST_DIR"
+	trustPassphraseEnv = "REPLICATION_TRUST_PASSPHRASE"
+)
+
+// notaryAuthorizer adds the token issued by the token service of Harbor to the requests sent to Notary
+type notaryAuthorizer struct {
+	token string
+}
+
+func (n *notaryAuthorizer) Modify(req *http.Request) error {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", n.token))
+	return nil
+}
+
+// signTags signs the tags on the specified Notary server of the destination Harbor with the keys stored
+// in the configured trust directory. If the repository doesn't exist in Notary, it's initialized with
+// the root key in the trust directory. Otherwise the key of its targets role must exist in the trust
+// directory. The tags whose digests don't match the signed ones are skipped
+func (a *adapter) signTags(repository string, tags []*model.TagMetadata, notaryURL string) error {
+	if len(notaryURL) == 0 {
+		return errors.New("the Notary server of the destination registry isn't specified")
+	}
+	trustDir := os.Getenv(trustDirEnv)
+	passphrase := os.Getenv(trustPassphraseEnv)
+	if len(trustDir) == 0 || len(passphrase) == 0 {
+		return fmt.Errorf("the signing keys aren't configured, both %s and %s must be set", trustDirEnv, trustPassphraseEnv)
+	}
+	gun, err := a.getGUN(repository)
+	if err != nil {
+		return err
+	}
+	token, err := a.getNotaryToken(gun)
+	if err != nil {
+		return fmt.Errorf("failed to get the token for Notary: %v", err)
+	}
+	transport := registry.NewTransport(util.GetHTTPTransport(a.registry.Insecure), &notaryAuthorizer{
+		token: token,
+	})
+	repo, err := client.NewFileCachedRepository(trustDir, gun, notaryURL, transport,
+		passRetriever(passphrase), trustpinning.TrustPinConfig{})
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, tag := range tags {
+		target, err := a.buildTarget(repository, tag)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			continue
+		}
+		if err = repo.AddTarget(target, data.CanonicalTargetsRole); err != nil {
+			return err
+		}
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+
+	err = repo.Publish()
+	if _, ok := err.(client.ErrRepoNotInitialized); ok {
+		rootKeyID, e := getRootKeyID(repo.GetCryptoService())
+		if e != nil {
+			return e
+		}
+		// the snapshot key is managed by the Notary server
+		if e = repo.Initialize([]string{rootKeyID}, data.CanonicalSnapshotRole); e != nil {
+			return e
+		}
+		err = repo.Publish()
+	}
+	if err != nil {
+		return err
+	}
+	log.Debugf("%d tags of repository %s signed", count, repository)
+	return nil
+}
+
+// build the Notary target for the tag from its manifest on the registry, nil is
+// returned if the digest of the manifest doesn't match the signed one
+func (a *adapter) buildTarget(repository string, tag *model.TagMetadata) (*client.Target, error) {
+	manifest, _, err := a.PullManifest(repository, tag.Name, []string{
+		schema1.MediaTypeManifest,
+		schema1.MediaTypeSignedManifest,
+		schema2.MediaTypeManifest,
+		manifestlist.MediaTypeManifestList,
+		v1.MediaTypeImageManifest,
+		v1.MediaTypeImageIndex,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, payload, err := manifest.Payload()
+	if err != nil {
+		return nil, err
+	}
+	dgt := digest.FromBytes(payload)
+	if dgt.String() != tag.SignedDigest {
+		log.Warningf("the digest %s of %s:%s doesn't match the signed one %s, skip signing it",
+			dgt.String(), repository, tag.Name, tag.SignedDigest)
+		return nil, nil
+	}
+	hash, err := hex.DecodeString(dgt.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return &client.Target{
+		Name: tag.Name,
+		Hashes: data.Hashes{
+			"sha256": hash,
+		},
+		Length: int64(len(payload)),
+	}, nil
+}
+
+// the GUN is the repository name prefixed with the host of the external URL of the destination
+// Harbor, which is the one that the content trust of Harbor checks the signatures against
+func (a *adapter) getGUN(repository string) (data.GUN, error) {
+	sys := &struct {
+		ExternalURL string `json:"external_url"`
+	}{}
+	if err := a.client.Get(a.getURL()+"/api/systeminfo", sys); err != nil {
+		return "", err
+	}
+	u, err := url.Parse(sys.ExternalURL)
+	if err != nil || len(u.Host) == 0 {
+		return "", fmt.Errorf("invalid external URL of the destination registry: %s", sys.ExternalURL)
+	}
+	return data.GUN(u.Host + "/" + repository), nil
+}
+
+// get the token with push permission of the repository from the token service of Harbor
+func (a *adapter) getNotaryToken(gun data.GUN) (string, error) {
+	token := &struct {
+		Token string `json:"token"`
+	}{}
+	endpoint := fmt.Sprintf("%s/service/token?service=harbor-notary&scope=repository:%s:pull,push",
+		a.getURL(), url.QueryEscape(gun.String()))
+	if err := a.client.Get(endpoint, token); err != nil {
+		return "", err
+	}
+	return token.Token, nil
+}
+
+// returns the ID of the root key in the trust directory, the key isn't generated as it must be
+// backed up by the operators to keep the trust data of the repositories
+func getRootKeyID(cs signed.CryptoService) (string, error) {
+	keys := cs.ListKeys(data.CanonicalRootRole)
+	if len(keys) == 0 {
+		return "", errors.New("no root key found in the trust directory")
+	}
+	return keys[0], nil
+}
+
+func passRetriever(passphrase string) notary.PassRetriever {
+	return func(keyName, alias string, createNew bool, attempts int) (string, bool, error) {
+		if attempts > 0 {
+			return "", true, fmt.Errorf("the passphrase of the %s key is invalid", alias)
+		}
+		return passphrase, false, nil
+	}
+}
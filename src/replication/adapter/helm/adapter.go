@@ -0,0 +1,133 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/http/modifier"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry/auth"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/util"
+)
+
+func init() {
+	for _, t := range []model.RegistryType{model.RegistryTypeChartMuseum, model.RegistryTypeHelmRepo} {
+		registryType := t
+		if err := adp.RegisterFactory(registryType, func(registry *model.Registry) (adp.Adapter, error) {
+			return newAdapter(registryType, registry)
+		}); err != nil {
+			log.Errorf("failed to register factory for %s: %v", registryType, err)
+			continue
+		}
+		log.Infof("the factory for adapter %s registered", registryType)
+	}
+}
+
+// adapter for the Helm chart repositories. The charts of any repository serving the "index.yaml"
+// can be pulled, and the charts can be pushed to or deleted from the ChartMuseum only.
+// The chart is named as "[<path>/]<chart name>", the path is the tenant of the multi-tenant
+// ChartMuseum or the sub path of the chart repository which contains its own "index.yaml"
+type adapter struct {
+	registryType model.RegistryType
+	registry     *model.Registry
+	url          string
+	client       *common_http.Client
+}
+
+var _ adp.Adapter = (*adapter)(nil)
+var _ adp.ChartRegistry = (*adapter)(nil)
+
+func newAdapter(registryType model.RegistryType, registry *model.Registry) (*adapter, error) {
+	modifiers := []modifier.Modifier{
+		&auth.UserAgentModifier{
+			UserAgent: adp.UserAgentReplication,
+		},
+	}
+	if registry.Credential != nil && len(registry.Credential.AccessSecret) != 0 {
+		modifiers = append(modifiers, auth.NewBasicAuthCredential(
+			registry.Credential.AccessKey,
+			registry.Credential.AccessSecret))
+	}
+	return &adapter{
+		registryType: registryType,
+		registry:     registry,
+		url:          strings.TrimSuffix(registry.URL, "/"),
+		client: common_http.NewClient(
+			&http.Client{
+				Transport: util.GetHTTPTransport(registry.Insecure),
+			}, modifiers...),
+	}, nil
+}
+
+// Info returns information of the registry
+func (a *adapter) Info() (*model.RegistryInfo, error) {
+	return &model.RegistryInfo{
+		Type: a.registryType,
+		SupportedResourceTypes: []model.ResourceType{
+			model.ResourceTypeChart,
+		},
+		SupportedResourceFilters: []*model.FilterStyle{
+			{
+				Type:  model.FilterTypeName,
+				Style: model.FilterStyleTypeText,
+			},
+			{
+				Type:  model.FilterTypeTag,
+				Style: model.FilterStyleTypeText,
+			},
+		},
+		SupportedTriggers: []model.TriggerType{
+			model.TriggerTypeManual,
+			model.TriggerTypeScheduled,
+		},
+	}, nil
+}
+
+// HealthCheck checks the health endpoint of ChartMuseum or the index file of the chart repository
+func (a *adapter) HealthCheck() (model.HealthStatus, error) {
+	url := a.url + "/index.yaml"
+	if a.registryType == model.RegistryTypeChartMuseum {
+		url = a.url + "/health"
+	}
+	if err := a.client.Get(url); err != nil {
+		log.Errorf("failed to ping registry %s: %v", a.registry.URL, err)
+		return model.Unhealthy, nil
+	}
+	return model.Healthy, nil
+}
+
+// PrepareForPush checks whether the charts can be pushed, the tenants of ChartMuseum
+// are created automatically when uploading
+func (a *adapter) PrepareForPush(resources []*model.Resource) error {
+	if a.registryType != model.RegistryTypeChartMuseum {
+		return fmt.Errorf("the registry of type %s is read-only", a.registryType)
+	}
+	for _, resource := range resources {
+		if resource == nil {
+			return errors.New("the resource cannot be null")
+		}
+		if resource.Type != model.ResourceTypeChart {
+			return fmt.Errorf("the resource type %s isn't supported", resource.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/utils/test"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const indexYAML = `apiVersion: v1
+entries:
+  nginx:
+  - name: nginx
+    version: 1.1.0
+    urls:
+    - charts/nginx-1.1.0.tgz
+  - name: nginx
+    version: 1.0.0
+    urls:
+    - %s/archive/nginx-1.0.0.tgz
+  redis:
+  - name: redis
+    version: 2.0.0
+    urls:
+    - charts/redis-2.0.0.tgz
+`
+
+// fakeChartMuseum serves the index files of the root and the tenant "library" and
+// records the charts uploaded and deleted
+type fakeChartMuseum struct {
+	uploaded map[string]string
+	deleted  []string
+}
+
+func (f *fakeChartMuseum) server() *httptest.Server {
+	f.uploaded = map[string]string{}
+	var server *httptest.Server
+	server = test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/health",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"healthy":true}`))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPost,
+			Pattern: "/api/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				if _, exist := r.URL.Query()["force"]; !exist {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				data, _ := ioutil.ReadAll(r.Body)
+				f.uploaded[r.URL.Path] = string(data)
+				w.WriteHeader(http.StatusCreated)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodDelete,
+			Pattern: "/api/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				f.deleted = append(f.deleted, r.URL.Path)
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/index.yaml" || r.URL.Path == "/library/index.yaml":
+					w.Write([]byte(strings.Replace(indexYAML, "%s", server.URL, -1)))
+				case strings.HasSuffix(r.URL.Path, ".tgz"):
+					w.Write([]byte("package " + r.URL.Path))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		},
+	)
+	return server
+}
+
+func TestAdapter_NewAdapter(t *testing.T) {
+	for _, registryType := range []model.RegistryType{model.RegistryTypeChartMuseum, model.RegistryTypeHelmRepo} {
+		factory, err := adp.GetFactory(registryType)
+		require.Nil(t, err)
+		adapter, err := factory(&model.Registry{
+			Type: registryType,
+			URL:  "https://charts.example.com",
+		})
+		require.Nil(t, err)
+		info, err := adapter.Info()
+		require.Nil(t, err)
+		assert.Equal(t, registryType, info.Type)
+		assert.Equal(t, []model.ResourceType{model.ResourceTypeChart}, info.SupportedResourceTypes)
+	}
+}
+
+func TestAdapter_HealthCheck(t *testing.T) {
+	fake := &fakeChartMuseum{}
+	server := fake.server()
+	defer server.Close()
+
+	for _, registryType := range []model.RegistryType{model.RegistryTypeChartMuseum, model.RegistryTypeHelmRepo} {
+		a, err := newAdapter(registryType, &model.Registry{URL: server.URL})
+		require.Nil(t, err)
+		status, err := a.HealthCheck()
+		require.Nil(t, err)
+		assert.Equal(t, model.Healthy, string(status))
+	}
+
+	a, err := newAdapter(model.RegistryTypeHelmRepo, &model.Registry{URL: server.URL + "/unknown"})
+	require.Nil(t, err)
+	status, err := a.HealthCheck()
+	require.Nil(t, err)
+	assert.Equal(t, model.Unhealthy, string(status))
+}
+
+func TestAdapter_PrepareForPush(t *testing.T) {
+	resources := []*model.Resource{
+		{
+			Type: model.ResourceTypeChart,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "library/nginx",
+				},
+			},
+		},
+	}
+	a, err := newAdapter(model.RegistryTypeChartMuseum, &model.Registry{URL: "http://chartmuseum"})
+	require.Nil(t, err)
+	assert.Nil(t, a.PrepareForPush(resources))
+
+	// the static chart repository is read-only
+	a, err = newAdapter(model.RegistryTypeHelmRepo, &model.Registry{URL: "http://charts"})
+	require.Nil(t, err)
+	assert.NotNil(t, a.PrepareForPush(resources))
+}
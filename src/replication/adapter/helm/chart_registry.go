@@ -0,0 +1,237 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	common_http "github.com/goharbor/harbor/src/common/http"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/util"
+	helm_repo "k8s.io/helm/pkg/repo"
+)
+
+// FetchCharts parses the index file of the chart repository and returns the charts matching the filters
+func (a *adapter) FetchCharts(filters []*model.Filter) ([]*model.Resource, error) {
+	index, err := a.getIndex("")
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		return nil, fmt.Errorf("the index file of chart repository %s not found", a.registry.URL)
+	}
+	names := []string{}
+	for name := range index.Entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	repositories := []*adp.Repository{}
+	for _, name := range names {
+		repositories = append(repositories, &adp.Repository{
+			ResourceType: string(model.ResourceTypeChart),
+			Name:         name,
+		})
+	}
+	for _, filter := range filters {
+		if err = filter.DoFilter(&repositories); err != nil {
+			return nil, err
+		}
+	}
+
+	resources := []*model.Resource{}
+	for _, repository := range repositories {
+		vTags := []*adp.VTag{}
+		for _, version := range index.Entries[repository.Name] {
+			vTags = append(vTags, &adp.VTag{
+				ResourceType: string(model.ResourceTypeChart),
+				Name:         version.Version,
+				PushTime:     version.Created,
+			})
+		}
+		for _, filter := range filters {
+			if err = filter.DoFilter(&vTags); err != nil {
+				return nil, err
+			}
+		}
+		for _, vTag := range vTags {
+			resources = append(resources, &model.Resource{
+				Type:     model.ResourceTypeChart,
+				Registry: a.registry,
+				Metadata: &model.ResourceMetadata{
+					Repository: &model.Repository{
+						Name: repository.Name,
+					},
+					Vtags: []string{vTag.Name},
+				},
+			})
+		}
+	}
+	return resources, nil
+}
+
+// ChartExist checks whether the chart exists in the index file
+func (a *adapter) ChartExist(name, version string) (bool, error) {
+	chart, err := a.getChartVersion(name, version)
+	if err != nil {
+		return false, err
+	}
+	return chart != nil, nil
+}
+
+// DownloadChart downloads the chart package from the URL recorded in the index file
+func (a *adapter) DownloadChart(name, version string) (io.ReadCloser, error) {
+	chart, err := a.getChartVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+	if chart == nil {
+		return nil, fmt.Errorf("chart %s:%s not found", name, version)
+	}
+	if len(chart.URLs) == 0 || len(chart.URLs[0]) == 0 {
+		return nil, fmt.Errorf("cannot get the download url for chart %s:%s", name, version)
+	}
+	url := chart.URLs[0]
+	// relative URL
+	if lower := strings.ToLower(url); !(strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")) {
+		path, _ := util.ParseRepository(name)
+		url = fmt.Sprintf("%s/%s", a.getRepositoryURL(path), strings.TrimPrefix(url, "/"))
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.send(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// UploadChart uploads the chart package through the API of ChartMuseum, the existing
+// one is overwritten as the transfer only uploads the chart when overriding is allowed
+func (a *adapter) UploadChart(name, version string, chart io.Reader) error {
+	if a.registryType != model.RegistryTypeChartMuseum {
+		return fmt.Errorf("uploading chart isn't supported by the registry of type %s", a.registryType)
+	}
+	path, _ := util.ParseRepository(name)
+	req, err := http.NewRequest(http.MethodPost, a.getAPIURL(path)+"/charts?force", chart)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := a.send(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// DeleteChart deletes the chart through the API of ChartMuseum
+func (a *adapter) DeleteChart(name, version string) error {
+	if a.registryType != model.RegistryTypeChartMuseum {
+		return fmt.Errorf("deleting chart isn't supported by the registry of type %s", a.registryType)
+	}
+	path, chart := util.ParseRepository(name)
+	if len(chart) == 0 {
+		return errors.New("the name of chart cannot be empty")
+	}
+	return a.client.Delete(fmt.Sprintf("%s/charts/%s/%s", a.getAPIURL(path), chart, version))
+}
+
+// getIndex returns the index file under the path, nil is returned if it doesn't exist
+func (a *adapter) getIndex(path string) (*helm_repo.IndexFile, error) {
+	req, err := http.NewRequest(http.MethodGet, a.getRepositoryURL(path)+"/index.yaml", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.send(req)
+	if err != nil {
+		if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	index := helm_repo.NewIndexFile()
+	if err = yaml.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse the index file of chart repository %s: %v", a.getRepositoryURL(path), err)
+	}
+	return index, nil
+}
+
+// getChartVersion returns the specified version of chart, nil is returned if it doesn't exist
+func (a *adapter) getChartVersion(name, version string) (*helm_repo.ChartVersion, error) {
+	path, chartName := util.ParseRepository(name)
+	if len(chartName) == 0 {
+		return nil, errors.New("the name of chart cannot be empty")
+	}
+	index, err := a.getIndex(path)
+	if err != nil || index == nil {
+		return nil, err
+	}
+	for _, chart := range index.Entries[chartName] {
+		if chart.Version == version {
+			return chart, nil
+		}
+	}
+	return nil, nil
+}
+
+func (a *adapter) getRepositoryURL(path string) string {
+	if len(path) == 0 {
+		return a.url
+	}
+	return a.url + "/" + path
+}
+
+func (a *adapter) getAPIURL(path string) string {
+	if len(path) == 0 {
+		return a.url + "/api"
+	}
+	return a.url + "/api/" + path
+}
+
+// send the request and return the error if the response status isn't 2xx
+func (a *adapter) send(req *http.Request) (*http.Response, error) {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &common_http.Error{
+			Code:    resp.StatusCode,
+			Message: string(data),
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,136 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCharts(t *testing.T) {
+	fake := &fakeChartMuseum{}
+	server := fake.server()
+	defer server.Close()
+	a, err := newAdapter(model.RegistryTypeHelmRepo, &model.Registry{URL: server.URL})
+	require.Nil(t, err)
+
+	resources, err := a.FetchCharts(nil)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(resources))
+	assert.Equal(t, model.ResourceTypeChart, resources[0].Type)
+	assert.Equal(t, "nginx", resources[0].Metadata.Repository.Name)
+	assert.Equal(t, []string{"1.1.0"}, resources[0].Metadata.Vtags)
+	assert.Equal(t, "redis", resources[2].Metadata.Repository.Name)
+
+	resources, err = a.FetchCharts([]*model.Filter{
+		{
+			Type:  model.FilterTypeName,
+			Value: "nginx",
+		},
+		{
+			Type:  model.FilterTypeTag,
+			Value: "1.0.*",
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(resources))
+	assert.Equal(t, []string{"1.0.0"}, resources[0].Metadata.Vtags)
+
+	// the index file doesn't exist
+	a, err = newAdapter(model.RegistryTypeHelmRepo, &model.Registry{URL: server.URL + "/unknown"})
+	require.Nil(t, err)
+	_, err = a.FetchCharts(nil)
+	assert.NotNil(t, err)
+}
+
+func TestChartExist(t *testing.T) {
+	fake := &fakeChartMuseum{}
+	server := fake.server()
+	defer server.Close()
+	a, err := newAdapter(model.RegistryTypeChartMuseum, &model.Registry{URL: server.URL})
+	require.Nil(t, err)
+
+	exist, err := a.ChartExist("nginx", "1.0.0")
+	require.Nil(t, err)
+	assert.True(t, exist)
+
+	// the chart under the tenant
+	exist, err = a.ChartExist("library/nginx", "1.1.0")
+	require.Nil(t, err)
+	assert.True(t, exist)
+
+	exist, err = a.ChartExist("nginx", "2.0.0")
+	require.Nil(t, err)
+	assert.False(t, exist)
+
+	// the tenant doesn't exist
+	exist, err = a.ChartExist("unknown/nginx", "1.0.0")
+	require.Nil(t, err)
+	assert.False(t, exist)
+}
+
+func TestDownloadChart(t *testing.T) {
+	fake := &fakeChartMuseum{}
+	server := fake.server()
+	defer server.Close()
+	a, err := newAdapter(model.RegistryTypeHelmRepo, &model.Registry{URL: server.URL})
+	require.Nil(t, err)
+
+	// relative URL
+	reader, err := a.DownloadChart("library/nginx", "1.1.0")
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(reader)
+	reader.Close()
+	require.Nil(t, err)
+	assert.Equal(t, "package /library/charts/nginx-1.1.0.tgz", string(data))
+
+	// absolute URL
+	reader, err = a.DownloadChart("nginx", "1.0.0")
+	require.Nil(t, err)
+	data, err = ioutil.ReadAll(reader)
+	reader.Close()
+	require.Nil(t, err)
+	assert.Equal(t, "package /archive/nginx-1.0.0.tgz", string(data))
+
+	_, err = a.DownloadChart("nginx", "2.0.0")
+	assert.NotNil(t, err)
+}
+
+func TestUploadAndDeleteChart(t *testing.T) {
+	fake := &fakeChartMuseum{}
+	server := fake.server()
+	defer server.Close()
+
+	// the static chart repository is read-only
+	a, err := newAdapter(model.RegistryTypeHelmRepo, &model.Registry{URL: server.URL})
+	require.Nil(t, err)
+	assert.NotNil(t, a.UploadChart("nginx", "1.2.0", bytes.NewReader([]byte("chart"))))
+	assert.NotNil(t, a.DeleteChart("nginx", "1.0.0"))
+
+	a, err = newAdapter(model.RegistryTypeChartMuseum, &model.Registry{URL: server.URL})
+	require.Nil(t, err)
+	require.Nil(t, a.UploadChart("nginx", "1.2.0", bytes.NewReader([]byte("chart"))))
+	require.Nil(t, a.UploadChart("library/nginx", "1.2.0", bytes.NewReader([]byte("tenant chart"))))
+	assert.Equal(t, "chart", fake.uploaded["/api/charts"])
+	assert.Equal(t, "tenant chart", fake.uploaded["/api/library/charts"])
+
+	require.Nil(t, a.DeleteChart("library/nginx", "1.0.0"))
+	assert.Equal(t, []string{"/api/library/charts/nginx/1.0.0"}, fake.deleted)
+}
@@ -21,7 +21,9 @@ import (
 	"sync"
 
 	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
 	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
 	"github.com/goharbor/harbor/src/common/http/modifier"
 	common_http_auth "github.com/goharbor/harbor/src/common/http/modifier/auth"
 	"github.com/goharbor/harbor/src/common/utils/log"
@@ -30,8 +32,20 @@ import (
 	adp "github.com/goharbor/harbor/src/replication/adapter"
 	"github.com/goharbor/harbor/src/replication/model"
 	"github.com/goharbor/harbor/src/replication/util"
+	"github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+// the manifest lists and OCI image indexes are accepted when checking the existence
+// of manifest to get their own digests rather than the ones of the default platforms
+var manifestExistAcceptedMediaTypes = []string{
+	schema1.MediaTypeManifest,
+	schema1.MediaTypeSignedManifest,
+	schema2.MediaTypeManifest,
+	manifestlist.MediaTypeManifestList,
+	v1.MediaTypeImageManifest,
+	v1.MediaTypeImageIndex,
+}
+
 func init() {
 	if err := adp.RegisterFactory(model.RegistryTypeDockerRegistry, func(registry *model.Registry) (adp.Adapter, error) {
 		return NewAdapter(registry)
@@ -43,6 +57,8 @@ func init() {
 }
 
 var _ adp.Adapter = &Adapter{}
+var _ adp.ChunkedBlobRegistry = &Adapter{}
+var _ adp.BlobMounter = &Adapter{}
 
 // Adapter implements an adapter for Docker registry. It can be used to all registries
 // that implement the registry V2 API
@@ -198,7 +214,7 @@ func (a *Adapter) FetchImages(filters []*model.Filter) ([]*model.Resource, error
 func (a *Adapter) getRepositories(filters []*model.Filter) ([]*adp.Repository, error) {
 	pattern := ""
 	for _, filter := range filters {
-		if filter.Type == model.FilterTypeName {
+		if filter.Type == model.FilterTypeName && !filter.IsExclusion() {
 			pattern = filter.Value.(string)
 			break
 		}
@@ -248,7 +264,7 @@ func (a *Adapter) ManifestExist(repository, reference string) (bool, string, err
 	if err != nil {
 		return false, "", err
 	}
-	digest, exist, err := client.ManifestExist(reference)
+	digest, exist, err := client.ManifestExist(reference, manifestExistAcceptedMediaTypes...)
 	return exist, digest, err
 }
 
@@ -290,7 +306,7 @@ func (a *Adapter) DeleteManifest(repository, reference string) error {
 	}
 	digest := reference
 	if !isDigest(digest) {
-		dgt, exist, err := client.ManifestExist(reference)
+		dgt, exist, err := client.ManifestExist(reference, manifestExistAcceptedMediaTypes...)
 		if err != nil {
 			return err
 		}
@@ -330,6 +346,51 @@ func (a *Adapter) PushBlob(repository, digest string, size int64, blob io.Reader
 	return client.PushBlob(digest, size, blob)
 }
 
+// PullBlobChunk ...
+func (a *Adapter) PullBlobChunk(repository, digest string, blobSize, start, end int64) (int64, io.ReadCloser, error) {
+	client, err := a.getClient(repository)
+	if err != nil {
+		return 0, nil, err
+	}
+	return client.PullBlobChunk(digest, blobSize, start, end)
+}
+
+// InitiateBlobUpload ...
+func (a *Adapter) InitiateBlobUpload(repository string) (string, error) {
+	client, err := a.getClient(repository)
+	if err != nil {
+		return "", err
+	}
+	return client.InitiateBlobUpload()
+}
+
+// GetBlobUploadStatus ...
+func (a *Adapter) GetBlobUploadStatus(repository, location string) (string, int64, error) {
+	client, err := a.getClient(repository)
+	if err != nil {
+		return "", 0, err
+	}
+	return client.GetBlobUploadStatus(location)
+}
+
+// PushBlobChunk ...
+func (a *Adapter) PushBlobChunk(repository, location string, offset, size int64, chunk io.Reader) (string, int64, error) {
+	client, err := a.getClient(repository)
+	if err != nil {
+		return "", 0, err
+	}
+	return client.PushBlobChunk(location, offset, size, chunk)
+}
+
+// CompleteBlobUpload ...
+func (a *Adapter) CompleteBlobUpload(repository, location, digest string) error {
+	client, err := a.getClient(repository)
+	if err != nil {
+		return err
+	}
+	return client.CompleteBlobUpload(location, digest)
+}
+
 func isDigest(str string) bool {
 	return strings.Contains(str, ":")
 }
@@ -343,6 +404,15 @@ func (a *Adapter) ListTag(repository string) ([]string, error) {
 	return client.ListTag()
 }
 
+// MountBlob ...
+func (a *Adapter) MountBlob(repository, digest, from string) (bool, error) {
+	client, err := a.getClient(repository)
+	if err != nil {
+		return false, err
+	}
+	return client.MountBlob(digest, from)
+}
+
 func (a *Adapter) getClient(repository string) (*registry_pkg.Repository, error) {
 	a.RLock()
 	client, exist := a.clients[repository]
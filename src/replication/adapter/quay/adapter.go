@@ -0,0 +1,267 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/http/modifier"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry/auth"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/adapter/native"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/util"
+)
+
+const (
+	// the user name used together with an OAuth access token when talking to the registry API
+	oauthTokenUsername = "$oauthtoken"
+	// the robot account is named as "<namespace>+<robot name>"
+	robotSeparator = "+"
+)
+
+func init() {
+	if err := adp.RegisterFactory(model.RegistryTypeQuay, func(registry *model.Registry) (adp.Adapter, error) {
+		return newAdapter(registry)
+	}); err != nil {
+		log.Errorf("failed to register factory for %s: %v", model.RegistryTypeQuay, err)
+		return
+	}
+	log.Infof("the factory for adapter %s registered", model.RegistryTypeQuay)
+}
+
+type adapter struct {
+	*native.Adapter
+	registry *model.Registry
+	url      string
+	client   *common_http.Client
+}
+
+var _ adp.Adapter = (*adapter)(nil)
+
+// newAdapter creates the adapter for Quay.io or Red Hat Quay. Two kinds of credential are supported:
+// the user name/password (including the robot account "<namespace>+<robot name>" and its token) and
+// the OAuth access token of an application, which is set as the access secret of the "oauth" credential
+func newAdapter(registry *model.Registry) (*adapter, error) {
+	modifiers := []modifier.Modifier{
+		&auth.UserAgentModifier{
+			UserAgent: adp.UserAgentReplication,
+		},
+	}
+	var credential auth.Credential
+	if registry.Credential != nil && len(registry.Credential.AccessSecret) != 0 {
+		if registry.Credential.Type == model.CredentialTypeOAuth {
+			credential = auth.NewBasicAuthCredential(oauthTokenUsername, registry.Credential.AccessSecret)
+			modifiers = append(modifiers, &bearerTokenModifier{token: registry.Credential.AccessSecret})
+		} else {
+			credential = auth.NewBasicAuthCredential(
+				registry.Credential.AccessKey,
+				registry.Credential.AccessSecret)
+			modifiers = append(modifiers, credential)
+		}
+	}
+	authorizer := auth.NewStandardTokenAuthorizer(&http.Client{
+		Transport: util.GetHTTPTransport(registry.Insecure),
+	}, credential)
+
+	dockerRegistryAdapter, err := native.NewAdapterWithCustomizedAuthorizer(registry, authorizer)
+	if err != nil {
+		return nil, err
+	}
+	return &adapter{
+		Adapter:  dockerRegistryAdapter,
+		registry: registry,
+		url:      strings.TrimSuffix(registry.URL, "/"),
+		client: common_http.NewClient(
+			&http.Client{
+				Transport: util.GetHTTPTransport(registry.Insecure),
+			}, modifiers...),
+	}, nil
+}
+
+// bearerTokenModifier sets the OAuth access token into the requests sent to Quay API
+type bearerTokenModifier struct {
+	token string
+}
+
+func (b *bearerTokenModifier) Modify(req *http.Request) error {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", b.token))
+	return nil
+}
+
+// Info returns information of the registry
+func (a *adapter) Info() (*model.RegistryInfo, error) {
+	return &model.RegistryInfo{
+		Type: model.RegistryTypeQuay,
+		SupportedResourceTypes: []model.ResourceType{
+			model.ResourceTypeImage,
+		},
+		SupportedResourceFilters: []*model.FilterStyle{
+			{
+				Type:  model.FilterTypeName,
+				Style: model.FilterStyleTypeText,
+			},
+			{
+				Type:  model.FilterTypeTag,
+				Style: model.FilterStyleTypeText,
+			},
+		},
+		SupportedTriggers: []model.TriggerType{
+			model.TriggerTypeManual,
+			model.TriggerTypeScheduled,
+		},
+	}, nil
+}
+
+// PrepareForPush creates the repositories which don't exist on Quay
+func (a *adapter) PrepareForPush(resources []*model.Resource) error {
+	repositories := map[string]struct{}{}
+	for _, resource := range resources {
+		if resource == nil {
+			return errors.New("the resource cannot be null")
+		}
+		if resource.Metadata == nil {
+			return errors.New("the metadata of resource cannot be null")
+		}
+		if resource.Metadata.Repository == nil {
+			return errors.New("the repository of resource cannot be null")
+		}
+		if len(resource.Metadata.Repository.Name) == 0 {
+			return errors.New("the name of the repository cannot be null")
+		}
+		repositories[resource.Metadata.Repository.Name] = struct{}{}
+	}
+
+	for repository := range repositories {
+		if err := a.createRepository(repository); err != nil {
+			return fmt.Errorf("failed to create repository %s on Quay: %v", repository, err)
+		}
+	}
+	return nil
+}
+
+// FetchImages lists the repositories of the candidate namespaces through Quay API and
+// returns the images matching the filters
+func (a *adapter) FetchImages(filters []*model.Filter) ([]*model.Resource, error) {
+	namespaces, err := a.listCandidateNamespaces(filters)
+	if err != nil {
+		return nil, err
+	}
+	repositories := []*adp.Repository{}
+	for _, namespace := range namespaces {
+		repos, err := a.listRepositories(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			repositories = append(repositories, &adp.Repository{
+				ResourceType: string(model.ResourceTypeImage),
+				Name:         fmt.Sprintf("%s/%s", repo.Namespace, repo.Name),
+			})
+		}
+	}
+	for _, filter := range filters {
+		if err = filter.DoFilter(&repositories); err != nil {
+			return nil, err
+		}
+	}
+
+	resources := []*model.Resource{}
+	for _, repository := range repositories {
+		tags, err := a.ListTag(repository.Name)
+		if err != nil {
+			return nil, err
+		}
+		vTags := []*adp.VTag{}
+		for _, tag := range tags {
+			vTags = append(vTags, &adp.VTag{
+				ResourceType: string(model.ResourceTypeImage),
+				Name:         tag,
+			})
+		}
+		for _, filter := range filters {
+			if err = filter.DoFilter(&vTags); err != nil {
+				return nil, err
+			}
+		}
+		if len(vTags) == 0 {
+			continue
+		}
+		tags = []string{}
+		for _, vTag := range vTags {
+			tags = append(tags, vTag.Name)
+		}
+		resources = append(resources, &model.Resource{
+			Type:     model.ResourceTypeImage,
+			Registry: a.registry,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: repository.Name,
+				},
+				Vtags: tags,
+			},
+		})
+	}
+	return resources, nil
+}
+
+// listCandidateNamespaces returns the namespaces parsed from the name filter if it specifies
+// the namespaces explicitly, otherwise returns the namespace of the robot account or the
+// namespaces of the user and the organizations the user belongs to
+func (a *adapter) listCandidateNamespaces(filters []*model.Filter) ([]string, error) {
+	pattern := ""
+	for _, filter := range filters {
+		if filter.Type == model.FilterTypeName && !filter.IsExclusion() {
+			pattern = filter.Value.(string)
+			break
+		}
+	}
+	if len(pattern) > 0 {
+		substrings := strings.Split(pattern, "/")
+		if namespaces, ok := util.IsSpecificPathComponent(substrings[0]); ok {
+			log.Debugf("parsed the namespaces %v from pattern %s", namespaces, pattern)
+			return namespaces, nil
+		}
+	}
+
+	credential := a.registry.Credential
+	if credential == nil || len(credential.AccessSecret) == 0 {
+		return nil, errors.New("the namespace must be specified in the name filter when no credential is provided")
+	}
+	if a.isRobot() {
+		return []string{strings.SplitN(credential.AccessKey, robotSeparator, 2)[0]}, nil
+	}
+	user, err := a.getUser()
+	if err != nil {
+		return nil, err
+	}
+	namespaces := []string{user.Username}
+	for _, org := range user.Organizations {
+		namespaces = append(namespaces, org.Name)
+	}
+	return namespaces, nil
+}
+
+// isRobot returns whether the credential belongs to a robot account
+func (a *adapter) isRobot() bool {
+	credential := a.registry.Credential
+	return credential != nil && credential.Type != model.CredentialTypeOAuth &&
+		strings.Contains(credential.AccessKey, robotSeparator)
+}
@@ -0,0 +1,254 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/utils/test"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQuay struct {
+	authorizations []string
+	created        []map[string]string
+}
+
+func (f *fakeQuay) server() *httptest.Server {
+	return test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/v1/user/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"username":"alice","organizations":[{"name":"operators"}]}`))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/v1/repository/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				f.authorizations = append(f.authorizations, r.Header.Get("Authorization"))
+				if r.URL.Path == "/api/v1/repository/operators/etcd" {
+					w.Write([]byte(`{"namespace":"operators","name":"etcd"}`))
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/api/v1/repository",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				f.authorizations = append(f.authorizations, r.Header.Get("Authorization"))
+				switch r.URL.Query().Get("namespace") {
+				case "operators":
+					if r.URL.Query().Get("next_page") == "" {
+						w.Write([]byte(`{"repositories":[{"namespace":"operators","name":"etcd"}],"next_page":"page2"}`))
+						return
+					}
+					w.Write([]byte(`{"repositories":[{"namespace":"operators","name":"prometheus"}]}`))
+				case "alice":
+					w.Write([]byte(`{"repositories":[{"namespace":"alice","name":"toolbox"}]}`))
+				default:
+					w.Write([]byte(`{"repositories":[]}`))
+				}
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodPost,
+			Pattern: "/api/v1/repository",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				f.authorizations = append(f.authorizations, r.Header.Get("Authorization"))
+				if strings.HasPrefix(r.Header.Get("Authorization"), "Basic") {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				req := map[string]string{}
+				json.NewDecoder(r.Body).Decode(&req)
+				f.created = append(f.created, req)
+				w.WriteHeader(http.StatusCreated)
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/v2/{namespace}/{name}/tags/list",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"tags":["v1.0","v2.0","latest"]}`))
+			},
+		},
+		&test.RequestHandlerMapping{
+			Method:  http.MethodGet,
+			Pattern: "/v2/",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		},
+	)
+}
+
+func TestAdapter_NewAdapter(t *testing.T) {
+	factory, err := adp.GetFactory(model.RegistryTypeQuay)
+	require.Nil(t, err)
+	adapter, err := factory(&model.Registry{
+		Type: model.RegistryTypeQuay,
+		URL:  "https://quay.io",
+	})
+	require.Nil(t, err)
+	info, err := adapter.Info()
+	require.Nil(t, err)
+	assert.Equal(t, model.RegistryTypeQuay, info.Type)
+}
+
+func TestAdapter_FetchImages(t *testing.T) {
+	fake := &fakeQuay{}
+	server := fake.server()
+	defer server.Close()
+
+	// anonymous access requires the namespace in the name filter
+	a, err := newAdapter(&model.Registry{
+		URL: server.URL,
+	})
+	require.Nil(t, err)
+	_, err = a.FetchImages(nil)
+	assert.NotNil(t, err)
+	resources, err := a.FetchImages([]*model.Filter{
+		{
+			Type:  model.FilterTypeName,
+			Value: "operators/e*",
+		},
+		{
+			Type:  model.FilterTypeTag,
+			Value: "v*",
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(resources))
+	assert.Equal(t, "operators/etcd", resources[0].Metadata.Repository.Name)
+	assert.Equal(t, []string{"v1.0", "v2.0"}, resources[0].Metadata.Vtags)
+
+	// the namespace of robot account
+	a, err = newAdapter(&model.Registry{
+		URL: server.URL,
+		Credential: &model.Credential{
+			Type:         model.CredentialTypeBasic,
+			AccessKey:    "operators+replicator",
+			AccessSecret: "token",
+		},
+	})
+	require.Nil(t, err)
+	resources, err = a.FetchImages(nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(resources))
+	assert.Equal(t, "operators/etcd", resources[0].Metadata.Repository.Name)
+	assert.Equal(t, "operators/prometheus", resources[1].Metadata.Repository.Name)
+
+	// the namespaces of the user and its organizations
+	a, err = newAdapter(&model.Registry{
+		URL: server.URL,
+		Credential: &model.Credential{
+			Type:         model.CredentialTypeOAuth,
+			AccessSecret: "oauth-token",
+		},
+	})
+	require.Nil(t, err)
+	resources, err = a.FetchImages([]*model.Filter{
+		{
+			Type:  model.FilterTypeName,
+			Value: "**/toolbox",
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(resources))
+	assert.Equal(t, "alice/toolbox", resources[0].Metadata.Repository.Name)
+	assert.Equal(t, "Bearer oauth-token", fake.authorizations[len(fake.authorizations)-1])
+}
+
+func TestAdapter_PrepareForPush(t *testing.T) {
+	fake := &fakeQuay{}
+	server := fake.server()
+	defer server.Close()
+
+	a, err := newAdapter(&model.Registry{
+		URL: server.URL,
+		Credential: &model.Credential{
+			Type:         model.CredentialTypeOAuth,
+			AccessSecret: "oauth-token",
+		},
+	})
+	require.Nil(t, err)
+
+	// invalid repository name
+	err = a.PrepareForPush([]*model.Resource{
+		{
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "etcd",
+				},
+			},
+		},
+	})
+	assert.NotNil(t, err)
+
+	err = a.PrepareForPush([]*model.Resource{
+		{
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "operators/etcd",
+				},
+			},
+		},
+		{
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "operators/new",
+				},
+			},
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(fake.created))
+	assert.Equal(t, "operators", fake.created[0]["namespace"])
+	assert.Equal(t, "new", fake.created[0]["repository"])
+	assert.Equal(t, "private", fake.created[0]["visibility"])
+
+	// the robot account isn't allowed to create repository via API
+	a, err = newAdapter(&model.Registry{
+		URL: server.URL,
+		Credential: &model.Credential{
+			Type:         model.CredentialTypeBasic,
+			AccessKey:    "operators+replicator",
+			AccessSecret: "token",
+		},
+	})
+	require.Nil(t, err)
+	err = a.PrepareForPush([]*model.Resource{
+		{
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "operators/new",
+				},
+			},
+		},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(fake.created))
+}
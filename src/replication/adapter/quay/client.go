@@ -0,0 +1,146 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quay
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	common_http "github.com/goharbor/harbor/src/common/http"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+type organization struct {
+	Name string `json:"name"`
+}
+
+type user struct {
+	Username      string          `json:"username"`
+	Organizations []*organization `json:"organizations"`
+}
+
+type repository struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+type repositoryList struct {
+	Repositories []*repository `json:"repositories"`
+	NextPage     string        `json:"next_page"`
+}
+
+// getUser returns the user that the credential belongs to
+func (a *adapter) getUser() (*user, error) {
+	u := &user{}
+	if err := a.client.Get(a.url+"/api/v1/user/", u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// listRepositories lists all the repositories under the namespace, the result is paginated
+// by the "next_page" token
+func (a *adapter) listRepositories(namespace string) ([]*repository, error) {
+	repositories := []*repository{}
+	nextPage := ""
+	for {
+		query := url.Values{}
+		query.Set("namespace", namespace)
+		if len(nextPage) > 0 {
+			query.Set("next_page", nextPage)
+		}
+		list := &repositoryList{}
+		if err := a.client.Get(fmt.Sprintf("%s/api/v1/repository?%s", a.url, query.Encode()), list); err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, list.Repositories...)
+		if len(list.NextPage) == 0 {
+			break
+		}
+		nextPage = list.NextPage
+	}
+	log.Debugf("got %d repositories under namespace %s", len(repositories), namespace)
+	return repositories, nil
+}
+
+// repositoryExist checks whether the repository exists
+func (a *adapter) repositoryExist(namespace, name string) (bool, error) {
+	err := a.client.Get(fmt.Sprintf("%s/api/v1/repository/%s/%s", a.url, namespace, name))
+	if err == nil {
+		return true, nil
+	}
+	if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusNotFound {
+		return false, nil
+	}
+	return false, err
+}
+
+// createRepository creates the private repository if it doesn't exist. The robot account
+// isn't allowed to call the API, in this case the repository is left to be created by the
+// push if the robot account has the creator permission of the namespace
+func (a *adapter) createRepository(repository string) error {
+	paths := strings.SplitN(repository, "/", 2)
+	if len(paths) != 2 {
+		return fmt.Errorf("quay only supports repository in format <namespace>/<name>, but got: %s", repository)
+	}
+	namespace, name := paths[0], paths[1]
+	exist, err := a.repositoryExist(namespace, name)
+	if err != nil {
+		if a.isRobot() && isUnauthorized(err) {
+			log.Warningf("the robot account isn't allowed to check the repository %s, it will be created when pushing", repository)
+			return nil
+		}
+		return err
+	}
+	if exist {
+		log.Debugf("repository %s already exists", repository)
+		return nil
+	}
+	req := struct {
+		Kind        string `json:"repo_kind"`
+		Namespace   string `json:"namespace"`
+		Repository  string `json:"repository"`
+		Visibility  string `json:"visibility"`
+		Description string `json:"description"`
+	}{
+		Kind:       "image",
+		Namespace:  namespace,
+		Repository: name,
+		Visibility: "private",
+	}
+	if err = a.client.Post(a.url+"/api/v1/repository", req); err != nil {
+		// the repository may be created by others at the same time
+		if e, ok := err.(*common_http.Error); ok && e.Code == http.StatusBadRequest &&
+			strings.Contains(e.Message, "already exists") {
+			return nil
+		}
+		if a.isRobot() && isUnauthorized(err) {
+			log.Warningf("the robot account isn't allowed to create the repository %s, it will be created when pushing", repository)
+			return nil
+		}
+		return err
+	}
+	log.Debugf("repository %s created", repository)
+	return nil
+}
+
+func isUnauthorized(err error) bool {
+	e, ok := err.(*common_http.Error)
+	return ok && (e.Code == http.StatusUnauthorized || e.Code == http.StatusForbidden)
+}
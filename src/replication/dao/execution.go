@@ -130,12 +130,47 @@ func fillExecution(execution *models.Execution) error {
 		execution.Total = total
 	}
 	resetExecutionStatus(execution)
+	if err = fillTransferStat(execution); err != nil {
+		log.Errorf("Query transfer statistics from tasks error execution %d: %v", execution.ID, err)
+	}
 
 	// if execution status changed to a final status, store to DB
 	if executionFinished(execution.Status) {
 		UpdateExecution(execution, models.ExecutionPropsName.Status, models.ExecutionPropsName.InProgress,
 			models.ExecutionPropsName.Succeed, models.ExecutionPropsName.Failed, models.ExecutionPropsName.Stopped,
-			models.ExecutionPropsName.EndTime, models.ExecutionPropsName.Total)
+			models.ExecutionPropsName.EndTime, models.ExecutionPropsName.Total,
+			models.ExecutionPropsName.BytesTransferred, models.ExecutionPropsName.BlobsCopied,
+			models.ExecutionPropsName.BlobsSkipped, models.ExecutionPropsName.Manifests,
+			models.ExecutionPropsName.Throughput)
+	}
+	return nil
+}
+
+// fillTransferStat rolls up the statistics of the data moved by the tasks, the throughput
+// of the execution is calculated by the time elapsed since the execution started
+func fillTransferStat(execution *models.Execution) error {
+	o := dao.GetOrmer()
+	sql := `select coalesce(sum(bytes_transferred), 0) as bytes_transferred,
+		coalesce(sum(blobs_copied), 0) as blobs_copied,
+		coalesce(sum(blobs_skipped), 0) as blobs_skipped,
+		coalesce(sum(array_length(string_to_array(manifest_digests, ','), 1)), 0) as manifests
+		from replication_task where execution_id = ?`
+	stat := &models.TransferStat{}
+	if err := o.Raw(sql, execution.ID).QueryRow(stat); err != nil {
+		return err
+	}
+	execution.BytesTransferred = stat.BytesTransferred
+	execution.BlobsCopied = stat.BlobsCopied
+	execution.BlobsSkipped = stat.BlobsSkipped
+	execution.Manifests = stat.Manifests
+
+	end := time.Now()
+	if executionFinished(execution.Status) {
+		end = execution.EndTime
+	}
+	execution.Throughput = 0
+	if seconds := end.Sub(execution.StartTime).Seconds(); seconds > 0 {
+		execution.Throughput = int64(float64(stat.BytesTransferred) / seconds)
 	}
 	return nil
 }
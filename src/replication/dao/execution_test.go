@@ -287,3 +287,54 @@ func TestExecutionFill2(t *testing.T) {
 	assert.Equal(t, 1, exes[0].Failed)
 	assert.Equal(t, 0, exes[0].Succeed)
 }
+
+func TestExecutionFillTransferStat(t *testing.T) {
+	now := time.Now()
+	execution := &models.Execution{
+		PolicyID:   11210,
+		Status:     "InProgress",
+		StatusText: "None",
+		Total:      2,
+		Trigger:    "Manual",
+		StartTime:  now.Add(-10 * time.Second),
+	}
+	executionID, _ := AddExecution(execution)
+	task1 := &models.Task{
+		ExecutionID:      executionID,
+		ResourceType:     "image",
+		Status:           "Succeed",
+		StartTime:        &now,
+		EndTime:          &now,
+		BytesTransferred: 1024,
+		BlobsCopied:      2,
+		BlobsSkipped:     1,
+		ManifestDigests:  "sha256:digest1,sha256:digest2",
+	}
+	task2 := &models.Task{
+		ExecutionID:      executionID,
+		ResourceType:     "image",
+		Status:           "Succeed",
+		StartTime:        &now,
+		EndTime:          &now,
+		BytesTransferred: 1024,
+		BlobsCopied:      1,
+		ManifestDigests:  "sha256:digest3",
+	}
+	AddTask(task1)
+	AddTask(task2)
+
+	defer func() {
+		DeleteAllTasks(executionID)
+		DeleteAllExecutions(11210)
+	}()
+
+	// query and fill
+	exe, err := GetExecution(executionID)
+	require.Nil(t, err)
+	assert.Equal(t, "Succeed", exe.Status)
+	assert.Equal(t, int64(2048), exe.BytesTransferred)
+	assert.Equal(t, 3, exe.BlobsCopied)
+	assert.Equal(t, 1, exe.BlobsSkipped)
+	assert.Equal(t, 3, exe.Manifests)
+	assert.True(t, exe.Throughput > 0)
+}
@@ -10,7 +10,8 @@ func init() {
 		new(RepPolicy),
 		new(Execution),
 		new(Task),
-		new(ScheduleJob))
+		new(ScheduleJob),
+		new(RegistryHealth))
 }
 
 // Pagination ...
@@ -47,6 +47,12 @@ var ExecutionPropsName = ExecutionFieldsName{
 	Trigger:    "Trigger",
 	StartTime:  "StartTime",
 	EndTime:    "EndTime",
+
+	BytesTransferred: "BytesTransferred",
+	BlobsCopied:      "BlobsCopied",
+	BlobsSkipped:     "BlobsSkipped",
+	Manifests:        "Manifests",
+	Throughput:       "Throughput",
 }
 
 // ExecutionFieldsName defines the props of Execution
@@ -63,6 +69,12 @@ type ExecutionFieldsName struct {
 	Trigger    string
 	StartTime  string
 	EndTime    string
+
+	BytesTransferred string
+	BlobsCopied      string
+	BlobsSkipped     string
+	Manifests        string
+	Throughput       string
 }
 
 // Execution holds information about once replication execution.
@@ -79,6 +91,14 @@ type Execution struct {
 	Trigger    model.TriggerType `orm:"column(trigger)" json:"trigger"`
 	StartTime  time.Time         `orm:"column(start_time)" json:"start_time"`
 	EndTime    time.Time         `orm:"column(end_time)" json:"end_time"`
+	// the statistics of the data moved rolled up from the tasks, the "Manifests" is
+	// the count of the manifests pushed and the "Throughput" is the average bytes
+	// transferred per second during the execution
+	BytesTransferred int64 `orm:"column(bytes_transferred)" json:"bytes_transferred"`
+	BlobsCopied      int   `orm:"column(blobs_copied)" json:"blobs_copied"`
+	BlobsSkipped     int   `orm:"column(blobs_skipped)" json:"blobs_skipped"`
+	Manifests        int   `orm:"column(manifests)" json:"manifests"`
+	Throughput       int64 `orm:"column(throughput)" json:"throughput"`
 }
 
 // TaskPropsName defines the names of fields of Task
@@ -92,6 +112,12 @@ var TaskPropsName = TaskFieldsName{
 	Status:       "Status",
 	StartTime:    "StartTime",
 	EndTime:      "EndTime",
+
+	BytesTransferred: "BytesTransferred",
+	BlobsCopied:      "BlobsCopied",
+	BlobsSkipped:     "BlobsSkipped",
+	ManifestDigests:  "ManifestDigests",
+	Throughput:       "Throughput",
 }
 
 // TaskFieldsName defines the props of Task
@@ -105,6 +131,12 @@ type TaskFieldsName struct {
 	Status       string
 	StartTime    string
 	EndTime      string
+
+	BytesTransferred string
+	BlobsCopied      string
+	BlobsSkipped     string
+	ManifestDigests  string
+	Throughput       string
 }
 
 // Task represent the tasks in one execution.
@@ -119,6 +151,19 @@ type Task struct {
 	Status       string     `orm:"column(status)" json:"status"`
 	StartTime    *time.Time `orm:"column(start_time)" json:"start_time"`
 	EndTime      *time.Time `orm:"column(end_time)" json:"end_time,omitempty"`
+	// the states of the chunked blob uploads, used to resume the uploads when the job is retried
+	UploadState string `orm:"column(upload_state)" json:"-"`
+	// the source and destination resources without registry information in JSON,
+	// used to reschedule the task when retrying the execution
+	Resources string `orm:"column(resources)" json:"-"`
+	// the statistics of the data moved by the task, the "ManifestDigests" are the digests
+	// of the manifests pushed separated by comma and the "Throughput" is the average
+	// bytes transferred per second
+	BytesTransferred int64  `orm:"column(bytes_transferred)" json:"bytes_transferred"`
+	BlobsCopied      int    `orm:"column(blobs_copied)" json:"blobs_copied"`
+	BlobsSkipped     int    `orm:"column(blobs_skipped)" json:"blobs_skipped"`
+	ManifestDigests  string `orm:"column(manifest_digests)" json:"manifest_digests"`
+	Throughput       int64  `orm:"column(throughput)" json:"throughput"`
 }
 
 // TableName is required by by beego orm to map Execution to table replication_execution
@@ -153,3 +198,11 @@ type TaskStat struct {
 	Status string `orm:"column(status)"`
 	C      int    `orm:"column(c)"`
 }
+
+// TransferStat holds the statistics of the data moved by the tasks of one execution
+type TransferStat struct {
+	BytesTransferred int64 `orm:"column(bytes_transferred)"`
+	BlobsCopied      int   `orm:"column(blobs_copied)"`
+	BlobsSkipped     int   `orm:"column(blobs_skipped)"`
+	Manifests        int   `orm:"column(manifests)"`
+}
@@ -14,18 +14,20 @@ const (
 
 // Registry is the model for a registry, which wraps the endpoint URL and credential of a remote registry.
 type Registry struct {
-	ID             int64     `orm:"pk;auto;column(id)" json:"id"`
-	URL            string    `orm:"column(url)" json:"endpoint"`
-	Name           string    `orm:"column(name)" json:"name"`
-	CredentialType string    `orm:"column(credential_type);default(basic)" json:"credential_type"`
-	AccessKey      string    `orm:"column(access_key)" json:"access_key"`
-	AccessSecret   string    `orm:"column(access_secret)" json:"access_secret"`
-	Type           string    `orm:"column(type)" json:"type"`
-	Insecure       bool      `orm:"column(insecure)" json:"insecure"`
-	Description    string    `orm:"column(description)" json:"description"`
-	Health         string    `orm:"column(health)" json:"health"`
-	CreationTime   time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
-	UpdateTime     time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+	ID                 int64     `orm:"pk;auto;column(id)" json:"id"`
+	URL                string    `orm:"column(url)" json:"endpoint"`
+	Name               string    `orm:"column(name)" json:"name"`
+	CredentialType     string    `orm:"column(credential_type);default(basic)" json:"credential_type"`
+	AccessKey          string    `orm:"column(access_key)" json:"access_key"`
+	AccessSecret       string    `orm:"column(access_secret)" json:"access_secret"`
+	Type               string    `orm:"column(type)" json:"type"`
+	Insecure           bool      `orm:"column(insecure)" json:"insecure"`
+	Description        string    `orm:"column(description)" json:"description"`
+	Health             string    `orm:"column(health)" json:"health"`
+	MaxConcurrentTasks int       `orm:"column(max_concurrent_tasks)" json:"max_concurrent_tasks"`
+	MaxBytesPerSecond  int64     `orm:"column(max_bytes_per_second)" json:"max_bytes_per_second"`
+	CreationTime       time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime         time.Time `orm:"column(update_time);auto_now" json:"update_time"`
 }
 
 // TableName is required by by beego orm to map Registry to table registry
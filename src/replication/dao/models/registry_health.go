@@ -0,0 +1,47 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+const (
+	// RegistryHealthTable is the table name for the health check history of registries
+	RegistryHealthTable = "registry_health"
+)
+
+// RegistryHealth is the result of one health check of the registry
+type RegistryHealth struct {
+	ID             int64  `orm:"pk;auto;column(id)" json:"id"`
+	RegistryID     int64  `orm:"column(registry_id)" json:"registry_id"`
+	Status         string `orm:"column(status)" json:"status"`
+	PreviousStatus string `orm:"column(previous_status)" json:"previous_status"`
+	// the time spent on the health check in milliseconds
+	Latency   int64     `orm:"column(latency)" json:"latency"`
+	Error     string    `orm:"column(error)" json:"error"`
+	CheckTime time.Time `orm:"column(check_time)" json:"check_time"`
+}
+
+// TableName is required by by beego orm to map RegistryHealth to table registry_health
+func (r *RegistryHealth) TableName() string {
+	return RegistryHealthTable
+}
+
+// RegistryHealthQuery holds the query conditions for the health check history
+type RegistryHealthQuery struct {
+	RegistryID int64
+	Pagination
+}
@@ -0,0 +1,69 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/replication/dao/models"
+)
+
+// AddRegistryHealth records the result of one health check
+func AddRegistryHealth(health *models.RegistryHealth) (int64, error) {
+	o := dao.GetOrmer()
+	return o.Insert(health)
+}
+
+// GetTotalOfRegistryHealth returns the total count of the health check history matching the query
+func GetTotalOfRegistryHealth(query ...*models.RegistryHealthQuery) (int64, error) {
+	return registryHealthQueryConditions(query...).Count()
+}
+
+// ListRegistryHealth lists the health check history matching the query, the latest one first
+func ListRegistryHealth(query ...*models.RegistryHealthQuery) ([]*models.RegistryHealth, error) {
+	histories := []*models.RegistryHealth{}
+	qs := registryHealthQueryConditions(query...)
+	if len(query) > 0 && query[0] != nil {
+		qs = paginateForQuerySetter(qs, query[0].Page, query[0].Size)
+	}
+	_, err := qs.OrderBy("-CheckTime", "-ID").All(&histories)
+	return histories, err
+}
+
+func registryHealthQueryConditions(query ...*models.RegistryHealthQuery) orm.QuerySeter {
+	qs := dao.GetOrmer().QueryTable(new(models.RegistryHealth))
+	if len(query) == 0 || query[0] == nil {
+		return qs
+	}
+	if query[0].RegistryID > 0 {
+		qs = qs.Filter("RegistryID", query[0].RegistryID)
+	}
+	return qs
+}
+
+// DeleteRegistryHealth deletes all the health check history of the registry
+func DeleteRegistryHealth(registryID int64) error {
+	_, err := dao.GetOrmer().QueryTable(new(models.RegistryHealth)).
+		Filter("RegistryID", registryID).Delete()
+	return err
+}
+
+// DeleteRegistryHealthBefore deletes the health check history of all registries older than the time
+func DeleteRegistryHealthBefore(t time.Time) (int64, error) {
+	return dao.GetOrmer().QueryTable(new(models.RegistryHealth)).
+		Filter("CheckTime__lt", t).Delete()
+}
@@ -0,0 +1,58 @@
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/replication/dao/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryHealth(t *testing.T) {
+	now := time.Now()
+	for i, status := range []string{"healthy", "unhealthy", "healthy"} {
+		_, err := AddRegistryHealth(&models.RegistryHealth{
+			RegistryID: 1000,
+			Status:     status,
+			Latency:    int64(i),
+			CheckTime:  now.Add(time.Duration(i-2) * time.Hour),
+		})
+		require.Nil(t, err)
+	}
+	defer DeleteRegistryHealth(1000)
+
+	total, err := GetTotalOfRegistryHealth(&models.RegistryHealthQuery{
+		RegistryID: 1000,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+
+	// the latest one first
+	histories, err := ListRegistryHealth(&models.RegistryHealthQuery{
+		RegistryID: 1000,
+		Pagination: models.Pagination{
+			Page: 1,
+			Size: 2,
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(histories))
+	assert.Equal(t, int64(2), histories[0].Latency)
+	assert.Equal(t, "unhealthy", histories[1].Status)
+
+	_, err = DeleteRegistryHealthBefore(now.Add(-90 * time.Minute))
+	require.Nil(t, err)
+	total, err = GetTotalOfRegistryHealth(&models.RegistryHealthQuery{
+		RegistryID: 1000,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+
+	require.Nil(t, DeleteRegistryHealth(1000))
+	total, err = GetTotalOfRegistryHealth(&models.RegistryHealthQuery{
+		RegistryID: 1000,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
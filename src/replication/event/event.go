@@ -22,6 +22,10 @@ const (
 	EventTypeImageDelete = "image_delete"
 	EventTypeChartUpload = "chart_upload"
 	EventTypeChartDelete = "chart_delete"
+	// the labels of image/chart are changed, the resource is replicated again
+	// to make the policies filtering by label take effect
+	EventTypeLabelAdd    = "label_add"
+	EventTypeLabelDelete = "label_delete"
 )
 
 // Event is the model that defines the image/chart pull/push event
@@ -18,9 +18,8 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/goharbor/harbor/src/replication/util"
-
 	"github.com/goharbor/harbor/src/common/utils/log"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
 	"github.com/goharbor/harbor/src/replication/config"
 	"github.com/goharbor/harbor/src/replication/model"
 	"github.com/goharbor/harbor/src/replication/operation"
@@ -58,7 +57,8 @@ func (h *handler) Handle(event *Event) error {
 	var err error
 	switch event.Type {
 	case EventTypeImagePush, EventTypeChartUpload,
-		EventTypeImageDelete, EventTypeChartDelete:
+		EventTypeImageDelete, EventTypeChartDelete,
+		EventTypeLabelAdd, EventTypeLabelDelete:
 		policies, err = h.getRelatedPolicies(event.Resource)
 	default:
 		return fmt.Errorf("unsupported event type %s", event.Type)
@@ -128,22 +128,24 @@ func (h *handler) getRelatedPolicies(resource *model.Resource) ([]*model.Policy,
 
 // TODO unify the match logic with other?
 func match(filters []*model.Filter, resource *model.Resource) (bool, error) {
-	match := true
-	repository := resource.Metadata.Repository.Name
+	repositories := []*adp.Repository{
+		{
+			ResourceType: string(resource.Type),
+			Name:         resource.Metadata.Repository.Name,
+		},
+	}
 	for _, filter := range filters {
-		if filter.Type != model.FilterTypeName {
+		if filter.Type != model.FilterTypeName && filter.Type != model.FilterTypeNameRegex {
 			continue
 		}
-		m, err := util.Match(filter.Value.(string), repository)
-		if err != nil {
+		if err := filter.DoFilter(&repositories); err != nil {
 			return false, err
 		}
-		if !m {
-			match = false
-			break
+		if len(repositories) == 0 {
+			return false, nil
 		}
 	}
-	return match, nil
+	return true, nil
 }
 
 // PopulateRegistries populates the source registry and destination registry properties for policy
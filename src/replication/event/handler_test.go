@@ -29,9 +29,15 @@ type fakedOperationController struct{}
 func (f *fakedOperationController) StartReplication(policy *model.Policy, resource *model.Resource, trigger model.TriggerType) (int64, error) {
 	return 1, nil
 }
+func (f *fakedOperationController) PlanReplication(*model.Policy) (*model.Plan, error) {
+	return nil, nil
+}
 func (f *fakedOperationController) StopReplication(int64) error {
 	return nil
 }
+func (f *fakedOperationController) RetryReplication(policy *model.Policy, executionID int64) error {
+	return nil
+}
 func (f *fakedOperationController) ListExecutions(...*models.ExecutionQuery) (int64, []*models.Execution, error) {
 	return 0, nil, nil
 }
@@ -205,6 +211,9 @@ func (f *fakedRegistryManager) Remove(int64) error {
 func (f *fakedRegistryManager) HealthCheck() error {
 	return nil
 }
+func (f *fakedRegistryManager) ListHealth(*models.RegistryHealthQuery) (int64, []*models.RegistryHealth, error) {
+	return 0, nil, nil
+}
 func TestGetRelatedPolicies(t *testing.T) {
 	handler := &handler{
 		policyCtl: &fakedPolicyController{},
@@ -298,4 +307,19 @@ func TestHandle(t *testing.T) {
 		Type: EventTypeImageDelete,
 	})
 	require.Nil(t, err)
+
+	// add label to image
+	err = handler.Handle(&Event{
+		Resource: &model.Resource{
+			Type: model.ResourceTypeImage,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "library/hello-world",
+				},
+				Vtags: []string{"latest"},
+			},
+		},
+		Type: EventTypeLabelAdd,
+	})
+	require.Nil(t, err)
 }
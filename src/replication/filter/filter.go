@@ -16,8 +16,13 @@ package filter
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/replication/util"
 )
@@ -41,6 +46,44 @@ type Filterable interface {
 	GetLabels() []string
 }
 
+// ArtifactFilterable defines the methods that the filterables carrying the information
+// of artifact must implement. It's used by the filters on push time, signature and
+// vulnerability severity, the filterables not implementing it are filtered out by them
+type ArtifactFilterable interface {
+	Filterable
+	// return the time when the artifact was pushed, zero value if it is unknown
+	GetPushTime() time.Time
+	// return whether the artifact is signed
+	IsSigned() bool
+	// return the highest severity of the vulnerabilities found by the scan,
+	// empty string if the artifact isn't scanned
+	GetSeverity() string
+}
+
+// the severities of vulnerability in ascending order, keep them consistent with the
+// string values of the severities defined in "common/models"
+var severities = []string{"negligible", "unknown", "low", "medium", "high"}
+
+// matches the whitespaces separating the comparisons of semantic version constraint
+var semverAndRegexp = regexp.MustCompile(`([^\s,|])\s+([<>=!~^])`)
+
+// matches the versions only containing the major or major and minor numbers
+var partialVersionRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// IsValidSeverity returns whether the severity is a valid one
+func IsValidSeverity(severity string) bool {
+	return severityRank(severity) > 0
+}
+
+func severityRank(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i + 1
+		}
+	}
+	return 0
+}
+
 // Filter defines the methods that a filter must implement
 type Filter interface {
 	// return whether the filter is applied to the specified Filterable
@@ -78,6 +121,91 @@ func NewVTagLabelFilter(labels []string) Filter {
 	}
 }
 
+// NewRepositoryNameRegexFilter return a Filter to filter the repositories whose names match the regular expression
+func NewRepositoryNameRegexFilter(pattern string) Filter {
+	return &regexFilter{
+		filterableType: FilterableTypeRepository,
+		pattern:        pattern,
+	}
+}
+
+// NewVTagNameRegexFilter return a Filter to filter the vtags whose names match the regular expression
+func NewVTagNameRegexFilter(pattern string) Filter {
+	return &regexFilter{
+		filterableType: FilterableTypeVTag,
+		pattern:        pattern,
+	}
+}
+
+// NewVTagSemverFilter return a Filter to filter the vtags whose names are semantic versions
+// satisfying the constraint, e.g. ">=1.4 <2", ">=1.4, <2 || 3.x"
+func NewVTagSemverFilter(constraint string) Filter {
+	return &semverFilter{
+		constraint: constraint,
+	}
+}
+
+// NewVTagPushTimeFilter return a Filter to filter the vtags pushed within the days
+func NewVTagPushTimeFilter(days int) Filter {
+	return &pushTimeFilter{
+		days: days,
+	}
+}
+
+// NewVTagSignatureFilter return a Filter to filter the image vtags according to whether they are signed
+func NewVTagSignatureFilter(signed bool) Filter {
+	return &signatureFilter{
+		signed: signed,
+	}
+}
+
+// NewVTagSeverityFilter return a Filter to filter the image vtags which are scanned and
+// whose severities of vulnerabilities are below the specified one
+func NewVTagSeverityFilter(severity string) Filter {
+	return &severityFilter{
+		severity: severity,
+	}
+}
+
+// NewExcludeFilter return a Filter which excludes the filterables kept by the specified filter
+func NewExcludeFilter(filter Filter) Filter {
+	return &excludeFilter{
+		filter: filter,
+	}
+}
+
+// ParseSemverConstraint parses the constraint of semantic version. Besides the
+// syntax supported by "github.com/Masterminds/semver", the comparisons can also
+// be separated by whitespaces for "AND", e.g. ">=1.4 <2"
+func ParseSemverConstraint(constraint string) (*semver.Constraints, error) {
+	constraint = semverAndRegexp.ReplaceAllString(strings.TrimSpace(constraint), "$1,$2")
+	var ors []string
+	for _, or := range strings.Split(constraint, "||") {
+		var ands []string
+		for _, and := range strings.Split(or, ",") {
+			ands = append(ands, completeLessThan(strings.TrimSpace(and)))
+		}
+		ors = append(ors, strings.Join(ands, ","))
+	}
+	return semver.NewConstraint(strings.Join(ors, "||"))
+}
+
+// the library treats the partial version in "less than" comparison as a wildcard,
+// e.g. "2.0.0" satisfies "<2", complete the version to make it exclusive
+func completeLessThan(comparison string) string {
+	if !strings.HasPrefix(comparison, "<") || strings.HasPrefix(comparison, "<=") {
+		return comparison
+	}
+	version := strings.TrimPrefix(strings.TrimSpace(comparison[1:]), "v")
+	if !partialVersionRegexp.MatchString(version) {
+		return comparison
+	}
+	for strings.Count(version, ".") < 2 {
+		version += ".0"
+	}
+	return "<" + version
+}
+
 type resourceTypeFilter struct {
 	resourceType string
 }
@@ -179,6 +307,175 @@ func (l *labelFilter) Filter(filterables ...Filterable) ([]Filterable, error) {
 	return result, nil
 }
 
+type regexFilter struct {
+	filterableType FilterableType
+	pattern        string
+}
+
+func (r *regexFilter) ApplyTo(filterable Filterable) bool {
+	if filterable == nil {
+		return false
+	}
+	return filterable.GetFilterableType() == r.filterableType
+}
+
+func (r *regexFilter) Filter(filterables ...Filterable) ([]Filterable, error) {
+	re, err := regexp.Compile(r.pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %s: %v", r.pattern, err)
+	}
+	result := []Filterable{}
+	for _, filterable := range filterables {
+		if re.MatchString(filterable.GetName()) {
+			result = append(result, filterable)
+		}
+	}
+	return result, nil
+}
+
+type semverFilter struct {
+	constraint string
+}
+
+func (s *semverFilter) ApplyTo(filterable Filterable) bool {
+	if filterable == nil {
+		return false
+	}
+	return filterable.GetFilterableType() == FilterableTypeVTag
+}
+
+func (s *semverFilter) Filter(filterables ...Filterable) ([]Filterable, error) {
+	constraint, err := ParseSemverConstraint(s.constraint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid semantic version constraint %s: %v", s.constraint, err)
+	}
+	result := []Filterable{}
+	for _, filterable := range filterables {
+		// the names that aren't semantic versions are filtered out
+		version, err := semver.NewVersion(filterable.GetName())
+		if err != nil {
+			log.Debugf("%q isn't a semantic version, skip", filterable.GetName())
+			continue
+		}
+		if constraint.Check(version) {
+			result = append(result, filterable)
+		}
+	}
+	return result, nil
+}
+
+type pushTimeFilter struct {
+	days int
+}
+
+func (p *pushTimeFilter) ApplyTo(filterable Filterable) bool {
+	if filterable == nil {
+		return false
+	}
+	return filterable.GetFilterableType() == FilterableTypeVTag
+}
+
+func (p *pushTimeFilter) Filter(filterables ...Filterable) ([]Filterable, error) {
+	since := time.Now().AddDate(0, 0, -p.days)
+	result := []Filterable{}
+	for _, filterable := range filterables {
+		artifact, ok := filterable.(ArtifactFilterable)
+		if !ok || artifact.GetPushTime().IsZero() {
+			log.Debugf("the push time of %q is unknown, skip", filterable.GetName())
+			continue
+		}
+		if artifact.GetPushTime().After(since) {
+			result = append(result, filterable)
+		}
+	}
+	return result, nil
+}
+
+// the signature and vulnerability are only available for images
+func applyToImageVTag(filterable Filterable) bool {
+	if filterable == nil {
+		return false
+	}
+	return filterable.GetFilterableType() == FilterableTypeVTag &&
+		filterable.GetResourceType() == "image"
+}
+
+type signatureFilter struct {
+	signed bool
+}
+
+func (s *signatureFilter) ApplyTo(filterable Filterable) bool {
+	return applyToImageVTag(filterable)
+}
+
+func (s *signatureFilter) Filter(filterables ...Filterable) ([]Filterable, error) {
+	result := []Filterable{}
+	for _, filterable := range filterables {
+		artifact, ok := filterable.(ArtifactFilterable)
+		if !ok {
+			continue
+		}
+		if artifact.IsSigned() == s.signed {
+			result = append(result, filterable)
+		}
+	}
+	return result, nil
+}
+
+type severityFilter struct {
+	severity string
+}
+
+func (s *severityFilter) ApplyTo(filterable Filterable) bool {
+	return applyToImageVTag(filterable)
+}
+
+func (s *severityFilter) Filter(filterables ...Filterable) ([]Filterable, error) {
+	threshold := severityRank(s.severity)
+	if threshold == 0 {
+		return nil, fmt.Errorf("invalid severity %s", s.severity)
+	}
+	result := []Filterable{}
+	for _, filterable := range filterables {
+		artifact, ok := filterable.(ArtifactFilterable)
+		// the images that aren't scanned are filtered out
+		if !ok || len(artifact.GetSeverity()) == 0 {
+			log.Debugf("%q isn't scanned, skip", filterable.GetName())
+			continue
+		}
+		if severityRank(artifact.GetSeverity()) < threshold {
+			result = append(result, filterable)
+		}
+	}
+	return result, nil
+}
+
+type excludeFilter struct {
+	filter Filter
+}
+
+func (e *excludeFilter) ApplyTo(filterable Filterable) bool {
+	return e.filter.ApplyTo(filterable)
+}
+
+func (e *excludeFilter) Filter(filterables ...Filterable) ([]Filterable, error) {
+	kept, err := e.filter.Filter(filterables...)
+	if err != nil {
+		return nil, err
+	}
+	excluded := map[Filterable]struct{}{}
+	for _, filterable := range kept {
+		excluded[filterable] = struct{}{}
+	}
+	result := []Filterable{}
+	for _, filterable := range filterables {
+		if _, exist := excluded[filterable]; !exist {
+			result = append(result, filterable)
+		}
+	}
+	return result, nil
+}
+
 // DoFilter is a util function to help filter filterables easily.
 // The parameter "filterables" must be a pointer points to a slice
 // whose elements must be Filterable. After applying all the "filters"
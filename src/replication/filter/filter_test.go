@@ -17,6 +17,7 @@ package filter
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +28,9 @@ type fakeFilterable struct {
 	resourceType   string
 	name           string
 	labels         []string
+	pushTime       time.Time
+	signed         bool
+	severity       string
 }
 
 func (f *fakeFilterable) GetFilterableType() FilterableType {
@@ -44,6 +48,18 @@ func (f *fakeFilterable) GetLabels() []string {
 	return f.labels
 }
 
+func (f *fakeFilterable) GetPushTime() time.Time {
+	return f.pushTime
+}
+
+func (f *fakeFilterable) IsSigned() bool {
+	return f.signed
+}
+
+func (f *fakeFilterable) GetSeverity() string {
+	return f.severity
+}
+
 func TestFilterOfResourceTypeFilter(t *testing.T) {
 	filterable := &fakeFilterable{
 		filterableType: FilterableTypeRepository,
@@ -168,3 +184,126 @@ func TestDoFilter(t *testing.T) {
 		assert.True(t, reflect.DeepEqual(tag1, filterables[0]))
 	}
 }
+
+func names(filterables []Filterable) []string {
+	result := []string{}
+	for _, filterable := range filterables {
+		result = append(result, filterable.GetName())
+	}
+	return result
+}
+
+func TestFilterOfRegexFilter(t *testing.T) {
+	filterables := []Filterable{
+		&fakeFilterable{name: "v1.0"},
+		&fakeFilterable{name: "dev-1.0"},
+	}
+	filter := NewVTagNameRegexFilter("^v[0-9.]+$")
+	result, err := filter.Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"v1.0"}, names(result))
+
+	// invalid regular expression
+	filter = NewVTagNameRegexFilter("[")
+	_, err = filter.Filter(filterables...)
+	assert.NotNil(t, err)
+}
+
+func TestFilterOfSemverFilter(t *testing.T) {
+	filterables := []Filterable{
+		&fakeFilterable{name: "1.3.9"},
+		&fakeFilterable{name: "1.4.0"},
+		&fakeFilterable{name: "v1.5"},
+		&fakeFilterable{name: "2.0.0"},
+		&fakeFilterable{name: "latest"},
+	}
+	filter := NewVTagSemverFilter(">=1.4 <2")
+	result, err := filter.Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"1.4.0", "v1.5"}, names(result))
+
+	filter = NewVTagSemverFilter("<1.5")
+	result, err = filter.Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"1.3.9", "1.4.0"}, names(result))
+
+	filter = NewVTagSemverFilter("<1.4 || >=2")
+	result, err = filter.Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"1.3.9", "2.0.0"}, names(result))
+
+	// invalid constraint
+	_, err = ParseSemverConstraint(">=a.b")
+	assert.NotNil(t, err)
+}
+
+func TestFilterOfPushTimeFilter(t *testing.T) {
+	filterables := []Filterable{
+		&fakeFilterable{name: "new", pushTime: time.Now().Add(-time.Hour)},
+		&fakeFilterable{name: "old", pushTime: time.Now().AddDate(0, 0, -10)},
+		&fakeFilterable{name: "unknown"},
+	}
+	filter := NewVTagPushTimeFilter(7)
+	result, err := filter.Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"new"}, names(result))
+}
+
+func TestApplyToOfSignatureFilter(t *testing.T) {
+	filter := NewVTagSignatureFilter(true)
+	assert.True(t, filter.ApplyTo(&fakeFilterable{
+		filterableType: FilterableTypeVTag,
+		resourceType:   "image",
+	}))
+	assert.False(t, filter.ApplyTo(&fakeFilterable{
+		filterableType: FilterableTypeVTag,
+		resourceType:   "chart",
+	}))
+	assert.False(t, filter.ApplyTo(&fakeFilterable{
+		filterableType: FilterableTypeRepository,
+		resourceType:   "image",
+	}))
+}
+
+func TestFilterOfSignatureFilter(t *testing.T) {
+	filterables := []Filterable{
+		&fakeFilterable{name: "signed", signed: true},
+		&fakeFilterable{name: "unsigned"},
+	}
+	result, err := NewVTagSignatureFilter(true).Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"signed"}, names(result))
+
+	result, err = NewVTagSignatureFilter(false).Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"unsigned"}, names(result))
+}
+
+func TestFilterOfSeverityFilter(t *testing.T) {
+	filterables := []Filterable{
+		&fakeFilterable{name: "low", severity: "low"},
+		&fakeFilterable{name: "high", severity: "high"},
+		&fakeFilterable{name: "medium", severity: "medium"},
+		&fakeFilterable{name: "unscanned"},
+	}
+	result, err := NewVTagSeverityFilter("medium").Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"low"}, names(result))
+
+	// invalid severity
+	_, err = NewVTagSeverityFilter("critical").Filter(filterables...)
+	assert.NotNil(t, err)
+}
+
+func TestFilterOfExcludeFilter(t *testing.T) {
+	filterables := []Filterable{
+		&fakeFilterable{filterableType: FilterableTypeVTag, name: "1.0"},
+		&fakeFilterable{filterableType: FilterableTypeVTag, name: "dev-1.0"},
+		&fakeFilterable{filterableType: FilterableTypeVTag, name: "1.1"},
+	}
+	filter := NewExcludeFilter(NewVTagNameFilter("dev-*"))
+	assert.True(t, filter.ApplyTo(filterables[0]))
+	result, err := filter.Filter(filterables...)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"1.0", "1.1"}, names(result))
+}
@@ -0,0 +1,53 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "fmt"
+
+// Limit restricts the concurrency and bandwidth of the replication tasks,
+// the tasks carrying the limits with the same key share the limit
+type Limit struct {
+	Key string `json:"key"`
+	// the max count of the tasks running concurrently, 0 means no limit
+	MaxConcurrentTasks int `json:"max_concurrent_tasks,omitempty"`
+	// the max bytes transferred per second, 0 means no limit
+	MaxBytesPerSecond int64 `json:"max_bytes_per_second,omitempty"`
+}
+
+// GetLimits returns the limits applied to the tasks of the policy, includes
+// the ones of the policy itself and the ones of its source and destination registries
+func GetLimits(policy *Policy) []*Limit {
+	var limits []*Limit
+	if policy.MaxConcurrentTasks > 0 || policy.MaxBytesPerSecond > 0 {
+		limits = append(limits, &Limit{
+			Key:                fmt.Sprintf("policy:%d", policy.ID),
+			MaxConcurrentTasks: policy.MaxConcurrentTasks,
+			MaxBytesPerSecond:  policy.MaxBytesPerSecond,
+		})
+	}
+	for _, registry := range []*Registry{policy.SrcRegistry, policy.DestRegistry} {
+		if registry == nil {
+			continue
+		}
+		if registry.MaxConcurrentTasks > 0 || registry.MaxBytesPerSecond > 0 {
+			limits = append(limits, &Limit{
+				Key:                fmt.Sprintf("registry:%d", registry.ID),
+				MaxConcurrentTasks: registry.MaxConcurrentTasks,
+				MaxBytesPerSecond:  registry.MaxBytesPerSecond,
+			})
+		}
+	}
+	return limits
+}
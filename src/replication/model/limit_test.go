@@ -0,0 +1,44 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLimits(t *testing.T) {
+	// no limits
+	policy := &Policy{
+		ID:           1,
+		SrcRegistry:  &Registry{},
+		DestRegistry: &Registry{ID: 2},
+	}
+	assert.Equal(t, 0, len(GetLimits(policy)))
+
+	// the limits of policy and registries
+	policy.MaxConcurrentTasks = 2
+	policy.DestRegistry.MaxBytesPerSecond = 1024
+	limits := GetLimits(policy)
+	require.Equal(t, 2, len(limits))
+	assert.Equal(t, "policy:1", limits[0].Key)
+	assert.Equal(t, 2, limits[0].MaxConcurrentTasks)
+	assert.Equal(t, int64(0), limits[0].MaxBytesPerSecond)
+	assert.Equal(t, "registry:2", limits[1].Key)
+	assert.Equal(t, 0, limits[1].MaxConcurrentTasks)
+	assert.Equal(t, int64(1024), limits[1].MaxBytesPerSecond)
+}
@@ -0,0 +1,57 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// MetadataSync specifies the metadata of the images replicated along with the contents.
+// It only takes effect when both the source and destination registries support it
+type MetadataSync struct {
+	// the labels of the repositories and tags, they're created on the destination
+	// registry if missing
+	Labels bool `json:"labels"`
+	// the descriptions of the repositories
+	Description bool `json:"description"`
+	// the content trust signatures of the tags, the tags are signed on the Notary server
+	// of the destination Harbor specified by "NotaryURL" with the keys configured in the
+	// job service
+	Signatures bool   `json:"signatures"`
+	NotaryURL  string `json:"notary_url,omitempty"`
+}
+
+// Enabled returns whether any metadata needs to be synced
+func (m *MetadataSync) Enabled() bool {
+	return m != nil && (m.Labels || m.Description || m.Signatures)
+}
+
+// Label is the label attached to the repositories or tags
+type Label struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Color       string `json:"color"`
+}
+
+// TagMetadata holds the metadata of one tag
+type TagMetadata struct {
+	Name   string   `json:"name"`
+	Labels []*Label `json:"labels"`
+	// the digest of the manifest signed by the content trust, empty if the tag isn't signed
+	SignedDigest string `json:"signed_digest"`
+}
+
+// Metadata holds the metadata of one repository and its tags
+type Metadata struct {
+	Description string         `json:"description"`
+	Labels      []*Label       `json:"labels"`
+	Tags        []*TagMetadata `json:"tags"`
+}
@@ -0,0 +1,82 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// const definition
+const (
+	// PlanActionCreate means the artifact doesn't exist on the destination registry and will be created
+	PlanActionCreate PlanAction = "create"
+	// PlanActionOverwrite means the artifact exists on the destination registry with different
+	// content and will be overwritten as the "override" of policy is enabled
+	PlanActionOverwrite PlanAction = "overwrite"
+	// PlanActionSkip means nothing will be done for the artifact, the reason is recorded in the plan item
+	PlanActionSkip PlanAction = "skip"
+	// PlanActionDelete means the artifact will be deleted from the destination registry
+	PlanActionDelete PlanAction = "delete"
+	// PlanActionUnknown means the state of artifact cannot be determined, the error is recorded in the plan item
+	PlanActionUnknown PlanAction = "unknown"
+)
+
+// PlanAction represents what the replication will do for one artifact
+type PlanAction string
+
+// Plan describes what the replication of a policy would do without running it
+type Plan struct {
+	PolicyID  int64       `json:"policy_id"`
+	Total     int         `json:"total"`
+	Create    int         `json:"create"`
+	Overwrite int         `json:"overwrite"`
+	Skip      int         `json:"skip"`
+	Delete    int         `json:"delete"`
+	Unknown   int         `json:"unknown"`
+	Items     []*PlanItem `json:"items"`
+}
+
+// PlanItem describes the replication of one artifact(a tag of an image
+// or a version of a chart)
+type PlanItem struct {
+	ResourceType  ResourceType `json:"resource_type"`
+	Operation     string       `json:"operation"`
+	SrcRepository string       `json:"src_repository"`
+	DstRepository string       `json:"dst_repository"`
+	Tag           string       `json:"tag"`
+	SrcDigest     string       `json:"src_digest,omitempty"`
+	DstDigest     string       `json:"dst_digest,omitempty"`
+	// whether the artifact already exists on the destination registry
+	Exist bool `json:"exist"`
+	// whether the artifact on the destination registry has the same digest with the source one,
+	// only available for images as charts have no digest
+	SameDigest bool       `json:"same_digest"`
+	Action     PlanAction `json:"action"`
+	Reason     string     `json:"reason,omitempty"`
+}
+
+// AddItem appends the item to the plan and updates the counts
+func (p *Plan) AddItem(item *PlanItem) {
+	p.Items = append(p.Items, item)
+	p.Total++
+	switch item.Action {
+	case PlanActionCreate:
+		p.Create++
+	case PlanActionOverwrite:
+		p.Overwrite++
+	case PlanActionSkip:
+		p.Skip++
+	case PlanActionDelete:
+		p.Delete++
+	default:
+		p.Unknown++
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"strings"
+)
+
+// Platform specifies the platform of the manifests in a manifest list
+// or OCI image index. The empty field matches any value
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Valid checks whether the platform is valid
+func (p *Platform) Valid() error {
+	if len(p.OS) == 0 && len(p.Architecture) == 0 {
+		return errors.New("the os and architecture of platform cannot be both empty")
+	}
+	return nil
+}
+
+// Match returns whether the platform matches the os, architecture and variant
+func (p *Platform) Match(os, architecture, variant string) bool {
+	return matchPlatformField(p.OS, os) &&
+		matchPlatformField(p.Architecture, architecture) &&
+		matchPlatformField(p.Variant, variant)
+}
+
+func matchPlatformField(expected, actual string) bool {
+	return len(expected) == 0 || strings.EqualFold(expected, actual)
+}
+
+// MatchPlatforms returns true if any of the platforms matches the os, architecture
+// and variant. All values are matched if the platforms are empty
+func MatchPlatforms(platforms []*Platform, os, architecture, variant string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+	for _, platform := range platforms {
+		if platform.Match(os, architecture, variant) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidOfPlatform(t *testing.T) {
+	assert.NotNil(t, (&Platform{}).Valid())
+	assert.NotNil(t, (&Platform{Variant: "v8"}).Valid())
+	assert.Nil(t, (&Platform{OS: "linux"}).Valid())
+	assert.Nil(t, (&Platform{Architecture: "arm64"}).Valid())
+}
+
+func TestMatchPlatforms(t *testing.T) {
+	cases := []struct {
+		platforms    []*Platform
+		os           string
+		architecture string
+		variant      string
+		match        bool
+	}{
+		// no platforms
+		{
+			platforms:    nil,
+			os:           "linux",
+			architecture: "amd64",
+			match:        true,
+		},
+		// match os and architecture
+		{
+			platforms: []*Platform{
+				{
+					OS:           "linux",
+					Architecture: "amd64",
+				},
+			},
+			os:           "linux",
+			architecture: "amd64",
+			match:        true,
+		},
+		// match architecture only
+		{
+			platforms: []*Platform{
+				{
+					Architecture: "ARM64",
+				},
+			},
+			os:           "linux",
+			architecture: "arm64",
+			variant:      "v8",
+			match:        true,
+		},
+		// variant doesn't match
+		{
+			platforms: []*Platform{
+				{
+					OS:           "linux",
+					Architecture: "arm",
+					Variant:      "v7",
+				},
+			},
+			os:           "linux",
+			architecture: "arm",
+			variant:      "v6",
+			match:        false,
+		},
+		// match one of the platforms
+		{
+			platforms: []*Platform{
+				{
+					OS: "windows",
+				},
+				{
+					Architecture: "s390x",
+				},
+			},
+			os:           "linux",
+			architecture: "s390x",
+			match:        true,
+		},
+		// no platform matches
+		{
+			platforms: []*Platform{
+				{
+					OS:           "linux",
+					Architecture: "amd64",
+				},
+			},
+			os:           "linux",
+			architecture: "ppc64le",
+			match:        false,
+		},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, MatchPlatforms(c.platforms, c.os, c.architecture, c.variant))
+	}
+}
@@ -16,6 +16,8 @@ package model
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/goharbor/harbor/src/replication/filter"
@@ -31,6 +33,23 @@ const (
 	FilterTypeName     FilterType = "name"
 	FilterTypeTag      FilterType = "tag"
 	FilterTypeLabel    FilterType = "label"
+	// the regular expression of repository name
+	FilterTypeNameRegex FilterType = "name_regex"
+	// the regular expression of tag name
+	FilterTypeTagRegex FilterType = "tag_regex"
+	// the constraint of semantic version, e.g. ">=1.4 <2"
+	FilterTypeTagSemver FilterType = "tag_semver"
+	// the artifacts pushed within the days
+	FilterTypePushedWithin FilterType = "pushed_within"
+	// the images whose signed state is the specified one
+	FilterTypeSigned FilterType = "signed"
+	// the images which are scanned and whose severities of vulnerabilities are below the specified one
+	FilterTypeSeverity FilterType = "severity"
+
+	// the filter keeps the matched resources, it's the default decoration
+	FilterDecorationMatches = "matches"
+	// the filter excludes the matched resources
+	FilterDecorationExcludes = "excludes"
 
 	TriggerTypeManual     TriggerType = "manual"
 	TriggerTypeScheduled  TriggerType = "scheduled"
@@ -43,15 +62,22 @@ type Policy struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Creator     string `json:"creator"`
-	// source
+	// source, the ID of local Harbor is 0
 	SrcRegistry *Registry `json:"src_registry"`
-	// destination
+	// destination, the ID of local Harbor is 0. The source and
+	// destination registries can be both remote ones
 	DestRegistry *Registry `json:"dest_registry"`
 	// Only support two dest namespace modes:
 	// Put all the src resources to the one single dest namespace
 	// or keep namespaces same with the source ones (under this case,
 	// the DestNamespace should be set to empty)
 	DestNamespace string `json:"dest_namespace"`
+	// The ordered rules used to rewrite the destination repository paths,
+	// they are applied after the "DestNamespace" is applied
+	DestRepoRules []*RewriteRule `json:"dest_repo_rules"`
+	// The platforms of the manifest lists to be replicated,
+	// all platforms are replicated if it is empty
+	Platforms []*Platform `json:"platforms"`
 	// Filters
 	Filters []*Filter `json:"filters"`
 	// Trigger
@@ -61,6 +87,19 @@ type Policy struct {
 	Deletion bool `json:"deletion"`
 	// If override the image tag
 	Override bool `json:"override"`
+	// If verify the digests of the manifests and the checksums of the charts
+	// on the destination registry after they are copied
+	VerifyDigest bool `json:"verify_digest"`
+	// The metadata of the images replicated along with the contents, e.g. labels,
+	// descriptions and signatures, nothing is synced if it is nil
+	MetadataSync *MetadataSync `json:"metadata_sync"`
+	// The max count of the tasks of the policy running concurrently, 0 means no limit
+	MaxConcurrentTasks int `json:"max_concurrent_tasks"`
+	// The max bytes the tasks of the policy transfer per second, 0 means no limit
+	MaxBytesPerSecond int64 `json:"max_bytes_per_second"`
+	// The warning set when the source or destination registry is unhealthy,
+	// it is maintained by the registry health check and empty if both are healthy
+	HealthWarning string `json:"health_warning"`
 	// Operations
 	Enabled      bool      `json:"enabled"`
 	CreationTime time.Time `json:"creation_time"`
@@ -80,15 +119,51 @@ func (p *Policy) Valid(v *validation.Validation) {
 		dstRegistryID = p.DestRegistry.ID
 	}
 
-	// one of the source registry and destination registry must be Harbor itself
-	if srcRegistryID != 0 && dstRegistryID != 0 ||
-		srcRegistryID == 0 && dstRegistryID == 0 {
-		v.SetError("src_registry, dest_registry", "one of them should be empty and the other one shouldn't be empty")
+	// the source registry and destination registry can be both remote ones,
+	// but cannot be the same one
+	if srcRegistryID == dstRegistryID {
+		v.SetError("src_registry, dest_registry", "the source registry and destination registry cannot be the same")
 	}
 
 	// valid the filters
 	for _, filter := range p.Filters {
+		switch filter.Decoration {
+		case "", FilterDecorationMatches, FilterDecorationExcludes:
+		default:
+			v.SetError("filters", fmt.Sprintf("invalid filter decoration: %s", filter.Decoration))
+		}
 		switch filter.Type {
+		case FilterTypeNameRegex, FilterTypeTagRegex:
+			value, ok := filter.Value.(string)
+			if !ok {
+				v.SetError("filters", "the type of filter value isn't string")
+				break
+			}
+			if _, err := regexp.Compile(value); err != nil {
+				v.SetError("filters", fmt.Sprintf("invalid regular expression %s: %v", value, err))
+			}
+		case FilterTypeTagSemver:
+			value, ok := filter.Value.(string)
+			if !ok {
+				v.SetError("filters", "the type of filter value isn't string")
+				break
+			}
+			if err := validSemverConstraint(value); err != nil {
+				v.SetError("filters", fmt.Sprintf("invalid semantic version constraint %s: %v", value, err))
+			}
+		case FilterTypePushedWithin:
+			if days, ok := parseDays(filter.Value); !ok || days <= 0 {
+				v.SetError("filters", "the value of pushed_within filter must be a positive integer")
+			}
+		case FilterTypeSigned:
+			if _, ok := filter.Value.(bool); !ok {
+				v.SetError("filters", "the type of signed filter value isn't boolean")
+			}
+		case FilterTypeSeverity:
+			value, ok := filter.Value.(string)
+			if !ok || !validSeverity(value) {
+				v.SetError("filters", fmt.Sprintf("invalid severity filter: %v", filter.Value))
+			}
 		case FilterTypeResource, FilterTypeName, FilterTypeTag:
 			value, ok := filter.Value.(string)
 			if !ok {
@@ -121,10 +196,55 @@ func (p *Policy) Valid(v *validation.Validation) {
 		}
 	}
 
+	// valid the rewrite rules of destination repository
+	for _, rule := range p.DestRepoRules {
+		if rule == nil {
+			v.SetError("dest_repo_rules", "the rewrite rule cannot be null")
+			break
+		}
+		if err := rule.Valid(); err != nil {
+			v.SetError("dest_repo_rules", err.Error())
+			break
+		}
+	}
+
+	// valid the platforms
+	for _, platform := range p.Platforms {
+		if platform == nil {
+			v.SetError("platforms", "the platform cannot be null")
+			break
+		}
+		if err := platform.Valid(); err != nil {
+			v.SetError("platforms", err.Error())
+			break
+		}
+	}
+
+	// valid the limits
+	if p.MaxConcurrentTasks < 0 {
+		v.SetError("max_concurrent_tasks", "cannot be negative")
+	}
+	if p.MaxBytesPerSecond < 0 {
+		v.SetError("max_bytes_per_second", "cannot be negative")
+	}
+
+	// valid the Notary server that the signatures are replicated to
+	if p.MetadataSync != nil && p.MetadataSync.Signatures {
+		u, err := url.Parse(p.MetadataSync.NotaryURL)
+		if err != nil || !(u.Scheme == "http" || u.Scheme == "https") || len(u.Host) == 0 {
+			v.SetError("metadata_sync", fmt.Sprintf("invalid Notary URL: %s", p.MetadataSync.NotaryURL))
+		}
+	}
+
 	// valid trigger
 	if p.Trigger != nil {
 		switch p.Trigger.Type {
-		case TriggerTypeManual, TriggerTypeEventBased:
+		case TriggerTypeManual:
+		case TriggerTypeEventBased:
+			// the events are only produced by the local Harbor
+			if srcRegistryID != 0 {
+				v.SetError("trigger", fmt.Sprintf("the trigger type %s is only supported when the source registry is the local Harbor", TriggerTypeEventBased))
+			}
 		case TriggerTypeScheduled:
 			if p.Trigger.Settings == nil || len(p.Trigger.Settings.Cron) == 0 {
 				v.SetError("trigger", fmt.Sprintf("the cron string cannot be empty when the trigger type is %s", TriggerTypeScheduled))
@@ -147,6 +267,13 @@ type FilterType string
 type Filter struct {
 	Type  FilterType  `json:"type"`
 	Value interface{} `json:"value"`
+	// "matches" or "excludes", the default value is "matches"
+	Decoration string `json:"decoration,omitempty"`
+}
+
+// IsExclusion returns whether the filter excludes the matched resources
+func (f *Filter) IsExclusion() bool {
+	return f.Decoration == FilterDecorationExcludes
 }
 
 // DoFilter filter the filterables
@@ -168,13 +295,62 @@ func (f *Filter) DoFilter(filterables interface{}) error {
 		}
 	case FilterTypeResource:
 		ft = filter.NewResourceTypeFilter(f.Value.(string))
+	case FilterTypeNameRegex:
+		ft = filter.NewRepositoryNameRegexFilter(f.Value.(string))
+	case FilterTypeTagRegex:
+		ft = filter.NewVTagNameRegexFilter(f.Value.(string))
+	case FilterTypeTagSemver:
+		ft = filter.NewVTagSemverFilter(f.Value.(string))
+	case FilterTypePushedWithin:
+		days, ok := parseDays(f.Value)
+		if !ok {
+			return fmt.Errorf("invalid pushed_within filter: %v", f.Value)
+		}
+		ft = filter.NewVTagPushTimeFilter(days)
+	case FilterTypeSigned:
+		ft = filter.NewVTagSignatureFilter(f.Value.(bool))
+	case FilterTypeSeverity:
+		ft = filter.NewVTagSeverityFilter(f.Value.(string))
 	default:
 		return fmt.Errorf("unsupported filter type: %s", f.Type)
 	}
+	// the label filter whose value isn't string slice is ignored
+	if ft == nil {
+		return nil
+	}
+	if f.IsExclusion() {
+		ft = filter.NewExcludeFilter(ft)
+	}
 
 	return filter.DoFilter(filterables, ft)
 }
 
+func validSeverity(severity string) bool {
+	return filter.IsValidSeverity(severity)
+}
+
+func validSemverConstraint(constraint string) error {
+	_, err := filter.ParseSemverConstraint(constraint)
+	return err
+}
+
+// the numbers decoded from JSON are float64
+func parseDays(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
 // TriggerType represents the type of trigger.
 type TriggerType string
 
@@ -39,6 +39,19 @@ func TestValidOfPolicy(t *testing.T) {
 			},
 			pass: false,
 		},
+		// source registry and destination registry are the same
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 1,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+			},
+			pass: false,
+		},
 		// source registry and destination registry both not empty
 		{
 			policy: &Policy{
@@ -50,6 +63,22 @@ func TestValidOfPolicy(t *testing.T) {
 					ID: 2,
 				},
 			},
+			pass: true,
+		},
+		// event based trigger for the remote source registry
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 1,
+				},
+				DestRegistry: &Registry{
+					ID: 2,
+				},
+				Trigger: &Trigger{
+					Type: TriggerTypeEventBased,
+				},
+			},
 			pass: false,
 		},
 		// invalid filter
@@ -112,6 +141,193 @@ func TestValidOfPolicy(t *testing.T) {
 			},
 			pass: false,
 		},
+		// invalid rewrite rule
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				DestRepoRules: []*RewriteRule{
+					{
+						Type:    RewriteRuleTypeRegex,
+						Pattern: "(a",
+					},
+				},
+			},
+			pass: false,
+		},
+		// invalid platform
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				Platforms: []*Platform{
+					{
+						Variant: "v8",
+					},
+				},
+			},
+			pass: false,
+		},
+		// invalid regex filter
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				Filters: []*Filter{
+					{
+						Type:  FilterTypeTagRegex,
+						Value: "[",
+					},
+				},
+			},
+			pass: false,
+		},
+		// invalid semver filter
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				Filters: []*Filter{
+					{
+						Type:  FilterTypeTagSemver,
+						Value: ">=a.b",
+					},
+				},
+			},
+			pass: false,
+		},
+		// invalid pushed within filter
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				Filters: []*Filter{
+					{
+						Type:  FilterTypePushedWithin,
+						Value: float64(1.5),
+					},
+				},
+			},
+			pass: false,
+		},
+		// invalid severity filter
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				Filters: []*Filter{
+					{
+						Type:  FilterTypeSeverity,
+						Value: "critical",
+					},
+				},
+			},
+			pass: false,
+		},
+		// invalid decoration
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				Filters: []*Filter{
+					{
+						Type:       FilterTypeName,
+						Value:      "library/**",
+						Decoration: "invalid",
+					},
+				},
+			},
+			pass: false,
+		},
+		// valid filters
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				Filters: []*Filter{
+					{
+						Type:  FilterTypeNameRegex,
+						Value: "^library/.*",
+					},
+					{
+						Type:       FilterTypeTagRegex,
+						Value:      "^dev-",
+						Decoration: FilterDecorationExcludes,
+					},
+					{
+						Type:  FilterTypeTagSemver,
+						Value: ">=1.4 <2",
+					},
+					{
+						Type:  FilterTypePushedWithin,
+						Value: float64(7),
+					},
+					{
+						Type:  FilterTypeSigned,
+						Value: true,
+					},
+					{
+						Type:  FilterTypeSeverity,
+						Value: "high",
+					},
+				},
+			},
+			pass: true,
+		},
+		// invalid limit
+		{
+			policy: &Policy{
+				Name: "policy01",
+				SrcRegistry: &Registry{
+					ID: 0,
+				},
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				MaxConcurrentTasks: -1,
+			},
+			pass: false,
+		},
 		// invalid trigger
 		{
 			policy: &Policy{
@@ -185,6 +401,33 @@ func TestValidOfPolicy(t *testing.T) {
 			},
 			pass: false,
 		},
+		// signatures without Notary URL
+		{
+			policy: &Policy{
+				Name: "policy01",
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				MetadataSync: &MetadataSync{
+					Signatures: true,
+				},
+			},
+			pass: false,
+		},
+		// signatures with Notary URL
+		{
+			policy: &Policy{
+				Name: "policy01",
+				DestRegistry: &Registry{
+					ID: 1,
+				},
+				MetadataSync: &MetadataSync{
+					Signatures: true,
+					NotaryURL:  "https://harbor.example.com:4443",
+				},
+			},
+			pass: true,
+		},
 		// pass
 		{
 			policy: &Policy{
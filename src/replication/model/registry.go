@@ -17,6 +17,7 @@ package model
 import (
 	"time"
 
+	"github.com/astaxie/beego/validation"
 	"github.com/goharbor/harbor/src/common/models"
 )
 
@@ -29,6 +30,11 @@ const (
 	RegistryTypeGoogleGcr      RegistryType = "google-gcr"
 	RegistryTypeAwsEcr         RegistryType = "aws-ecr"
 	RegistryTypeAzureAcr       RegistryType = "azure-acr"
+	RegistryTypeQuay           RegistryType = "quay"
+	RegistryTypeGitLab         RegistryType = "gitlab"
+	RegistryTypeArtifactory    RegistryType = "artifactory"
+	RegistryTypeChartMuseum    RegistryType = "chartmuseum"
+	RegistryTypeHelmRepo       RegistryType = "helm-repo"
 
 	FilterStyleTypeText  = "input"
 	FilterStyleTypeRadio = "radio"
@@ -91,8 +97,23 @@ type Registry struct {
 	Credential      *Credential `json:"credential"`
 	Insecure        bool        `json:"insecure"`
 	Status          string      `json:"status"`
-	CreationTime    time.Time   `json:"creation_time"`
-	UpdateTime      time.Time   `json:"update_time"`
+	// the max count of the tasks reading from or writing to the registry
+	// running concurrently, 0 means no limit
+	MaxConcurrentTasks int `json:"max_concurrent_tasks"`
+	// the max bytes transferred from or to the registry per second, 0 means no limit
+	MaxBytesPerSecond int64     `json:"max_bytes_per_second"`
+	CreationTime      time.Time `json:"creation_time"`
+	UpdateTime        time.Time `json:"update_time"`
+}
+
+// Valid the registry
+func (r *Registry) Valid(v *validation.Validation) {
+	if r.MaxConcurrentTasks < 0 {
+		v.SetError("max_concurrent_tasks", "cannot be negative")
+	}
+	if r.MaxBytesPerSecond < 0 {
+		v.SetError("max_bytes_per_second", "cannot be negative")
+	}
 }
 
 // RegistryQuery defines the query conditions for listing registries
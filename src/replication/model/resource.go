@@ -61,4 +61,14 @@ type Resource struct {
 	Deleted bool `json:"deleted"`
 	// indicate whether the resource can be overridden
 	Override bool `json:"override"`
+	// indicate whether to verify the digest of the resource on the
+	// destination registry after it is copied
+	VerifyDigest bool `json:"verify_digest,omitempty"`
+	// the metadata replicated along with the contents, only used by the image resource
+	MetadataSync *MetadataSync `json:"metadata_sync,omitempty"`
+	// the platforms of the manifest lists to be replicated, all platforms
+	// are replicated if it is empty. Only used by the image resource
+	Platforms []*Platform `json:"platforms,omitempty"`
+	// the concurrency and bandwidth limits applied when transferring the resource
+	Limits []*Limit `json:"limits,omitempty"`
 }
@@ -0,0 +1,136 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// const definition
+const (
+	// RewriteRuleTypeRegex replaces the matched part of repository with the replacement,
+	// the capture groups can be referenced in the replacement by "$1", "${name}", etc.
+	RewriteRuleTypeRegex RewriteRuleType = "regex"
+	// RewriteRuleTypeKeepLast keeps only the last N path levels of repository
+	RewriteRuleTypeKeepLast RewriteRuleType = "keep_last"
+	// RewriteRuleTypeDropFirst drops the first N path levels of repository
+	RewriteRuleTypeDropFirst RewriteRuleType = "drop_first"
+	// RewriteRuleTypeAddPrefix adds the value as the prefix of repository,
+	// e.g. value "mirror/" adds one path level
+	RewriteRuleTypeAddPrefix RewriteRuleType = "add_prefix"
+	// RewriteRuleTypeAddSuffix adds the value as the suffix of repository
+	RewriteRuleTypeAddSuffix RewriteRuleType = "add_suffix"
+	// RewriteRuleTypeTrimPrefix removes the value from the beginning of repository if present
+	RewriteRuleTypeTrimPrefix RewriteRuleType = "trim_prefix"
+	// RewriteRuleTypeTrimSuffix removes the value from the end of repository if present
+	RewriteRuleTypeTrimSuffix RewriteRuleType = "trim_suffix"
+)
+
+var repositoryNameRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*(?:/[a-z0-9]+(?:[._-][a-z0-9]+)*)*$`)
+
+// RewriteRuleType represents the type of the rewrite rule
+type RewriteRuleType string
+
+// RewriteRule rewrites the destination repository path. The "Pattern" and
+// "Replacement" are used by the regex rule, the "Count" is used by the rules
+// operating on path levels and the "Value" is used by the rules adding or
+// removing prefix/suffix
+type RewriteRule struct {
+	Type        RewriteRuleType `json:"type"`
+	Pattern     string          `json:"pattern,omitempty"`
+	Replacement string          `json:"replacement,omitempty"`
+	Count       int             `json:"count,omitempty"`
+	Value       string          `json:"value,omitempty"`
+}
+
+// Valid checks whether the rule is valid
+func (r *RewriteRule) Valid() error {
+	switch r.Type {
+	case RewriteRuleTypeRegex:
+		if len(r.Pattern) == 0 {
+			return errors.New("the pattern of regex rule cannot be empty")
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern %s: %v", r.Pattern, err)
+		}
+	case RewriteRuleTypeKeepLast, RewriteRuleTypeDropFirst:
+		if r.Count <= 0 {
+			return fmt.Errorf("the count of %s rule must be greater than 0", r.Type)
+		}
+	case RewriteRuleTypeAddPrefix, RewriteRuleTypeAddSuffix,
+		RewriteRuleTypeTrimPrefix, RewriteRuleTypeTrimSuffix:
+		if len(r.Value) == 0 {
+			return fmt.Errorf("the value of %s rule cannot be empty", r.Type)
+		}
+	default:
+		return fmt.Errorf("invalid rewrite rule type %s", r.Type)
+	}
+	return nil
+}
+
+// Apply the rule to the repository and return the rewritten one
+func (r *RewriteRule) Apply(repository string) (string, error) {
+	switch r.Type {
+	case RewriteRuleTypeRegex:
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return "", err
+		}
+		return re.ReplaceAllString(repository, r.Replacement), nil
+	case RewriteRuleTypeKeepLast:
+		levels := strings.Split(repository, "/")
+		if len(levels) > r.Count {
+			levels = levels[len(levels)-r.Count:]
+		}
+		return strings.Join(levels, "/"), nil
+	case RewriteRuleTypeDropFirst:
+		levels := strings.Split(repository, "/")
+		if len(levels) <= r.Count {
+			return "", fmt.Errorf("cannot drop %d path levels from repository %s", r.Count, repository)
+		}
+		return strings.Join(levels[r.Count:], "/"), nil
+	case RewriteRuleTypeAddPrefix:
+		return r.Value + repository, nil
+	case RewriteRuleTypeAddSuffix:
+		return repository + r.Value, nil
+	case RewriteRuleTypeTrimPrefix:
+		return strings.TrimPrefix(repository, r.Value), nil
+	case RewriteRuleTypeTrimSuffix:
+		return strings.TrimSuffix(repository, r.Value), nil
+	default:
+		return "", fmt.Errorf("invalid rewrite rule type %s", r.Type)
+	}
+}
+
+// RewriteRepository applies the rules to the repository in order and
+// returns the rewritten repository. An error is returned if the result
+// isn't a valid repository name
+func RewriteRepository(repository string, rules []*RewriteRule) (string, error) {
+	result := repository
+	for _, rule := range rules {
+		var err error
+		result, err = rule.Apply(result)
+		if err != nil {
+			return "", err
+		}
+	}
+	if !repositoryNameRegexp.MatchString(result) {
+		return "", fmt.Errorf("the repository %s is rewritten to an invalid name %s", repository, result)
+	}
+	return result, nil
+}
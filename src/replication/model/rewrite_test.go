@@ -0,0 +1,147 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidOfRewriteRule(t *testing.T) {
+	cases := []struct {
+		rule *RewriteRule
+		pass bool
+	}{
+		{
+			rule: &RewriteRule{Type: "invalid_type"},
+			pass: false,
+		},
+		{
+			rule: &RewriteRule{Type: RewriteRuleTypeRegex},
+			pass: false,
+		},
+		{
+			rule: &RewriteRule{Type: RewriteRuleTypeRegex, Pattern: "(a"},
+			pass: false,
+		},
+		{
+			rule: &RewriteRule{Type: RewriteRuleTypeRegex, Pattern: "^library/(.*)$", Replacement: "$1"},
+			pass: true,
+		},
+		{
+			rule: &RewriteRule{Type: RewriteRuleTypeKeepLast},
+			pass: false,
+		},
+		{
+			rule: &RewriteRule{Type: RewriteRuleTypeDropFirst, Count: 1},
+			pass: true,
+		},
+		{
+			rule: &RewriteRule{Type: RewriteRuleTypeAddPrefix},
+			pass: false,
+		},
+		{
+			rule: &RewriteRule{Type: RewriteRuleTypeTrimSuffix, Value: "-dev"},
+			pass: true,
+		},
+	}
+	for _, c := range cases {
+		err := c.rule.Valid()
+		assert.Equal(t, c.pass, err == nil)
+	}
+}
+
+func TestRewriteRepository(t *testing.T) {
+	cases := []struct {
+		repository string
+		rules      []*RewriteRule
+		result     string
+		err        bool
+	}{
+		// no rules
+		{
+			repository: "library/hello-world",
+			result:     "library/hello-world",
+		},
+		// regex
+		{
+			repository: "library/team-a/app",
+			rules: []*RewriteRule{
+				{Type: RewriteRuleTypeRegex, Pattern: "^library/[^/]+/(.*)$", Replacement: "mirror/$1"},
+			},
+			result: "mirror/app",
+		},
+		// keep last
+		{
+			repository: "a/b/c/d",
+			rules: []*RewriteRule{
+				{Type: RewriteRuleTypeKeepLast, Count: 2},
+			},
+			result: "c/d",
+		},
+		{
+			repository: "d",
+			rules: []*RewriteRule{
+				{Type: RewriteRuleTypeKeepLast, Count: 2},
+			},
+			result: "d",
+		},
+		// drop first
+		{
+			repository: "a/b/c",
+			rules: []*RewriteRule{
+				{Type: RewriteRuleTypeDropFirst, Count: 1},
+			},
+			result: "b/c",
+		},
+		{
+			repository: "a/b",
+			rules: []*RewriteRule{
+				{Type: RewriteRuleTypeDropFirst, Count: 2},
+			},
+			err: true,
+		},
+		// prefix and suffix in order
+		{
+			repository: "library/app-dev",
+			rules: []*RewriteRule{
+				{Type: RewriteRuleTypeTrimPrefix, Value: "library/"},
+				{Type: RewriteRuleTypeTrimSuffix, Value: "-dev"},
+				{Type: RewriteRuleTypeAddPrefix, Value: "mirror/team/"},
+				{Type: RewriteRuleTypeAddSuffix, Value: "-mirror"},
+			},
+			result: "mirror/team/app-mirror",
+		},
+		// invalid result
+		{
+			repository: "library/app",
+			rules: []*RewriteRule{
+				{Type: RewriteRuleTypeRegex, Pattern: ".*", Replacement: "Upper/Case"},
+			},
+			err: true,
+		},
+	}
+	for _, c := range cases {
+		result, err := RewriteRepository(c.repository, c.rules)
+		if c.err {
+			require.NotNil(t, err)
+			continue
+		}
+		require.Nil(t, err)
+		assert.Equal(t, c.result, result)
+	}
+}
@@ -32,7 +32,11 @@ import (
 type Controller interface {
 	// trigger is used to specify what this replication is triggered by
 	StartReplication(policy *model.Policy, resource *model.Resource, trigger model.TriggerType) (int64, error)
+	// PlanReplication returns what the replication of the policy would do without running it
+	PlanReplication(policy *model.Policy) (*model.Plan, error)
 	StopReplication(int64) error
+	// RetryReplication reschedules the failed and stopped tasks of the finished execution
+	RetryReplication(policy *model.Policy, executionID int64) error
 	ListExecutions(...*models.ExecutionQuery) (int64, []*models.Execution, error)
 	GetExecution(int64) (*models.Execution, error)
 	ListTasks(...*models.TaskQuery) (int64, []*models.Task, error)
@@ -118,6 +122,10 @@ func (c *controller) createFlow(executionID int64, policy *model.Policy, resourc
 	return flow.NewCopyFlow(c.executionMgr, c.scheduler, executionID, policy, resources...)
 }
 
+func (c *controller) PlanReplication(policy *model.Policy) (*model.Plan, error) {
+	return flow.BuildPlan(c.scheduler, policy)
+}
+
 func (c *controller) StopReplication(executionID int64) error {
 	_, tasks, err := c.ListTasks(&models.TaskQuery{
 		ExecutionID: executionID,
@@ -161,6 +169,35 @@ func (c *controller) StopReplication(executionID int64) error {
 	return nil
 }
 
+func (c *controller) RetryReplication(policy *model.Policy, executionID int64) error {
+	execution, err := c.executionMgr.Get(executionID)
+	if err != nil {
+		return err
+	}
+	if execution == nil {
+		return fmt.Errorf("the execution %d not found", executionID)
+	}
+	if execution.PolicyID != policy.ID {
+		return fmt.Errorf("the execution %d doesn't belong to the policy %d", executionID, policy.ID)
+	}
+	if execution.Status == models.ExecutionStatusInProgress {
+		return fmt.Errorf("the execution %d is in progress", executionID)
+	}
+	log.Debugf("waiting for the available replicator ...")
+	<-c.replicators
+	log.Debugf("got an available replicator, retrying the execution ...")
+	go func() {
+		defer func() {
+			c.replicators <- struct{}{}
+		}()
+		flow := flow.NewRetryFlow(c.executionMgr, c.scheduler, executionID, policy)
+		if _, err := c.flowCtl.Start(flow); err != nil {
+			log.Errorf("failed to retry the execution %d: %v", executionID, err)
+		}
+	}()
+	return nil
+}
+
 func isTaskRunning(task *models.Task) bool {
 	if task == nil {
 		return false
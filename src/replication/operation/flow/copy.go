@@ -53,7 +53,7 @@ func (c *copyFlow) Run(interface{}) (int, error) {
 	}
 	var srcResources []*model.Resource
 	if len(c.resources) > 0 {
-		srcResources, err = filterResources(c.resources, c.policy.Filters)
+		srcResources, err = filterResources(srcAdapter, c.resources, c.policy.Filters)
 	} else {
 		srcResources, err = fetchResources(srcAdapter, c.policy)
 	}
@@ -77,7 +77,10 @@ func (c *copyFlow) Run(interface{}) (int, error) {
 	}
 
 	srcResources = assembleSourceResources(srcResources, c.policy)
-	dstResources := assembleDestinationResources(srcResources, c.policy)
+	dstResources, err := assembleDestinationResources(srcResources, c.policy)
+	if err != nil {
+		return 0, err
+	}
 
 	if err = prepareForPush(dstAdapter, dstResources); err != nil {
 		return 0, err
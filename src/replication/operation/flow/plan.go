@@ -0,0 +1,186 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	adp "github.com/goharbor/harbor/src/replication/adapter"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/operation/scheduler"
+)
+
+// BuildPlan runs the stages of the copy flow except preparing the destination
+// registry, creating and scheduling the tasks, and returns the plan that describes
+// what the replication of the policy would do
+func BuildPlan(scheduler scheduler.Scheduler, policy *model.Policy) (*model.Plan, error) {
+	srcAdapter, dstAdapter, err := initialize(policy)
+	if err != nil {
+		return nil, err
+	}
+	srcResources, err := fetchResources(srcAdapter, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &model.Plan{
+		PolicyID: policy.ID,
+		Items:    []*model.PlanItem{},
+	}
+	if len(srcResources) == 0 {
+		log.Debugf("no resources need to be replicated for the policy %d", policy.ID)
+		return plan, nil
+	}
+
+	srcResources = assembleSourceResources(srcResources, policy)
+	dstResources, err := assembleDestinationResources(srcResources, policy)
+	if err != nil {
+		return nil, err
+	}
+	items, err := preprocess(scheduler, srcResources, dstResources)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		for _, planItem := range buildPlanItems(srcAdapter, dstAdapter, item) {
+			plan.AddItem(planItem)
+		}
+	}
+	log.Debugf("build the plan for the policy %d completed", policy.ID)
+	return plan, nil
+}
+
+// build the plan items for every tag/version of the schedule item
+func buildPlanItems(srcAdapter, dstAdapter adp.Adapter, item *scheduler.ScheduleItem) []*model.PlanItem {
+	src, dst := item.SrcResource, item.DstResource
+	operation := "copy"
+	if dst.Deleted {
+		operation = "deletion"
+	}
+	if len(src.Metadata.Vtags) == 0 {
+		return []*model.PlanItem{
+			{
+				ResourceType:  src.Type,
+				Operation:     operation,
+				SrcRepository: src.Metadata.Repository.Name,
+				DstRepository: dst.Metadata.Repository.Name,
+				Action:        model.PlanActionUnknown,
+				Reason:        "no tag specified, all tags of the repository will be replicated",
+			},
+		}
+	}
+
+	var planItems []*model.PlanItem
+	for _, tag := range src.Metadata.Vtags {
+		planItem := &model.PlanItem{
+			ResourceType:  src.Type,
+			Operation:     operation,
+			SrcRepository: src.Metadata.Repository.Name,
+			DstRepository: dst.Metadata.Repository.Name,
+			Tag:           tag,
+		}
+		var err error
+		switch src.Type {
+		case model.ResourceTypeImage:
+			err = planImage(srcAdapter, dstAdapter, planItem, dst.Deleted, dst.Override)
+		case model.ResourceTypeChart:
+			err = planChart(dstAdapter, planItem, dst.Deleted, dst.Override)
+		default:
+			err = fmt.Errorf("unsupported resource type %s", src.Type)
+		}
+		if err != nil {
+			log.Errorf("failed to plan the replication of %s:%s: %v", planItem.SrcRepository, tag, err)
+			planItem.Action = model.PlanActionUnknown
+			planItem.Reason = err.Error()
+		}
+		planItems = append(planItems, planItem)
+	}
+	return planItems
+}
+
+func planImage(srcAdapter, dstAdapter adp.Adapter, item *model.PlanItem, deleted, override bool) error {
+	dstRegistry, ok := dstAdapter.(adp.ImageRegistry)
+	if !ok {
+		return errors.New("the adapter of destination registry doesn't implement the ImageRegistry interface")
+	}
+	exist, digest, err := dstRegistry.ManifestExist(item.DstRepository, item.Tag)
+	if err != nil {
+		return fmt.Errorf("failed to check the existence of the image on the destination registry: %v", err)
+	}
+	item.Exist = exist
+	item.DstDigest = digest
+	if deleted {
+		planDeletion(item)
+		return nil
+	}
+
+	srcRegistry, ok := srcAdapter.(adp.ImageRegistry)
+	if !ok {
+		return errors.New("the adapter of source registry doesn't implement the ImageRegistry interface")
+	}
+	_, digest, err = srcRegistry.ManifestExist(item.SrcRepository, item.Tag)
+	if err != nil {
+		return fmt.Errorf("failed to check the existence of the image on the source registry: %v", err)
+	}
+	item.SrcDigest = digest
+	item.SameDigest = item.Exist && len(item.SrcDigest) > 0 && item.SrcDigest == item.DstDigest
+	planCopy(item, override)
+	return nil
+}
+
+func planChart(dstAdapter adp.Adapter, item *model.PlanItem, deleted, override bool) error {
+	dstRegistry, ok := dstAdapter.(adp.ChartRegistry)
+	if !ok {
+		return errors.New("the adapter of destination registry doesn't implement the ChartRegistry interface")
+	}
+	exist, err := dstRegistry.ChartExist(item.DstRepository, item.Tag)
+	if err != nil {
+		return fmt.Errorf("failed to check the existence of the chart on the destination registry: %v", err)
+	}
+	item.Exist = exist
+	if deleted {
+		planDeletion(item)
+		return nil
+	}
+	planCopy(item, override)
+	return nil
+}
+
+// decide the action of copy in the same way as the transfers do
+func planCopy(item *model.PlanItem, override bool) {
+	switch {
+	case !item.Exist:
+		item.Action = model.PlanActionCreate
+	case item.SameDigest:
+		item.Action = model.PlanActionSkip
+		item.Reason = "the same artifact already exists on the destination registry"
+	case override:
+		item.Action = model.PlanActionOverwrite
+	default:
+		item.Action = model.PlanActionSkip
+		item.Reason = "the artifact exists on the destination registry, but the \"override\" is disabled"
+	}
+}
+
+func planDeletion(item *model.PlanItem) {
+	if item.Exist {
+		item.Action = model.PlanActionDelete
+		return
+	}
+	item.Action = model.PlanActionSkip
+	item.Reason = "the artifact doesn't exist on the destination registry"
+}
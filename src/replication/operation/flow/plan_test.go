@@ -0,0 +1,106 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPlan(t *testing.T) {
+	scheduler := &fakedScheduler{}
+	policy := &model.Policy{
+		ID: 1,
+		SrcRegistry: &model.Registry{
+			Type: model.RegistryTypeHarbor,
+		},
+		DestRegistry: &model.Registry{
+			Type: model.RegistryTypeHarbor,
+		},
+		DestNamespace: "mirror",
+	}
+	plan, err := BuildPlan(scheduler, policy)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), plan.PolicyID)
+	assert.Equal(t, 2, plan.Total)
+	assert.Equal(t, 2, plan.Create)
+	require.Equal(t, 2, len(plan.Items))
+	assert.Equal(t, model.ResourceTypeImage, plan.Items[0].ResourceType)
+	assert.Equal(t, "copy", plan.Items[0].Operation)
+	assert.Equal(t, "library/hello-world", plan.Items[0].SrcRepository)
+	assert.Equal(t, "mirror/hello-world", plan.Items[0].DstRepository)
+	assert.Equal(t, "latest", plan.Items[0].Tag)
+	assert.Equal(t, model.PlanActionCreate, plan.Items[0].Action)
+	assert.Equal(t, model.ResourceTypeChart, plan.Items[1].ResourceType)
+	assert.Equal(t, "mirror/harbor", plan.Items[1].DstRepository)
+	assert.Equal(t, "0.2.0", plan.Items[1].Tag)
+}
+
+func TestPlanCopy(t *testing.T) {
+	cases := []struct {
+		item     *model.PlanItem
+		override bool
+		action   model.PlanAction
+	}{
+		// not exist
+		{
+			item:   &model.PlanItem{},
+			action: model.PlanActionCreate,
+		},
+		// exist with the same digest
+		{
+			item: &model.PlanItem{
+				Exist:      true,
+				SameDigest: true,
+			},
+			override: true,
+			action:   model.PlanActionSkip,
+		},
+		// exist with different digest, override
+		{
+			item: &model.PlanItem{
+				Exist: true,
+			},
+			override: true,
+			action:   model.PlanActionOverwrite,
+		},
+		// exist with different digest, not override
+		{
+			item: &model.PlanItem{
+				Exist: true,
+			},
+			action: model.PlanActionSkip,
+		},
+	}
+	for _, c := range cases {
+		planCopy(c.item, c.override)
+		assert.Equal(t, c.action, c.item.Action)
+	}
+}
+
+func TestPlanDeletion(t *testing.T) {
+	item := &model.PlanItem{
+		Exist: true,
+	}
+	planDeletion(item)
+	assert.Equal(t, model.PlanActionDelete, item.Action)
+
+	item = &model.PlanItem{}
+	planDeletion(item)
+	assert.Equal(t, model.PlanActionSkip, item.Action)
+}
@@ -0,0 +1,104 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/replication/dao/models"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/operation/execution"
+	"github.com/goharbor/harbor/src/replication/operation/scheduler"
+)
+
+type retryFlow struct {
+	executionID  int64
+	policy       *model.Policy
+	executionMgr execution.Manager
+	scheduler    scheduler.Scheduler
+}
+
+// NewRetryFlow returns an instance of the retry flow which reschedules the failed and
+// stopped tasks of the execution. The tasks are reused, so they are still linked to
+// the execution and the status of the execution is refreshed according to them
+func NewRetryFlow(executionMgr execution.Manager, scheduler scheduler.Scheduler,
+	executionID int64, policy *model.Policy) Flow {
+	return &retryFlow{
+		executionMgr: executionMgr,
+		scheduler:    scheduler,
+		executionID:  executionID,
+		policy:       policy,
+	}
+}
+
+func (r *retryFlow) Run(interface{}) (int, error) {
+	_, tasks, err := r.executionMgr.ListTasks(&models.TaskQuery{
+		ExecutionID: r.executionID,
+		Statuses:    []string{models.TaskStatusFailed, models.TaskStatusStopped},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	limits := model.GetLimits(r.policy)
+	var items []*scheduler.ScheduleItem
+	for _, task := range tasks {
+		// the resources aren't recorded for the tasks created by previous versions
+		if len(task.Resources) == 0 {
+			log.Warningf("the resources of task %d aren't recorded, cannot retry it", task.ID)
+			continue
+		}
+		src, dst, err := unmarshalTaskResources(task.Resources)
+		if err != nil {
+			return 0, fmt.Errorf("failed to unmarshal the resources of task %d: %v", task.ID, err)
+		}
+		// populate the registries and limits from the policy as they may be changed
+		src.Registry = r.policy.SrcRegistry
+		dst.Registry = r.policy.DestRegistry
+		dst.Limits = limits
+		items = append(items, &scheduler.ScheduleItem{
+			TaskID:      task.ID,
+			SrcResource: src,
+			DstResource: dst,
+		})
+	}
+	if len(items) == 0 {
+		log.Infof("no tasks need to be retried for the execution %d, skip", r.executionID)
+		return 0, nil
+	}
+
+	for _, item := range items {
+		if err = r.executionMgr.UpdateTask(&models.Task{
+			ID:     item.TaskID,
+			Status: models.TaskStatusInitialized,
+		}, models.TaskPropsName.Status, models.TaskPropsName.JobID, models.TaskPropsName.EndTime); err != nil {
+			return 0, fmt.Errorf("failed to reset the task %d: %v", item.TaskID, err)
+		}
+	}
+	// reset the status and counters of the execution, they are refreshed according to
+	// the status of tasks when querying the execution until all the tasks are finished
+	if err = r.executionMgr.Update(&models.Execution{
+		ID:     r.executionID,
+		Status: models.ExecutionStatusInProgress,
+	}, models.ExecutionPropsName.Status, models.ExecutionPropsName.StatusText,
+		models.ExecutionPropsName.InProgress, models.ExecutionPropsName.Succeed,
+		models.ExecutionPropsName.Failed, models.ExecutionPropsName.Stopped); err != nil {
+		return 0, fmt.Errorf("failed to reset the execution %d: %v", r.executionID, err)
+	}
+	log.Debugf("%d tasks of the execution %d will be retried", len(items), r.executionID)
+
+	return schedule(r.scheduler, r.executionMgr, items)
+}
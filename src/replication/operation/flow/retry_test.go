@@ -0,0 +1,153 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/replication/dao/models"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/operation/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakedRetryExecutionManager struct {
+	fakedExecutionManager
+	tasks []*models.Task
+}
+
+func (f *fakedRetryExecutionManager) ListTasks(...*models.TaskQuery) (int64, []*models.Task, error) {
+	return int64(len(f.tasks)), f.tasks, nil
+}
+
+type fakedRetryScheduler struct {
+	fakedScheduler
+	items []*scheduler.ScheduleItem
+}
+
+func (f *fakedRetryScheduler) Schedule(items []*scheduler.ScheduleItem) ([]*scheduler.ScheduleResult, error) {
+	f.items = items
+	return f.fakedScheduler.Schedule(items)
+}
+
+func TestMarshalTaskResources(t *testing.T) {
+	src := &model.Resource{
+		Type: model.ResourceTypeImage,
+		Registry: &model.Registry{
+			ID: 1,
+			Credential: &model.Credential{
+				AccessSecret: "secret",
+			},
+		},
+		Metadata: &model.ResourceMetadata{
+			Repository: &model.Repository{
+				Name: "library/hello-world",
+			},
+			Vtags: []string{"latest"},
+		},
+	}
+	dst := &model.Resource{
+		Type: model.ResourceTypeImage,
+		Registry: &model.Registry{
+			ID: 2,
+		},
+		Metadata: &model.ResourceMetadata{
+			Repository: &model.Repository{
+				Name: "mirror/hello-world",
+			},
+			Vtags: []string{"latest"},
+		},
+		Override: true,
+	}
+	str, err := marshalTaskResources(src, dst)
+	require.Nil(t, err)
+	assert.NotContains(t, str, "secret")
+	// the original resources aren't changed
+	assert.NotNil(t, src.Registry)
+	assert.NotNil(t, dst.Registry)
+
+	s, d, err := unmarshalTaskResources(str)
+	require.Nil(t, err)
+	assert.Nil(t, s.Registry)
+	assert.Nil(t, d.Registry)
+	assert.Equal(t, "library/hello-world", s.Metadata.Repository.Name)
+	assert.Equal(t, "mirror/hello-world", d.Metadata.Repository.Name)
+	assert.True(t, d.Override)
+
+	_, _, err = unmarshalTaskResources("{}")
+	assert.NotNil(t, err)
+}
+
+func TestRunOfRetryFlow(t *testing.T) {
+	resources, err := marshalTaskResources(&model.Resource{
+		Type: model.ResourceTypeImage,
+		Metadata: &model.ResourceMetadata{
+			Repository: &model.Repository{
+				Name: "library/hello-world",
+			},
+			Vtags: []string{"latest"},
+		},
+	}, &model.Resource{
+		Type: model.ResourceTypeImage,
+		Metadata: &model.ResourceMetadata{
+			Repository: &model.Repository{
+				Name: "library/hello-world",
+			},
+			Vtags: []string{"latest"},
+		},
+	})
+	require.Nil(t, err)
+	executionMgr := &fakedRetryExecutionManager{
+		tasks: []*models.Task{
+			{
+				ID:        1,
+				Status:    models.TaskStatusFailed,
+				Resources: resources,
+			},
+			// the task created by previous versions
+			{
+				ID:     2,
+				Status: models.TaskStatusStopped,
+			},
+		},
+	}
+	scheduler := &fakedRetryScheduler{}
+	policy := &model.Policy{
+		SrcRegistry: &model.Registry{
+			ID: 0,
+		},
+		DestRegistry: &model.Registry{
+			ID:                 1,
+			MaxConcurrentTasks: 2,
+		},
+	}
+	flow := NewRetryFlow(executionMgr, scheduler, 1, policy)
+	n, err := flow.Run(nil)
+	require.Nil(t, err)
+	assert.Equal(t, 1, n)
+	require.Equal(t, 1, len(scheduler.items))
+	item := scheduler.items[0]
+	assert.Equal(t, int64(1), item.TaskID)
+	assert.Equal(t, policy.SrcRegistry, item.SrcResource.Registry)
+	assert.Equal(t, policy.DestRegistry, item.DstResource.Registry)
+	assert.Equal(t, 1, len(item.DstResource.Limits))
+
+	// no tasks to retry
+	executionMgr.tasks = nil
+	n, err = flow.Run(nil)
+	require.Nil(t, err)
+	assert.Equal(t, 0, n)
+}
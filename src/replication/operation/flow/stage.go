@@ -15,6 +15,7 @@
 package flow
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -61,11 +62,16 @@ func initialize(policy *model.Policy) (adp.Adapter, adp.Adapter, error) {
 func fetchResources(adapter adp.Adapter, policy *model.Policy) ([]*model.Resource, error) {
 	var resTypes []model.ResourceType
 	var filters []*model.Filter
+	excludedTypes := map[model.ResourceType]struct{}{}
 	for _, filter := range policy.Filters {
 		if filter.Type != model.FilterTypeResource {
 			filters = append(filters, filter)
 			continue
 		}
+		if filter.IsExclusion() {
+			excludedTypes[filter.Value.(model.ResourceType)] = struct{}{}
+			continue
+		}
 		resTypes = append(resTypes, filter.Value.(model.ResourceType))
 	}
 	if len(resTypes) == 0 {
@@ -79,6 +85,9 @@ func fetchResources(adapter adp.Adapter, policy *model.Policy) ([]*model.Resourc
 	resources := []*model.Resource{}
 	// convert the adapter to different interfaces according to its required resource types
 	for _, typ := range resTypes {
+		if _, exist := excludedTypes[typ]; exist {
+			continue
+		}
 		var res []*model.Resource
 		var err error
 		if typ == model.ResourceTypeImage {
@@ -109,70 +118,15 @@ func fetchResources(adapter adp.Adapter, policy *model.Policy) ([]*model.Resourc
 	return resources, nil
 }
 
-// apply the filters to the resources and returns the filtered resources
-func filterResources(resources []*model.Resource, filters []*model.Filter) ([]*model.Resource, error) {
+// apply the filters to the resources and returns the filtered resources. The
+// labels, push time, signature and severity of vTags are listed from the source
+// registry by the adapter when the filters depend on them
+func filterResources(adapter adp.Adapter, resources []*model.Resource, filters []*model.Filter) ([]*model.Resource, error) {
 	var res []*model.Resource
 	for _, resource := range resources {
-		match := true
-	FILTER_LOOP:
-		for _, filter := range filters {
-			switch filter.Type {
-			case model.FilterTypeResource:
-				resourceType, ok := filter.Value.(model.ResourceType)
-				if !ok {
-					return nil, fmt.Errorf("%v is not a valid string", filter.Value)
-				}
-				if model.ResourceType(resourceType) != resource.Type {
-					match = false
-					break FILTER_LOOP
-				}
-			case model.FilterTypeName:
-				pattern, ok := filter.Value.(string)
-				if !ok {
-					return nil, fmt.Errorf("%v is not a valid string", filter.Value)
-				}
-				if resource.Metadata == nil {
-					match = false
-					break FILTER_LOOP
-				}
-				m, err := util.Match(pattern, resource.Metadata.Repository.Name)
-				if err != nil {
-					return nil, err
-				}
-				if !m {
-					match = false
-					break FILTER_LOOP
-				}
-			case model.FilterTypeTag:
-				pattern, ok := filter.Value.(string)
-				if !ok {
-					return nil, fmt.Errorf("%v is not a valid string", filter.Value)
-				}
-				if resource.Metadata == nil {
-					match = false
-					break FILTER_LOOP
-				}
-				var versions []string
-				for _, version := range resource.Metadata.Vtags {
-					m, err := util.Match(pattern, version)
-					if err != nil {
-						return nil, err
-					}
-					if m {
-						versions = append(versions, version)
-					}
-				}
-				if len(versions) == 0 {
-					match = false
-					break FILTER_LOOP
-				}
-				// NOTE: the property "Vtags" of the origin resource struct is overrided here
-				resource.Metadata.Vtags = versions
-			case model.FilterTypeLabel:
-				// TODO add support to label
-			default:
-				return nil, fmt.Errorf("unsupportted filter type: %v", filter.Type)
-			}
+		match, err := filterResource(adapter, resource, filters)
+		if err != nil {
+			return nil, err
 		}
 		if match {
 			res = append(res, resource)
@@ -182,6 +136,112 @@ func filterResources(resources []*model.Resource, filters []*model.Filter) ([]*m
 	return res, nil
 }
 
+func filterResource(adapter adp.Adapter, resource *model.Resource, filters []*model.Filter) (bool, error) {
+	var repositoryFilters, vTagFilters []*model.Filter
+	detailRequired := false
+	for _, filter := range filters {
+		switch filter.Type {
+		case model.FilterTypeResource:
+			resourceType, ok := filter.Value.(model.ResourceType)
+			if !ok {
+				return false, fmt.Errorf("%v is not a valid string", filter.Value)
+			}
+			if (resourceType == resource.Type) == filter.IsExclusion() {
+				return false, nil
+			}
+		case model.FilterTypeName, model.FilterTypeNameRegex:
+			repositoryFilters = append(repositoryFilters, filter)
+		case model.FilterTypeTag, model.FilterTypeTagRegex, model.FilterTypeTagSemver:
+			vTagFilters = append(vTagFilters, filter)
+		case model.FilterTypeLabel, model.FilterTypePushedWithin,
+			model.FilterTypeSigned, model.FilterTypeSeverity:
+			// the information isn't available any more for the deleted resources
+			if resource.Deleted {
+				continue
+			}
+			vTagFilters = append(vTagFilters, filter)
+			detailRequired = true
+		default:
+			return false, fmt.Errorf("unsupportted filter type: %v", filter.Type)
+		}
+	}
+	if len(repositoryFilters) == 0 && len(vTagFilters) == 0 {
+		return true, nil
+	}
+	if resource.Metadata == nil || resource.Metadata.Repository == nil {
+		return false, nil
+	}
+
+	repositories := []*adp.Repository{
+		{
+			ResourceType: string(resource.Type),
+			Name:         resource.Metadata.Repository.Name,
+		},
+	}
+	for _, filter := range repositoryFilters {
+		if err := filter.DoFilter(&repositories); err != nil {
+			return false, err
+		}
+	}
+	if len(repositories) == 0 {
+		return false, nil
+	}
+	if len(vTagFilters) == 0 {
+		return true, nil
+	}
+
+	vTags, err := getVTags(adapter, resource, detailRequired)
+	if err != nil {
+		return false, err
+	}
+	for _, filter := range vTagFilters {
+		if err = filter.DoFilter(&vTags); err != nil {
+			return false, err
+		}
+	}
+	if len(vTags) == 0 {
+		return false, nil
+	}
+	var versions []string
+	for _, vTag := range vTags {
+		versions = append(versions, vTag.Name)
+	}
+	// NOTE: the property "Vtags" of the origin resource struct is overrided here
+	resource.Metadata.Vtags = versions
+	return true, nil
+}
+
+// get the vTags of the resource, the detail information of vTags is listed from the
+// source registry if it is required and the adapter supports it
+func getVTags(adapter adp.Adapter, resource *model.Resource, detailRequired bool) ([]*adp.VTag, error) {
+	vTags := []*adp.VTag{}
+	lister, ok := adapter.(adp.VTagLister)
+	if !detailRequired || !ok {
+		for _, version := range resource.Metadata.Vtags {
+			vTags = append(vTags, &adp.VTag{
+				ResourceType: string(resource.Type),
+				Name:         version,
+			})
+		}
+		return vTags, nil
+	}
+
+	all, err := lister.ListVTags(resource.Type, resource.Metadata.Repository.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of %s: %v", resource.Metadata.Repository.Name, err)
+	}
+	versions := map[string]struct{}{}
+	for _, version := range resource.Metadata.Vtags {
+		versions[version] = struct{}{}
+	}
+	for _, vTag := range all {
+		if _, exist := versions[vTag.Name]; exist {
+			vTags = append(vTags, vTag)
+		}
+	}
+	return vTags, nil
+}
+
 // assemble the source resources by filling the registry information
 func assembleSourceResources(resources []*model.Resource,
 	policy *model.Policy) []*model.Resource {
@@ -192,10 +252,12 @@ func assembleSourceResources(resources []*model.Resource,
 	return resources
 }
 
-// assemble the destination resources by filling the metadata, registry and override properties
+// assemble the destination resources by filling the metadata, registry, override, verify digest, platforms,
+// metadata sync and limits properties
 func assembleDestinationResources(resources []*model.Resource,
-	policy *model.Policy) []*model.Resource {
+	policy *model.Policy) ([]*model.Resource, error) {
 	var result []*model.Resource
+	limits := model.GetLimits(policy)
 	for _, resource := range resources {
 		res := &model.Resource{
 			Type:         resource.Type,
@@ -203,10 +265,20 @@ func assembleDestinationResources(resources []*model.Resource,
 			ExtendedInfo: resource.ExtendedInfo,
 			Deleted:      resource.Deleted,
 			Override:     policy.Override,
+			VerifyDigest: policy.VerifyDigest,
+			Platforms:    policy.Platforms,
+			MetadataSync: policy.MetadataSync,
+			Limits:       limits,
+		}
+		name, err := model.RewriteRepository(
+			replaceNamespace(resource.Metadata.Repository.Name, policy.DestNamespace),
+			policy.DestRepoRules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite the destination repository: %v", err)
 		}
 		res.Metadata = &model.ResourceMetadata{
 			Repository: &model.Repository{
-				Name:     replaceNamespace(resource.Metadata.Repository.Name, policy.DestNamespace),
+				Name:     name,
 				Metadata: resource.Metadata.Repository.Metadata,
 			},
 			Vtags: resource.Metadata.Vtags,
@@ -214,7 +286,7 @@ func assembleDestinationResources(resources []*model.Resource,
 		result = append(result, res)
 	}
 	log.Debug("assemble the destination resources completed")
-	return result
+	return result, nil
 }
 
 // do the prepare work for pushing/uploading the resources: create the namespace or repository
@@ -244,6 +316,11 @@ func createTasks(mgr execution.Manager, executionID int64, items []*scheduler.Sc
 			operation = "deletion"
 		}
 
+		resources, err := marshalTaskResources(item.SrcResource, item.DstResource)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the resources of task for the execution %d: %v", executionID, err)
+		}
+
 		task := &models.Task{
 			ExecutionID:  executionID,
 			Status:       models.TaskStatusInitialized,
@@ -251,6 +328,7 @@ func createTasks(mgr execution.Manager, executionID int64, items []*scheduler.Sc
 			SrcResource:  getResourceName(item.SrcResource),
 			DstResource:  getResourceName(item.DstResource),
 			Operation:    operation,
+			Resources:    resources,
 		}
 
 		id, err := mgr.CreateTask(task)
@@ -267,6 +345,38 @@ func createTasks(mgr execution.Manager, executionID int64, items []*scheduler.Sc
 	return nil
 }
 
+// the resources of task persisted in database. The registries are removed as they
+// contain the credentials, populate them from the policy when using the resources
+type taskResources struct {
+	SrcResource *model.Resource `json:"src_resource"`
+	DstResource *model.Resource `json:"dst_resource"`
+}
+
+func marshalTaskResources(src, dst *model.Resource) (string, error) {
+	srcResource, dstResource := *src, *dst
+	srcResource.Registry = nil
+	dstResource.Registry = nil
+	data, err := json.Marshal(&taskResources{
+		SrcResource: &srcResource,
+		DstResource: &dstResource,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func unmarshalTaskResources(str string) (*model.Resource, *model.Resource, error) {
+	resources := &taskResources{}
+	if err := json.Unmarshal([]byte(str), resources); err != nil {
+		return nil, nil, err
+	}
+	if resources.SrcResource == nil || resources.DstResource == nil {
+		return nil, nil, errors.New("the source or destination resource is null")
+	}
+	return resources.SrcResource, resources.DstResource, nil
+}
+
 // schedule the replication tasks and update the task's status
 // returns the count of tasks which have been scheduled and the error
 func schedule(scheduler scheduler.Scheduler, executionMgr execution.Manager, items []*scheduler.ScheduleItem) (int, error) {
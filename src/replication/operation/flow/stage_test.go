@@ -18,6 +18,7 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/docker/distribution"
 	"github.com/goharbor/harbor/src/replication/adapter"
@@ -88,6 +89,28 @@ func (f *fakedAdapter) PullBlob(repository, digest string) (size int64, blob io.
 func (f *fakedAdapter) PushBlob(repository, digest string, size int64, blob io.Reader) error {
 	return nil
 }
+func (f *fakedAdapter) ListVTags(resourceType model.ResourceType, repository string) ([]*adapter.VTag, error) {
+	return []*adapter.VTag{
+		{
+			ResourceType: string(model.ResourceTypeImage),
+			Name:         "1.0",
+			PushTime:     time.Now().AddDate(0, 0, -30),
+			Signed:       true,
+		},
+		{
+			ResourceType: string(model.ResourceTypeImage),
+			Name:         "1.1",
+			PushTime:     time.Now(),
+			Signed:       true,
+		},
+		{
+			ResourceType: string(model.ResourceTypeImage),
+			Name:         "1.2",
+			PushTime:     time.Now(),
+		},
+	}, nil
+}
+
 func (f *fakedAdapter) FetchCharts(filters []*model.Filter) ([]*model.Resource, error) {
 	return []*model.Resource{
 		{
@@ -268,12 +291,97 @@ func TestFilterResources(t *testing.T) {
 			Value: "0.2.?",
 		},
 	}
-	res, err := filterResources(resources, filters)
+	res, err := filterResources(nil, resources, filters)
 	require.Nil(t, err)
 	assert.Equal(t, 1, len(res))
 	assert.Equal(t, "library/harbor", res[0].Metadata.Repository.Name)
 	assert.Equal(t, 1, len(res[0].Metadata.Vtags))
 	assert.Equal(t, "0.2.0", res[0].Metadata.Vtags[0])
+
+	// exclusions
+	resources = []*model.Resource{
+		{
+			Type: model.ResourceTypeImage,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "library/hello-world",
+				},
+				Vtags: []string{"1.0", "1.1", "dev-1.2"},
+			},
+		},
+		{
+			Type: model.ResourceTypeImage,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "library/busybox",
+				},
+				Vtags: []string{"1.0"},
+			},
+		},
+	}
+	filters = []*model.Filter{
+		{
+			Type:       model.FilterTypeName,
+			Value:      "library/busybox",
+			Decoration: model.FilterDecorationExcludes,
+		},
+		{
+			Type:       model.FilterTypeTagRegex,
+			Value:      "^dev-",
+			Decoration: model.FilterDecorationExcludes,
+		},
+	}
+	res, err = filterResources(nil, resources, filters)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(res))
+	assert.Equal(t, "library/hello-world", res[0].Metadata.Repository.Name)
+	assert.Equal(t, []string{"1.0", "1.1"}, res[0].Metadata.Vtags)
+}
+
+func TestFilterResourcesByDetail(t *testing.T) {
+	resources := []*model.Resource{
+		{
+			Type: model.ResourceTypeImage,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "library/hello-world",
+				},
+				Vtags: []string{"1.0", "1.1", "1.2"},
+			},
+		},
+	}
+	filters := []*model.Filter{
+		{
+			Type:  model.FilterTypePushedWithin,
+			Value: 7,
+		},
+		{
+			Type:  model.FilterTypeSigned,
+			Value: true,
+		},
+	}
+	res, err := filterResources(&fakedAdapter{}, resources, filters)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(res))
+	assert.Equal(t, []string{"1.1"}, res[0].Metadata.Vtags)
+
+	// the filters depending on the detail information are ignored for the deleted resources
+	resources = []*model.Resource{
+		{
+			Type: model.ResourceTypeImage,
+			Metadata: &model.ResourceMetadata{
+				Repository: &model.Repository{
+					Name: "library/hello-world",
+				},
+				Vtags: []string{"1.0"},
+			},
+			Deleted: true,
+		},
+	}
+	res, err = filterResources(&fakedAdapter{}, resources, filters)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(res))
+	assert.Equal(t, []string{"1.0"}, res[0].Metadata.Vtags)
 }
 
 func TestAssembleSourceResources(t *testing.T) {
@@ -316,13 +424,48 @@ func TestAssembleDestinationResources(t *testing.T) {
 		DestRegistry:  &model.Registry{},
 		DestNamespace: "test",
 		Override:      true,
+		Platforms: []*model.Platform{
+			{
+				OS:           "linux",
+				Architecture: "arm64",
+			},
+		},
+		MaxConcurrentTasks: 1,
 	}
-	res := assembleDestinationResources(resources, policy)
+	res, err := assembleDestinationResources(resources, policy)
+	require.Nil(t, err)
 	assert.Equal(t, 1, len(res))
 	assert.Equal(t, model.ResourceTypeChart, res[0].Type)
 	assert.Equal(t, "test/hello-world", res[0].Metadata.Repository.Name)
 	assert.Equal(t, 1, len(res[0].Metadata.Vtags))
 	assert.Equal(t, "latest", res[0].Metadata.Vtags[0])
+	assert.True(t, res[0].Override)
+	assert.Equal(t, policy.Platforms, res[0].Platforms)
+	require.Equal(t, 1, len(res[0].Limits))
+	assert.Equal(t, 1, res[0].Limits[0].MaxConcurrentTasks)
+
+	// with rewrite rules
+	policy.DestNamespace = ""
+	policy.DestRepoRules = []*model.RewriteRule{
+		{
+			Type:        model.RewriteRuleTypeRegex,
+			Pattern:     "^library/(.*)$",
+			Replacement: "mirror/$1",
+		},
+	}
+	res, err = assembleDestinationResources(resources, policy)
+	require.Nil(t, err)
+	assert.Equal(t, "mirror/hello-world", res[0].Metadata.Repository.Name)
+
+	// rewritten to an invalid repository name
+	policy.DestRepoRules = []*model.RewriteRule{
+		{
+			Type:  model.RewriteRuleTypeAddSuffix,
+			Value: "/",
+		},
+	}
+	_, err = assembleDestinationResources(resources, policy)
+	require.NotNil(t, err)
 }
 
 func TestPreprocess(t *testing.T) {
@@ -31,9 +31,15 @@ type fakedOperationController struct {
 func (f *fakedOperationController) StartReplication(*model.Policy, *model.Resource, model.TriggerType) (int64, error) {
 	return 0, nil
 }
+func (f *fakedOperationController) PlanReplication(*model.Policy) (*model.Plan, error) {
+	return nil, nil
+}
 func (f *fakedOperationController) StopReplication(int64) error {
 	return nil
 }
+func (f *fakedOperationController) RetryReplication(policy *model.Policy, executionID int64) error {
+	return nil
+}
 func (f *fakedOperationController) ListExecutions(...*models.ExecutionQuery) (int64, []*models.Execution, error) {
 	return 0, nil, nil
 }
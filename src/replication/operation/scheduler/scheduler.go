@@ -117,6 +117,7 @@ func (d *defaultScheduler) Schedule(items []*ScheduleItem) ([]*ScheduleResult, e
 		j.Parameters = map[string]interface{}{
 			"src_resource": string(src),
 			"dst_resource": string(dest),
+			"task_id":      item.TaskID,
 		}
 		id, joberr := d.client.SubmitJob(j)
 		if joberr != nil {
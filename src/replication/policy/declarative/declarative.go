@@ -0,0 +1,450 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declarative
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/astaxie/beego/validation"
+	"github.com/ghodss/yaml"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/goharbor/harbor/src/replication/policy"
+	"github.com/goharbor/harbor/src/replication/registry"
+)
+
+// const definition
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// Document is the declarative representation of a set of replication policies,
+// it can be serialized to YAML or JSON and kept in the version control system
+type Document struct {
+	Policies []*Policy `json:"policies"`
+}
+
+// Policy is the declarative representation of the replication policy. The registries
+// are referenced by their names rather than IDs so that the document can be applied
+// to different Harbor instances, the empty name refers to the local Harbor registry
+type Policy struct {
+	Name               string               `json:"name"`
+	Description        string               `json:"description,omitempty"`
+	SrcRegistry        string               `json:"src_registry,omitempty"`
+	DestRegistry       string               `json:"dest_registry,omitempty"`
+	DestNamespace      string               `json:"dest_namespace,omitempty"`
+	DestRepoRules      []*model.RewriteRule `json:"dest_repo_rules,omitempty"`
+	Platforms          []*model.Platform    `json:"platforms,omitempty"`
+	Filters            []*model.Filter      `json:"filters,omitempty"`
+	Trigger            *model.Trigger       `json:"trigger,omitempty"`
+	Deletion           bool                 `json:"deletion"`
+	Override           bool                 `json:"override"`
+	VerifyDigest       bool                 `json:"verify_digest"`
+	MetadataSync       *model.MetadataSync  `json:"metadata_sync,omitempty"`
+	MaxConcurrentTasks int                  `json:"max_concurrent_tasks,omitempty"`
+	MaxBytesPerSecond  int64                `json:"max_bytes_per_second,omitempty"`
+	Enabled            bool                 `json:"enabled"`
+}
+
+// InvalidError is returned when the document cannot be applied because of the
+// content of it, e.g. the policy is invalid or the registry referenced doesn't exist
+type InvalidError struct {
+	message string
+}
+
+func (i *InvalidError) Error() string {
+	return i.message
+}
+
+func invalidf(format string, args ...interface{}) error {
+	return &InvalidError{
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Parse the document in YAML or JSON format, as JSON is a subset of YAML
+func Parse(data []byte) (*Document, error) {
+	doc := &Document{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Marshal the document in the specified format
+func Marshal(doc *Document, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(doc)
+	case FormatJSON:
+		return json.MarshalIndent(doc, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// Export converts the policies to the declarative document
+func Export(registryMgr registry.Manager, policies ...*model.Policy) (*Document, error) {
+	names := map[int64]string{}
+	doc := &Document{
+		Policies: []*Policy{},
+	}
+	for _, p := range policies {
+		src, err := registryName(registryMgr, p.SrcRegistry, names)
+		if err != nil {
+			return nil, err
+		}
+		dest, err := registryName(registryMgr, p.DestRegistry, names)
+		if err != nil {
+			return nil, err
+		}
+		doc.Policies = append(doc.Policies, &Policy{
+			Name:               p.Name,
+			Description:        p.Description,
+			SrcRegistry:        src,
+			DestRegistry:       dest,
+			DestNamespace:      p.DestNamespace,
+			DestRepoRules:      p.DestRepoRules,
+			Platforms:          p.Platforms,
+			Filters:            p.Filters,
+			Trigger:            p.Trigger,
+			Deletion:           p.Deletion,
+			Override:           p.Override,
+			VerifyDigest:       p.VerifyDigest,
+			MetadataSync:       p.MetadataSync,
+			MaxConcurrentTasks: p.MaxConcurrentTasks,
+			MaxBytesPerSecond:  p.MaxBytesPerSecond,
+			Enabled:            p.Enabled,
+		})
+	}
+	return doc, nil
+}
+
+// return the name of the registry, the names are cached in the map to avoid querying
+// the same registry repeatedly
+func registryName(registryMgr registry.Manager, reg *model.Registry, names map[int64]string) (string, error) {
+	// the local Harbor registry
+	if reg == nil || reg.ID == 0 {
+		return "", nil
+	}
+	if name, exist := names[reg.ID]; exist {
+		return name, nil
+	}
+	r, err := registryMgr.Get(reg.ID)
+	if err != nil {
+		return "", err
+	}
+	if r == nil {
+		return "", fmt.Errorf("registry %d not found", reg.ID)
+	}
+	names[reg.ID] = r.Name
+	return r.Name, nil
+}
+
+// Plan describes the changes that applying the document makes
+type Plan struct {
+	// the policies to be created
+	Creations []*model.Policy
+	// the existing policies to be updated
+	Updates []*model.Policy
+	// the existing policies that are same with the declared ones
+	Unchanged []*model.Policy
+	// the existing policies not declared in the document, they're removed when pruning
+	Deletions []*model.Policy
+	// the existing policies to be updated, keyed by their IDs, used to roll back the updates
+	origins map[int64]*model.Policy
+}
+
+// Summary is the names of the policies created, updated, unchanged and deleted
+type Summary struct {
+	DryRun    bool     `json:"dry_run"`
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+	Deleted   []string `json:"deleted"`
+}
+
+// Options of importing the document
+type Options struct {
+	// update the existing policies that have the same names with the declared ones,
+	// an error is returned if any of them exists when it is false
+	Upsert bool
+	// remove the existing policies that aren't declared in the document
+	Prune bool
+	// the creator of the policies created
+	Creator string
+}
+
+// NewPlan compares the declared policies with the existing ones and returns the changes
+// needed to make the existing policies match the document
+func NewPlan(ctl policy.Controller, registryMgr registry.Manager, doc *Document, options *Options) (*Plan, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	_, existingPolicies, err := ctl.List()
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]*model.Policy{}
+	for _, p := range existingPolicies {
+		existing[p.Name] = p
+	}
+
+	plan := &Plan{
+		origins: map[int64]*model.Policy{},
+	}
+	declared := map[string]bool{}
+	ids := map[string]int64{}
+	for _, p := range doc.Policies {
+		if p == nil {
+			return nil, invalidf("the policy cannot be null")
+		}
+		if declared[p.Name] {
+			return nil, invalidf("the policy %s is declared more than once", p.Name)
+		}
+		declared[p.Name] = true
+
+		ply, err := toPolicy(registryMgr, p, ids)
+		if err != nil {
+			return nil, err
+		}
+		if err = validate(ply); err != nil {
+			return nil, err
+		}
+
+		origin, exist := existing[p.Name]
+		if !exist {
+			ply.Creator = options.Creator
+			plan.Creations = append(plan.Creations, ply)
+			continue
+		}
+		same, err := equal(registryMgr, origin, p)
+		if err != nil {
+			return nil, err
+		}
+		if same {
+			plan.Unchanged = append(plan.Unchanged, origin)
+			continue
+		}
+		if !options.Upsert {
+			return nil, invalidf("policy %s already exists", p.Name)
+		}
+		ply.ID = origin.ID
+		ply.Creator = origin.Creator
+		// the health warning is maintained by the registry health check
+		ply.HealthWarning = origin.HealthWarning
+		plan.Updates = append(plan.Updates, ply)
+		plan.origins[origin.ID] = origin
+	}
+
+	if options.Prune {
+		for _, p := range existingPolicies {
+			if !declared[p.Name] {
+				plan.Deletions = append(plan.Deletions, p)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// Apply the changes of the plan. The plan is checked against the current policies before
+// any change is made, and the changes already made are rolled back if a later one fails,
+// so that the policies are either all changed or left as they were
+func (p *Plan) Apply(ctl policy.Controller) error {
+	if err := p.check(ctl); err != nil {
+		return err
+	}
+
+	var rollbacks []func() error
+	rollback := func(cause error) error {
+		for i := len(rollbacks) - 1; i >= 0; i-- {
+			if err := rollbacks[i](); err != nil {
+				log.Errorf("failed to roll back the import of replication policies: %v", err)
+				return fmt.Errorf("%v, and the rollback failed: %v", cause, err)
+			}
+		}
+		return cause
+	}
+	for _, ply := range p.Creations {
+		id, err := ctl.Create(ply)
+		if err != nil {
+			return rollback(fmt.Errorf("failed to create the policy %s: %v", ply.Name, err))
+		}
+		ply.ID = id
+		rollbacks = append(rollbacks, func() error {
+			return ctl.Remove(id)
+		})
+	}
+	for _, ply := range p.Updates {
+		if err := ctl.Update(ply); err != nil {
+			return rollback(fmt.Errorf("failed to update the policy %s: %v", ply.Name, err))
+		}
+		origin := p.origins[ply.ID]
+		rollbacks = append(rollbacks, func() error {
+			return ctl.Update(origin)
+		})
+	}
+	for _, ply := range p.Deletions {
+		if err := ctl.Remove(ply.ID); err != nil {
+			return rollback(fmt.Errorf("failed to delete the policy %s: %v", ply.Name, err))
+		}
+		// the policy is re-created with a new ID as the deletion cannot be undone
+		deleted := *ply
+		rollbacks = append(rollbacks, func() error {
+			_, err := ctl.Create(&deleted)
+			return err
+		})
+	}
+	return nil
+}
+
+// check whether the plan still matches the current policies, which may be changed by
+// others after the plan is built, and returns an InvalidError if it doesn't
+func (p *Plan) check(ctl policy.Controller) error {
+	_, policies, err := ctl.List()
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	ids := map[int64]bool{}
+	for _, ply := range policies {
+		names[ply.Name] = true
+		ids[ply.ID] = true
+	}
+	for _, ply := range p.Creations {
+		if names[ply.Name] {
+			return invalidf("policy %s already exists", ply.Name)
+		}
+	}
+	for _, plys := range [][]*model.Policy{p.Updates, p.Deletions} {
+		for _, ply := range plys {
+			if !ids[ply.ID] {
+				return invalidf("policy %s not found", ply.Name)
+			}
+		}
+	}
+	for _, ply := range p.Updates {
+		if _, exist := p.origins[ply.ID]; !exist {
+			return fmt.Errorf("the original of policy %s is unknown", ply.Name)
+		}
+	}
+	return nil
+}
+
+// Summary returns the summary of the plan
+func (p *Plan) Summary(dryRun bool) *Summary {
+	return &Summary{
+		DryRun:    dryRun,
+		Created:   policyNames(p.Creations),
+		Updated:   policyNames(p.Updates),
+		Unchanged: policyNames(p.Unchanged),
+		Deleted:   policyNames(p.Deletions),
+	}
+}
+
+func policyNames(policies []*model.Policy) []string {
+	names := []string{}
+	for _, p := range policies {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// convert the declarative policy to the replication policy, the IDs of the registries
+// are cached in the map to avoid querying the same registry repeatedly
+func toPolicy(registryMgr registry.Manager, p *Policy, ids map[string]int64) (*model.Policy, error) {
+	ply := &model.Policy{
+		Name:               p.Name,
+		Description:        p.Description,
+		DestNamespace:      p.DestNamespace,
+		DestRepoRules:      p.DestRepoRules,
+		Platforms:          p.Platforms,
+		Filters:            p.Filters,
+		Trigger:            p.Trigger,
+		Deletion:           p.Deletion,
+		Override:           p.Override,
+		VerifyDigest:       p.VerifyDigest,
+		MetadataSync:       p.MetadataSync,
+		MaxConcurrentTasks: p.MaxConcurrentTasks,
+		MaxBytesPerSecond:  p.MaxBytesPerSecond,
+		Enabled:            p.Enabled,
+	}
+	for _, item := range []struct {
+		name     string
+		registry **model.Registry
+	}{
+		{p.SrcRegistry, &ply.SrcRegistry},
+		{p.DestRegistry, &ply.DestRegistry},
+	} {
+		// the local Harbor registry
+		if len(item.name) == 0 {
+			*item.registry = &model.Registry{}
+			continue
+		}
+		id, exist := ids[item.name]
+		if !exist {
+			r, err := registryMgr.GetByName(item.name)
+			if err != nil {
+				return nil, err
+			}
+			if r == nil {
+				return nil, invalidf("the registry %s referenced by policy %s not found", item.name, p.Name)
+			}
+			id = r.ID
+			ids[item.name] = id
+		}
+		*item.registry = &model.Registry{
+			ID: id,
+		}
+	}
+	return ply, nil
+}
+
+func validate(policy *model.Policy) error {
+	v := &validation.Validation{}
+	isValid, err := v.Valid(policy)
+	if err != nil {
+		return err
+	}
+	if isValid {
+		return nil
+	}
+	messages := []string{}
+	for _, e := range v.Errors {
+		messages = append(messages, fmt.Sprintf("%s %s", e.Field, e.Message))
+	}
+	return invalidf("invalid policy %s: %s", policy.Name, strings.Join(messages, "; "))
+}
+
+// compare the existing policy with the declared one by their serialized declarative
+// representations, which ignores the properties that cannot be declared, e.g. ID
+func equal(registryMgr registry.Manager, existing *model.Policy, declared *Policy) (bool, error) {
+	doc, err := Export(registryMgr, existing)
+	if err != nil {
+		return false, err
+	}
+	a, err := json.Marshal(doc.Policies[0])
+	if err != nil {
+		return false, err
+	}
+	b, err := json.Marshal(declared)
+	if err != nil {
+		return false, err
+	}
+	return string(a) == string(b), nil
+}
@@ -0,0 +1,290 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package declarative
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goharbor/harbor/src/replication/dao/models"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakedRegistryManager struct{}
+
+func (f *fakedRegistryManager) Add(*model.Registry) (int64, error) {
+	return 0, nil
+}
+func (f *fakedRegistryManager) List(...*model.RegistryQuery) (int64, []*model.Registry, error) {
+	return 0, nil, nil
+}
+func (f *fakedRegistryManager) Get(id int64) (*model.Registry, error) {
+	if id == 1 {
+		return &model.Registry{ID: 1, Name: "remote"}, nil
+	}
+	return nil, nil
+}
+func (f *fakedRegistryManager) GetByName(name string) (*model.Registry, error) {
+	if name == "remote" {
+		return &model.Registry{ID: 1, Name: "remote"}, nil
+	}
+	return nil, nil
+}
+func (f *fakedRegistryManager) Update(*model.Registry, ...string) error {
+	return nil
+}
+func (f *fakedRegistryManager) Remove(int64) error {
+	return nil
+}
+func (f *fakedRegistryManager) HealthCheck() error {
+	return nil
+}
+func (f *fakedRegistryManager) ListHealth(*models.RegistryHealthQuery) (int64, []*models.RegistryHealth, error) {
+	return 0, nil, nil
+}
+
+type fakedPolicyController struct {
+	policies []*model.Policy
+	created  []string
+	updated  []string
+	removed  []int64
+	// the ID of the policy whose removal fails
+	failedRemoval int64
+}
+
+func (f *fakedPolicyController) Create(policy *model.Policy) (int64, error) {
+	f.created = append(f.created, policy.Name)
+	return 100, nil
+}
+func (f *fakedPolicyController) List(...*model.PolicyQuery) (int64, []*model.Policy, error) {
+	return int64(len(f.policies)), f.policies, nil
+}
+func (f *fakedPolicyController) Get(int64) (*model.Policy, error) {
+	return nil, nil
+}
+func (f *fakedPolicyController) GetByName(string) (*model.Policy, error) {
+	return nil, nil
+}
+func (f *fakedPolicyController) Update(policy *model.Policy) error {
+	f.updated = append(f.updated, policy.Name)
+	return nil
+}
+func (f *fakedPolicyController) Remove(id int64) error {
+	if id == f.failedRemoval {
+		return errors.New("failed to remove")
+	}
+	f.removed = append(f.removed, id)
+	return nil
+}
+
+func newPolicyController() *fakedPolicyController {
+	return &fakedPolicyController{
+		policies: []*model.Policy{
+			{
+				ID:           1,
+				Name:         "push",
+				DestRegistry: &model.Registry{ID: 1},
+				Filters: []*model.Filter{
+					{
+						Type:  model.FilterTypeName,
+						Value: "library/**",
+					},
+				},
+				Trigger: &model.Trigger{
+					Type: model.TriggerTypeManual,
+				},
+				Enabled: true,
+			},
+			{
+				ID:          2,
+				Name:        "pull",
+				SrcRegistry: &model.Registry{ID: 1},
+				Enabled:     true,
+			},
+			{
+				ID:           3,
+				Name:         "legacy",
+				DestRegistry: &model.Registry{ID: 1},
+			},
+		},
+	}
+}
+
+func TestExportAndParse(t *testing.T) {
+	ctl := newPolicyController()
+	doc, err := Export(&fakedRegistryManager{}, ctl.policies...)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(doc.Policies))
+	assert.Equal(t, "", doc.Policies[0].SrcRegistry)
+	assert.Equal(t, "remote", doc.Policies[0].DestRegistry)
+	assert.Equal(t, "remote", doc.Policies[1].SrcRegistry)
+	assert.Equal(t, "", doc.Policies[1].DestRegistry)
+
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		data, err := Marshal(doc, format)
+		require.Nil(t, err)
+		parsed, err := Parse(data)
+		require.Nil(t, err)
+		require.Equal(t, 3, len(parsed.Policies))
+		assert.Equal(t, "push", parsed.Policies[0].Name)
+		assert.Equal(t, "remote", parsed.Policies[0].DestRegistry)
+		assert.Equal(t, model.FilterTypeName, parsed.Policies[0].Filters[0].Type)
+		assert.Equal(t, "library/**", parsed.Policies[0].Filters[0].Value)
+	}
+
+	_, err = Marshal(doc, "xml")
+	assert.NotNil(t, err)
+
+	// registry not found
+	_, err = Export(&fakedRegistryManager{}, &model.Policy{
+		Name:         "invalid",
+		DestRegistry: &model.Registry{ID: 2},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestNewPlan(t *testing.T) {
+	registryMgr := &fakedRegistryManager{}
+	doc, err := Parse([]byte(`
+policies:
+- name: push
+  dest_registry: remote
+  filters:
+  - type: name
+    value: library/**
+  trigger:
+    type: manual
+    trigger_settings: null
+  enabled: true
+- name: pull
+  src_registry: remote
+  enabled: false
+- name: new
+  dest_registry: remote
+  enabled: true
+`))
+	require.Nil(t, err)
+
+	// the existing policy "pull" is changed
+	_, err = NewPlan(newPolicyController(), registryMgr, doc, nil)
+	require.NotNil(t, err)
+	_, ok := err.(*InvalidError)
+	assert.True(t, ok)
+
+	// upsert
+	ctl := newPolicyController()
+	plan, err := NewPlan(ctl, registryMgr, doc, &Options{Upsert: true, Creator: "admin"})
+	require.Nil(t, err)
+	summary := plan.Summary(true)
+	assert.True(t, summary.DryRun)
+	assert.Equal(t, []string{"new"}, summary.Created)
+	assert.Equal(t, []string{"pull"}, summary.Updated)
+	assert.Equal(t, []string{"push"}, summary.Unchanged)
+	assert.Equal(t, []string{}, summary.Deleted)
+	assert.Equal(t, "admin", plan.Creations[0].Creator)
+	assert.Equal(t, int64(2), plan.Updates[0].ID)
+	require.Nil(t, plan.Apply(ctl))
+	assert.Equal(t, []string{"new"}, ctl.created)
+	assert.Equal(t, []string{"pull"}, ctl.updated)
+	assert.Equal(t, 0, len(ctl.removed))
+
+	// upsert and prune
+	ctl = newPolicyController()
+	plan, err = NewPlan(ctl, registryMgr, doc, &Options{Upsert: true, Prune: true})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"legacy"}, plan.Summary(false).Deleted)
+	require.Nil(t, plan.Apply(ctl))
+	assert.Equal(t, []int64{3}, ctl.removed)
+}
+
+func TestApplyPlan(t *testing.T) {
+	registryMgr := &fakedRegistryManager{}
+	doc, err := Parse([]byte(`
+policies:
+- name: pull
+  src_registry: remote
+  enabled: false
+- name: new
+  dest_registry: remote
+  enabled: true
+`))
+	require.Nil(t, err)
+
+	// the policy with the same name is created after the plan is built
+	ctl := newPolicyController()
+	plan, err := NewPlan(ctl, registryMgr, doc, &Options{Upsert: true})
+	require.Nil(t, err)
+	ctl.policies = append(ctl.policies, &model.Policy{ID: 4, Name: "new"})
+	err = plan.Apply(ctl)
+	require.NotNil(t, err)
+	_, ok := err.(*InvalidError)
+	assert.True(t, ok)
+	assert.Equal(t, 0, len(ctl.created))
+	assert.Equal(t, 0, len(ctl.updated))
+
+	// the changes made are rolled back when the deletion of "legacy" fails
+	ctl = newPolicyController()
+	ctl.failedRemoval = 3
+	plan, err = NewPlan(ctl, registryMgr, doc, &Options{Upsert: true, Prune: true})
+	require.Nil(t, err)
+	require.NotNil(t, plan.Apply(ctl))
+	// "push" is removed before "legacy" and re-created when rolling back
+	assert.Equal(t, []string{"new", "push"}, ctl.created)
+	assert.Equal(t, []int64{1, 100}, ctl.removed)
+	// updated and then restored
+	assert.Equal(t, []string{"pull", "pull"}, ctl.updated)
+}
+
+func TestNewPlanWithInvalidDocument(t *testing.T) {
+	cases := []string{
+		// duplicated names
+		`
+policies:
+- name: a
+  dest_registry: remote
+- name: a
+  dest_registry: remote
+`,
+		// registry not found
+		`
+policies:
+- name: a
+  dest_registry: unknown
+`,
+		// the source and destination are both the local Harbor
+		`
+policies:
+- name: a
+`,
+		// invalid filter
+		`
+policies:
+- name: a
+  dest_registry: remote
+  filters:
+  - type: unknown
+`,
+	}
+	for _, c := range cases {
+		doc, err := Parse([]byte(c))
+		require.Nil(t, err)
+		_, err = NewPlan(newPolicyController(), &fakedRegistryManager{}, doc, &Options{Upsert: true})
+		require.NotNil(t, err)
+		_, ok := err.(*InvalidError)
+		assert.True(t, ok, err.Error())
+	}
+}
@@ -36,16 +36,20 @@ func convertFromPersistModel(policy *persist_models.RepPolicy) (*model.Policy, e
 	}
 
 	ply := model.Policy{
-		ID:            policy.ID,
-		Name:          policy.Name,
-		Description:   policy.Description,
-		Creator:       policy.Creator,
-		DestNamespace: policy.DestNamespace,
-		Deletion:      policy.ReplicateDeletion,
-		Override:      policy.Override,
-		Enabled:       policy.Enabled,
-		CreationTime:  policy.CreationTime,
-		UpdateTime:    policy.UpdateTime,
+		ID:                 policy.ID,
+		Name:               policy.Name,
+		Description:        policy.Description,
+		Creator:            policy.Creator,
+		DestNamespace:      policy.DestNamespace,
+		Deletion:           policy.ReplicateDeletion,
+		Override:           policy.Override,
+		VerifyDigest:       policy.VerifyDigest,
+		MaxConcurrentTasks: policy.MaxConcurrentTasks,
+		MaxBytesPerSecond:  policy.MaxBytesPerSecond,
+		HealthWarning:      policy.HealthWarning,
+		Enabled:            policy.Enabled,
+		CreationTime:       policy.CreationTime,
+		UpdateTime:         policy.UpdateTime,
 	}
 	if policy.SrcRegistryID > 0 {
 		ply.SrcRegistry = &model.Registry{
@@ -65,6 +69,27 @@ func convertFromPersistModel(policy *persist_models.RepPolicy) (*model.Policy, e
 	}
 	ply.Filters = filters
 
+	// parse the rewrite rules of destination repository
+	rules, err := parseRewriteRules(policy.DestRepoRules)
+	if err != nil {
+		return nil, err
+	}
+	ply.DestRepoRules = rules
+
+	// parse the platforms
+	platforms, err := parsePlatforms(policy.Platforms)
+	if err != nil {
+		return nil, err
+	}
+	ply.Platforms = platforms
+
+	// parse the settings of metadata sync
+	metadataSync, err := parseMetadataSync(policy.MetadataSync)
+	if err != nil {
+		return nil, err
+	}
+	ply.MetadataSync = metadataSync
+
 	// parse Trigger
 	trigger, err := parseTrigger(policy.Trigger)
 	if err != nil {
@@ -81,16 +106,20 @@ func convertToPersistModel(policy *model.Policy) (*persist_models.RepPolicy, err
 	}
 
 	ply := &persist_models.RepPolicy{
-		ID:                policy.ID,
-		Name:              policy.Name,
-		Description:       policy.Description,
-		Creator:           policy.Creator,
-		DestNamespace:     policy.DestNamespace,
-		Override:          policy.Override,
-		Enabled:           policy.Enabled,
-		ReplicateDeletion: policy.Deletion,
-		CreationTime:      policy.CreationTime,
-		UpdateTime:        time.Now(),
+		ID:                 policy.ID,
+		Name:               policy.Name,
+		Description:        policy.Description,
+		Creator:            policy.Creator,
+		DestNamespace:      policy.DestNamespace,
+		Override:           policy.Override,
+		VerifyDigest:       policy.VerifyDigest,
+		Enabled:            policy.Enabled,
+		ReplicateDeletion:  policy.Deletion,
+		MaxConcurrentTasks: policy.MaxConcurrentTasks,
+		MaxBytesPerSecond:  policy.MaxBytesPerSecond,
+		HealthWarning:      policy.HealthWarning,
+		CreationTime:       policy.CreationTime,
+		UpdateTime:         time.Now(),
 	}
 	if policy.SrcRegistry != nil {
 		ply.SrcRegistryID = policy.SrcRegistry.ID
@@ -115,6 +144,30 @@ func convertToPersistModel(policy *model.Policy) (*persist_models.RepPolicy, err
 		ply.Filters = string(filters)
 	}
 
+	if len(policy.DestRepoRules) > 0 {
+		rules, err := json.Marshal(policy.DestRepoRules)
+		if err != nil {
+			return nil, err
+		}
+		ply.DestRepoRules = string(rules)
+	}
+
+	if len(policy.Platforms) > 0 {
+		platforms, err := json.Marshal(policy.Platforms)
+		if err != nil {
+			return nil, err
+		}
+		ply.Platforms = string(platforms)
+	}
+
+	if policy.MetadataSync != nil {
+		metadataSync, err := json.Marshal(policy.MetadataSync)
+		if err != nil {
+			return nil, err
+		}
+		ply.MetadataSync = string(metadataSync)
+	}
+
 	return ply, nil
 }
 
@@ -199,10 +252,11 @@ func (m *DefaultManager) Remove(policyID int64) error {
 }
 
 type filter struct {
-	Type    model.FilterType `json:"type"`
-	Value   interface{}      `json:"value"`
-	Kind    string           `json:"kind"`
-	Pattern string           `json:"pattern"`
+	Type       model.FilterType `json:"type"`
+	Value      interface{}      `json:"value"`
+	Decoration string           `json:"decoration"`
+	Kind       string           `json:"kind"`
+	Pattern    string           `json:"pattern"`
 }
 
 type trigger struct {
@@ -230,8 +284,9 @@ func parseFilters(str string) ([]*model.Filter, error) {
 	filters := []*model.Filter{}
 	for _, item := range items {
 		filter := &model.Filter{
-			Type:  item.Type,
-			Value: item.Value,
+			Type:       item.Type,
+			Value:      item.Value,
+			Decoration: item.Decoration,
 		}
 		// keep backwards compatibility
 		if len(filter.Type) == 0 {
@@ -315,6 +370,39 @@ func parseTrigger(str string) (*model.Trigger, error) {
 	return trigger, nil
 }
 
+func parseRewriteRules(str string) ([]*model.RewriteRule, error) {
+	if len(str) == 0 {
+		return nil, nil
+	}
+	rules := []*model.RewriteRule{}
+	if err := json.Unmarshal([]byte(str), &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func parsePlatforms(str string) ([]*model.Platform, error) {
+	if len(str) == 0 {
+		return nil, nil
+	}
+	platforms := []*model.Platform{}
+	if err := json.Unmarshal([]byte(str), &platforms); err != nil {
+		return nil, err
+	}
+	return platforms, nil
+}
+
+func parseMetadataSync(str string) (*model.MetadataSync, error) {
+	if len(str) == 0 {
+		return nil, nil
+	}
+	metadataSync := &model.MetadataSync{}
+	if err := json.Unmarshal([]byte(str), metadataSync); err != nil {
+		return nil, err
+	}
+	return metadataSync, nil
+}
+
 func parseScheduleParamToCron(param *scheduleParam) string {
 	if param == nil {
 		return ""
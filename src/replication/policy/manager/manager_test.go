@@ -47,6 +47,16 @@ func Test_convertFromPersistModel(t *testing.T) {
 			from: &persist_models.RepPolicy{Trigger: "abc"},
 			want: nil, wantErr: true,
 		},
+		{
+			name: "parse Rewrite Rules Error",
+			from: &persist_models.RepPolicy{DestRepoRules: "abc"},
+			want: nil, wantErr: true,
+		},
+		{
+			name: "parse Platforms Error",
+			from: &persist_models.RepPolicy{Platforms: "abc"},
+			want: nil, wantErr: true,
+		},
 		{
 			name: "Persist Model", from: &persist_models.RepPolicy{
 				ID:                999,
@@ -58,9 +68,13 @@ func Test_convertFromPersistModel(t *testing.T) {
 				DestNamespace:     "target_ns",
 				ReplicateDeletion: true,
 				Override:          true,
+				VerifyDigest:      true,
 				Enabled:           true,
 				Trigger:           "",
 				Filters:           "[]",
+				DestRepoRules:     "[{\"type\":\"keep_last\",\"count\":2}]",
+				Platforms:         "[{\"os\":\"linux\",\"architecture\":\"arm64\"}]",
+				MetadataSync:      "{\"labels\":true,\"description\":false,\"signatures\":true}",
 			}, want: &model.Policy{
 				ID:          999,
 				Name:        "Policy Test",
@@ -75,9 +89,13 @@ func Test_convertFromPersistModel(t *testing.T) {
 				DestNamespace: "target_ns",
 				Deletion:      true,
 				Override:      true,
+				VerifyDigest:  true,
 				Enabled:       true,
 				Trigger:       nil,
 				Filters:       []*model.Filter{},
+				DestRepoRules: []*model.RewriteRule{{Type: model.RewriteRuleTypeKeepLast, Count: 2}},
+				Platforms:     []*model.Platform{{OS: "linux", Architecture: "arm64"}},
+				MetadataSync:  &model.MetadataSync{Labels: true, Signatures: true},
 			},
 		},
 	}
@@ -105,9 +123,13 @@ func Test_convertFromPersistModel(t *testing.T) {
 			assert.Equal(t, tt.want.DestNamespace, got.DestNamespace)
 			assert.Equal(t, tt.want.Deletion, got.Deletion)
 			assert.Equal(t, tt.want.Override, got.Override)
+			assert.Equal(t, tt.want.VerifyDigest, got.VerifyDigest)
 			assert.Equal(t, tt.want.Enabled, got.Enabled)
 			assert.Equal(t, tt.want.Trigger, got.Trigger)
 			assert.Equal(t, tt.want.Filters, got.Filters)
+			assert.Equal(t, tt.want.DestRepoRules, got.DestRepoRules)
+			assert.Equal(t, tt.want.Platforms, got.Platforms)
+			assert.Equal(t, tt.want.MetadataSync, got.MetadataSync)
 
 		})
 	}
@@ -136,9 +158,13 @@ func Test_convertToPersistModel(t *testing.T) {
 				DestNamespace: "target_ns",
 				Deletion:      true,
 				Override:      true,
+				VerifyDigest:  true,
 				Enabled:       true,
 				Trigger:       &model.Trigger{},
 				Filters:       []*model.Filter{{Type: "registry", Value: "abc"}},
+				DestRepoRules: []*model.RewriteRule{{Type: model.RewriteRuleTypeAddPrefix, Value: "mirror/"}},
+				Platforms:     []*model.Platform{{OS: "linux", Architecture: "arm", Variant: "v7"}},
+				MetadataSync:  &model.MetadataSync{Description: true},
 			}, want: &persist_models.RepPolicy{
 				ID:                999,
 				Name:              "Policy Test",
@@ -149,9 +175,13 @@ func Test_convertToPersistModel(t *testing.T) {
 				DestNamespace:     "target_ns",
 				ReplicateDeletion: true,
 				Override:          true,
+				VerifyDigest:      true,
 				Enabled:           true,
 				Trigger:           "{\"type\":\"\",\"trigger_settings\":null}",
 				Filters:           "[{\"type\":\"registry\",\"value\":\"abc\"}]",
+				DestRepoRules:     "[{\"type\":\"add_prefix\",\"value\":\"mirror/\"}]",
+				Platforms:         "[{\"os\":\"linux\",\"architecture\":\"arm\",\"variant\":\"v7\"}]",
+				MetadataSync:      "{\"labels\":false,\"description\":true,\"signatures\":false}",
 			},
 		},
 	}
@@ -174,9 +204,13 @@ func Test_convertToPersistModel(t *testing.T) {
 			assert.Equal(t, tt.want.DestNamespace, got.DestNamespace)
 			assert.Equal(t, tt.want.ReplicateDeletion, got.ReplicateDeletion)
 			assert.Equal(t, tt.want.Override, got.Override)
+			assert.Equal(t, tt.want.VerifyDigest, got.VerifyDigest)
 			assert.Equal(t, tt.want.Enabled, got.Enabled)
 			assert.Equal(t, tt.want.Trigger, got.Trigger)
 			assert.Equal(t, tt.want.Filters, got.Filters)
+			assert.Equal(t, tt.want.DestRepoRules, got.DestRepoRules)
+			assert.Equal(t, tt.want.Platforms, got.Platforms)
+			assert.Equal(t, tt.want.MetadataSync, got.MetadataSync)
 
 		})
 	}
@@ -225,6 +259,13 @@ func TestParseFilters(t *testing.T) {
 	require.Equal(t, 1, len(filters))
 	assert.Equal(t, model.FilterTypeName, filters[0].Type)
 	assert.Equal(t, "library/hello-world", filters[0].Value.(string))
+	// contains the decoration
+	str = `[{"type":"tag_regex","value":"^dev-.*","decoration":"excludes"}]`
+	filters, err = parseFilters(str)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(filters))
+	assert.Equal(t, model.FilterTypeTagRegex, filters[0].Type)
+	assert.True(t, filters[0].IsExclusion())
 }
 
 func TestParseTrigger(t *testing.T) {
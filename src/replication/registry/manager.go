@@ -15,10 +15,16 @@
 package registry
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
+	common_models "github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/notifier"
+	"github.com/goharbor/harbor/src/pkg/webhook"
 	"github.com/goharbor/harbor/src/replication/adapter"
 	"github.com/goharbor/harbor/src/replication/config"
 	"github.com/goharbor/harbor/src/replication/dao"
@@ -43,6 +49,25 @@ type Manager interface {
 	Remove(int64) error
 	// HealthCheck checks health status of all registries and update result in database
 	HealthCheck() error
+	// ListHealth lists the health check history of registries, returns total count, history list and error
+	ListHealth(query *models.RegistryHealthQuery) (int64, []*models.RegistryHealth, error)
+}
+
+// HealthHistoryRetention defines how long the health check history of registries is kept
+const HealthHistoryRetention = time.Hour * 24 * 7
+
+// HealthEvent is published to the topic "notifier.RegistryHealthTopic" when
+// the health status of a registry changes
+type HealthEvent struct {
+	Registry *model.Registry
+	// the previous health status, empty if the registry has never been checked
+	PreviousStatus string
+	Status         string
+	Error          string
+	// the IDs of the policies which use the registry as source or destination
+	Policies []int64
+	// the local projects which the policies replicate from or to
+	Projects []string
 }
 
 // DefaultManager implement the Manager interface
@@ -152,11 +177,29 @@ func (m *DefaultManager) Remove(id int64) error {
 		return err
 	}
 
+	if err := dao.DeleteRegistryHealth(id); err != nil {
+		log.Warningf("Delete health check history of registry %d error: %v", id, err)
+	}
+
 	return nil
 }
 
+// ListHealth lists the health check history of registries according to the query provided
+func (m *DefaultManager) ListHealth(query *models.RegistryHealthQuery) (int64, []*models.RegistryHealth, error) {
+	total, err := dao.GetTotalOfRegistryHealth(query)
+	if err != nil {
+		return 0, nil, err
+	}
+	histories, err := dao.ListRegistryHealth(query)
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, histories, nil
+}
+
 // HealthCheck checks health status of every registries and update their status. It will check whether a registry
-// is reachable and the credential is valid
+// is reachable and the credential is valid. The result of every check is recorded as history, the policies
+// depending on the unhealthy registries are flagged and an event is published when the status of registry changes
 func (m *DefaultManager) HealthCheck() error {
 	_, registries, err := m.List()
 	if err != nil {
@@ -164,18 +207,59 @@ func (m *DefaultManager) HealthCheck() error {
 	}
 
 	errCount := 0
+	changed := []*HealthEvent{}
+	registryMap := map[int64]*model.Registry{}
 	for _, r := range registries {
+		registryMap[r.ID] = r
+		previous := r.Status
+		start := time.Now()
 		status, err := CheckHealthStatus(r)
+		latency := time.Since(start)
+		health := &models.RegistryHealth{
+			RegistryID:     r.ID,
+			Status:         string(status),
+			PreviousStatus: previous,
+			Latency:        int64(latency / time.Millisecond),
+			CheckTime:      start,
+		}
 		if err != nil {
 			log.Warningf("Check health status for %s error: %v", r.URL, err)
+			health.Error = err.Error()
 		}
-		r.Status = string(status)
-		err = m.Update(r, "status")
-		if err != nil {
+		if _, err = dao.AddRegistryHealth(health); err != nil {
+			log.Warningf("Record health status for '%s' error: %v", r.URL, err)
+		}
+
+		if health.Status == previous {
+			continue
+		}
+		r.Status = health.Status
+		if err = m.Update(r, "status"); err != nil {
 			log.Warningf("Update health status for '%s' error: %v", r.URL, err)
 			errCount++
 			continue
 		}
+		// skip the first check of the new added healthy registry
+		if len(previous) == 0 && health.Status == model.Healthy {
+			continue
+		}
+		changed = append(changed, &HealthEvent{
+			Registry:       r,
+			PreviousStatus: previous,
+			Status:         health.Status,
+			Error:          health.Error,
+		})
+	}
+
+	if err = flagPolicies(registryMap, changed); err != nil {
+		log.Warningf("Flag policies according to the health status of registries error: %v", err)
+		errCount++
+	}
+
+	publishHealthEvents(changed)
+
+	if _, err = dao.DeleteRegistryHealthBefore(time.Now().Add(-HealthHistoryRetention)); err != nil {
+		log.Warningf("Delete expired health check history error: %v", err)
 	}
 
 	if errCount > 0 {
@@ -184,6 +268,169 @@ func (m *DefaultManager) HealthCheck() error {
 	return nil
 }
 
+// publishHealthEvents publishes the events to the topic "notifier.RegistryHealthTopic"
+func publishHealthEvents(events []*HealthEvent) {
+	for _, e := range events {
+		if err := notifier.Publish(notifier.RegistryHealthTopic, e); err != nil {
+			log.Errorf("Publish health event of registry %s error: %v", e.Registry.Name, err)
+		}
+	}
+}
+
+// HealthEventHandler handles the events published to the topic "notifier.RegistryHealthTopic"
+type HealthEventHandler struct{}
+
+// Handle logs the change of the health status as a warning, as the replications from or to the
+// registry may fail until it recovers, and delivers it to the webhook endpoints of the projects
+// which the affected policies replicate from or to
+func (h *HealthEventHandler) Handle(value interface{}) error {
+	e, ok := value.(*HealthEvent)
+	if !ok || e.Registry == nil {
+		return fmt.Errorf("invalid registry health event: %v", value)
+	}
+	previous := e.PreviousStatus
+	if len(previous) == 0 {
+		previous = "unchecked"
+	}
+	msg := fmt.Sprintf("The health status of registry %s(%s) changed from %s to %s",
+		e.Registry.Name, e.Registry.URL, previous, e.Status)
+	if len(e.Error) > 0 {
+		msg += fmt.Sprintf(", error: %s", e.Error)
+	}
+	if len(e.Policies) > 0 {
+		msg += fmt.Sprintf(", affected replication policies: %v", e.Policies)
+	}
+	log.Warning(msg)
+	for _, project := range e.Projects {
+		webhook.Publish(&webhook.Event{
+			Type:    common_models.WebhookEventRegistryHealthChanged,
+			Project: project,
+			Message: msg,
+		})
+	}
+	return nil
+}
+
+// IsStateful ...
+func (h *HealthEventHandler) IsStateful() bool {
+	return false
+}
+
+// flagPolicies updates the health warning of the policies and populates the related policies of the health events.
+// All policies are checked rather than only the ones of changed registries to cover the policies created or
+// updated after the last check
+func flagPolicies(registries map[int64]*model.Registry, events []*HealthEvent) error {
+	_, policies, err := dao.GetPolicies()
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		for _, e := range events {
+			if e.Registry.ID == policy.SrcRegistryID || e.Registry.ID == policy.DestRegistryID {
+				e.Policies = append(e.Policies, policy.ID)
+				e.Projects = appendProjects(e.Projects, policyProjects(policy)...)
+			}
+		}
+		warning := policyHealthWarning(policy, registries)
+		if warning == policy.HealthWarning {
+			continue
+		}
+		policy.HealthWarning = warning
+		if err = dao.UpdateRepPolicy(policy, "HealthWarning"); err != nil {
+			return err
+		}
+		if len(warning) > 0 {
+			log.Warningf("Replication policy %s: %s", policy.Name, warning)
+		}
+	}
+	return nil
+}
+
+// policyProjects returns the local projects which the policy replicates to or from: the destination
+// namespace of the policy pulling images into the local Harbor, and the projects matched by the name
+// filters without wildcards of the policy pushing images from the local Harbor
+func policyProjects(policy *models.RepPolicy) []string {
+	var projects []string
+	if policy.DestRegistryID == 0 && len(policy.DestNamespace) > 0 {
+		projects = append(projects, policy.DestNamespace)
+	}
+	if policy.SrcRegistryID == 0 && len(policy.Filters) > 0 {
+		filters := []*model.Filter{}
+		if err := json.Unmarshal([]byte(policy.Filters), &filters); err != nil {
+			log.Errorf("failed to parse the filters of policy %d: %v", policy.ID, err)
+			return projects
+		}
+		for _, filter := range filters {
+			if filter.Type != model.FilterTypeName {
+				continue
+			}
+			name, ok := filter.Value.(string)
+			if !ok || !strings.Contains(name, "/") {
+				continue
+			}
+			project := strings.SplitN(name, "/", 2)[0]
+			if strings.ContainsAny(project, "*?[{") {
+				continue
+			}
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// appendProjects appends the projects which aren't in the list yet
+func appendProjects(projects []string, added ...string) []string {
+	for _, project := range added {
+		exist := false
+		for _, p := range projects {
+			if p == project {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// policyHealthWarning returns the warning of the policy according to the health status of
+// its source and destination registries, empty string is returned if both are healthy
+func policyHealthWarning(policy *models.RepPolicy, registries map[int64]*model.Registry) string {
+	var warnings []string
+	if r, exist := registries[policy.SrcRegistryID]; exist && r.Status == model.Unhealthy {
+		warnings = append(warnings, fmt.Sprintf("the source registry %s is unhealthy", r.Name))
+	}
+	if r, exist := registries[policy.DestRegistryID]; exist && r.Status == model.Unhealthy {
+		warnings = append(warnings, fmt.Sprintf("the destination registry %s is unhealthy", r.Name))
+	}
+	return strings.Join(warnings, "; ")
+}
+
+// PolicyHealthWarning returns the warning of the policy with the source and destination registries according
+// to their health status recorded by the last health check, empty string is returned if both are healthy
+func PolicyHealthWarning(mgr Manager, srcRegistryID, destRegistryID int64) (string, error) {
+	registries := map[int64]*model.Registry{}
+	for _, id := range []int64{srcRegistryID, destRegistryID} {
+		// the local Harbor registry isn't checked
+		if id == 0 {
+			continue
+		}
+		registry, err := mgr.Get(id)
+		if err != nil {
+			return "", err
+		}
+		if registry != nil {
+			registries[id] = registry
+		}
+	}
+	return policyHealthWarning(&models.RepPolicy{
+		SrcRegistryID:  srcRegistryID,
+		DestRegistryID: destRegistryID,
+	}, registries), nil
+}
+
 // CheckHealthStatus checks status of a given registry
 func CheckHealthStatus(r *model.Registry) (model.HealthStatus, error) {
 	if !adapter.HasFactory(r.Type) {
@@ -235,16 +482,18 @@ func encrypt(secret string) (string, error) {
 // Also, if access secret is provided, decrypt it.
 func fromDaoModel(registry *models.Registry) (*model.Registry, error) {
 	r := &model.Registry{
-		ID:           registry.ID,
-		Name:         registry.Name,
-		Description:  registry.Description,
-		Type:         model.RegistryType(registry.Type),
-		Credential:   &model.Credential{},
-		URL:          registry.URL,
-		Insecure:     registry.Insecure,
-		Status:       registry.Health,
-		CreationTime: registry.CreationTime,
-		UpdateTime:   registry.UpdateTime,
+		ID:                 registry.ID,
+		Name:               registry.Name,
+		Description:        registry.Description,
+		Type:               model.RegistryType(registry.Type),
+		Credential:         &model.Credential{},
+		URL:                registry.URL,
+		Insecure:           registry.Insecure,
+		Status:             registry.Health,
+		MaxConcurrentTasks: registry.MaxConcurrentTasks,
+		MaxBytesPerSecond:  registry.MaxBytesPerSecond,
+		CreationTime:       registry.CreationTime,
+		UpdateTime:         registry.UpdateTime,
 	}
 
 	if len(registry.AccessKey) != 0 {
@@ -270,15 +519,17 @@ func fromDaoModel(registry *models.Registry) (*model.Registry, error) {
 // Also, if access secret is provided, encrypt it.
 func toDaoModel(registry *model.Registry) (*models.Registry, error) {
 	m := &models.Registry{
-		ID:           registry.ID,
-		URL:          registry.URL,
-		Name:         registry.Name,
-		Type:         string(registry.Type),
-		Insecure:     registry.Insecure,
-		Description:  registry.Description,
-		Health:       registry.Status,
-		CreationTime: registry.CreationTime,
-		UpdateTime:   registry.UpdateTime,
+		ID:                 registry.ID,
+		URL:                registry.URL,
+		Name:               registry.Name,
+		Type:               string(registry.Type),
+		Insecure:           registry.Insecure,
+		Description:        registry.Description,
+		Health:             registry.Status,
+		MaxConcurrentTasks: registry.MaxConcurrentTasks,
+		MaxBytesPerSecond:  registry.MaxBytesPerSecond,
+		CreationTime:       registry.CreationTime,
+		UpdateTime:         registry.UpdateTime,
 	}
 
 	if registry.Credential != nil && len(registry.Credential.AccessKey) != 0 {
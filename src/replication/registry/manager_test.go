@@ -16,11 +16,172 @@ package registry
 
 import (
 	"testing"
+	"time"
 
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/notifier"
+	"github.com/goharbor/harbor/src/pkg/webhook"
+	"github.com/goharbor/harbor/src/replication/dao/models"
+	"github.com/goharbor/harbor/src/replication/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewDefaultManager(t *testing.T) {
 	mgr := NewDefaultManager()
 	assert.NotNil(t, mgr)
 }
+
+func TestPolicyHealthWarning(t *testing.T) {
+	registries := map[int64]*model.Registry{
+		1: {ID: 1, Name: "healthy", Status: model.Healthy},
+		2: {ID: 2, Name: "unhealthy-a", Status: model.Unhealthy},
+		3: {ID: 3, Name: "unhealthy-b", Status: model.Unhealthy},
+		4: {ID: 4, Name: "unknown", Status: model.Unknown},
+	}
+	cases := []struct {
+		srcRegistryID  int64
+		destRegistryID int64
+		warning        string
+	}{
+		// the local Harbor registry isn't checked
+		{0, 1, ""},
+		{1, 0, ""},
+		{0, 4, ""},
+		{2, 0, "the source registry unhealthy-a is unhealthy"},
+		{0, 3, "the destination registry unhealthy-b is unhealthy"},
+		{2, 3, "the source registry unhealthy-a is unhealthy; the destination registry unhealthy-b is unhealthy"},
+		// the registry that has been removed
+		{5, 0, ""},
+	}
+	for _, c := range cases {
+		policy := &models.RepPolicy{
+			SrcRegistryID:  c.srcRegistryID,
+			DestRegistryID: c.destRegistryID,
+		}
+		assert.Equal(t, c.warning, policyHealthWarning(policy, registries))
+	}
+}
+
+type fakeManager struct {
+	Manager
+	registries map[int64]*model.Registry
+}
+
+func (f *fakeManager) Get(id int64) (*model.Registry, error) {
+	return f.registries[id], nil
+}
+
+func TestPolicyHealthWarningOfRegistries(t *testing.T) {
+	mgr := &fakeManager{
+		registries: map[int64]*model.Registry{
+			1: {ID: 1, Name: "healthy", Status: model.Healthy},
+			2: {ID: 2, Name: "unhealthy", Status: model.Unhealthy},
+		},
+	}
+	warning, err := PolicyHealthWarning(mgr, 0, 1)
+	require.Nil(t, err)
+	assert.Equal(t, "", warning)
+
+	warning, err = PolicyHealthWarning(mgr, 2, 0)
+	require.Nil(t, err)
+	assert.Equal(t, "the source registry unhealthy is unhealthy", warning)
+}
+
+type recordingHandler struct {
+	events chan *HealthEvent
+}
+
+func (r *recordingHandler) Handle(value interface{}) error {
+	r.events <- value.(*HealthEvent)
+	return nil
+}
+
+func (r *recordingHandler) IsStateful() bool {
+	return false
+}
+
+func TestPublishHealthEvents(t *testing.T) {
+	handler := &recordingHandler{events: make(chan *HealthEvent, 1)}
+	require.Nil(t, notifier.Subscribe(notifier.RegistryHealthTopic, handler))
+	defer notifier.UnSubscribe(notifier.RegistryHealthTopic, "*registry.recordingHandler")
+
+	event := &HealthEvent{
+		Registry:       &model.Registry{ID: 1, Name: "remote"},
+		PreviousStatus: model.Healthy,
+		Status:         model.Unhealthy,
+		Policies:       []int64{1, 2},
+	}
+	publishHealthEvents([]*HealthEvent{event})
+
+	select {
+	case e := <-handler.events:
+		assert.Equal(t, event, e)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the health event isn't observed by the subscriber")
+	}
+}
+
+func TestHealthEventHandler(t *testing.T) {
+	handler := &HealthEventHandler{}
+	assert.False(t, handler.IsStateful())
+	assert.NotNil(t, handler.Handle("invalid"))
+	assert.NotNil(t, handler.Handle(&HealthEvent{}))
+
+	webhooks := make(chan *webhook.Event, 1)
+	require.Nil(t, notifier.Subscribe(notifier.WebhookTopic, &webhookRecorder{events: webhooks}))
+	defer notifier.UnSubscribe(notifier.WebhookTopic, "*registry.webhookRecorder")
+
+	assert.Nil(t, handler.Handle(&HealthEvent{
+		Registry:       &model.Registry{ID: 1, Name: "remote", URL: "https://remote.example.com"},
+		PreviousStatus: model.Healthy,
+		Status:         model.Unhealthy,
+		Error:          "connection refused",
+		Policies:       []int64{1},
+		Projects:       []string{"library"},
+	}))
+
+	select {
+	case e := <-webhooks:
+		assert.Equal(t, common_models.WebhookEventRegistryHealthChanged, e.Type)
+		assert.Equal(t, "library", e.Project)
+		assert.Contains(t, e.Message, "remote")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook event isn't published")
+	}
+}
+
+type webhookRecorder struct {
+	events chan *webhook.Event
+}
+
+func (w *webhookRecorder) Handle(value interface{}) error {
+	w.events <- value.(*webhook.Event)
+	return nil
+}
+
+func (w *webhookRecorder) IsStateful() bool {
+	return false
+}
+
+func TestPolicyProjects(t *testing.T) {
+	cases := []struct {
+		policy   *models.RepPolicy
+		projects []string
+	}{
+		// pull from a remote registry into the local project
+		{&models.RepPolicy{SrcRegistryID: 1, DestNamespace: "library"}, []string{"library"}},
+		// the destination namespace is kept as the source one
+		{&models.RepPolicy{SrcRegistryID: 1}, nil},
+		// push the local projects to a remote registry
+		{&models.RepPolicy{DestRegistryID: 1, Filters: `[{"type":"name","value":"library/**"},{"type":"tag","value":"v*"}]`}, []string{"library"}},
+		// the project matched by wildcards isn't known
+		{&models.RepPolicy{DestRegistryID: 1, Filters: `[{"type":"name","value":"lib*/**"}]`}, nil},
+		// replicate between remote registries
+		{&models.RepPolicy{SrcRegistryID: 1, DestRegistryID: 2, DestNamespace: "library"}, nil},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.projects, policyProjects(c.policy))
+	}
+	assert.Equal(t, []string{"a", "b"}, appendProjects([]string{"a"}, "a", "b"))
+}
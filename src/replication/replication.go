@@ -20,6 +20,7 @@ import (
 	"github.com/goharbor/harbor/src/common/job"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	cfg "github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/notifier"
 	"github.com/goharbor/harbor/src/replication/config"
 	"github.com/goharbor/harbor/src/replication/event"
 	"github.com/goharbor/harbor/src/replication/operation"
@@ -41,6 +42,14 @@ import (
 	_ "github.com/goharbor/harbor/src/replication/adapter/awsecr"
 	// register the AzureAcr adapter
 	_ "github.com/goharbor/harbor/src/replication/adapter/azurecr"
+	// register the Quay adapter
+	_ "github.com/goharbor/harbor/src/replication/adapter/quay"
+	// register the GitLab adapter
+	_ "github.com/goharbor/harbor/src/replication/adapter/gitlab"
+	// register the Artifactory adapter
+	_ "github.com/goharbor/harbor/src/replication/adapter/artifactory"
+	// register the ChartMuseum and Helm chart repository adapters
+	_ "github.com/goharbor/harbor/src/replication/adapter/helm"
 )
 
 var (
@@ -79,6 +88,9 @@ func Init(closing chan struct{}) error {
 	OperationCtl = operation.NewController(js)
 	// init event handler
 	EventHandler = event.NewHandler(PolicyCtl, RegistryMgr, OperationCtl)
+	if err = notifier.Subscribe(notifier.RegistryHealthTopic, &registry.HealthEventHandler{}); err != nil {
+		return err
+	}
 	log.Debug("the replication initialization completed")
 
 	// Start health checker for registries
@@ -15,9 +15,16 @@
 package chart
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
 
 	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/replication/adapter"
 	"github.com/goharbor/harbor/src/replication/model"
 	trans "github.com/goharbor/harbor/src/replication/transfer"
@@ -46,6 +53,26 @@ type transfer struct {
 	isStopped trans.StopFunc
 	src       adapter.ChartRegistry
 	dst       adapter.ChartRegistry
+	limiter   trans.Limiter
+	limits    []*model.Limit
+	// whether to verify the checksum of the chart on the destination registry after uploading it
+	verifyDigest bool
+	// the statistics of the data moved, reported to the reporter when the copy is done
+	stats    trans.Statistics
+	reporter trans.StatisticsReporter
+}
+
+var _ trans.Limitable = &transfer{}
+var _ trans.Measurable = &transfer{}
+
+// SetLimiter sets the limiter used to limit the concurrency and bandwidth of the transfer
+func (t *transfer) SetLimiter(limiter trans.Limiter) {
+	t.limiter = limiter
+}
+
+// SetStatisticsReporter sets the reporter which receives the statistics of the data moved
+func (t *transfer) SetStatisticsReporter(reporter trans.StatisticsReporter) {
+	t.reporter = reporter
 }
 
 func (t *transfer) Transfer(src *model.Resource, dst *model.Resource) error {
@@ -54,6 +81,17 @@ func (t *transfer) Transfer(src *model.Resource, dst *model.Resource) error {
 		return err
 	}
 
+	// the transfer is requeued if the concurrency limits are reached
+	release, err := trans.AcquireSlots(t.limiter, dst.Limits, t.logger)
+	if err != nil {
+		if !errs.IsRequeueError(err) {
+			t.logger.Errorf("failed to acquire the slots of the concurrency limits: %v", err)
+		}
+		return err
+	}
+	defer release()
+	t.limits = dst.Limits
+
 	// delete the chart on destination registry
 	if dst.Deleted {
 		return t.delete(&chart{
@@ -70,10 +108,23 @@ func (t *transfer) Transfer(src *model.Resource, dst *model.Resource) error {
 		name:    dst.Metadata.GetResourceName(),
 		version: dst.Metadata.Vtags[0],
 	}
+	t.verifyDigest = dst.VerifyDigest
 	// copy the chart from source registry to the destination
+	defer t.reportStatistics(time.Now())
 	return t.copy(srcChart, dstChart, dst.Override)
 }
 
+// report the statistics of the data moved, the failure is ignored as it doesn't affect the result of the copy
+func (t *transfer) reportStatistics(start time.Time) {
+	if t.reporter == nil {
+		return
+	}
+	t.stats.Duration = time.Since(start)
+	if err := t.reporter.Report(&t.stats); err != nil {
+		t.logger.Warningf("failed to report the statistics of the transfer: %v", err)
+	}
+}
+
 func (t *transfer) initialize(src, dst *model.Resource) error {
 	if t.shouldStop() {
 		return nil
@@ -158,10 +209,23 @@ func (t *transfer) copy(src, dst *chart, override bool) error {
 	}
 	defer chart.Close()
 
-	if err = t.dst.UploadChart(dst.name, dst.version, chart); err != nil {
+	// calculate the checksum of the source chart package when uploading it
+	hash := sha256.New()
+	reader := io.TeeReader(chart, hash)
+	if err = t.dst.UploadChart(dst.name, dst.version, t.stats.CountingReader(trans.NewLimitedReader(reader, t.limiter, t.limits))); err != nil {
 		t.logger.Errorf("failed to upload the chart %s:%s: %v", dst.name, dst.version, err)
 		return err
 	}
+	if t.verifyDigest {
+		// make sure the whole package is hashed even if the upload doesn't consume all the content
+		if _, err = io.Copy(ioutil.Discard, reader); err != nil {
+			t.logger.Errorf("failed to read the chart %s:%s: %v", src.name, src.version, err)
+			return err
+		}
+		if err = t.verifyChecksum(dst.name, dst.version, hex.EncodeToString(hash.Sum(nil))); err != nil {
+			return err
+		}
+	}
 
 	t.logger.Infof("copy %s:%s(source registry) to %s:%s(destination registry) completed",
 		src.name, src.version, dst.name, dst.version)
@@ -169,6 +233,30 @@ func (t *transfer) copy(src, dst *chart, override bool) error {
 	return nil
 }
 
+// download the chart from the destination registry and compare the checksum of
+// the package with the source one to make sure the content isn't changed
+func (t *transfer) verifyChecksum(name, version, checksum string) error {
+	chart, err := t.dst.DownloadChart(name, version)
+	if err != nil {
+		t.logger.Errorf("failed to download the chart %s:%s from the destination registry: %v", name, version, err)
+		return err
+	}
+	defer chart.Close()
+	hash := sha256.New()
+	if _, err = io.Copy(hash, chart); err != nil {
+		t.logger.Errorf("failed to read the chart %s:%s from the destination registry: %v", name, version, err)
+		return err
+	}
+	if dstChecksum := hex.EncodeToString(hash.Sum(nil)); dstChecksum != checksum {
+		err = fmt.Errorf("the checksum %s of the chart %s:%s on the destination registry doesn't match the source one %s",
+			dstChecksum, name, version, checksum)
+		t.logger.Error(err.Error())
+		return err
+	}
+	t.logger.Infof("the checksum %s of the chart %s:%s on the destination registry verified", checksum, name, version)
+	return nil
+}
+
 func (t *transfer) delete(chart *chart) error {
 	exist, err := t.dst.ChartExist(chart.name, chart.version)
 	if err != nil {
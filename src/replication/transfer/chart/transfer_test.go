@@ -100,6 +100,42 @@ func TestCopy(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+type fakeRewritingRegistry struct {
+	fakeRegistry
+}
+
+func (f *fakeRewritingRegistry) DownloadChart(name, version string) (io.ReadCloser, error) {
+	r := ioutil.NopCloser(bytes.NewReader([]byte{'b'}))
+	return r, nil
+}
+
+func TestCopyWithVerification(t *testing.T) {
+	src := &chart{
+		name:    "library/harbor",
+		version: "0.2.0",
+	}
+	dst := &chart{
+		name:    "dest/harbor",
+		version: "0.2.0",
+	}
+
+	// the checksums match
+	transfer := &transfer{
+		logger:       log.DefaultLogger(),
+		isStopped:    func() bool { return false },
+		src:          &fakeRegistry{},
+		dst:          &fakeRegistry{},
+		verifyDigest: true,
+	}
+	err := transfer.copy(src, dst, true)
+	assert.Nil(t, err)
+
+	// the content is changed on the destination registry
+	transfer.dst = &fakeRewritingRegistry{}
+	err = transfer.copy(src, dst, true)
+	assert.NotNil(t, err)
+}
+
 func TestDelete(t *testing.T) {
 	stopFunc := func() bool { return false }
 	transfer := &transfer{
@@ -18,16 +18,29 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-
-	"github.com/docker/distribution/manifest/manifestlist"
+	"time"
 
 	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
 	"github.com/docker/distribution/manifest/schema1"
 	"github.com/docker/distribution/manifest/schema2"
 	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/replication/adapter"
 	"github.com/goharbor/harbor/src/replication/model"
 	trans "github.com/goharbor/harbor/src/replication/transfer"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var (
+	// the blobs bigger than the chunk size are copied by chunks if both
+	// the source and destination registries support it
+	chunkSize int64 = 10 * 1024 * 1024
+	// the max retry times of one chunk
+	maxChunkRetries = 5
+	// the interval before the first retry of one chunk, it is doubled for the following retries
+	chunkRetryInterval = 2 * time.Second
 )
 
 func init() {
@@ -53,6 +66,49 @@ type transfer struct {
 	isStopped trans.StopFunc
 	src       adapter.ImageRegistry
 	dst       adapter.ImageRegistry
+	// only the manifests matching the platforms in the manifest lists are
+	// replicated, all the manifests are replicated if it is empty
+	platforms []*model.Platform
+	// whether to verify the digests of the manifests on the destination registry after pushing them
+	verifyDigest bool
+	// the metadata of the repository and tags replicated along with the images
+	metadataSync *model.MetadataSync
+	// the store used to persist the states of chunked blob uploads
+	stateStore trans.UploadStateStore
+	limiter    trans.Limiter
+	limits     []*model.Limit
+	// the locator used to find the repositories that the blobs can be mounted from
+	locator trans.BlobLocator
+	// the URL of the destination registry, used to identify the registry in the locator
+	dstRegistry string
+	// the statistics of the data moved, reported to the reporter when the copy is done
+	stats    trans.Statistics
+	reporter trans.StatisticsReporter
+}
+
+var _ trans.Resumable = &transfer{}
+var _ trans.Limitable = &transfer{}
+var _ trans.Mountable = &transfer{}
+var _ trans.Measurable = &transfer{}
+
+// SetUploadStateStore sets the store used to persist the states of chunked blob uploads
+func (t *transfer) SetUploadStateStore(store trans.UploadStateStore) {
+	t.stateStore = store
+}
+
+// SetLimiter sets the limiter used to limit the concurrency and bandwidth of the transfer
+func (t *transfer) SetLimiter(limiter trans.Limiter) {
+	t.limiter = limiter
+}
+
+// SetBlobLocator sets the locator used to find the repositories that the blobs can be mounted from
+func (t *transfer) SetBlobLocator(locator trans.BlobLocator) {
+	t.locator = locator
+}
+
+// SetStatisticsReporter sets the reporter which receives the statistics of the data moved
+func (t *transfer) SetStatisticsReporter(reporter trans.StatisticsReporter) {
+	t.reporter = reporter
 }
 
 func (t *transfer) Transfer(src *model.Resource, dst *model.Resource) error {
@@ -61,6 +117,17 @@ func (t *transfer) Transfer(src *model.Resource, dst *model.Resource) error {
 		return err
 	}
 
+	// the transfer is requeued if the concurrency limits are reached
+	release, err := trans.AcquireSlots(t.limiter, dst.Limits, t.logger)
+	if err != nil {
+		if !errs.IsRequeueError(err) {
+			t.logger.Errorf("failed to acquire the slots of the concurrency limits: %v", err)
+		}
+		return err
+	}
+	defer release()
+	t.limits = dst.Limits
+
 	// delete the repository on destination registry
 	if dst.Deleted {
 		return t.delete(&repository{
@@ -77,10 +144,26 @@ func (t *transfer) Transfer(src *model.Resource, dst *model.Resource) error {
 		repository: dst.Metadata.GetResourceName(),
 		tags:       dst.Metadata.Vtags,
 	}
+	t.platforms = dst.Platforms
+	t.verifyDigest = dst.VerifyDigest
+	t.metadataSync = dst.MetadataSync
+	t.dstRegistry = dst.Registry.URL
 	// copy the repository from source registry to the destination
+	defer t.reportStatistics(time.Now())
 	return t.copy(srcRepo, dstRepo, dst.Override)
 }
 
+// report the statistics of the data moved, the failure is ignored as it doesn't affect the result of the copy
+func (t *transfer) reportStatistics(start time.Time) {
+	if t.reporter == nil {
+		return
+	}
+	t.stats.Duration = time.Since(start)
+	if err := t.reporter.Report(&t.stats); err != nil {
+		t.logger.Warningf("failed to report the statistics of the transfer: %v", err)
+	}
+}
+
 func (t *transfer) initialize(src *model.Resource, dst *model.Resource) error {
 	if t.shouldStop() {
 		return nil
@@ -147,12 +230,53 @@ func (t *transfer) copy(src *repository, dst *repository, override bool) error {
 	if err != nil {
 		return err
 	}
+	if err = t.syncMetadata(src, dst); err != nil {
+		return err
+	}
 
 	t.logger.Infof("copy %s:[%s](source registry) to %s:[%s](destination registry) completed",
 		srcRepo, strings.Join(src.tags, ","), dstRepo, strings.Join(dst.tags, ","))
 	return nil
 }
 
+// replicate the metadata of the repository and tags, e.g. labels, descriptions and
+// signatures, it's skipped if the source or destination registry doesn't support it
+func (t *transfer) syncMetadata(src *repository, dst *repository) error {
+	if !t.metadataSync.Enabled() || t.shouldStop() {
+		return nil
+	}
+	srcReg, srcOK := t.src.(adapter.MetadataRegistry)
+	dstReg, dstOK := t.dst.(adapter.MetadataRegistry)
+	if !srcOK || !dstOK {
+		t.logger.Warning("the source or destination registry doesn't support replicating the metadata, skip")
+		return nil
+	}
+	t.logger.Infof("syncing the metadata of %s:[%s](source registry) to %s:[%s](destination registry)...",
+		src.repository, strings.Join(src.tags, ","), dst.repository, strings.Join(dst.tags, ","))
+	metadata, err := srcReg.GetMetadata(src.repository, src.tags, t.metadataSync)
+	if err != nil {
+		t.logger.Errorf("failed to get the metadata of %s from the source registry: %v", src.repository, err)
+		return err
+	}
+	// map the source tags to the destination ones
+	tags := map[string]string{}
+	for i := range src.tags {
+		tags[src.tags[i]] = dst.tags[i]
+	}
+	for _, tag := range metadata.Tags {
+		if name, exist := tags[tag.Name]; exist {
+			tag.Name = name
+		}
+	}
+	if err = dstReg.PutMetadata(dst.repository, metadata, t.metadataSync); err != nil {
+		t.logger.Errorf("failed to put the metadata of %s to the destination registry: %v", dst.repository, err)
+		return err
+	}
+	t.logger.Infof("sync the metadata of %s(source registry) to %s(destination registry) completed",
+		src.repository, dst.repository)
+	return nil
+}
+
 func (t *transfer) copyImage(srcRepo, srcRef, dstRepo, dstRef string, override bool) error {
 	t.logger.Infof("copying %s:%s(source registry) to %s:%s(destination registry)...",
 		srcRepo, srcRef, dstRepo, dstRef)
@@ -161,6 +285,10 @@ func (t *transfer) copyImage(srcRepo, srcRef, dstRepo, dstRef string, override b
 	if err != nil {
 		return err
 	}
+	// the job is stopped or no manifest in the manifest list matches the platforms
+	if manifest == nil {
+		return nil
+	}
 
 	// check the existence of the image on the destination registry
 	exist, digest2, err := t.exist(dstRepo, dstRef)
@@ -196,6 +324,12 @@ func (t *transfer) copyImage(srcRepo, srcRef, dstRepo, dstRef string, override b
 	if err := t.pushManifest(manifest, dstRepo, dstRef); err != nil {
 		return err
 	}
+	if t.verifyDigest {
+		if err := t.verifyManifest(manifest, dstRepo, dstRef, digest); err != nil {
+			return err
+		}
+	}
+	t.stats.ManifestDigests = append(t.stats.ManifestDigests, digest)
 
 	t.logger.Infof("copy %s:%s(source registry) to %s:%s(destination registry) completed",
 		srcRepo, srcRef, dstRepo, dstRef)
@@ -206,9 +340,10 @@ func (t *transfer) copyImage(srcRepo, srcRef, dstRepo, dstRef string, override b
 func (t *transfer) copyContent(content distribution.Descriptor, srcRepo, dstRepo string) error {
 	digest := content.Digest.String()
 	switch content.MediaType {
-	// when the media type of pulled manifest is manifest list,
+	// when the media type of pulled manifest is manifest list or OCI image index,
 	// the contents it contains are a few manifests
-	case schema2.MediaTypeManifest:
+	case schema2.MediaTypeManifest, v1.MediaTypeImageManifest,
+		manifestlist.MediaTypeManifestList, v1.MediaTypeImageIndex:
 		// as using digest as the reference, so set the override to true directly
 		return t.copyImage(srcRepo, digest, dstRepo, digest, true)
 	// handle foreign layer
@@ -219,12 +354,12 @@ func (t *transfer) copyContent(content distribution.Descriptor, srcRepo, dstRepo
 	// the media type of the layer or config can be "application/octet-stream",
 	// schema1.MediaTypeManifestLayer, schema2.MediaTypeLayer, schema2.MediaTypeImageConfig
 	default:
-		return t.copyBlob(srcRepo, dstRepo, digest)
+		return t.copyBlob(srcRepo, dstRepo, digest, content.Size)
 	}
 }
 
 // copy the layer or image config from the source registry to destination
-func (t *transfer) copyBlob(srcRepo, dstRepo, digest string) error {
+func (t *transfer) copyBlob(srcRepo, dstRepo, digest string, size int64) error {
 	if t.shouldStop() {
 		return nil
 	}
@@ -236,23 +371,218 @@ func (t *transfer) copyBlob(srcRepo, dstRepo, digest string) error {
 	}
 	if exist {
 		t.logger.Infof("the blob %s already exists on the destination registry, skip", digest)
+		t.recordBlob(dstRepo, digest)
+		t.stats.BlobsSkipped++
+		return nil
+	}
+
+	// the mounted blob isn't transferred, so it is counted as skipped
+	if t.mountBlob(dstRepo, digest) {
+		t.stats.BlobsSkipped++
 		return nil
 	}
 
+	if size > chunkSize {
+		src, srcOK := t.src.(adapter.ChunkedBlobRegistry)
+		dst, dstOK := t.dst.(adapter.ChunkedBlobRegistry)
+		if srcOK && dstOK {
+			return t.copyBlobByChunks(src, dst, srcRepo, dstRepo, digest, size)
+		}
+	}
+
 	size, data, err := t.src.PullBlob(srcRepo, digest)
 	if err != nil {
 		t.logger.Errorf("failed to pulling the blob %s: %v", digest, err)
 		return err
 	}
 	defer data.Close()
-	if err = t.dst.PushBlob(dstRepo, digest, size, data); err != nil {
+	if err = t.dst.PushBlob(dstRepo, digest, size, t.stats.CountingReader(trans.NewLimitedReader(data, t.limiter, t.limits))); err != nil {
 		t.logger.Errorf("failed to pushing the blob %s: %v", digest, err)
 		return err
 	}
+	t.recordBlob(dstRepo, digest)
+	t.stats.BlobsCopied++
+	t.logger.Infof("copy the blob %s completed", digest)
+	return nil
+}
+
+// mount the blob from another repository on the destination registry which is
+// recorded to contain the blob, returns whether the blob is mounted
+func (t *transfer) mountBlob(dstRepo, digest string) bool {
+	if t.locator == nil {
+		return false
+	}
+	mounter, ok := t.dst.(adapter.BlobMounter)
+	if !ok {
+		return false
+	}
+	from, err := t.locator.Locate(t.dstRegistry, digest)
+	if err != nil {
+		t.logger.Warningf("failed to locate the blob %s on the destination registry: %v", digest, err)
+		return false
+	}
+	if len(from) == 0 || from == dstRepo {
+		return false
+	}
+	mounted, err := mounter.MountBlob(dstRepo, digest, from)
+	if err != nil {
+		t.logger.Warningf("failed to mount the blob %s from %s, copy it: %v", digest, from, err)
+		return false
+	}
+	if !mounted {
+		t.logger.Infof("the blob %s cannot be mounted from %s, copy it", digest, from)
+		return false
+	}
+	t.recordBlob(dstRepo, digest)
+	t.logger.Infof("the blob %s is mounted from %s", digest, from)
+	return true
+}
+
+// record that the repository on the destination registry contains the blob,
+// the failure is ignored as it only affects whether the blob can be mounted
+func (t *transfer) recordBlob(dstRepo, digest string) {
+	if t.locator == nil {
+		return
+	}
+	if err := t.locator.Record(t.dstRegistry, digest, dstRepo); err != nil {
+		t.logger.Warningf("failed to record the location of blob %s: %v", digest, err)
+	}
+}
+
+// copy the blob by chunks, the upload is resumed from the last committed offset
+// if the upload session of the blob was persisted by the previous attempt
+func (t *transfer) copyBlobByChunks(src, dst adapter.ChunkedBlobRegistry, srcRepo, dstRepo, digest string, size int64) error {
+	location, offset, err := t.startBlobUpload(dst, dstRepo, digest)
+	if err != nil {
+		t.logger.Errorf("failed to start the upload session of blob %s: %v", digest, err)
+		return err
+	}
+	for offset < size {
+		if t.shouldStop() {
+			return nil
+		}
+		end := offset + chunkSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		location, offset, err = t.copyChunk(src, dst, srcRepo, dstRepo, digest, size, location, offset, end)
+		if err != nil {
+			t.logger.Errorf("failed to copy the blob %s by chunks: %v", digest, err)
+			return err
+		}
+		t.logger.Debugf("%d/%d bytes of the blob %s copied", offset, size, digest)
+		t.saveUploadState(&trans.UploadState{
+			Repository: dstRepo,
+			Digest:     digest,
+			Location:   location,
+			Offset:     offset,
+		})
+	}
+	if err = dst.CompleteBlobUpload(dstRepo, location, digest); err != nil {
+		t.logger.Errorf("failed to complete the upload of blob %s: %v", digest, err)
+		return err
+	}
+	t.deleteUploadState(digest)
+	t.recordBlob(dstRepo, digest)
+	t.stats.BlobsCopied++
 	t.logger.Infof("copy the blob %s completed", digest)
 	return nil
 }
 
+// resume the upload session persisted before or start a new one, returns
+// the location and the offset of the next byte that the session expects
+func (t *transfer) startBlobUpload(dst adapter.ChunkedBlobRegistry, dstRepo, digest string) (string, int64, error) {
+	if state := t.getUploadState(digest); state != nil && state.Repository == dstRepo {
+		location, offset, err := dst.GetBlobUploadStatus(dstRepo, state.Location)
+		if err == nil {
+			t.logger.Infof("resume the upload of blob %s from the offset %d", digest, offset)
+			return location, offset, nil
+		}
+		t.logger.Warningf("failed to get the status of the upload session of blob %s, start a new one: %v", digest, err)
+	}
+	location, err := dst.InitiateBlobUpload(dstRepo)
+	if err != nil {
+		return "", 0, err
+	}
+	return location, 0, nil
+}
+
+// copy the bytes [offset, end] of the blob, the chunk is retried with backoff when fails.
+// As part of the chunk may have been committed, the status of the upload session is
+// queried before retrying. Returns the location and offset for the next chunk
+func (t *transfer) copyChunk(src, dst adapter.ChunkedBlobRegistry, srcRepo, dstRepo, digest string,
+	size int64, location string, offset, end int64) (string, int64, error) {
+	interval := chunkRetryInterval
+	for i := 0; ; i++ {
+		nextLocation, nextOffset, err := t.pushChunk(src, dst, srcRepo, dstRepo, digest, size, location, offset, end)
+		if err == nil {
+			return nextLocation, nextOffset, nil
+		}
+		if i >= maxChunkRetries {
+			return "", 0, err
+		}
+		t.logger.Warningf("failed to copy the bytes [%d, %d] of blob %s: %v, retry after %v", offset, end, digest, err, interval)
+		time.Sleep(interval)
+		interval = interval * 2
+		if t.shouldStop() {
+			return "", 0, errors.New("the job is stopped")
+		}
+
+		loc, off, e := dst.GetBlobUploadStatus(dstRepo, location)
+		if e != nil {
+			t.logger.Warningf("failed to get the status of the upload session of blob %s: %v", digest, e)
+			continue
+		}
+		location, offset = loc, off
+		// the whole chunk has been committed
+		if offset > end {
+			return location, offset, nil
+		}
+	}
+}
+
+func (t *transfer) pushChunk(src, dst adapter.ChunkedBlobRegistry, srcRepo, dstRepo, digest string,
+	size int64, location string, offset, end int64) (string, int64, error) {
+	_, data, err := src.PullBlobChunk(srcRepo, digest, size, offset, end)
+	if err != nil {
+		return "", 0, err
+	}
+	defer data.Close()
+	return dst.PushBlobChunk(dstRepo, location, offset, end-offset+1, t.stats.CountingReader(trans.NewLimitedReader(data, t.limiter, t.limits)))
+}
+
+// the failures of persisting the upload states are ignored as they only
+// affect whether the upload can be resumed when the transfer is retried
+func (t *transfer) getUploadState(digest string) *trans.UploadState {
+	if t.stateStore == nil {
+		return nil
+	}
+	state, err := t.stateStore.Get(digest)
+	if err != nil {
+		t.logger.Warningf("failed to get the upload state of blob %s: %v", digest, err)
+		return nil
+	}
+	return state
+}
+
+func (t *transfer) saveUploadState(state *trans.UploadState) {
+	if t.stateStore == nil {
+		return
+	}
+	if err := t.stateStore.Save(state); err != nil {
+		t.logger.Warningf("failed to save the upload state of blob %s: %v", state.Digest, err)
+	}
+}
+
+func (t *transfer) deleteUploadState(digest string) {
+	if t.stateStore == nil {
+		return
+	}
+	if err := t.stateStore.Delete(digest); err != nil {
+		t.logger.Warningf("failed to delete the upload state of blob %s: %v", digest, err)
+	}
+}
+
 func (t *transfer) pullManifest(repository, reference string) (
 	distribution.Manifest, string, error) {
 	if t.shouldStop() {
@@ -264,6 +594,8 @@ func (t *transfer) pullManifest(repository, reference string) (
 		schema1.MediaTypeSignedManifest,
 		schema2.MediaTypeManifest,
 		manifestlist.MediaTypeManifestList,
+		v1.MediaTypeImageManifest,
+		v1.MediaTypeImageIndex,
 	})
 	if err != nil {
 		t.logger.Errorf("failed to pull the manifest of image %s:%s: %v", repository, reference, err)
@@ -271,47 +603,56 @@ func (t *transfer) pullManifest(repository, reference string) (
 	}
 	t.logger.Infof("the manifest of image %s:%s pulled", repository, reference)
 
-	// this is a solution to work around that harbor doesn't support manifest list
-	return t.handleManifest(manifest, repository, digest)
+	// the manifest list and OCI image index are deserialized as the same type
+	list, ok := manifest.(*manifestlist.DeserializedManifestList)
+	if !ok {
+		return manifest, digest, nil
+	}
+	return t.filterManifestList(list, repository, reference, digest)
 }
 
-// if the media type of the specified manifest is manifest list, just abstract one
-// manifest from the list and return it
-func (t *transfer) handleManifest(manifest distribution.Manifest, repository, digest string) (
+// filter the manifests in the manifest list by the platforms. The original manifest list
+// is returned if all the manifests match to keep the digest unchanged on the destination
+// registry, otherwise a new one only contains the matched manifests is built. Nil is returned
+// if no manifest matches
+func (t *transfer) filterManifestList(list *manifestlist.DeserializedManifestList, repository, reference, digest string) (
 	distribution.Manifest, string, error) {
-	mediaType, _, err := manifest.Payload()
-	if err != nil {
-		t.logger.Errorf("failed to call the payload method for manifest of %s:%s: %v", repository, digest, err)
-		return nil, "", err
+	var descriptors []manifestlist.ManifestDescriptor
+	for _, descriptor := range list.Manifests {
+		platform := descriptor.Platform
+		if model.MatchPlatforms(t.platforms, platform.OS, platform.Architecture, platform.Variant) {
+			descriptors = append(descriptors, descriptor)
+			continue
+		}
+		t.logger.Infof("the manifest %s(os: %s, architecture: %s, variant: %s) doesn't match the platforms, skip",
+			descriptor.Digest.String(), platform.OS, platform.Architecture, platform.Variant)
 	}
-	// manifest
-	if mediaType == schema1.MediaTypeManifest ||
-		mediaType == schema1.MediaTypeSignedManifest ||
-		mediaType == schema2.MediaTypeManifest {
-		return manifest, digest, nil
+	if len(descriptors) == len(list.Manifests) {
+		return list, digest, nil
 	}
-	// manifest list
-	t.logger.Info("trying abstract a manifest from the manifest list...")
-	manifestlist, ok := manifest.(*manifestlist.DeserializedManifestList)
-	if !ok {
-		err := fmt.Errorf("the object isn't a DeserializedManifestList")
-		t.logger.Errorf(err.Error())
+	if len(descriptors) == 0 {
+		t.logger.Warningf("no manifest in the manifest list of image %s:%s matches the platforms, skip", repository, reference)
+		return nil, "", nil
+	}
+
+	mediaType, _, err := list.Payload()
+	if err != nil {
+		t.logger.Errorf("failed to call the payload method for manifest list of %s:%s: %v", repository, reference, err)
 		return nil, "", err
 	}
-	digest = ""
-	for _, reference := range manifestlist.Manifests {
-		if strings.ToLower(reference.Platform.Architecture) == "amd64" &&
-			strings.ToLower(reference.Platform.OS) == "linux" {
-			digest = reference.Digest.String()
-			t.logger.Infof("a manifest(architecture: amd64, os: linux) found, using this one: %s", digest)
-			break
-		}
+	filtered, err := manifestlist.FromDescriptorsWithMediaType(descriptors, mediaType)
+	if err != nil {
+		t.logger.Errorf("failed to build the manifest list for %s:%s: %v", repository, reference, err)
+		return nil, "", err
 	}
-	if len(digest) == 0 {
-		digest = manifest.References()[0].Digest.String()
-		t.logger.Infof("no manifest(architecture: amd64, os: linux) found, using the first one: %s", digest)
+	_, payload, err := filtered.Payload()
+	if err != nil {
+		t.logger.Errorf("failed to call the payload method for manifest list of %s:%s: %v", repository, reference, err)
+		return nil, "", err
 	}
-	return t.pullManifest(repository, digest)
+	t.logger.Infof("%d of %d manifests in the manifest list of image %s:%s match the platforms",
+		len(descriptors), len(list.Manifests), repository, reference)
+	return filtered, godigest.FromBytes(payload).String(), nil
 }
 
 func (t *transfer) exist(repository, tag string) (bool, string, error) {
@@ -340,11 +681,45 @@ func (t *transfer) pushManifest(manifest distribution.Manifest, repository, tag
 			repository, tag, err)
 		return err
 	}
+	t.stats.BytesTransferred += int64(len(payload))
 	t.logger.Infof("the manifest of image %s:%s pushed",
 		repository, tag)
 	return nil
 }
 
+// pull the manifest from the destination registry and compare the digest calculated
+// from its payload with the source one to make sure the content isn't changed
+func (t *transfer) verifyManifest(manifest distribution.Manifest, repository, reference, digest string) error {
+	mediaType, _, err := manifest.Payload()
+	if err != nil {
+		t.logger.Errorf("failed to call the payload method for manifest of %s:%s: %v", repository, reference, err)
+		return err
+	}
+	// the schema1 manifests may be signed again by the destination registry, which changes the digest
+	if mediaType == schema1.MediaTypeManifest || mediaType == schema1.MediaTypeSignedManifest {
+		t.logger.Infof("the manifest of image %s:%s is a schema1 manifest, skip the verification of digest", repository, reference)
+		return nil
+	}
+	pulled, _, err := t.dst.PullManifest(repository, reference, []string{mediaType})
+	if err != nil {
+		t.logger.Errorf("failed to pull the manifest of image %s:%s from the destination registry: %v", repository, reference, err)
+		return err
+	}
+	_, payload, err := pulled.Payload()
+	if err != nil {
+		t.logger.Errorf("failed to call the payload method for manifest of %s:%s: %v", repository, reference, err)
+		return err
+	}
+	if dstDigest := godigest.FromBytes(payload).String(); dstDigest != digest {
+		err = fmt.Errorf("the digest %s of the manifest of image %s:%s on the destination registry doesn't match the source one %s",
+			dstDigest, repository, reference, digest)
+		t.logger.Error(err.Error())
+		return err
+	}
+	t.logger.Infof("the digest %s of the manifest of image %s:%s on the destination registry verified", digest, repository, reference)
+	return nil
+}
+
 func (t *transfer) delete(repo *repository) error {
 	if t.shouldStop() {
 		return nil
@@ -16,16 +16,20 @@ package image
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
 	"github.com/docker/distribution/manifest/schema2"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	pkg_registry "github.com/goharbor/harbor/src/common/utils/registry"
 	"github.com/goharbor/harbor/src/replication/model"
 	trans "github.com/goharbor/harbor/src/replication/transfer"
+	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -155,3 +159,407 @@ func TestDelete(t *testing.T) {
 	err := tr.delete(repo)
 	require.Nil(t, err)
 }
+
+func TestFilterManifestList(t *testing.T) {
+	list, err := manifestlist.FromDescriptors([]manifestlist.ManifestDescriptor{
+		{
+			Descriptor: distribution.Descriptor{
+				MediaType: schema2.MediaTypeManifest,
+				Digest:    "sha256:c6b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7",
+				Size:      527,
+			},
+			Platform: manifestlist.PlatformSpec{
+				OS:           "linux",
+				Architecture: "amd64",
+			},
+		},
+		{
+			Descriptor: distribution.Descriptor{
+				MediaType: schema2.MediaTypeManifest,
+				Digest:    "sha256:e692418e4cbaf90ca69d05a66403747baa33ee08806650b51fab815ad7fc331f",
+				Size:      527,
+			},
+			Platform: manifestlist.PlatformSpec{
+				OS:           "linux",
+				Architecture: "arm64",
+				Variant:      "v8",
+			},
+		},
+	})
+	require.Nil(t, err)
+	_, payload, err := list.Payload()
+	require.Nil(t, err)
+	dgt := digest.FromBytes(payload).String()
+
+	tr := &transfer{
+		logger: log.DefaultLogger(),
+	}
+
+	// no platforms, the original one is returned
+	manifest, d, err := tr.filterManifestList(list, "library/hello-world", "latest", dgt)
+	require.Nil(t, err)
+	assert.Equal(t, list, manifest)
+	assert.Equal(t, dgt, d)
+
+	// all manifests match
+	tr.platforms = []*model.Platform{
+		{
+			OS: "linux",
+		},
+	}
+	manifest, d, err = tr.filterManifestList(list, "library/hello-world", "latest", dgt)
+	require.Nil(t, err)
+	assert.Equal(t, list, manifest)
+	assert.Equal(t, dgt, d)
+
+	// part of manifests match
+	tr.platforms = []*model.Platform{
+		{
+			OS:           "linux",
+			Architecture: "arm64",
+		},
+	}
+	manifest, d, err = tr.filterManifestList(list, "library/hello-world", "latest", dgt)
+	require.Nil(t, err)
+	require.NotNil(t, manifest)
+	require.Equal(t, 1, len(manifest.References()))
+	assert.Equal(t, "sha256:e692418e4cbaf90ca69d05a66403747baa33ee08806650b51fab815ad7fc331f",
+		manifest.References()[0].Digest.String())
+	mediaType, payload, err := manifest.Payload()
+	require.Nil(t, err)
+	assert.Equal(t, manifestlist.MediaTypeManifestList, mediaType)
+	assert.Equal(t, digest.FromBytes(payload).String(), d)
+
+	// no manifest matches
+	tr.platforms = []*model.Platform{
+		{
+			OS: "windows",
+		},
+	}
+	manifest, d, err = tr.filterManifestList(list, "library/hello-world", "latest", dgt)
+	require.Nil(t, err)
+	assert.Nil(t, manifest)
+	assert.Equal(t, "", d)
+}
+
+type fakeChunkedRegistry struct {
+	fakeRegistry
+	blob     []byte
+	received []byte
+	// the count of failures the chunk pushing simulates
+	failures  int
+	initiated int
+	completed bool
+}
+
+func (f *fakeChunkedRegistry) PullBlobChunk(repository, digest string, blobSize, start, end int64) (int64, io.ReadCloser, error) {
+	return end - start + 1, ioutil.NopCloser(bytes.NewReader(f.blob[start : end+1])), nil
+}
+
+func (f *fakeChunkedRegistry) InitiateBlobUpload(repository string) (string, error) {
+	f.initiated++
+	return "/v2/destination/blobs/uploads/uuid", nil
+}
+
+func (f *fakeChunkedRegistry) GetBlobUploadStatus(repository, location string) (string, int64, error) {
+	return location, int64(len(f.received)), nil
+}
+
+func (f *fakeChunkedRegistry) PushBlobChunk(repository, location string, offset, size int64, chunk io.Reader) (string, int64, error) {
+	data, err := ioutil.ReadAll(chunk)
+	if err != nil {
+		return "", 0, err
+	}
+	if f.failures > 0 {
+		f.failures--
+		// part of the chunk is committed before the failure
+		f.received = append(f.received, data[:len(data)/2]...)
+		return "", 0, errors.New("error")
+	}
+	f.received = append(f.received[:offset], data...)
+	return location, int64(len(f.received)), nil
+}
+
+func (f *fakeChunkedRegistry) CompleteBlobUpload(repository, location, digest string) error {
+	f.completed = true
+	return nil
+}
+
+type fakeUploadStateStore struct {
+	states map[string]*trans.UploadState
+}
+
+func (f *fakeUploadStateStore) Get(digest string) (*trans.UploadState, error) {
+	return f.states[digest], nil
+}
+
+func (f *fakeUploadStateStore) Save(state *trans.UploadState) error {
+	f.states[state.Digest] = state
+	return nil
+}
+
+func (f *fakeUploadStateStore) Delete(digest string) error {
+	delete(f.states, digest)
+	return nil
+}
+
+func TestCopyBlobByChunks(t *testing.T) {
+	defaultChunkSize, defaultInterval := chunkSize, chunkRetryInterval
+	defer func() {
+		chunkSize, chunkRetryInterval = defaultChunkSize, defaultInterval
+	}()
+	chunkSize, chunkRetryInterval = 4, time.Millisecond
+
+	blob := []byte("0123456789abcdef0123")
+	src := &fakeChunkedRegistry{blob: blob}
+	dst := &fakeChunkedRegistry{failures: 2}
+	store := &fakeUploadStateStore{states: map[string]*trans.UploadState{}}
+	tr := &transfer{
+		logger:    log.DefaultLogger(),
+		isStopped: func() bool { return false },
+		src:       src,
+		dst:       dst,
+	}
+	tr.SetUploadStateStore(store)
+	err := tr.copyBlob("source", "destination", "sha256:digest", int64(len(blob)))
+	require.Nil(t, err)
+	assert.Equal(t, blob, dst.received)
+	assert.Equal(t, 1, dst.initiated)
+	assert.True(t, dst.completed)
+	assert.Equal(t, 0, len(store.states))
+
+	// resume from the persisted offset
+	dst = &fakeChunkedRegistry{received: append([]byte{}, blob[:8]...)}
+	store.states["sha256:digest"] = &trans.UploadState{
+		Repository: "destination",
+		Digest:     "sha256:digest",
+		Location:   "/v2/destination/blobs/uploads/uuid",
+		Offset:     8,
+	}
+	tr.dst = dst
+	err = tr.copyBlob("source", "destination", "sha256:digest", int64(len(blob)))
+	require.Nil(t, err)
+	assert.Equal(t, blob, dst.received)
+	assert.Equal(t, 0, dst.initiated)
+	assert.True(t, dst.completed)
+
+	// the retries of one chunk are exhausted
+	dst = &fakeChunkedRegistry{failures: maxChunkRetries + 1}
+	tr.dst = dst
+	err = tr.copyBlob("source", "destination", "sha256:digest", int64(len(blob)))
+	require.NotNil(t, err)
+	assert.False(t, dst.completed)
+}
+
+type fakeMountableRegistry struct {
+	fakeRegistry
+	mountable bool
+	mounted   []string
+}
+
+func (f *fakeMountableRegistry) MountBlob(repository, digest, from string) (bool, error) {
+	if !f.mountable {
+		return false, nil
+	}
+	f.mounted = append(f.mounted, digest)
+	return true, nil
+}
+
+type fakeBlobLocator struct {
+	locations map[string]string
+}
+
+func (f *fakeBlobLocator) Locate(registry, digest string) (string, error) {
+	return f.locations[registry+"/"+digest], nil
+}
+
+func (f *fakeBlobLocator) Record(registry, digest, repository string) error {
+	f.locations[registry+"/"+digest] = repository
+	return nil
+}
+
+func TestMountBlob(t *testing.T) {
+	dst := &fakeMountableRegistry{mountable: true}
+	locator := &fakeBlobLocator{locations: map[string]string{}}
+	tr := &transfer{
+		logger:      log.DefaultLogger(),
+		isStopped:   func() bool { return false },
+		src:         &fakeRegistry{},
+		dst:         dst,
+		dstRegistry: "https://registry.com",
+	}
+	tr.SetBlobLocator(locator)
+
+	// no location recorded, copy the blob and record the location
+	err := tr.copyBlob("source", "destination1", "sha256:digest", 1)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(dst.mounted))
+	assert.Equal(t, "destination1", locator.locations["https://registry.com/sha256:digest"])
+
+	// mounted from the recorded repository
+	err = tr.copyBlob("source", "destination2", "sha256:digest", 1)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"sha256:digest"}, dst.mounted)
+	assert.Equal(t, "destination2", locator.locations["https://registry.com/sha256:digest"])
+
+	// cannot be mounted, copy it
+	dst.mountable = false
+	err = tr.copyBlob("source", "destination3", "sha256:digest", 1)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(dst.mounted))
+	assert.Equal(t, "destination3", locator.locations["https://registry.com/sha256:digest"])
+}
+
+type fakeReadingRegistry struct {
+	fakeRegistry
+	existingBlobs map[string]bool
+}
+
+func (f *fakeReadingRegistry) BlobExist(repository, digest string) (bool, error) {
+	return f.existingBlobs[digest], nil
+}
+
+func (f *fakeReadingRegistry) PushBlob(repository, digest string, size int64, blob io.Reader) error {
+	_, err := ioutil.ReadAll(blob)
+	return err
+}
+
+type fakeStatisticsReporter struct {
+	stats *trans.Statistics
+}
+
+func (f *fakeStatisticsReporter) Report(stats *trans.Statistics) error {
+	f.stats = stats
+	return nil
+}
+
+func TestStatistics(t *testing.T) {
+	dst := &fakeReadingRegistry{
+		existingBlobs: map[string]bool{
+			"sha256:b5b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7": true,
+		},
+	}
+	reporter := &fakeStatisticsReporter{}
+	tr := &transfer{
+		logger:    log.DefaultLogger(),
+		isStopped: func() bool { return false },
+		src:       &fakeRegistry{},
+		dst:       dst,
+	}
+	tr.SetStatisticsReporter(reporter)
+
+	src := &repository{
+		repository: "source",
+		tags:       []string{"a1", "a2"},
+	}
+	dstRepo := &repository{
+		repository: "destination",
+		tags:       []string{"b1", "b2"},
+	}
+	// the image "destination:b1" already exists, only "b2" is copied
+	err := tr.copy(src, dstRepo, true)
+	require.Nil(t, err)
+	tr.reportStatistics(time.Now().Add(-time.Second))
+
+	manifest, digest, err := (&fakeRegistry{}).PullManifest("source", "a2", nil)
+	require.Nil(t, err)
+	_, payload, err := manifest.Payload()
+	require.Nil(t, err)
+
+	require.NotNil(t, reporter.stats)
+	assert.Equal(t, 3, reporter.stats.BlobsCopied)
+	assert.Equal(t, 1, reporter.stats.BlobsSkipped)
+	assert.Equal(t, int64(3+len(payload)), reporter.stats.BytesTransferred)
+	assert.Equal(t, []string{digest}, reporter.stats.ManifestDigests)
+	assert.True(t, reporter.stats.Duration >= time.Second)
+	assert.True(t, reporter.stats.Throughput() > 0)
+}
+
+func TestVerifyManifest(t *testing.T) {
+	tr := &transfer{
+		logger:    log.DefaultLogger(),
+		isStopped: func() bool { return false },
+		dst:       &fakeRegistry{},
+	}
+	manifest, _, err := (&fakeRegistry{}).PullManifest("source", "a1", nil)
+	require.Nil(t, err)
+	_, payload, err := manifest.Payload()
+	require.Nil(t, err)
+
+	// the digest matches
+	err = tr.verifyManifest(manifest, "destination", "b2", digest.FromBytes(payload).String())
+	require.Nil(t, err)
+
+	// the digest doesn't match
+	err = tr.verifyManifest(manifest, "destination", "b2", "sha256:c6b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7")
+	require.NotNil(t, err)
+}
+
+type fakeMetadataRegistry struct {
+	fakeRegistry
+	metadata *model.Metadata
+	put      map[string]*model.Metadata
+}
+
+func (f *fakeMetadataRegistry) GetMetadata(repository string, tags []string, sync *model.MetadataSync) (*model.Metadata, error) {
+	return f.metadata, nil
+}
+
+func (f *fakeMetadataRegistry) PutMetadata(repository string, metadata *model.Metadata, sync *model.MetadataSync) error {
+	f.put[repository] = metadata
+	return nil
+}
+
+func TestSyncMetadata(t *testing.T) {
+	src := &fakeMetadataRegistry{
+		metadata: &model.Metadata{
+			Description: "description",
+			Tags: []*model.TagMetadata{
+				{Name: "a1", SignedDigest: "sha256:digest"},
+				{Name: "a2"},
+			},
+		},
+	}
+	dst := &fakeMetadataRegistry{
+		put: map[string]*model.Metadata{},
+	}
+	tr := &transfer{
+		logger:    log.DefaultLogger(),
+		isStopped: func() bool { return false },
+		src:       src,
+		dst:       dst,
+	}
+	srcRepo := &repository{
+		repository: "source",
+		tags:       []string{"a1", "a2"},
+	}
+	dstRepo := &repository{
+		repository: "destination",
+		tags:       []string{"b1", "b2"},
+	}
+
+	// not enabled
+	err := tr.syncMetadata(srcRepo, dstRepo)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(dst.put))
+
+	// the tags are mapped to the destination ones
+	tr.metadataSync = &model.MetadataSync{
+		Description: true,
+		Signatures:  true,
+	}
+	err = tr.syncMetadata(srcRepo, dstRepo)
+	require.Nil(t, err)
+	metadata := dst.put["destination"]
+	require.NotNil(t, metadata)
+	assert.Equal(t, "description", metadata.Description)
+	require.Equal(t, 2, len(metadata.Tags))
+	assert.Equal(t, "b1", metadata.Tags[0].Name)
+	assert.Equal(t, "sha256:digest", metadata.Tags[0].SignedDigest)
+	assert.Equal(t, "b2", metadata.Tags[1].Name)
+
+	// the destination registry doesn't support the metadata
+	tr.dst = &fakeRegistry{}
+	err = tr.syncMetadata(srcRepo, dstRepo)
+	require.Nil(t, err)
+}
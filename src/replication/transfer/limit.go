@@ -0,0 +1,138 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/replication/model"
+)
+
+// Limiter limits the concurrency and bandwidth of the transfers. The
+// implementations should share the limits across all the job workers
+type Limiter interface {
+	// Acquire tries to take one of the "max" slots identified by the key without
+	// blocking, returns whether the slot is acquired and the function to release it
+	Acquire(key string, max int) (bool, func(), error)
+	// Take takes n tokens from the bucket identified by the key, the bucket is
+	// refilled with "rate" tokens per second. Returns how long the caller should
+	// wait before going on
+	Take(key string, rate int64, n int64) (time.Duration, error)
+}
+
+// Limitable defines an interface for the transfers whose concurrency
+// and bandwidth can be limited by the limiter
+type Limitable interface {
+	SetLimiter(Limiter)
+}
+
+// AcquireSlots acquires the slots of all the concurrency limits without blocking and returns
+// the function to release them. If any slot isn't available, the acquired ones are released to
+// avoid the deadlock between the tasks sharing the same limits, and a requeue error is returned
+// so that jobservice puts the job back to the queue rather than holding a worker while waiting
+func AcquireSlots(limiter Limiter, limits []*model.Limit, logger Logger) (func(), error) {
+	var releases []func()
+	release := func() {
+		for _, r := range releases {
+			r()
+		}
+		releases = nil
+	}
+	if limiter == nil {
+		return release, nil
+	}
+	for _, limit := range limits {
+		if limit.MaxConcurrentTasks <= 0 {
+			continue
+		}
+		ok, r, err := limiter.Acquire(limit.Key, limit.MaxConcurrentTasks)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if !ok {
+			release()
+			reason := fmt.Sprintf("the max concurrency %d of %s is reached", limit.MaxConcurrentTasks, limit.Key)
+			logger.Infof("%s, the transfer is requeued", reason)
+			return nil, errs.RequeueError(reason)
+		}
+		releases = append(releases, r)
+	}
+	return release, nil
+}
+
+// NewLimitedReader returns a reader whose reading rate doesn't exceed
+// the bandwidth limits. The original reader is returned if there is no
+// bandwidth limit
+func NewLimitedReader(reader io.Reader, limiter Limiter, limits []*model.Limit) io.Reader {
+	if limiter == nil {
+		return reader
+	}
+	var bandwidthLimits []*model.Limit
+	var minRate int64
+	for _, limit := range limits {
+		if limit.MaxBytesPerSecond <= 0 {
+			continue
+		}
+		bandwidthLimits = append(bandwidthLimits, limit)
+		if minRate == 0 || limit.MaxBytesPerSecond < minRate {
+			minRate = limit.MaxBytesPerSecond
+		}
+	}
+	if len(bandwidthLimits) == 0 {
+		return reader
+	}
+	return &limitedReader{
+		reader:  reader,
+		limiter: limiter,
+		limits:  bandwidthLimits,
+		minRate: minRate,
+	}
+}
+
+type limitedReader struct {
+	reader  io.Reader
+	limiter Limiter
+	limits  []*model.Limit
+	minRate int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	// read at most the bytes allowed by the lowest rate per second
+	if int64(len(p)) > l.minRate {
+		p = p[:l.minRate]
+	}
+	n, err := l.reader.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+	var wait time.Duration
+	for _, limit := range l.limits {
+		w, e := l.limiter.Take(limit.Key, limit.MaxBytesPerSecond, int64(n))
+		if e != nil {
+			return n, e
+		}
+		if w > wait {
+			wait = w
+		}
+	}
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
@@ -0,0 +1,117 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/replication/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLimiter struct {
+	slots    map[string]int
+	taken    map[string]int64
+	rejected int
+}
+
+func (f *fakeLimiter) Acquire(key string, max int) (bool, func(), error) {
+	if f.rejected > 0 {
+		f.rejected--
+		return false, nil, nil
+	}
+	if f.slots[key] >= max {
+		return false, nil, nil
+	}
+	f.slots[key]++
+	return true, func() { f.slots[key]-- }, nil
+}
+
+func (f *fakeLimiter) Take(key string, rate int64, n int64) (time.Duration, error) {
+	f.taken[key] += n
+	return 0, nil
+}
+
+func TestAcquireSlots(t *testing.T) {
+	limiter := &fakeLimiter{
+		slots:    map[string]int{},
+		rejected: 1,
+	}
+	limits := []*model.Limit{
+		{
+			Key:                "policy:1",
+			MaxConcurrentTasks: 1,
+		},
+		{
+			Key:               "registry:1",
+			MaxBytesPerSecond: 1024,
+		},
+	}
+	// nil limiter
+	release, err := AcquireSlots(nil, limits, log.DefaultLogger())
+	require.Nil(t, err)
+	release()
+
+	// requeued rather than waiting for the slot
+	_, err = AcquireSlots(limiter, limits, log.DefaultLogger())
+	require.NotNil(t, err)
+	assert.True(t, errs.IsRequeueError(err))
+
+	release, err = AcquireSlots(limiter, limits, log.DefaultLogger())
+	require.Nil(t, err)
+	assert.Equal(t, 1, limiter.slots["policy:1"])
+	assert.Equal(t, 0, limiter.slots["registry:1"])
+
+	// the slot is taken
+	_, err = AcquireSlots(limiter, limits, log.DefaultLogger())
+	assert.True(t, errs.IsRequeueError(err))
+	assert.Equal(t, 1, limiter.slots["policy:1"])
+
+	release()
+	assert.Equal(t, 0, limiter.slots["policy:1"])
+}
+
+func TestNewLimitedReader(t *testing.T) {
+	limiter := &fakeLimiter{
+		taken: map[string]int64{},
+	}
+	reader := bytes.NewReader([]byte("0123456789"))
+
+	// no bandwidth limit
+	r := NewLimitedReader(reader, limiter, []*model.Limit{{Key: "policy:1", MaxConcurrentTasks: 1}})
+	assert.Equal(t, reader, r)
+
+	r = NewLimitedReader(reader, limiter, []*model.Limit{
+		{
+			Key:               "policy:1",
+			MaxBytesPerSecond: 4,
+		},
+		{
+			Key:               "registry:1",
+			MaxBytesPerSecond: 8,
+		},
+	})
+	data, err := ioutil.ReadAll(r)
+	require.Nil(t, err)
+	assert.Equal(t, "0123456789", string(data))
+	assert.Equal(t, int64(10), limiter.taken["policy:1"])
+	assert.Equal(t, int64(10), limiter.taken["registry:1"])
+}
@@ -0,0 +1,73 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer
+
+import (
+	"io"
+	"time"
+)
+
+// Statistics holds the statistics of the data moved by one transfer
+type Statistics struct {
+	// the bytes read from the source registry and sent to the destination
+	BytesTransferred int64
+	// the blobs uploaded to the destination registry
+	BlobsCopied int
+	// the blobs skipped as they already exist on the destination registry
+	BlobsSkipped int
+	// the digests of the manifests pushed to the destination registry
+	ManifestDigests []string
+	// the time spent on the transfer, the time waiting for the concurrency slots excluded
+	Duration time.Duration
+}
+
+// Throughput returns the average bytes transferred per second
+func (s *Statistics) Throughput() int64 {
+	seconds := s.Duration.Seconds()
+	if seconds <= 0 {
+		return 0
+	}
+	return int64(float64(s.BytesTransferred) / seconds)
+}
+
+// CountingReader returns a reader which adds the bytes read from the reader to the statistics
+func (s *Statistics) CountingReader(reader io.Reader) io.Reader {
+	return &countingReader{
+		reader: reader,
+		stats:  s,
+	}
+}
+
+type countingReader struct {
+	reader io.Reader
+	stats  *Statistics
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.stats.BytesTransferred += int64(n)
+	return n, err
+}
+
+// StatisticsReporter receives the statistics when the transfer is done
+type StatisticsReporter interface {
+	Report(*Statistics) error
+}
+
+// Measurable defines an interface for the transfers which can report
+// the statistics of the data moved to the reporter
+type Measurable interface {
+	SetStatisticsReporter(StatisticsReporter)
+}
@@ -0,0 +1,48 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountingReader(t *testing.T) {
+	stats := &Statistics{}
+	data, err := ioutil.ReadAll(stats.CountingReader(bytes.NewReader([]byte("hello"))))
+	require.Nil(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, int64(5), stats.BytesTransferred)
+
+	_, err = ioutil.ReadAll(stats.CountingReader(bytes.NewReader([]byte("world"))))
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), stats.BytesTransferred)
+}
+
+func TestThroughput(t *testing.T) {
+	// no duration
+	stats := &Statistics{
+		BytesTransferred: 1024,
+	}
+	assert.Equal(t, int64(0), stats.Throughput())
+
+	stats.Duration = 2 * time.Second
+	assert.Equal(t, int64(512), stats.Throughput())
+}
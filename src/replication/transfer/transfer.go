@@ -61,6 +61,48 @@ type Logger interface {
 // process is stopped
 type StopFunc func() bool
 
+// UploadState is the state of a chunked blob upload session
+type UploadState struct {
+	Repository string `json:"repository"`
+	Digest     string `json:"digest"`
+	Location   string `json:"location"`
+	Offset     int64  `json:"offset"`
+}
+
+// UploadStateStore persists the states of the chunked blob upload sessions,
+// with it the uploads can be resumed from the last committed offsets when
+// the transfer is retried
+type UploadStateStore interface {
+	// Get returns the state of the upload session for the blob, nil is
+	// returned if there is no one
+	Get(digest string) (*UploadState, error)
+	Save(state *UploadState) error
+	Delete(digest string) error
+}
+
+// Resumable defines an interface for the transfers which can resume
+// the uploads from the states persisted in the store
+type Resumable interface {
+	SetUploadStateStore(UploadStateStore)
+}
+
+// BlobLocator records the repositories that contain the blobs on the registries.
+// With it the blobs can be mounted from the recorded repositories rather than
+// being uploaded again when they are needed by other repositories
+type BlobLocator interface {
+	// Locate returns the repository containing the blob on the registry, empty
+	// string is returned if there is no record
+	Locate(registry, digest string) (string, error)
+	// Record that the repository on the registry contains the blob
+	Record(registry, digest, repository string) error
+}
+
+// Mountable defines an interface for the transfers which can mount
+// the blobs located by the locator
+type Mountable interface {
+	SetBlobLocator(BlobLocator)
+}
+
 // RegisterFactory registers one transfer factory to the registry
 func RegisterFactory(name model.ResourceType, factory Factory) error {
 	if !name.Valid() {